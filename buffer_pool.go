@@ -2,6 +2,7 @@ package quic
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/quic-go/quic-go/internal/protocol"
 )
@@ -13,6 +14,13 @@ type packetBuffer struct {
 	// It doesn't support concurrent use.
 	// It is > 1 when used for coalesced packet.
 	refCount int
+
+	// When receiving packets using GRO, every packet of a coalesced datagram gets its own packetBuffer,
+	// with Data pointing into the GRO buffer (the parent).
+	parent *packetBuffer
+	// numSegments counts the packets using a GRO buffer, plus one for the reader.
+	// Unlike refCount, it is safe for concurrent use, since the packets are handled by different connections.
+	numSegments atomic.Int32
 }
 
 // Split increases the refCount.
@@ -55,6 +63,14 @@ func (b *packetBuffer) Len() protocol.ByteCount { return protocol.ByteCount(len(
 func (b *packetBuffer) Cap() protocol.ByteCount { return protocol.ByteCount(cap(b.Data)) }
 
 func (b *packetBuffer) putBack() {
+	if b.parent != nil {
+		parent := b.parent
+		b.parent = nil
+		b.Data = nil
+		groSegmentPool.Put(b)
+		parent.releaseGROSegment()
+		return
+	}
 	if cap(b.Data) == protocol.MaxPacketBufferSize {
 		bufferPool.Put(b)
 		return
@@ -63,10 +79,14 @@ func (b *packetBuffer) putBack() {
 		largeBufferPool.Put(b)
 		return
 	}
+	if cap(b.Data) == protocol.MaxGROBufferSize {
+		groBufferPool.Put(b)
+		return
+	}
 	panic("putPacketBuffer called with packet of wrong size!")
 }
 
-var bufferPool, largeBufferPool sync.Pool
+var bufferPool, largeBufferPool, groBufferPool, groSegmentPool sync.Pool
 
 func getPacketBuffer() *packetBuffer {
 	buf := bufferPool.Get().(*packetBuffer)
@@ -82,6 +102,36 @@ func getLargePacketBuffer() *packetBuffer {
 	return buf
 }
 
+// getGROBuffer returns a buffer large enough to receive a coalesced GRO datagram.
+// It is held by the reader until releaseGROSegment is called,
+// and only put back into the pool once all packets split off using newGROSegment are released.
+func getGROBuffer() *packetBuffer {
+	buf := groBufferPool.Get().(*packetBuffer)
+	buf.refCount = 1
+	buf.numSegments.Store(1)
+	buf.Data = buf.Data[:0]
+	return buf
+}
+
+// newGROSegment returns a packet buffer for a single packet of a datagram received using GRO.
+// The data is not copied: it must be a slice of the GRO buffer.
+func (b *packetBuffer) newGROSegment(data []byte) *packetBuffer {
+	b.numSegments.Add(1)
+	seg := groSegmentPool.Get().(*packetBuffer)
+	seg.refCount = 1
+	seg.parent = b
+	seg.Data = data
+	return seg
+}
+
+// releaseGROSegment releases a reference to the GRO buffer.
+// The buffer is put back into the pool once it is not referenced any more.
+func (b *packetBuffer) releaseGROSegment() {
+	if b.numSegments.Add(-1) == 0 {
+		groBufferPool.Put(b)
+	}
+}
+
 func init() {
 	bufferPool.New = func() any {
 		return &packetBuffer{Data: make([]byte, 0, protocol.MaxPacketBufferSize)}
@@ -89,4 +139,8 @@ func init() {
 	largeBufferPool.New = func() any {
 		return &packetBuffer{Data: make([]byte, 0, protocol.MaxLargePacketBufferSize)}
 	}
+	groBufferPool.New = func() any {
+		return &packetBuffer{Data: make([]byte, 0, protocol.MaxGROBufferSize)}
+	}
+	groSegmentPool.New = func() any { return &packetBuffer{} }
 }
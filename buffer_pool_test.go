@@ -18,6 +18,11 @@ func TestBufferPoolSizes(t *testing.T) {
 	buf2 := getLargePacketBuffer()
 	require.Equal(t, protocol.MaxLargePacketBufferSize, cap(buf2.Data))
 	require.Zero(t, buf2.Len())
+
+	buf3 := getGROBuffer()
+	require.Equal(t, protocol.MaxGROBufferSize, cap(buf3.Data))
+	require.Zero(t, buf3.Len())
+	buf3.Release()
 }
 
 func TestBufferPoolRelease(t *testing.T) {
@@ -42,3 +47,26 @@ func TestBufferPoolSplitting(t *testing.T) {
 	buf.Decrement()
 	require.Panics(t, func() { buf.Decrement() })
 }
+
+func TestBufferPoolGROSegments(t *testing.T) {
+	buf := getGROBuffer()
+	buf.Data = append(buf.Data, []byte("foobar")...)
+	seg1 := buf.newGROSegment(buf.Data[:3:3])
+	seg2 := buf.newGROSegment(buf.Data[3:6:6])
+	require.Equal(t, []byte("foo"), seg1.Data)
+	require.Equal(t, []byte("bar"), seg2.Data)
+	// the reader is done splitting the datagram
+	buf.releaseGROSegment()
+	require.EqualValues(t, 2, buf.numSegments.Load())
+
+	seg1.Release()
+	require.EqualValues(t, 1, buf.numSegments.Load())
+	require.Nil(t, seg1.parent)
+	// splitting a coalesced packet works the same way as for other buffers
+	seg2.Split()
+	seg2.Decrement()
+	seg2.MaybeRelease()
+	require.EqualValues(t, 1, buf.numSegments.Load())
+	seg2.Release()
+	require.Zero(t, buf.numSegments.Load())
+}
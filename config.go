@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -45,12 +46,29 @@ func validateConfig(config *Config) error {
 	if config.InitialPacketSize > protocol.MaxPacketBufferSize {
 		config.InitialPacketSize = protocol.MaxPacketBufferSize
 	}
+	if config.KeyUpdatePacketInterval > protocol.MaxKeyUpdateInterval {
+		config.KeyUpdatePacketInterval = protocol.MaxKeyUpdateInterval
+	}
 	// check that all QUIC versions are actually supported
 	for _, v := range config.Versions {
 		if !protocol.IsValidVersion(v) {
 			return fmt.Errorf("invalid QUIC version: %s", v)
 		}
 	}
+	if pa := config.PreferredAddress; pa != nil {
+		if pa.Transport == nil {
+			return errors.New("preferred address: missing Transport")
+		}
+		if !pa.IPv4.IsValid() && !pa.IPv6.IsValid() {
+			return errors.New("preferred address: no address set")
+		}
+		if pa.IPv4.IsValid() && !pa.IPv4.Addr().Is4() {
+			return fmt.Errorf("preferred address: %s is not an IPv4 address", pa.IPv4)
+		}
+		if pa.IPv6.IsValid() && (!pa.IPv6.Addr().Is6() || pa.IPv6.Addr().Is4In6()) {
+			return fmt.Errorf("preferred address: %s is not an IPv6 address", pa.IPv6)
+		}
+	}
 	return nil
 }
 
@@ -104,6 +122,10 @@ func populateConfig(config *Config) *Config {
 	if initialPacketSize == 0 {
 		initialPacketSize = protocol.InitialPacketSize
 	}
+	keyUpdateInterval := config.KeyUpdatePacketInterval
+	if keyUpdateInterval == 0 {
+		keyUpdateInterval = protocol.KeyUpdateInterval
+	}
 
 	return &Config{
 		GetConfigForClient:               config.GetConfigForClient,
@@ -122,8 +144,17 @@ func populateConfig(config *Config) *Config {
 		EnableDatagrams:                  config.EnableDatagrams,
 		InitialPacketSize:                initialPacketSize,
 		DisablePathMTUDiscovery:          config.DisablePathMTUDiscovery,
+		DisableSpinBit:                   config.DisableSpinBit,
+		DisableQUICBitGreasing:           config.DisableQUICBitGreasing,
+		KeyUpdatePacketInterval:          keyUpdateInterval,
+		KeyUpdatePeriod:                  config.KeyUpdatePeriod,
 		EnableStreamResetPartialDelivery: config.EnableStreamResetPartialDelivery,
+		EnableAckFrequency:               config.EnableAckFrequency,
+		EnableMultipath:                  config.EnableMultipath,
+		MultipathScheduler:               config.MultipathScheduler,
+		PreferredAddress:                 config.PreferredAddress,
 		Allow0RTT:                        config.Allow0RTT,
+		CongestionControl:                config.CongestionControl,
 		Tracer:                           config.Tracer,
 	}
 }
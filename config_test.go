@@ -2,6 +2,7 @@ package quic
 
 import (
 	"context"
+	"net/netip"
 	"reflect"
 	"testing"
 	"time"
@@ -49,6 +50,32 @@ func TestConfigValidation(t *testing.T) {
 		require.Equal(t, uint64(quicvarint.Max), conf.MaxConnectionReceiveWindow)
 	})
 
+	t.Run("preferred address", func(t *testing.T) {
+		tr := &Transport{}
+		require.NoError(t, validateConfig(&Config{
+			PreferredAddress: &PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:5678"), Transport: tr},
+		}))
+		require.NoError(t, validateConfig(&Config{
+			PreferredAddress: &PreferredAddress{IPv6: netip.MustParseAddrPort("[2001:db8::1]:5678"), Transport: tr},
+		}))
+		require.ErrorContains(t,
+			validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:5678")}}),
+			"missing Transport",
+		)
+		require.ErrorContains(t,
+			validateConfig(&Config{PreferredAddress: &PreferredAddress{Transport: tr}}),
+			"no address set",
+		)
+		require.ErrorContains(t,
+			validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: netip.MustParseAddrPort("[2001:db8::1]:5678"), Transport: tr}}),
+			"not an IPv4 address",
+		)
+		require.ErrorContains(t,
+			validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv6: netip.MustParseAddrPort("1.2.3.4:5678"), Transport: tr}}),
+			"not an IPv6 address",
+		)
+	})
+
 	t.Run("initial packet size", func(t *testing.T) {
 		// not set
 		conf := &Config{InitialPacketSize: 0}
@@ -65,6 +92,17 @@ func TestConfigValidation(t *testing.T) {
 		require.NoError(t, validateConfig(conf))
 		require.Equal(t, uint16(protocol.MaxPacketBufferSize), conf.InitialPacketSize)
 	})
+
+	t.Run("key update interval", func(t *testing.T) {
+		conf := &Config{KeyUpdatePacketInterval: 1000}
+		require.NoError(t, validateConfig(conf))
+		require.Equal(t, uint64(1000), conf.KeyUpdatePacketInterval)
+
+		// too large
+		conf = &Config{KeyUpdatePacketInterval: protocol.MaxKeyUpdateInterval + 1}
+		require.NoError(t, validateConfig(conf))
+		require.Equal(t, uint64(protocol.MaxKeyUpdateInterval), conf.KeyUpdatePacketInterval)
+	})
 }
 
 func TestConfigHandshakeIdleTimeout(t *testing.T) {
@@ -86,7 +124,7 @@ func configWithNonZeroNonFunctionFields(t *testing.T) *Config {
 		}
 
 		switch fn := typ.Field(i).Name; fn {
-		case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+		case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer", "CongestionControl", "MultipathScheduler":
 			// Can't compare functions.
 		case "Versions":
 			f.Set(reflect.ValueOf([]Version{1, 2, 3}))
@@ -124,10 +162,24 @@ func configWithNonZeroNonFunctionFields(t *testing.T) *Config {
 			f.Set(reflect.ValueOf(uint16(1350)))
 		case "DisablePathMTUDiscovery":
 			f.Set(reflect.ValueOf(true))
+		case "DisableSpinBit":
+			f.Set(reflect.ValueOf(true))
+		case "DisableQUICBitGreasing":
+			f.Set(reflect.ValueOf(true))
+		case "KeyUpdatePacketInterval":
+			f.Set(reflect.ValueOf(uint64(1000)))
+		case "KeyUpdatePeriod":
+			f.Set(reflect.ValueOf(time.Minute))
 		case "Allow0RTT":
 			f.Set(reflect.ValueOf(true))
 		case "EnableStreamResetPartialDelivery":
 			f.Set(reflect.ValueOf(true))
+		case "EnableAckFrequency":
+			f.Set(reflect.ValueOf(true))
+		case "EnableMultipath":
+			f.Set(reflect.ValueOf(true))
+		case "PreferredAddress":
+			f.Set(reflect.ValueOf(&PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:5678"), Transport: &Transport{}}))
 		default:
 			t.Fatalf("all fields must be accounted for, but saw unknown field %q", fn)
 		}
@@ -185,6 +237,10 @@ func TestConfigDefaultValues(t *testing.T) {
 	require.EqualValues(t, protocol.DefaultMaxIncomingStreams, c.MaxIncomingStreams)
 	require.EqualValues(t, protocol.DefaultMaxIncomingUniStreams, c.MaxIncomingUniStreams)
 	require.False(t, c.DisablePathMTUDiscovery)
+	require.False(t, c.DisableSpinBit)
+	require.False(t, c.DisableQUICBitGreasing)
+	require.EqualValues(t, protocol.KeyUpdateInterval, c.KeyUpdatePacketInterval)
+	require.Zero(t, c.KeyUpdatePeriod)
 	require.Nil(t, c.GetConfigForClient)
 }
 
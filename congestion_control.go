@@ -0,0 +1,265 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/congestion"
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/qlogwriter"
+)
+
+// A ByteCount is a number of bytes.
+type ByteCount = protocol.ByteCount
+
+// A PacketNumber is a QUIC packet number.
+type PacketNumber = protocol.PacketNumber
+
+// RTTStats provides the round-trip time estimates of a connection.
+type RTTStats interface {
+	// MinRTT is the minimum RTT observed on the path.
+	MinRTT() time.Duration
+	// LatestRTT is the most recent RTT sample.
+	LatestRTT() time.Duration
+	// SmoothedRTT is the exponentially weighted moving average of the RTT samples, see RFC 9002, section 5.3.
+	SmoothedRTT() time.Duration
+	// MeanDeviation is the mean deviation of the RTT samples, see RFC 9002, section 5.3.
+	MeanDeviation() time.Duration
+}
+
+// CongestionControlInfo contains the information needed to create a CongestionController.
+// It is passed to the Config.CongestionControl factory function.
+// The built-in congestion controllers can also be created from a CongestionControlInfo that was not
+// provided by quic-go, in which case they use their own RTT estimates, and don't report any statistics.
+type CongestionControlInfo struct {
+	// RTTStats are the RTT estimates of the connection.
+	// The values are updated by the connection as ACKs are received.
+	RTTStats RTTStats
+	// InitialMaxDatagramSize is the initial maximum size of a QUIC packet.
+	// The controller is informed about updates of this value by SetMaxDatagramSize.
+	InitialMaxDatagramSize ByteCount
+
+	rttStats  *utils.RTTStats
+	connStats *utils.ConnectionStats
+	qlogger   qlogwriter.Recorder
+}
+
+// A CongestionController implements a congestion control algorithm.
+// A new CongestionController is created for every connection, and every time the connection migrates to a new path.
+// Its methods are called from the connection's run loop, and therefore don't need to be safe for concurrent use.
+type CongestionController interface {
+	// TimeUntilSend returns the time when the next packet should be sent.
+	// It is used for pacing packets. The zero value means that a packet can be sent immediately.
+	TimeUntilSend(bytesInFlight ByteCount) time.Time
+	// HasPacingBudget says if the pacer allows sending a packet at this time.
+	HasPacingBudget(now time.Time) bool
+	// OnPacketSent is called for every packet sent.
+	// isAckEliciting is false for packets that only contain ACK (and PADDING) frames.
+	OnPacketSent(sentTime time.Time, bytesInFlight ByteCount, pn PacketNumber, bytes ByteCount, isAckEliciting bool)
+	// CanSend says if the congestion window allows sending more data.
+	CanSend(bytesInFlight ByteCount) bool
+	// MaybeExitSlowStart is called after the RTT estimate was updated by an ACK frame.
+	MaybeExitSlowStart()
+	// OnPacketAcked is called for every packet acknowledged by the peer.
+	OnPacketAcked(pn PacketNumber, ackedBytes, priorInFlight ByteCount, eventTime time.Time)
+	// OnCongestionEvent is called when a packet is declared lost,
+	// or when the peer reports an increase of the ECN-CE counter (in which case lostBytes is 0).
+	OnCongestionEvent(pn PacketNumber, lostBytes, priorInFlight ByteCount)
+	// OnRetransmissionTimeout is called when a persistent congestion is detected,
+	// see RFC 9002, section 7.6.
+	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// SetMaxDatagramSize is called when path MTU discovery finds a new maximum packet size.
+	SetMaxDatagramSize(ByteCount)
+
+	// InSlowStart says if the controller is in slow start.
+	InSlowStart() bool
+	// InRecovery says if the controller is in recovery.
+	InRecovery() bool
+	// GetCongestionWindow returns the current size of the congestion window.
+	GetCongestionWindow() ByteCount
+}
+
+// NewRenoCongestionController creates a congestion controller implementing NewReno (RFC 9002, section 7).
+// This is the congestion controller used if Config.CongestionControl is not set.
+func NewRenoCongestionController(info *CongestionControlInfo) CongestionController {
+	rttStats, connStats, maxDatagramSize := info.internals()
+	return &builtinCongestionController{
+		SendAlgorithmWithDebugInfos: congestion.NewCubicSender(
+			congestion.DefaultClock{},
+			rttStats,
+			connStats,
+			maxDatagramSize,
+			true, // use Reno
+			info.recorder(),
+		),
+	}
+}
+
+// NewCubicCongestionController creates a congestion controller implementing CUBIC (RFC 9438).
+func NewCubicCongestionController(info *CongestionControlInfo) CongestionController {
+	rttStats, connStats, maxDatagramSize := info.internals()
+	return &builtinCongestionController{
+		SendAlgorithmWithDebugInfos: congestion.NewCubicSender(
+			congestion.DefaultClock{},
+			rttStats,
+			connStats,
+			maxDatagramSize,
+			false, // use Cubic
+			info.recorder(),
+		),
+	}
+}
+
+// NewBBRCongestionController creates a congestion controller implementing BBR (draft-ietf-ccwg-bbr).
+// BBR builds a model of the path's bottleneck bandwidth and round-trip time,
+// and is therefore more robust against random packet loss than loss-based congestion controllers.
+func NewBBRCongestionController(info *CongestionControlInfo) CongestionController {
+	rttStats, connStats, maxDatagramSize := info.internals()
+	return &builtinCongestionController{
+		SendAlgorithmWithDebugInfos: congestion.NewBBRSender(
+			congestion.DefaultClock{},
+			rttStats,
+			connStats,
+			maxDatagramSize,
+			info.recorder(),
+		),
+	}
+}
+
+// internals returns the values used to initialize the built-in congestion controllers.
+// If the CongestionControlInfo wasn't created by quic-go, new RTT and connection statistics are used.
+func (i *CongestionControlInfo) internals() (*utils.RTTStats, *utils.ConnectionStats, protocol.ByteCount) {
+	var (
+		rttStats        *utils.RTTStats
+		connStats       *utils.ConnectionStats
+		maxDatagramSize protocol.ByteCount
+	)
+	if i != nil {
+		rttStats = i.rttStats
+		connStats = i.connStats
+		maxDatagramSize = i.InitialMaxDatagramSize
+	}
+	if rttStats == nil {
+		rttStats = utils.NewRTTStats()
+	}
+	if connStats == nil {
+		connStats = &utils.ConnectionStats{}
+	}
+	if maxDatagramSize == 0 {
+		maxDatagramSize = protocol.InitialPacketSize
+	}
+	return rttStats, connStats, maxDatagramSize
+}
+
+func (i *CongestionControlInfo) recorder() qlogwriter.Recorder {
+	if i == nil {
+		return nil
+	}
+	return i.qlogger
+}
+
+// newCongestionControllerFactory returns the function used by the sent packet handler
+// to create a congestion controller using the factory set in the config.
+// It returns nil if no factory is set, in which case the default congestion controller is used.
+func newCongestionControllerFactory(
+	factory func(*CongestionControlInfo) CongestionController,
+	rttStats *utils.RTTStats,
+	connStats *utils.ConnectionStats,
+	qlogger qlogwriter.Recorder,
+) func(protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+	if factory == nil {
+		return nil
+	}
+	return func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+		cc := factory(&CongestionControlInfo{
+			RTTStats:               rttStats,
+			InitialMaxDatagramSize: initialMaxDatagramSize,
+			rttStats:               rttStats,
+			connStats:              connStats,
+			qlogger:                qlogger,
+		})
+		if b, ok := cc.(*builtinCongestionController); ok {
+			return b.SendAlgorithmWithDebugInfos
+		}
+		return &congestionControllerAdapter{cc: cc, connStats: connStats}
+	}
+}
+
+// The builtinCongestionController exposes one of the internal congestion controllers
+// as a CongestionController.
+type builtinCongestionController struct {
+	congestion.SendAlgorithmWithDebugInfos
+}
+
+var _ CongestionController = &builtinCongestionController{}
+
+func (c *builtinCongestionController) TimeUntilSend(bytesInFlight ByteCount) time.Time {
+	return c.SendAlgorithmWithDebugInfos.TimeUntilSend(bytesInFlight).ToTime()
+}
+
+func (c *builtinCongestionController) HasPacingBudget(now time.Time) bool {
+	return c.SendAlgorithmWithDebugInfos.HasPacingBudget(monotime.FromTime(now))
+}
+
+func (c *builtinCongestionController) OnPacketSent(sentTime time.Time, bytesInFlight ByteCount, pn PacketNumber, bytes ByteCount, isAckEliciting bool) {
+	c.SendAlgorithmWithDebugInfos.OnPacketSent(monotime.FromTime(sentTime), bytesInFlight, pn, bytes, isAckEliciting)
+}
+
+func (c *builtinCongestionController) OnPacketAcked(pn PacketNumber, ackedBytes, priorInFlight ByteCount, eventTime time.Time) {
+	c.SendAlgorithmWithDebugInfos.OnPacketAcked(pn, ackedBytes, priorInFlight, monotime.FromTime(eventTime))
+}
+
+// The congestionControllerAdapter adapts an application-provided CongestionController
+// to the interface used by the sent packet handler.
+type congestionControllerAdapter struct {
+	cc        CongestionController
+	connStats *utils.ConnectionStats
+}
+
+var _ congestion.SendAlgorithmWithDebugInfos = &congestionControllerAdapter{}
+
+func (a *congestionControllerAdapter) TimeUntilSend(bytesInFlight protocol.ByteCount) monotime.Time {
+	return monotime.FromTime(a.cc.TimeUntilSend(bytesInFlight))
+}
+
+func (a *congestionControllerAdapter) HasPacingBudget(now monotime.Time) bool {
+	return a.cc.HasPacingBudget(now.ToTime())
+}
+
+func (a *congestionControllerAdapter) OnPacketSent(sentTime monotime.Time, bytesInFlight protocol.ByteCount, pn protocol.PacketNumber, bytes protocol.ByteCount, isAckEliciting bool) {
+	a.cc.OnPacketSent(sentTime.ToTime(), bytesInFlight, pn, bytes, isAckEliciting)
+}
+
+func (a *congestionControllerAdapter) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return a.cc.CanSend(bytesInFlight)
+}
+
+func (a *congestionControllerAdapter) MaybeExitSlowStart() { a.cc.MaybeExitSlowStart() }
+
+func (a *congestionControllerAdapter) OnPacketAcked(pn protocol.PacketNumber, ackedBytes, priorInFlight protocol.ByteCount, eventTime monotime.Time) {
+	a.cc.OnPacketAcked(pn, ackedBytes, priorInFlight, eventTime.ToTime())
+}
+
+func (a *congestionControllerAdapter) OnCongestionEvent(pn protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	// The built-in congestion controllers update the loss counters themselves.
+	a.connStats.PacketsLost.Add(1)
+	a.connStats.BytesLost.Add(uint64(lostBytes))
+	a.cc.OnCongestionEvent(pn, lostBytes, priorInFlight)
+}
+
+func (a *congestionControllerAdapter) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	a.cc.OnRetransmissionTimeout(packetsRetransmitted)
+}
+
+func (a *congestionControllerAdapter) SetMaxDatagramSize(s protocol.ByteCount) {
+	a.cc.SetMaxDatagramSize(s)
+}
+
+func (a *congestionControllerAdapter) InSlowStart() bool { return a.cc.InSlowStart() }
+
+func (a *congestionControllerAdapter) InRecovery() bool { return a.cc.InRecovery() }
+
+func (a *congestionControllerAdapter) GetCongestionWindow() protocol.ByteCount {
+	return a.cc.GetCongestionWindow()
+}
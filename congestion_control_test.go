@@ -0,0 +1,110 @@
+package quic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCongestionController struct {
+	CongestionController // embedded to satisfy the interface, all methods used by the test are overridden
+
+	sentTimes  []time.Time
+	ackedTimes []time.Time
+	lost       []PacketNumber
+	window     ByteCount
+}
+
+func (c *recordingCongestionController) OnPacketSent(t time.Time, _ ByteCount, _ PacketNumber, _ ByteCount, _ bool) {
+	c.sentTimes = append(c.sentTimes, t)
+}
+
+func (c *recordingCongestionController) OnPacketAcked(_ PacketNumber, _, _ ByteCount, t time.Time) {
+	c.ackedTimes = append(c.ackedTimes, t)
+}
+
+func (c *recordingCongestionController) OnCongestionEvent(pn PacketNumber, _, _ ByteCount) {
+	c.lost = append(c.lost, pn)
+}
+
+func (c *recordingCongestionController) TimeUntilSend(ByteCount) time.Time { return time.Time{} }
+
+func (c *recordingCongestionController) GetCongestionWindow() ByteCount { return c.window }
+
+func TestCongestionControllerFactory(t *testing.T) {
+	t.Run("no factory", func(t *testing.T) {
+		require.Nil(t, newCongestionControllerFactory(nil, utils.NewRTTStats(), &utils.ConnectionStats{}, nil))
+	})
+
+	t.Run("built-in controllers", func(t *testing.T) {
+		for _, f := range []func(*CongestionControlInfo) CongestionController{
+			NewRenoCongestionController,
+			NewCubicCongestionController,
+			NewBBRCongestionController,
+		} {
+			newCC := newCongestionControllerFactory(f, utils.NewRTTStats(), &utils.ConnectionStats{}, nil)
+			cc := newCC(1234)
+			// built-in controllers are used directly, without converting timestamps
+			require.NotNil(t, cc)
+			_, isAdapter := cc.(*congestionControllerAdapter)
+			require.False(t, isAdapter)
+			require.True(t, cc.InSlowStart())
+		}
+	})
+
+	t.Run("custom controller", func(t *testing.T) {
+		rttStats := utils.NewRTTStats()
+		var connStats utils.ConnectionStats
+		var info *CongestionControlInfo
+		rcc := &recordingCongestionController{window: 4321}
+		newCC := newCongestionControllerFactory(
+			func(i *CongestionControlInfo) CongestionController { info = i; return rcc },
+			rttStats,
+			&connStats,
+			nil,
+		)
+		cc := newCC(1234)
+		require.Equal(t, ByteCount(1234), info.InitialMaxDatagramSize)
+		require.Equal(t, rttStats, info.RTTStats)
+
+		now := monotime.Now()
+		cc.OnPacketSent(now, 0, 1, 1000, true)
+		cc.OnPacketAcked(1, 1000, 1000, now.Add(time.Second))
+		require.Equal(t, []time.Time{now.ToTime()}, rcc.sentTimes)
+		require.Equal(t, []time.Time{now.Add(time.Second).ToTime()}, rcc.ackedTimes)
+		require.Zero(t, cc.TimeUntilSend(0))
+		require.Equal(t, ByteCount(4321), cc.GetCongestionWindow())
+
+		cc.OnCongestionEvent(2, 1000, 2000)
+		require.Equal(t, []PacketNumber{2}, rcc.lost)
+		require.EqualValues(t, 1, connStats.PacketsLost.Load())
+		require.EqualValues(t, 1000, connStats.BytesLost.Load())
+	})
+}
+
+func TestBuiltinCongestionControllersWithUserProvidedInfo(t *testing.T) {
+	for _, f := range []func(*CongestionControlInfo) CongestionController{
+		NewRenoCongestionController,
+		NewCubicCongestionController,
+		NewBBRCongestionController,
+	} {
+		for _, info := range []*CongestionControlInfo{
+			nil,
+			{},
+			{RTTStats: utils.NewRTTStats(), InitialMaxDatagramSize: 1400},
+		} {
+			cc := f(info)
+			require.True(t, cc.InSlowStart())
+			require.NotZero(t, cc.GetCongestionWindow())
+			now := time.Now()
+			cc.OnPacketSent(now, 0, 1, 1000, true)
+			cc.OnPacketAcked(1, 1000, 1000, now.Add(time.Millisecond))
+			cc.OnCongestionEvent(2, 1000, 1000)
+			require.True(t, cc.InRecovery())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"time"
@@ -52,6 +53,11 @@ type connIDGenerator struct {
 	connIDsToRetire         []connIDToRetire       // sorted by t
 	initialClientDestConnID *protocol.ConnectionID // nil for the client
 
+	// connection IDs issued for paths other than the initial path, when using the multipath extension
+	pathConnIDs    map[protocol.PathID]map[uint64]protocol.ConnectionID // initialized lazily
+	pathHighestSeq map[protocol.PathID]uint64
+	connIDToPath   map[protocol.ConnectionID]protocol.PathID
+
 	statelessResetter *statelessResetter
 
 	queueControlFrame func(wire.Frame)
@@ -86,8 +92,6 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
 	for i := uint64(len(m.activeSrcConnIDs)); i < min(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -150,6 +154,125 @@ func (m *connIDGenerator) issueNewConnID() error {
 	return nil
 }
 
+// IssuePreferredAddressConnID issues the connection ID sent in the preferred_address transport parameter.
+// It has the sequence number 1, and it must be called before any other connection IDs are issued.
+func (m *connIDGenerator) IssuePreferredAddressConnID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	if m.highestSeq != 0 {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, errors.New("connection IDs already issued")
+	}
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, err
+	}
+	m.highestSeq++
+	m.activeSrcConnIDs[m.highestSeq] = connID
+	m.connRunners.AddConnectionID(connID)
+	return connID, m.statelessResetter.GetStatelessResetToken(connID), nil
+}
+
+// IssuePathConnIDs issues connection IDs for all paths up to maxPathID,
+// using PATH_NEW_CONNECTION_ID frames.
+// It is only used with the multipath extension.
+func (m *connIDGenerator) IssuePathConnIDs(maxPathID protocol.PathID) error {
+	if m.generator.ConnectionIDLen() == 0 {
+		return nil
+	}
+	if m.pathConnIDs == nil {
+		m.pathConnIDs = make(map[protocol.PathID]map[uint64]protocol.ConnectionID)
+		m.pathHighestSeq = make(map[protocol.PathID]uint64)
+		m.connIDToPath = make(map[protocol.ConnectionID]protocol.PathID)
+	}
+	for id := protocol.InitialPathID + 1; id <= maxPathID; id++ {
+		if _, ok := m.pathConnIDs[id]; ok {
+			continue
+		}
+		if _, ok := m.pathHighestSeq[id]; ok { // the path was already removed
+			continue
+		}
+		m.pathConnIDs[id] = make(map[uint64]protocol.ConnectionID, protocol.ConnectionIDsPerPath)
+		for range protocol.ConnectionIDsPerPath {
+			if err := m.issueNewPathConnID(id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *connIDGenerator) issueNewPathConnID(pathID protocol.PathID) error {
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return err
+	}
+	var seq uint64
+	if s, ok := m.pathHighestSeq[pathID]; ok {
+		seq = s + 1
+	}
+	m.pathConnIDs[pathID][seq] = connID
+	m.pathHighestSeq[pathID] = seq
+	m.connIDToPath[connID] = pathID
+	m.connRunners.AddConnectionID(connID)
+	m.queueControlFrame(&wire.PathNewConnectionIDFrame{
+		PathID:              pathID,
+		SequenceNumber:      seq,
+		ConnectionID:        connID,
+		StatelessResetToken: m.statelessResetter.GetStatelessResetToken(connID),
+	})
+	return nil
+}
+
+// PathForConnID returns the path that a connection ID was issued for.
+// Connection IDs not issued using PATH_NEW_CONNECTION_ID frames belong to the initial path.
+func (m *connIDGenerator) PathForConnID(connID protocol.ConnectionID) protocol.PathID {
+	if id, ok := m.connIDToPath[connID]; ok {
+		return id
+	}
+	return protocol.InitialPathID
+}
+
+// RetirePathConnID handles a PATH_RETIRE_CONNECTION_ID frame.
+func (m *connIDGenerator) RetirePathConnID(pathID protocol.PathID, seq uint64, sentWithDestConnID protocol.ConnectionID, expiry monotime.Time) error {
+	highestSeq, ok := m.pathHighestSeq[pathID]
+	if !ok || seq > highestSeq {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("retired connection ID %d on path %d (highest issued: %d)", seq, pathID, highestSeq),
+		}
+	}
+	connIDs, ok := m.pathConnIDs[pathID]
+	// The path might already have been removed.
+	if !ok {
+		return nil
+	}
+	connID, ok := connIDs[seq]
+	// We might already have deleted this connection ID, if this is a duplicate frame.
+	if !ok {
+		return nil
+	}
+	if connID == sentWithDestConnID {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("retired connection ID %d (%s) on path %d, which was used as the Destination Connection ID on this packet", seq, connID, pathID),
+		}
+	}
+	m.queueConnIDForRetiring(connID, expiry)
+	delete(connIDs, seq)
+	return m.issueNewPathConnID(pathID)
+}
+
+// RemovePath retires all connection IDs issued for a path.
+// No new connection IDs will be issued for this path.
+func (m *connIDGenerator) RemovePath(pathID protocol.PathID, expiry monotime.Time) {
+	connIDs, ok := m.pathConnIDs[pathID]
+	if !ok {
+		return
+	}
+	for _, connID := range connIDs {
+		m.queueConnIDForRetiring(connID, expiry)
+	}
+	delete(m.pathConnIDs, pathID)
+}
+
 func (m *connIDGenerator) SetHandshakeComplete(connIDExpiry monotime.Time) {
 	if m.initialClientDestConnID != nil {
 		m.queueConnIDForRetiring(*m.initialClientDestConnID, connIDExpiry)
@@ -166,6 +289,7 @@ func (m *connIDGenerator) RemoveRetiredConnIDs(now monotime.Time) {
 			break
 		}
 		m.connRunners.RemoveConnectionID(c.connID)
+		delete(m.connIDToPath, c.connID)
 		m.connIDsToRetire = m.connIDsToRetire[1:]
 	}
 }
@@ -177,6 +301,11 @@ func (m *connIDGenerator) RemoveAll() {
 	for _, connID := range m.activeSrcConnIDs {
 		m.connRunners.RemoveConnectionID(connID)
 	}
+	for _, connIDs := range m.pathConnIDs {
+		for _, connID := range connIDs {
+			m.connRunners.RemoveConnectionID(connID)
+		}
+	}
 	for _, c := range m.connIDsToRetire {
 		m.connRunners.RemoveConnectionID(c.connID)
 	}
@@ -190,6 +319,11 @@ func (m *connIDGenerator) ReplaceWithClosed(connClose []byte, expiry time.Durati
 	for _, connID := range m.activeSrcConnIDs {
 		connIDs = append(connIDs, connID)
 	}
+	for _, pathConnIDs := range m.pathConnIDs {
+		for _, connID := range pathConnIDs {
+			connIDs = append(connIDs, connID)
+		}
+	}
 	for _, c := range m.connIDsToRetire {
 		connIDs = append(connIDs, c.connID)
 	}
@@ -209,4 +343,9 @@ func (m *connIDGenerator) AddConnRunner(runner connRunner, r connRunnerCallbacks
 	for _, connID := range m.activeSrcConnIDs {
 		r.AddConnectionID(connID)
 	}
+	for _, connIDs := range m.pathConnIDs {
+		for _, connID := range connIDs {
+			r.AddConnectionID(connID)
+		}
+	}
 }
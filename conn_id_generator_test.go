@@ -104,6 +104,46 @@ func testConnIDGeneratorIssueAndRetire(t *testing.T, hasInitialClientDestConnID
 	require.Empty(t, removed)
 }
 
+func TestConnIDGeneratorPreferredAddress(t *testing.T) {
+	var added []protocol.ConnectionID
+	var queuedFrames []wire.Frame
+	sr := newStatelessResetter(&StatelessResetKey{1, 2, 3, 4})
+	g := newConnIDGenerator(
+		&packetHandlerMap{},
+		protocol.ParseConnectionID([]byte{1, 1, 1, 1}),
+		nil,
+		sr,
+		connRunnerCallbacks{
+			AddConnectionID:    func(c protocol.ConnectionID) { added = append(added, c) },
+			RemoveConnectionID: func(c protocol.ConnectionID) {},
+			ReplaceWithClosed:  func([]protocol.ConnectionID, []byte, time.Duration) {},
+		},
+		func(f wire.Frame) { queuedFrames = append(queuedFrames, f) },
+		&protocol.DefaultConnectionIDGenerator{ConnLen: 5},
+	)
+
+	connID, token, err := g.IssuePreferredAddressConnID()
+	require.NoError(t, err)
+	require.Equal(t, []protocol.ConnectionID{connID}, added)
+	require.Equal(t, sr.GetStatelessResetToken(connID), token)
+	// the connection ID is sent in the transport parameters, not in a NEW_CONNECTION_ID frame
+	require.Empty(t, queuedFrames)
+	_, _, err = g.IssuePreferredAddressConnID()
+	require.Error(t, err)
+
+	// the active_connection_id_limit includes the preferred address connection ID
+	require.NoError(t, g.SetMaxActiveConnIDs(4))
+	require.Len(t, queuedFrames, 2)
+	require.EqualValues(t, 2, queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber)
+	require.EqualValues(t, 3, queuedFrames[1].(*wire.NewConnectionIDFrame).SequenceNumber)
+
+	// retiring the preferred address connection ID makes us issue a new one
+	queuedFrames = queuedFrames[:0]
+	require.NoError(t, g.Retire(1, protocol.ParseConnectionID([]byte{3, 3, 3, 3}), monotime.Now()))
+	require.Len(t, queuedFrames, 1)
+	require.EqualValues(t, 4, queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber)
+}
+
 func TestConnIDGeneratorRetiring(t *testing.T) {
 	initialConnID := protocol.ParseConnectionID([]byte{2, 2, 2, 2})
 	var added, removed []protocol.ConnectionID
@@ -348,3 +388,78 @@ func TestConnIDGeneratorAddConnRunner(t *testing.T) {
 	require.NotEmpty(t, tracker1.removed)
 	require.Equal(t, tracker1.removed, tracker2.removed)
 }
+
+func TestConnIDGeneratorMultipath(t *testing.T) {
+	var added, removed []protocol.ConnectionID
+	var queuedFrames []wire.Frame
+	sr := newStatelessResetter(&StatelessResetKey{1, 2, 3, 4})
+	g := newConnIDGenerator(
+		&packetHandlerMap{},
+		protocol.ParseConnectionID([]byte{1, 1, 1, 1}),
+		nil,
+		sr,
+		connRunnerCallbacks{
+			AddConnectionID:    func(c protocol.ConnectionID) { added = append(added, c) },
+			RemoveConnectionID: func(c protocol.ConnectionID) { removed = append(removed, c) },
+			ReplaceWithClosed:  func([]protocol.ConnectionID, []byte, time.Duration) {},
+		},
+		func(f wire.Frame) { queuedFrames = append(queuedFrames, f) },
+		&protocol.DefaultConnectionIDGenerator{ConnLen: 5},
+	)
+
+	require.NoError(t, g.IssuePathConnIDs(2))
+	require.Len(t, added, 2*protocol.ConnectionIDsPerPath)
+	require.Len(t, queuedFrames, 2*protocol.ConnectionIDsPerPath)
+	connIDs := make(map[protocol.PathID][]protocol.ConnectionID)
+	for i, f := range queuedFrames {
+		pncid := f.(*wire.PathNewConnectionIDFrame)
+		require.Equal(t, protocol.PathID(1+i/protocol.ConnectionIDsPerPath), pncid.PathID)
+		require.EqualValues(t, i%protocol.ConnectionIDsPerPath, pncid.SequenceNumber)
+		require.Equal(t, added[i], pncid.ConnectionID)
+		require.Equal(t, sr.GetStatelessResetToken(pncid.ConnectionID), pncid.StatelessResetToken)
+		require.Equal(t, pncid.PathID, g.PathForConnID(pncid.ConnectionID))
+		connIDs[pncid.PathID] = append(connIDs[pncid.PathID], pncid.ConnectionID)
+	}
+	require.Equal(t, protocol.InitialPathID, g.PathForConnID(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+
+	// issuing connection IDs for paths that already have connection IDs doesn't do anything
+	added = added[:0]
+	queuedFrames = queuedFrames[:0]
+	require.NoError(t, g.IssuePathConnIDs(3))
+	require.Len(t, added, protocol.ConnectionIDsPerPath)
+	for _, f := range queuedFrames {
+		require.Equal(t, protocol.PathID(3), f.(*wire.PathNewConnectionIDFrame).PathID)
+	}
+	queuedFrames = queuedFrames[:0]
+
+	// retiring a connection ID on a path makes us issue a new one for the same path
+	err := g.RetirePathConnID(1, 0, connIDs[1][0], monotime.Now())
+	require.ErrorIs(t, err, &qerr.TransportError{ErrorCode: qerr.ProtocolViolation})
+	require.ErrorContains(t, err, "was used as the Destination Connection ID on this packet")
+	err = g.RetirePathConnID(1, 10, connIDs[1][1], monotime.Now())
+	require.ErrorIs(t, err, &qerr.TransportError{ErrorCode: qerr.ProtocolViolation})
+	require.ErrorContains(t, err, "retired connection ID 10 on path 1 (highest issued: 1)")
+	require.NoError(t, g.RetirePathConnID(1, 0, connIDs[1][1], monotime.Now()))
+	require.Len(t, queuedFrames, 1)
+	pncid := queuedFrames[0].(*wire.PathNewConnectionIDFrame)
+	require.Equal(t, protocol.PathID(1), pncid.PathID)
+	require.EqualValues(t, 2, pncid.SequenceNumber)
+	g.RemoveRetiredConnIDs(monotime.Now())
+	require.Equal(t, []protocol.ConnectionID{connIDs[1][0]}, removed)
+	removed = removed[:0]
+	queuedFrames = queuedFrames[:0]
+
+	// removing a path retires all its connection IDs
+	g.RemovePath(2, monotime.Now())
+	g.RemoveRetiredConnIDs(monotime.Now())
+	require.ElementsMatch(t, connIDs[2], removed)
+	require.Empty(t, queuedFrames)
+	// no new connection IDs are issued for removed paths
+	require.NoError(t, g.IssuePathConnIDs(3))
+	require.Empty(t, queuedFrames)
+
+	// all connection IDs are removed when the connection is closed
+	removed = removed[:0]
+	g.RemoveAll()
+	require.Len(t, removed, 1+2*protocol.ConnectionIDsPerPath)
+}
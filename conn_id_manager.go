@@ -294,6 +294,35 @@ func (h *connIDManager) RetireConnIDForPath(pathID pathID) {
 	delete(h.pathProbing, pathID)
 }
 
+// SwitchToConnIDForPath is called when the connection migrates to a path probed using GetConnIDForPath.
+// The connection ID of that path becomes the active connection ID, and the previously active one is retired.
+func (h *connIDManager) SwitchToConnIDForPath(id pathID) {
+	h.assertNotClosed()
+	// if we're using zero-length connection IDs, we don't need to change the connection ID
+	if h.activeConnectionID.Len() == 0 {
+		return
+	}
+
+	entry, ok := h.pathProbing[id]
+	if !ok {
+		return
+	}
+	delete(h.pathProbing, id)
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: h.activeSequenceNumber,
+	})
+	h.highestRetired = max(h.highestRetired, h.activeSequenceNumber)
+	if h.activeStatelessResetToken != nil {
+		h.removeStatelessResetToken(*h.activeStatelessResetToken)
+	}
+	// The stateless reset token was already added by GetConnIDForPath.
+	h.activeSequenceNumber = entry.SequenceNumber
+	h.activeConnectionID = entry.ConnectionID
+	h.activeStatelessResetToken = &entry.StatelessResetToken
+	h.packetsSinceLastChange = 0
+	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
+}
+
 func (h *connIDManager) IsActiveStatelessResetToken(token protocol.StatelessResetToken) bool {
 	if h.activeStatelessResetToken != nil {
 		if *h.activeStatelessResetToken == token {
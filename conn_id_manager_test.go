@@ -333,6 +333,51 @@ func TestConnIDManagerPathMigration(t *testing.T) {
 	}, removedTokens)
 }
 
+func TestConnIDManagerSwitchToConnIDForPath(t *testing.T) {
+	var frameQueue []wire.Frame
+	var addedTokens, removedTokens []protocol.StatelessResetToken
+	m := newConnIDManager(
+		protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+		func(token protocol.StatelessResetToken) { addedTokens = append(addedTokens, token) },
+		func(token protocol.StatelessResetToken) { removedTokens = append(removedTokens, token) },
+		func(f wire.Frame) { frameQueue = append(frameQueue, f) },
+	)
+	m.SetStatelessResetToken(protocol.StatelessResetToken{1, 2, 3, 4})
+	require.NoError(t, m.AddFromPreferredAddress(
+		protocol.ParseConnectionID([]byte{4, 3, 2, 1}),
+		protocol.StatelessResetToken{4, 3, 2, 1},
+	))
+	addedTokens = addedTokens[:0]
+
+	connID, ok := m.GetConnIDForPath(preferredAddressPathID)
+	require.True(t, ok)
+	require.Equal(t, protocol.ParseConnectionID([]byte{4, 3, 2, 1}), connID)
+	require.Equal(t, []protocol.StatelessResetToken{{4, 3, 2, 1}}, addedTokens)
+	// the active connection ID doesn't change while probing
+	require.Equal(t, protocol.ParseConnectionID([]byte{1, 2, 3, 4}), m.Get())
+
+	// switching to a path that doesn't have a connection ID is a no-op
+	m.SwitchToConnIDForPath(1)
+	require.Empty(t, frameQueue)
+	require.Equal(t, protocol.ParseConnectionID([]byte{1, 2, 3, 4}), m.Get())
+
+	m.SwitchToConnIDForPath(preferredAddressPathID)
+	require.Equal(t, protocol.ParseConnectionID([]byte{4, 3, 2, 1}), m.Get())
+	require.Equal(t, []wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 0}}, frameQueue)
+	require.Equal(t, []protocol.StatelessResetToken{{1, 2, 3, 4}}, removedTokens)
+	require.False(t, m.IsActiveStatelessResetToken(protocol.StatelessResetToken{1, 2, 3, 4}))
+	require.True(t, m.IsActiveStatelessResetToken(protocol.StatelessResetToken{4, 3, 2, 1}))
+
+	// the connection ID is no longer reserved for the path
+	retiredBefore := len(frameQueue)
+	m.RetireConnIDForPath(preferredAddressPathID)
+	require.Len(t, frameQueue, retiredBefore)
+
+	removedTokens = removedTokens[:0]
+	m.Close()
+	require.Equal(t, []protocol.StatelessResetToken{{4, 3, 2, 1}}, removedTokens)
+}
+
 func TestConnIDManagerZeroLengthConnectionID(t *testing.T) {
 	m := newConnIDManager(
 		protocol.ConnectionID{},
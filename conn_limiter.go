@@ -0,0 +1,77 @@
+package quic
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+)
+
+// The prefix lengths used to group client addresses when limiting the number of connections per IP.
+// IPv6 hosts are usually assigned (at least) a /64.
+const (
+	connLimiterPrefixLenIPv4 = 32
+	connLimiterPrefixLenIPv6 = 64
+)
+
+// The connLimiter limits the number of (server-side) connections,
+// both in total and per client IP.
+// A limit of 0 means that the number of connections is not limited.
+type connLimiter struct {
+	maxConns      int
+	maxConnsPerIP int
+
+	mx    sync.Mutex
+	conns int
+	perIP map[netip.Prefix]int
+}
+
+func newConnLimiter(maxConns, maxConnsPerIP int) *connLimiter {
+	if maxConns <= 0 && maxConnsPerIP <= 0 {
+		return nil
+	}
+	return &connLimiter{
+		maxConns:      maxConns,
+		maxConnsPerIP: maxConnsPerIP,
+		perIP:         make(map[netip.Prefix]int),
+	}
+}
+
+// Allow says if a new connection from the address is allowed.
+func (l *connLimiter) Allow(addr net.Addr) bool {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if l.maxConns > 0 && l.conns >= l.maxConns {
+		return false
+	}
+	if l.maxConnsPerIP > 0 {
+		if prefix, ok := addrPrefix(addr, connLimiterPrefixLenIPv4, connLimiterPrefixLenIPv6); ok {
+			return l.perIP[prefix] < l.maxConnsPerIP
+		}
+	}
+	return true
+}
+
+func (l *connLimiter) AddConn(addr net.Addr) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.conns++
+	if prefix, ok := addrPrefix(addr, connLimiterPrefixLenIPv4, connLimiterPrefixLenIPv6); ok {
+		l.perIP[prefix]++
+	}
+}
+
+func (l *connLimiter) RemoveConn(addr net.Addr) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.conns--
+	if prefix, ok := addrPrefix(addr, connLimiterPrefixLenIPv4, connLimiterPrefixLenIPv6); ok {
+		if l.perIP[prefix] <= 1 {
+			delete(l.perIP, prefix)
+		} else {
+			l.perIP[prefix]--
+		}
+	}
+}
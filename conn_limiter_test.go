@@ -0,0 +1,50 @@
+package quic
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnLimiterUnlimited(t *testing.T) {
+	require.Nil(t, newConnLimiter(0, 0))
+}
+
+func TestConnLimiterTotal(t *testing.T) {
+	l := newConnLimiter(2, 0)
+	addr1 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}
+	addr2 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 1234}
+
+	require.True(t, l.Allow(addr1))
+	l.AddConn(addr1)
+	require.True(t, l.Allow(addr1))
+	l.AddConn(addr1)
+	require.False(t, l.Allow(addr1))
+	require.False(t, l.Allow(addr2))
+
+	l.RemoveConn(addr1)
+	require.True(t, l.Allow(addr2))
+}
+
+func TestConnLimiterPerIP(t *testing.T) {
+	l := newConnLimiter(0, 1)
+	addr1 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}
+	addr2 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 4321} // same IP as addr1
+	addr3 := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 1234}
+	addr4 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1234}
+	addr5 := &net.UDPAddr{IP: net.ParseIP("2001:db8::2"), Port: 1234} // same /64 as addr4
+
+	l.AddConn(addr1)
+	require.False(t, l.Allow(addr2))
+	require.True(t, l.Allow(addr3))
+	l.AddConn(addr4)
+	require.False(t, l.Allow(addr5))
+
+	l.RemoveConn(addr1)
+	require.True(t, l.Allow(addr2))
+	l.RemoveConn(addr4)
+	require.True(t, l.Allow(addr5))
+	require.Empty(t, l.perIP)
+	require.Zero(t, l.conns)
+}
@@ -30,13 +30,16 @@ import (
 type unpacker interface {
 	UnpackLongHeader(hdr *wire.Header, data []byte) (*unpackedPacket, error)
 	UnpackShortHeader(rcvTime monotime.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
+	UnpackPathShortHeader(rcvTime monotime.Time, pathID protocol.PathID, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)
 }
 
 type cryptoStreamHandler interface {
 	StartHandshake(context.Context) error
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.Version)
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
+	InitiateKeyUpdate() error
 	GetSessionTicket() ([]byte, error)
 	NextEvent() handshake.Event
 	DiscardInitialKeys()
@@ -55,6 +58,8 @@ type receivedPacket struct {
 	ecn protocol.ECN
 
 	info packetInfo // only valid if the contained IP address is valid
+
+	onPreferredAddress bool // the packet was received on the server's preferred address
 }
 
 type receivedPacketWithDatagramID struct {
@@ -145,7 +150,15 @@ type Conn struct {
 	// lazily initialzed: most connections never migrate
 	pathManager         *pathManager
 	largestRcvdAppData  protocol.PacketNumber
+	spinBit             *spinBit // the spin bit of the current path
 	pathManagerOutgoing atomic.Pointer[pathManagerOutgoing]
+	// only set if the multipath extension was negotiated
+	multipath *multipathManager
+	// only set for clients, if the server sent a preferred address that the client is migrating to
+	preferredAddress *preferredAddressMigrator
+	// only set for servers, if a preferred address was configured
+	preferredAddressTransport *Transport
+	usingPreferredAddress     bool
 
 	streamsMap      *streamsMap
 	connIDManager   *connIDManager
@@ -184,6 +197,10 @@ type Conn struct {
 	closeChan chan struct{}
 	closeErr  atomic.Pointer[closeError]
 
+	// keyUpdateRequests is used to pass key update requests to the run loop.
+	// The result is sent on the channel contained in the request.
+	keyUpdateRequests chan chan error
+
 	ctx                   context.Context
 	ctxCancel             context.CancelCauseFunc
 	handshakeCompleteChan chan struct{}
@@ -200,6 +217,9 @@ type Conn struct {
 	receivedRetry       bool
 	versionNegotiated   bool
 	receivedFirstPacket bool
+	// The version used for the first Initial packet (after incompatible version negotiation, if any).
+	// This can differ from version if compatible version negotiation was performed.
+	originalVersion protocol.Version
 
 	blocked blockMode
 
@@ -283,6 +303,7 @@ var newConnection = func(
 		qlogTrace:           qlogTrace,
 		logger:              logger,
 		version:             v,
+		originalVersion:     v,
 	}
 	if qlogTrace != nil {
 		s.qlogger = qlogTrace.AddProducer()
@@ -318,6 +339,7 @@ var newConnection = func(
 		protocol.ByteCount(s.config.InitialPacketSize),
 		s.rttStats,
 		&s.connStats,
+		newCongestionControllerFactory(s.config.CongestionControl, s.rttStats, &s.connStats, s.qlogger),
 		clientAddressValidated,
 		s.conn.capabilities().ECN,
 		s.receivedPacketHandler.IgnorePacketsBelow,
@@ -348,13 +370,29 @@ var newConnection = func(
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
 		RetrySourceConnectionID:   retrySrcConnID,
+		GreaseQUICBit:             !s.config.DisableQUICBitGreasing, // we accept packets with the QUIC bit cleared
 		EnableResetStreamAt:       conf.EnableStreamResetPartialDelivery,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     v,
+			AvailableVersions: s.config.Versions,
+		},
+	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	if s.config.EnableMultipath {
+		maxPathID := protocol.MaxMultipathPathID
+		params.InitialMaxPathID = &maxPathID
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
 	} else {
 		params.MaxDatagramFrameSize = protocol.InvalidByteCount
 	}
+	if s.config.PreferredAddress != nil {
+		s.setupPreferredAddress(s.config.PreferredAddress, params)
+	}
 	if s.qlogger != nil {
 		s.qlogTransportParameters(params, protocol.PerspectiveServer, false)
 	}
@@ -365,14 +403,16 @@ var newConnection = func(
 		params,
 		tlsConf,
 		conf.Allow0RTT,
+		s.config.KeyUpdatePacketInterval,
+		s.config.KeyUpdatePeriod,
 		s.rttStats,
 		s.qlogger,
 		logger,
 		s.version,
 	)
 	s.cryptoStreamHandler = cs
-	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective)
-	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
+	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.spinBit, s.perspective)
+	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, !s.config.DisableQUICBitGreasing)
 	s.cryptoStreamManager = newCryptoStreamManager(s.initialStream, s.handshakeStream, s.oneRTTStream)
 	return &wrappedConn{Conn: s}
 }
@@ -407,6 +447,7 @@ var newClientConnection = func(
 		qlogTrace:           qlogTrace,
 		versionNegotiated:   hasNegotiatedVersion,
 		version:             v,
+		originalVersion:     v,
 	}
 	if qlogTrace != nil {
 		s.qlogger = qlogTrace.AddProducer()
@@ -447,6 +488,7 @@ var newClientConnection = func(
 		protocol.ByteCount(s.config.InitialPacketSize),
 		s.rttStats,
 		&s.connStats,
+		newCongestionControllerFactory(s.config.CongestionControl, s.rttStats, &s.connStats, s.qlogger),
 		false, // has no effect
 		s.conn.capabilities().ECN,
 		s.receivedPacketHandler.IgnorePacketsBelow,
@@ -474,7 +516,20 @@ var newClientConnection = func(
 		// See https://github.com/quic-go/quic-go/pull/3806.
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
+		GreaseQUICBit:             !s.config.DisableQUICBitGreasing, // we accept packets with the QUIC bit cleared
 		EnableResetStreamAt:       conf.EnableStreamResetPartialDelivery,
+		VersionInformation: &wire.VersionInformation{
+			ChosenVersion:     v,
+			AvailableVersions: s.config.Versions,
+		},
+	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
+	if s.config.EnableMultipath {
+		maxPathID := protocol.MaxMultipathPathID
+		params.InitialMaxPathID = &maxPathID
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
@@ -489,6 +544,8 @@ var newClientConnection = func(
 		params,
 		tlsConf,
 		enable0RTT,
+		s.config.KeyUpdatePacketInterval,
+		s.config.KeyUpdatePeriod,
 		s.rttStats,
 		s.qlogger,
 		logger,
@@ -496,8 +553,8 @@ var newClientConnection = func(
 	)
 	s.cryptoStreamHandler = cs
 	s.cryptoStreamManager = newCryptoStreamManager(s.initialStream, s.handshakeStream, oneRTTStream)
-	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen)
-	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.perspective)
+	s.unpacker = newPacketUnpacker(cs, s.srcConnIDLen, !s.config.DisableQUICBitGreasing)
+	s.packer = newPacketPacker(srcConnID, s.connIDManager.Get, s.initialStream, s.handshakeStream, s.sentPacketHandler, s.retransmissionQueue, cs, s.framer, s.receivedPacketHandler, s.datagramQueue, s.spinBit, s.perspective)
 	if len(tlsConf.ServerName) > 0 {
 		s.tokenStoreKey = tlsConf.ServerName
 	} else {
@@ -518,10 +575,12 @@ func (c *Conn) preSetup() {
 	c.handshakeStream = newCryptoStream()
 	c.sendQueue = newSendQueue(c.conn)
 	c.retransmissionQueue = newRetransmissionQueue()
+	c.spinBit = newSpinBit(c.perspective, spinBitDisabled(c.config))
 	c.frameParser = *wire.NewFrameParser(
 		c.config.EnableDatagrams,
 		c.config.EnableStreamResetPartialDelivery,
-		false, // ACK_FREQUENCY is not supported yet
+		c.config.EnableAckFrequency,
+		c.config.EnableMultipath,
 	)
 	c.rttStats = utils.NewRTTStats()
 	c.connFlowController = flowcontrol.NewConnectionFlowController(
@@ -550,6 +609,7 @@ func (c *Conn) preSetup() {
 	c.receivedPackets.Init(8)
 	c.notifyReceivedPacket = make(chan struct{}, 1)
 	c.closeChan = make(chan struct{}, 1)
+	c.keyUpdateRequests = make(chan chan error)
 	c.sendingScheduled = make(chan struct{}, 1)
 	c.handshakeCompleteChan = make(chan struct{})
 
@@ -657,11 +717,14 @@ runLoop:
 			// * sending scheduled
 			// * send queue available
 			// * received packets
+			// * key update requested
 			select {
 			case <-c.closeChan:
 				break runLoop
 			case <-c.timer.C:
 			case <-c.sendingScheduled:
+			case errChan := <-c.keyUpdateRequests:
+				errChan <- c.initiateKeyUpdate()
 			case <-sendQueueAvailable:
 			case <-c.notifyReceivedPacket:
 				wasProcessed, err := c.handlePackets()
@@ -685,6 +748,12 @@ runLoop:
 				break runLoop
 			}
 		}
+		if c.multipath != nil {
+			if err := c.handleMultipathTimers(now); err != nil {
+				c.setCloseError(&closeError{err: err})
+				break runLoop
+			}
+		}
 
 		if keepAliveTime := c.nextKeepAliveTime(); !keepAliveTime.IsZero() && !now.Before(keepAliveTime) {
 			// send a PING frame since there is no activity in the connection
@@ -706,10 +775,14 @@ runLoop:
 		c.connIDGenerator.RemoveRetiredConnIDs(now)
 
 		if c.perspective == protocol.PerspectiveClient {
+			if c.preferredAddress != nil && c.preferredAddress.ShouldMigrate() {
+				c.migrateToPreferredAddress(now)
+			}
 			pm := c.pathManagerOutgoing.Load()
 			if pm != nil {
 				tr, ok := pm.ShouldSwitchPath()
-				if ok {
+				// When using the multipath extension, all validated paths are used at the same time.
+				if ok && c.multipath == nil {
 					c.switchToNewPath(tr, now)
 				}
 			}
@@ -747,6 +820,9 @@ runLoop:
 	closeErr := c.closeErr.Load()
 	c.cryptoStreamHandler.Close()
 	c.sendQueue.Close() // close the send queue before sending the CONNECTION_CLOSE
+	if c.multipath != nil {
+		c.multipath.Close()
+	}
 	c.handleCloseError(closeErr)
 	if c.qlogger != nil {
 		if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) {
@@ -822,14 +898,40 @@ type ConnectionStats struct {
 	// (does not monotonically increase, because packets that are declared lost
 	// can subsequently be received).
 	PacketsLost uint64
+
+	// CongestionWindow is the current size of the congestion window of the active network path.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent in ack-eliciting packets on the active network path
+	// that have neither been acknowledged nor declared lost.
+	BytesInFlight uint64
+	// InSlowStart says if the congestion controller of the active network path is in slow start.
+	InSlowStart bool
+	// InRecovery says if the congestion controller of the active network path is in recovery.
+	InRecovery bool
+	// PacingRate is the rate at which packets are paced on the active network path, in bytes per second.
+	// It is 0 if the congestion controller doesn't report a pacing rate.
+	PacingRate uint64
+	// PTOCount is the number of times the probe timeout (PTO) fired
+	// without receiving an acknowledgment, see section 6.2 of RFC 9002.
+	PTOCount uint32
+	// PathMTU is the maximum size of a QUIC packet sent on the active network path.
+	// It is increased by Path MTU Discovery.
+	PathMTU uint64
+	// ECN contains the ECN statistics of the active network path.
+	ECN ECNStats
+
+	// Paths contains the statistics of every network path, if the multipath extension is used.
+	// The first entry is the initial path.
+	Paths []PathStats
 }
 
 func (c *Conn) ConnectionStats() ConnectionStats {
-	return ConnectionStats{
-		MinRTT:        c.rttStats.MinRTT(),
-		LatestRTT:     c.rttStats.LatestRTT(),
-		SmoothedRTT:   c.rttStats.SmoothedRTT(),
-		MeanDeviation: c.rttStats.MeanDeviation(),
+	path := newPathStats(c.LocalAddr(), c.RemoteAddr(), c.rttStats, c.sentPacketHandler.Stats())
+	stats := ConnectionStats{
+		MinRTT:        path.MinRTT,
+		LatestRTT:     path.LatestRTT,
+		SmoothedRTT:   path.SmoothedRTT,
+		MeanDeviation: path.MeanDeviation,
 
 		BytesSent:       c.connStats.BytesSent.Load(),
 		PacketsSent:     c.connStats.PacketsSent.Load(),
@@ -837,7 +939,24 @@ func (c *Conn) ConnectionStats() ConnectionStats {
 		PacketsReceived: c.connStats.PacketsReceived.Load(),
 		BytesLost:       c.connStats.BytesLost.Load(),
 		PacketsLost:     c.connStats.PacketsLost.Load(),
+
+		CongestionWindow: path.CongestionWindow,
+		BytesInFlight:    path.BytesInFlight,
+		InSlowStart:      path.InSlowStart,
+		InRecovery:       path.InRecovery,
+		PacingRate:       path.PacingRate,
+		PTOCount:         path.PTOCount,
+		PathMTU:          path.PathMTU,
+		ECN:              path.ECN,
 	}
+	c.connStateMutex.Lock()
+	multipath := c.connState.Multipath
+	c.connStateMutex.Unlock()
+	// c.multipath is set before connState.Multipath, so it's safe to access it here
+	if multipath {
+		stats.Paths = append([]PathStats{path}, c.multipath.pathStats()...)
+	}
+	return stats
 }
 
 // Time when the connection should time out
@@ -886,6 +1005,11 @@ func (c *Conn) maybeResetTimer() {
 	if t := c.receivedPacketHandler.GetAlarmTimeout(); !t.IsZero() && t.Before(deadline) {
 		deadline = t
 	}
+	if c.multipath != nil {
+		if t := c.multipath.nextAlarm(); !t.IsZero() && t.Before(deadline) {
+			deadline = t
+		}
+	}
 	if t := c.sentPacketHandler.GetLossDetectionTimeout(); !t.IsZero() && t.Before(deadline) {
 		deadline = t
 	}
@@ -909,6 +1033,12 @@ func (c *Conn) idleTimeoutStartTime() monotime.Time {
 }
 
 func (c *Conn) switchToNewPath(tr *Transport, now monotime.Time) {
+	c.switchSendConn(newSendConn(tr.conn, c.conn.RemoteAddr(), packetInfo{}, utils.DefaultLogger), now) // TODO: find a better way
+}
+
+// resetPathState resets the congestion controller, the RTT estimate and the Path MTU
+// when the connection moves to a new path.
+func (c *Conn) resetPathState(now monotime.Time) {
 	initialPacketSize := protocol.ByteCount(c.config.InitialPacketSize)
 	c.sentPacketHandler.MigratedPath(now, initialPacketSize)
 	maxPacketSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
@@ -916,7 +1046,11 @@ func (c *Conn) switchToNewPath(tr *Transport, now monotime.Time) {
 		maxPacketSize = c.peerParams.MaxUDPPayloadSize
 	}
 	c.mtuDiscoverer.Reset(now, initialPacketSize, maxPacketSize)
-	c.conn = newSendConn(tr.conn, c.conn.RemoteAddr(), packetInfo{}, utils.DefaultLogger) // TODO: find a better way
+}
+
+func (c *Conn) switchSendConn(conn sendConn, now monotime.Time) {
+	c.resetPathState(now)
+	c.conn = conn
 	c.sendQueue.Close()
 	c.sendQueue = newSendQueue(c.conn)
 	go func() {
@@ -924,6 +1058,7 @@ func (c *Conn) switchToNewPath(tr *Transport, now monotime.Time) {
 			c.destroyImpl(err)
 		}
 	}()
+	c.qlogMigrationComplete()
 }
 
 func (c *Conn) handleHandshakeComplete(now monotime.Time) error {
@@ -1085,7 +1220,11 @@ func (c *Conn) handleOnePacket(rp receivedPacket, datagramID qlog.DatagramID) (w
 		}
 
 		if wire.IsLongHeaderPacket(p.data[0]) {
-			hdr, packetData, rest, err := wire.ParsePacket(p.data)
+			parsePacket := wire.ParsePacket
+			if !c.config.DisableQUICBitGreasing {
+				parsePacket = wire.ParsePacketAllowClearedQUICBit
+			}
+			hdr, packetData, rest, err := parsePacket(p.data)
 			if err != nil {
 				if c.qlogger != nil {
 					if err == wire.ErrUnsupportedVersion {
@@ -1108,7 +1247,7 @@ func (c *Conn) handleOnePacket(rp receivedPacket, datagramID qlog.DatagramID) (w
 			}
 			lastConnID = hdr.DestConnectionID
 
-			if hdr.Version != c.version {
+			if hdr.Version != c.version && !c.canUpgradeVersion(hdr) && !c.acceptsOriginalVersion(hdr) {
 				if c.qlogger != nil {
 					c.qlogger.RecordEvent(qlog.PacketDropped{
 						Raw:        qlog.RawInfo{Length: len(data)},
@@ -1187,7 +1326,19 @@ func (c *Conn) handleShortHeaderPacket(
 		})
 		return false, nil
 	}
-	pn, pnLen, keyPhase, data, err := c.unpacker.UnpackShortHeader(p.rcvTime, p.data)
+	var pathID protocol.PathID
+	if c.multipath != nil {
+		pathID = c.connIDGenerator.PathForConnID(destConnID)
+	}
+	var pn protocol.PacketNumber
+	var pnLen protocol.PacketNumberLen
+	var keyPhase protocol.KeyPhaseBit
+	var data []byte
+	if pathID == protocol.InitialPathID {
+		pn, pnLen, keyPhase, data, err = c.unpacker.UnpackShortHeader(p.rcvTime, p.data)
+	} else {
+		pn, pnLen, keyPhase, data, err = c.unpacker.UnpackPathShortHeader(p.rcvTime, pathID, p.data)
+	}
 	if err != nil {
 		// Stateless reset packets (see RFC 9000, section 10.3):
 		// * fill the entire UDP datagram (i.e. they cannot be part of a coalesced packet)
@@ -1196,13 +1347,17 @@ func (c *Conn) handleShortHeaderPacket(
 		// * are at least 21 bytes long
 		if !isCoalesced && len(p.data) >= protocol.MinReceivedStatelessResetSize && p.data[0]&0b11000000 == 0b01000000 {
 			token := protocol.StatelessResetToken(p.data[len(p.data)-16:])
-			if c.connIDManager.IsActiveStatelessResetToken(token) {
+			if c.connIDManager.IsActiveStatelessResetToken(token) ||
+				(c.multipath != nil && c.multipath.isActiveStatelessResetToken(token)) {
 				return false, &StatelessResetError{}
 			}
 		}
 		wasQueued, err = c.handleUnpackError(err, p, qlog.PacketType1RTT, datagramID)
 		return false, err
 	}
+	if pathID != protocol.InitialPathID {
+		return c.handleMultipathPacket(p, pathID, destConnID, pn, pnLen, keyPhase, data, datagramID)
+	}
 	c.largestRcvdAppData = max(c.largestRcvdAppData, pn)
 
 	if c.logger.Debug() {
@@ -1250,11 +1405,18 @@ func (c *Conn) handleShortHeaderPacket(
 	if err != nil {
 		return false, err
 	}
+	// Packets received on a different path (before migration) don't affect the spin value of the current path.
+	if c.perspective == protocol.PerspectiveClient || addrsEqual(p.remoteAddr, c.RemoteAddr()) {
+		c.spinBit.ReceivedPacket(pn, wire.ShortHeaderSpinBit(p.data[0]))
+	}
 
 	// In RFC 9000, only the client can migrate between paths.
 	if c.perspective == protocol.PerspectiveClient {
 		return true, nil
 	}
+	if p.onPreferredAddress && !c.usingPreferredAddress {
+		return true, c.handlePreferredAddressPacket(p, pn, pathChallenge, isNonProbing)
+	}
 	if addrsEqual(p.remoteAddr, c.RemoteAddr()) {
 		return true, nil
 	}
@@ -1275,7 +1437,7 @@ func (c *Conn) handleShortHeaderPacket(
 		}
 		c.logger.Debugf("sending path probe packet to %s", p.remoteAddr)
 		c.logShortHeaderPacketWithDatagramID(probe, protocol.ECNNon, buf.Len(), false, datagramID)
-		c.registerPackedShortHeaderPacket(probe, protocol.ECNNon, p.rcvTime)
+		c.registerPackedShortHeaderPacket(probe, protocol.ECNNon, p.rcvTime, 0)
 		c.sendQueue.SendProbe(buf, p.remoteAddr)
 	}
 	// We only switch paths in response to the highest-numbered non-probing packet,
@@ -1295,6 +1457,7 @@ func (c *Conn) handleShortHeaderPacket(
 		maxPacketSize,
 	)
 	c.conn.ChangeRemoteAddr(p.remoteAddr, p.info)
+	c.qlogMigrationComplete()
 	return true, nil
 }
 
@@ -1345,11 +1508,24 @@ func (c *Conn) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header, datagr
 		return false, nil
 	}
 
+	// Compatible version negotiation (RFC 9368):
+	// Only switch to the server's version if the packet can be decrypted.
+	// The server doesn't switch back when receiving packets using the client's original version.
+	changeVersion := hdr.Version != c.version && c.canUpgradeVersion(hdr)
+	if changeVersion {
+		c.cryptoStreamHandler.ChangeVersion(hdr.Version)
+	}
 	packet, err := c.unpacker.UnpackLongHeader(hdr, p.data)
 	if err != nil {
+		if changeVersion {
+			c.cryptoStreamHandler.ChangeVersion(c.version)
+		}
 		wasQueued, err = c.handleUnpackError(err, p, toQlogPacketType(hdr.Type), datagramID)
 		return false, err
 	}
+	if changeVersion {
+		c.upgradeVersion(hdr.Version)
+	}
 
 	if c.logger.Debug() {
 		c.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, %s", packet.hdr.PacketNumber, p.Size(), hdr.DestConnectionID, packet.encryptionLevel)
@@ -1924,7 +2100,7 @@ func (c *Conn) handleFrame(
 		err = c.streamsMap.HandleStopSendingFrame(frame)
 	case *wire.PingFrame:
 	case *wire.PathChallengeFrame:
-		c.handlePathChallengeFrame(frame)
+		c.handlePathChallengeFrame(frame, destConnID)
 		pathChallenge = frame
 	case *wire.PathResponseFrame:
 		err = c.handlePathResponseFrame(frame)
@@ -1936,6 +2112,13 @@ func (c *Conn) handleFrame(
 		err = c.connIDGenerator.Retire(frame.SequenceNumber, destConnID, rcvTime.Add(3*c.rttStats.PTO(false)))
 	case *wire.HandshakeDoneFrame:
 		err = c.handleHandshakeDoneFrame(rcvTime)
+	case *wire.AckFrequencyFrame:
+		err = c.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		c.receivedPacketHandler.ReceivedImmediateAckFrame()
+	case *wire.PathAckFrame, *wire.PathAbandonFrame, *wire.PathStatusFrame, *wire.PathNewConnectionIDFrame,
+		*wire.PathRetireConnectionIDFrame, *wire.MaxPathIDFrame, *wire.PathsBlockedFrame, *wire.PathCIDsBlockedFrame:
+		err = c.handleMultipathFrame(frame, destConnID, rcvTime)
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -2014,6 +2197,8 @@ func (c *Conn) handleHandshakeEvents(now monotime.Time) error {
 			// Don't call handleHandshakeComplete yet.
 			// It's advantageous to process ACK frames that might be serialized after the CRYPTO frame first.
 			c.handshakeComplete = true
+		case handshake.EventChangedVersion:
+			c.upgradeVersion(ev.Version)
 		case handshake.EventReceivedTransportParameters:
 			err = c.handleTransportParameters(ev.TransportParameters)
 		case handshake.EventRestoredTransportParameters:
@@ -2036,7 +2221,11 @@ func (c *Conn) handleHandshakeEvents(now monotime.Time) error {
 	}
 }
 
-func (c *Conn) handlePathChallengeFrame(f *wire.PathChallengeFrame) {
+func (c *Conn) handlePathChallengeFrame(f *wire.PathChallengeFrame, destConnID protocol.ConnectionID) {
+	// When using the multipath extension, the PATH_RESPONSE is sent on the path that the PATH_CHALLENGE was received on.
+	if c.multipath != nil && c.connIDGenerator.PathForConnID(destConnID) != protocol.InitialPathID {
+		return
+	}
 	if c.perspective == protocol.PerspectiveClient {
 		c.queueControlFrame(&wire.PathResponseFrame{Data: f.Data})
 	}
@@ -2054,7 +2243,14 @@ func (c *Conn) handlePathResponseFrame(f *wire.PathResponseFrame) error {
 }
 
 func (c *Conn) handlePathResponseFrameClient(f *wire.PathResponseFrame) error {
+	if c.preferredAddress != nil && c.preferredAddress.HandlePathResponseFrame(f) {
+		return nil
+	}
 	pm := c.pathManagerOutgoing.Load()
+	if c.multipath != nil && c.multipath.handlePathResponse(f) && pm != nil {
+		pm.HandlePathResponseFrame(f)
+		return nil
+	}
 	if pm == nil {
 		return &qerr.TransportError{
 			ErrorCode:    qerr.ProtocolViolation,
@@ -2066,6 +2262,9 @@ func (c *Conn) handlePathResponseFrameClient(f *wire.PathResponseFrame) error {
 }
 
 func (c *Conn) handlePathResponseFrameServer(f *wire.PathResponseFrame) error {
+	if c.multipath != nil && c.multipath.handlePathResponse(f) {
+		return nil
+	}
 	if c.pathManager == nil {
 		// since we didn't send PATH_CHALLENGEs yet, we don't expect PATH_RESPONSEs
 		return &qerr.TransportError{
@@ -2103,6 +2302,17 @@ func (c *Conn) handleHandshakeDoneFrame(rcvTime monotime.Time) error {
 	return nil
 }
 
+func (c *Conn) handleAckFrequencyFrame(frame *wire.AckFrequencyFrame) error {
+	if frame.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max_ack_delay (%s) is smaller than min_ack_delay (%s)", frame.RequestMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	c.receivedPacketHandler.ReceivedAckFrequencyFrame(frame)
+	return nil
+}
+
 func (c *Conn) handleAckFrame(frame *wire.AckFrame, encLevel protocol.EncryptionLevel, rcvTime monotime.Time) error {
 	acked1RTTPacket, err := c.sentPacketHandler.ReceivedAck(frame, encLevel, c.lastPacketReceivedTime)
 	if err != nil {
@@ -2111,6 +2321,9 @@ func (c *Conn) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encryption
 	if !acked1RTTPacket {
 		return nil
 	}
+	if c.multipath != nil {
+		c.multipath.initialUnresponsive = false
+	}
 	// On the client side: If the packet acknowledged a 1-RTT packet, this confirms the handshake.
 	// This is only possible if the ACK was sent in a 1-RTT packet.
 	// This is an optimization over simply waiting for a HANDSHAKE_DONE frame, see section 4.1.2 of RFC 9001.
@@ -2356,6 +2569,12 @@ func (c *Conn) handleTransportParameters(params *wire.TransportParameters) error
 			ErrorMessage: err.Error(),
 		}
 	}
+	if err := c.checkVersionInformation(params.VersionInformation); err != nil {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.VersionNegotiationErrorErrorCode,
+			ErrorMessage: err.Error(),
+		}
+	}
 
 	if c.perspective == protocol.PerspectiveClient && c.peerParams != nil && c.ConnectionState().Used0RTT && !params.ValidForUpdate(c.peerParams) {
 		return &qerr.TransportError{
@@ -2423,14 +2642,25 @@ func (c *Conn) applyTransportParameters() {
 	c.frameParser.SetAckDelayExponent(params.AckDelayExponent)
 	c.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	c.rttStats.SetMaxAckDelay(params.MaxAckDelay)
+	if c.config.EnableAckFrequency && params.MinAckDelay != nil {
+		c.sentPacketHandler.EnableAckFrequency(c.framer.QueueControlFrame)
+	}
 	c.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
+	c.negotiateMultipath(params)
+	if params.GreaseQUICBit {
+		c.packer.EnableQUICBitGreasing()
+	}
 	if params.StatelessResetToken != nil {
 		c.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		c.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
+		c.preferredAddress = newPreferredAddressMigrator(
+			params.PreferredAddress,
+			c.conn.RemoteAddr(),
+			func() { c.connIDManager.RetireConnIDForPath(preferredAddressPathID) },
+			c.logger,
+		)
 	}
 	maxPacketSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
 	if params.MaxUDPPayloadSize > 0 && params.MaxUDPPayloadSize < maxPacketSize {
@@ -2445,16 +2675,24 @@ func (c *Conn) applyTransportParameters() {
 }
 
 func (c *Conn) triggerSending(now monotime.Time) error {
+	if c.multipath != nil && c.handshakeConfirmed && len(c.multipath.paths) > 0 {
+		return c.triggerSendingMultipath(now)
+	}
 	c.pacingDeadline = 0
 
 	sendMode := c.sentPacketHandler.SendMode(now)
 	switch sendMode {
 	case ackhandler.SendAny:
-		return c.sendPackets(now)
+		return c.sendPackets(now, now)
 	case ackhandler.SendNone:
 		c.blocked = blockModeHardBlocked
 		return nil
 	case ackhandler.SendPacingLimited:
+		if c.handshakeConfirmed {
+			if sendTime, ok := c.kernelPacingSendTime(now); ok {
+				return c.sendPackets(now, sendTime)
+			}
+		}
 		deadline := c.sentPacketHandler.TimeUntilSend()
 		if deadline.IsZero() {
 			deadline = deadlineSendImmediately
@@ -2484,10 +2722,36 @@ func (c *Conn) triggerSending(now monotime.Time) error {
 	}
 }
 
-func (c *Conn) sendPackets(now monotime.Time) error {
+// sendPackets sends packets, as allowed by the pacer at sendTime.
+// If sendTime is in the future, the packets are handed to the kernel, which sends them at sendTime (see kernelPacingSendTime).
+// They are registered as sent now, and sendTime is only used for pacing.
+func (c *Conn) sendPackets(now, sendTime monotime.Time) error {
+	if c.perspective == protocol.PerspectiveClient && c.handshakeConfirmed && c.preferredAddress != nil && c.preferredAddress.ShouldSendProbe() {
+		// The client must not migrate before the handshake is confirmed, see section 9.6.1 of RFC 9000.
+		if connID, ok := c.connIDManager.GetConnIDForPath(preferredAddressPathID); ok {
+			probe, buf, err := c.packer.PackPathProbePacket(connID, []ackhandler.Frame{c.preferredAddress.NextProbe()}, c.version)
+			if err != nil {
+				return err
+			}
+			c.logger.Debugf("sending path probe packet to preferred address %s", c.preferredAddress.Addr())
+			c.logShortHeaderPacket(probe, protocol.ECNNon, buf.Len())
+			c.registerPackedShortHeaderPacket(probe, protocol.ECNNon, now, 0)
+			c.sendQueue.SendProbe(buf, c.preferredAddress.Addr())
+			// There's (likely) more data to send. Loop around again.
+			c.scheduleSending()
+			return nil
+		}
+	}
 	if c.perspective == protocol.PerspectiveClient && c.handshakeConfirmed {
 		if pm := c.pathManagerOutgoing.Load(); pm != nil {
 			connID, frame, tr, ok := pm.NextPathToProbe()
+			if ok && c.multipath != nil {
+				if err := c.sendMultipathPathProbe(connID, frame, tr, now); err != nil {
+					return err
+				}
+				c.scheduleSending()
+				return nil
+			}
 			if ok {
 				probe, buf, err := c.packer.PackPathProbePacket(connID, []ackhandler.Frame{frame}, c.version)
 				if err != nil {
@@ -2495,7 +2759,7 @@ func (c *Conn) sendPackets(now monotime.Time) error {
 				}
 				c.logger.Debugf("sending path probe packet from %s", c.LocalAddr())
 				c.logShortHeaderPacket(probe, protocol.ECNNon, buf.Len())
-				c.registerPackedShortHeaderPacket(probe, protocol.ECNNon, now)
+				c.registerPackedShortHeaderPacket(probe, protocol.ECNNon, now, 0)
 				tr.WriteTo(buf.Data, c.conn.RemoteAddr())
 				// There's (likely) more data to send. Loop around again.
 				c.scheduleSending()
@@ -2516,8 +2780,9 @@ func (c *Conn) sendPackets(now monotime.Time) error {
 		}
 		ecn := c.sentPacketHandler.ECNMode(true)
 		c.logShortHeaderPacket(p, ecn, buf.Len())
-		c.registerPackedShortHeaderPacket(p, ecn, now)
-		c.sendQueue.Send(buf, 0, ecn)
+		txTime := kernelPacingTXTime(now, sendTime)
+		c.registerPackedShortHeaderPacket(p, ecn, now, txTime)
+		c.sendQueue.Send(buf, 0, ecn, txTime)
 		// There's (likely) more data to send. Loop around again.
 		c.scheduleSending()
 		return nil
@@ -2550,16 +2815,17 @@ func (c *Conn) sendPackets(now monotime.Time) error {
 	}
 
 	if c.conn.capabilities().GSO {
-		return c.sendPacketsWithGSO(now)
+		return c.sendPacketsWithGSO(now, sendTime)
 	}
-	return c.sendPacketsWithoutGSO(now)
+	return c.sendPacketsWithoutGSO(now, sendTime)
 }
 
-func (c *Conn) sendPacketsWithoutGSO(now monotime.Time) error {
+func (c *Conn) sendPacketsWithoutGSO(now, sendTime monotime.Time) error {
 	for {
 		buf := getPacketBuffer()
 		ecn := c.sentPacketHandler.ECNMode(true)
-		if _, err := c.appendOneShortHeaderPacket(buf, c.maxPacketSize(), ecn, now); err != nil {
+		txTime := kernelPacingTXTime(now, sendTime)
+		if _, err := c.appendOneShortHeaderPacket(buf, c.maxPacketSize(), ecn, now, txTime); err != nil {
 			if err == errNothingToPack {
 				buf.Release()
 				return nil
@@ -2567,15 +2833,20 @@ func (c *Conn) sendPacketsWithoutGSO(now monotime.Time) error {
 			return err
 		}
 
-		c.sendQueue.Send(buf, 0, ecn)
+		c.sendQueue.Send(buf, 0, ecn, txTime)
 
 		if c.sendQueue.WouldBlock() {
 			return nil
 		}
-		sendMode := c.sentPacketHandler.SendMode(now)
+		sendMode := c.sentPacketHandler.SendMode(sendTime)
 		if sendMode == ackhandler.SendPacingLimited {
-			c.resetPacingDeadline()
-			return nil
+			t, ok := c.kernelPacingSendTime(now)
+			if !ok {
+				c.resetPacingDeadline()
+				return nil
+			}
+			sendTime = t
+			sendMode = ackhandler.SendAny
 		}
 		if sendMode != ackhandler.SendAny {
 			return nil
@@ -2591,14 +2862,14 @@ func (c *Conn) sendPacketsWithoutGSO(now monotime.Time) error {
 	}
 }
 
-func (c *Conn) sendPacketsWithGSO(now monotime.Time) error {
+func (c *Conn) sendPacketsWithGSO(now, sendTime monotime.Time) error {
 	buf := getLargePacketBuffer()
 	maxSize := c.maxPacketSize()
 
 	ecn := c.sentPacketHandler.ECNMode(true)
 	for {
 		var dontSendMore bool
-		size, err := c.appendOneShortHeaderPacket(buf, maxSize, ecn, now)
+		size, err := c.appendOneShortHeaderPacket(buf, maxSize, ecn, now, kernelPacingTXTime(now, sendTime))
 		if err != nil {
 			if err != errNothingToPack {
 				return err
@@ -2610,10 +2881,16 @@ func (c *Conn) sendPacketsWithGSO(now monotime.Time) error {
 			dontSendMore = true
 		}
 
+		nextSendTime := sendTime
 		if !dontSendMore {
-			sendMode := c.sentPacketHandler.SendMode(now)
+			sendMode := c.sentPacketHandler.SendMode(sendTime)
 			if sendMode == ackhandler.SendPacingLimited {
-				c.resetPacingDeadline()
+				if t, ok := c.kernelPacingSendTime(now); ok {
+					nextSendTime = t
+					sendMode = ackhandler.SendAny
+				} else {
+					c.resetPacingDeadline()
+				}
 			}
 			if sendMode != ackhandler.SendAny {
 				dontSendMore = true
@@ -2627,12 +2904,13 @@ func (c *Conn) sendPacketsWithGSO(now monotime.Time) error {
 		// 1. The congestion controller and pacer allow sending more
 		// 2. The last packet appended was a full-size packet
 		// 3. The next packet will have the same ECN marking
-		// 4. We still have enough space for another full-size packet in the buffer
-		if !dontSendMore && size == maxSize && nextECN == ecn && buf.Len()+maxSize <= buf.Cap() {
+		// 4. The next packet will be sent at the same time (relevant when using kernel pacing)
+		// 5. We still have enough space for another full-size packet in the buffer
+		if !dontSendMore && size == maxSize && nextECN == ecn && nextSendTime == sendTime && buf.Len()+maxSize <= buf.Cap() {
 			continue
 		}
 
-		c.sendQueue.Send(buf, uint16(maxSize), ecn)
+		c.sendQueue.Send(buf, uint16(maxSize), ecn, kernelPacingTXTime(now, sendTime))
 
 		if dontSendMore {
 			return nil
@@ -2651,6 +2929,7 @@ func (c *Conn) sendPacketsWithGSO(now monotime.Time) error {
 		}
 
 		ecn = nextECN
+		sendTime = nextSendTime
 		buf = getLargePacketBuffer()
 	}
 }
@@ -2659,10 +2938,41 @@ func (c *Conn) resetPacingDeadline() {
 	deadline := c.sentPacketHandler.TimeUntilSend()
 	if deadline.IsZero() {
 		deadline = deadlineSendImmediately
+	} else if c.handshakeConfirmed && c.conn.capabilities().KernelPacing {
+		// wake up early, so we can hand the next packets to the kernel ahead of time
+		deadline = deadline.Add(-protocol.MaxKernelPacingDelay)
 	}
 	c.pacingDeadline = deadline
 }
 
+// kernelPacingSendTime is used when the pacer doesn't allow sending a packet right now.
+// If the kernel takes care of pacing (using SO_TXTIME), packets that the pacer allows sending
+// within the next MaxKernelPacingDelay are handed to the kernel right away,
+// together with the time the kernel should send them at.
+// This saves waking up for every single packet.
+func (c *Conn) kernelPacingSendTime(now monotime.Time) (monotime.Time, bool) {
+	if !c.conn.capabilities().KernelPacing {
+		return 0, false
+	}
+	t := c.sentPacketHandler.TimeUntilSend()
+	if t.IsZero() || !t.After(now) || t.Sub(now) > protocol.MaxKernelPacingDelay {
+		return 0, false
+	}
+	if c.sentPacketHandler.SendMode(t) != ackhandler.SendAny {
+		return 0, false
+	}
+	return t, true
+}
+
+// kernelPacingTXTime returns the time the kernel should send a packet at,
+// or zero if the packet should be sent right away.
+func kernelPacingTXTime(now, sendTime monotime.Time) monotime.Time {
+	if sendTime.After(now) {
+		return sendTime
+	}
+	return 0
+}
+
 func (c *Conn) maybeSendAckOnlyPacket(now monotime.Time) error {
 	if !c.handshakeConfirmed {
 		ecn := c.sentPacketHandler.ECNMode(false)
@@ -2685,8 +2995,8 @@ func (c *Conn) maybeSendAckOnlyPacket(now monotime.Time) error {
 		return err
 	}
 	c.logShortHeaderPacket(p, ecn, buf.Len())
-	c.registerPackedShortHeaderPacket(p, ecn, now)
-	c.sendQueue.Send(buf, 0, ecn)
+	c.registerPackedShortHeaderPacket(p, ecn, now, 0)
+	c.sendQueue.Send(buf, 0, ecn, 0)
 	return nil
 }
 
@@ -2731,7 +3041,9 @@ func (c *Conn) sendProbePacket(sendMode ackhandler.SendMode, now monotime.Time)
 
 // appendOneShortHeaderPacket appends a new packet to the given packetBuffer.
 // If there was nothing to pack, the returned size is 0.
-func (c *Conn) appendOneShortHeaderPacket(buf *packetBuffer, maxSize protocol.ByteCount, ecn protocol.ECN, now monotime.Time) (protocol.ByteCount, error) {
+// appendOneShortHeaderPacket packs a short header packet and registers it as sent.
+// If txTime is not zero, the packet is handed to the kernel now, but sent at txTime (when using kernel pacing).
+func (c *Conn) appendOneShortHeaderPacket(buf *packetBuffer, maxSize protocol.ByteCount, ecn protocol.ECN, now, txTime monotime.Time) (protocol.ByteCount, error) {
 	startLen := buf.Len()
 	p, err := c.packer.AppendPacket(buf, maxSize, now, c.version)
 	if err != nil {
@@ -2739,14 +3051,15 @@ func (c *Conn) appendOneShortHeaderPacket(buf *packetBuffer, maxSize protocol.By
 	}
 	size := buf.Len() - startLen
 	c.logShortHeaderPacket(p, ecn, size)
-	c.registerPackedShortHeaderPacket(p, ecn, now)
+	c.registerPackedShortHeaderPacket(p, ecn, now, txTime)
 	return size, nil
 }
 
-func (c *Conn) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn protocol.ECN, now monotime.Time) {
+func (c *Conn) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn protocol.ECN, now, txTime monotime.Time) {
 	if p.IsPathProbePacket {
 		c.sentPacketHandler.SentPacket(
 			now,
+			txTime,
 			p.PacketNumber,
 			protocol.InvalidPacketNumber,
 			p.StreamFrames,
@@ -2769,6 +3082,7 @@ func (c *Conn) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn protocol
 	}
 	c.sentPacketHandler.SentPacket(
 		now,
+		txTime,
 		p.PacketNumber,
 		largestAcked,
 		p.StreamFrames,
@@ -2794,6 +3108,7 @@ func (c *Conn) sendPackedCoalescedPacket(packet *coalescedPacket, ecn protocol.E
 		}
 		c.sentPacketHandler.SentPacket(
 			now,
+			0,
 			p.header.PacketNumber,
 			largestAcked,
 			p.streamFrames,
@@ -2823,6 +3138,7 @@ func (c *Conn) sendPackedCoalescedPacket(packet *coalescedPacket, ecn protocol.E
 		}
 		c.sentPacketHandler.SentPacket(
 			now,
+			0,
 			p.PacketNumber,
 			largestAcked,
 			p.StreamFrames,
@@ -2835,7 +3151,7 @@ func (c *Conn) sendPackedCoalescedPacket(packet *coalescedPacket, ecn protocol.E
 		)
 	}
 	c.connIDManager.SentPacket()
-	c.sendQueue.Send(packet.buffer, 0, ecn)
+	c.sendQueue.Send(packet.buffer, 0, ecn, 0)
 	return nil
 }
 
@@ -2859,7 +3175,7 @@ func (c *Conn) sendConnectionClose(e error) ([]byte, error) {
 	}
 	ecn := c.sentPacketHandler.ECNMode(packet.IsOnlyShortHeaderPacket())
 	c.logCoalescedPacket(packet, ecn)
-	return packet.buffer.Data, c.conn.Write(packet.buffer.Data, 0, ecn)
+	return packet.buffer.Data, c.conn.Write(packet.buffer.Data, 0, ecn, 0)
 }
 
 func (c *Conn) maxPacketSize() protocol.ByteCount {
@@ -3003,6 +3319,10 @@ func (c *Conn) onHasStreamData(id protocol.StreamID, str *SendStream) {
 	c.scheduleSending()
 }
 
+func (c *Conn) onStreamPriorityChanged(id protocol.StreamID) {
+	c.framer.UpdateStreamPriority(id)
+}
+
 func (c *Conn) onHasStreamControlFrame(id protocol.StreamID, str streamControlFrameGetter) {
 	c.framer.AddStreamWithControlFrames(id, str)
 	c.scheduleSending()
@@ -3022,6 +3342,14 @@ func (c *Conn) onStreamCompleted(id protocol.StreamID) {
 // In addition, a datagram may be dropped before being sent out if the available packet size suddenly decreases.
 // If the payload is too large to be sent at the current time, a DatagramTooLargeError is returned.
 func (c *Conn) SendDatagram(p []byte) error {
+	return c.SendDatagramWithOptions(p, DatagramSendOptions{})
+}
+
+// SendDatagramWithOptions sends a message using a QUIC datagram, like SendDatagram.
+// The options allow setting a deadline after which the datagram is not sent anymore,
+// the priority of the datagram relative to stream data,
+// and a callback to learn if the datagram was acknowledged or lost.
+func (c *Conn) SendDatagramWithOptions(p []byte, opts DatagramSendOptions) error {
 	if !c.supportsDatagrams() {
 		return errors.New("datagram support disabled")
 	}
@@ -3038,7 +3366,7 @@ func (c *Conn) SendDatagram(p []byte) error {
 	}
 	f.Data = make([]byte, len(p))
 	copy(f.Data, p)
-	return c.datagramQueue.Add(f)
+	return c.datagramQueue.AddWithOptions(f, opts)
 }
 
 // ReceiveDatagram gets a message received in a QUIC datagram, as specified in RFC 9221.
@@ -3066,8 +3394,19 @@ func (c *Conn) getPathManager() *pathManagerOutgoing {
 
 	// Initialize the path manager
 	new := newPathManagerOutgoing(
-		c.connIDManager.GetConnIDForPath,
-		c.connIDManager.RetireConnIDForPath,
+		func(id pathID) (protocol.ConnectionID, bool) {
+			if c.multipath != nil {
+				return c.openClientPath(id)
+			}
+			return c.connIDManager.GetConnIDForPath(id)
+		},
+		func(id pathID) {
+			if c.multipath != nil && c.multipath.queueAbandonClientPath(id) {
+				c.scheduleSending()
+				return
+			}
+			c.connIDManager.RetireConnIDForPath(id)
+		},
 		c.scheduleSending,
 	)
 	if c.pathManagerOutgoing.CompareAndSwap(old, new) {
@@ -3113,6 +3452,30 @@ func (c *Conn) HandshakeComplete() <-chan struct{} {
 	return c.handshakeCompleteChan
 }
 
+// InitiateKeyUpdate initiates an update of the 1-RTT keys (see section 6 of RFC 9001).
+// The keys are rolled when the next packet is sent.
+// It returns ErrHandshakeNotConfirmed if the handshake is not yet confirmed,
+// and ErrKeyUpdateInProgress if the peer hasn't acknowledged a packet sent with the current keys yet.
+// In that case, the application should try again later.
+func (c *Conn) InitiateKeyUpdate() error {
+	errChan := make(chan error, 1)
+	select {
+	case c.keyUpdateRequests <- errChan:
+	case <-c.ctx.Done():
+		return context.Cause(c.ctx)
+	}
+	return <-errChan
+}
+
+func (c *Conn) initiateKeyUpdate() error {
+	if err := c.cryptoStreamHandler.InitiateKeyUpdate(); err != nil {
+		return err
+	}
+	// make sure that a packet is sent, such that the peer learns about the key update
+	c.framer.QueueControlFrame(&wire.PingFrame{})
+	return nil
+}
+
 // QlogTrace returns the qlog trace of the QUIC connection.
 // It is nil if qlog is not enabled.
 func (c *Conn) QlogTrace() qlogwriter.Trace {
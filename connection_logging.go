@@ -114,13 +114,25 @@ func (c *Conn) logShortHeaderPacket(p shortHeaderPacket, ecn protocol.ECN, size
 
 func (c *Conn) logShortHeaderPacketWithDatagramID(p shortHeaderPacket, ecn protocol.ECN, size protocol.ByteCount, isCoalesced bool, datagramID qlog.DatagramID) {
 	if c.logger.Debug() && !isCoalesced {
-		c.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, 1-RTT (ECN: %s)", p.PacketNumber, size, c.logID, ecn)
+		if p.PathID != protocol.InitialPathID {
+			c.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, 1-RTT, path %d (ECN: %s)", p.PacketNumber, size, c.logID, p.PathID, ecn)
+		} else {
+			c.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, 1-RTT (ECN: %s)", p.PacketNumber, size, c.logID, ecn)
+		}
+	}
+	// When using the multipath extension, acknowledgements for other paths are sent in PATH_ACK frames.
+	var ack wire.Frame
+	if p.Ack != nil {
+		ack = p.Ack
+		if p.PathID != protocol.InitialPathID {
+			ack = &wire.PathAckFrame{PathID: p.PathID, AckFrame: *p.Ack}
+		}
 	}
 	// quic-go logging
 	if c.logger.Debug() {
 		wire.LogShortHeader(c.logger, p.DestConnID, p.PacketNumber, p.PacketNumberLen, p.KeyPhase)
-		if p.Ack != nil {
-			wire.LogFrame(c.logger, p.Ack, true)
+		if ack != nil {
+			wire.LogFrame(c.logger, ack, true)
 		}
 		for _, f := range p.Frames {
 			wire.LogFrame(c.logger, f.Frame, true)
@@ -137,8 +149,8 @@ func (c *Conn) logShortHeaderPacketWithDatagramID(p shortHeaderPacket, ecn proto
 			numFrames++
 		}
 		fs := make([]qlog.Frame, 0, numFrames)
-		if p.Ack != nil {
-			fs = append(fs, toQlogFrame(p.Ack))
+		if ack != nil {
+			fs = append(fs, toQlogFrame(ack))
 		}
 		for _, f := range p.Frames {
 			fs = append(fs, toQlogFrame(f.Frame))
@@ -209,6 +221,7 @@ func (c *Conn) qlogTransportParameters(tp *wire.TransportParameters, sentBy prot
 		MaxUDPPayloadSize:               tp.MaxUDPPayloadSize,
 		AckDelayExponent:                tp.AckDelayExponent,
 		MaxAckDelay:                     tp.MaxAckDelay,
+		MinAckDelay:                     tp.MinAckDelay,
 		ActiveConnectionIDLimit:         tp.ActiveConnectionIDLimit,
 		InitialMaxData:                  tp.InitialMaxData,
 		InitialMaxStreamDataBidiLocal:   tp.InitialMaxStreamDataBidiLocal,
@@ -217,6 +230,7 @@ func (c *Conn) qlogTransportParameters(tp *wire.TransportParameters, sentBy prot
 		InitialMaxStreamsBidi:           int64(tp.MaxBidiStreamNum),
 		InitialMaxStreamsUni:            int64(tp.MaxUniStreamNum),
 		MaxDatagramFrameSize:            tp.MaxDatagramFrameSize,
+		GreaseQUICBit:                   tp.GreaseQUICBit,
 		EnableResetStreamAt:             tp.EnableResetStreamAt,
 	}
 	if sentBy == c.perspective {
@@ -313,3 +327,24 @@ func startedConnectionEvent(local, remote *net.UDPAddr) qlog.StartedConnection {
 	}
 	return qlog.StartedConnection{Local: localInfo, Remote: remoteInfo}
 }
+
+// qlogMigrationComplete logs that the connection is now using the current local and remote address.
+func (c *Conn) qlogMigrationComplete() {
+	if c.qlogger == nil {
+		return
+	}
+	var local, remote *net.UDPAddr
+	if addr, ok := c.conn.LocalAddr().(*net.UDPAddr); ok {
+		local = addr
+	}
+	if addr, ok := c.conn.RemoteAddr().(*net.UDPAddr); ok {
+		remote = addr
+	}
+	// use the same logic for the path endpoints as for the connection_started event
+	ev := startedConnectionEvent(local, remote)
+	c.qlogger.RecordEvent(qlog.MigrationStateUpdated{
+		State:  qlog.MigrationStateComplete,
+		Local:  ev.Local,
+		Remote: ev.Remote,
+	})
+}
@@ -70,6 +70,19 @@ func connectionOptHandshakeConfirmed() testConnectionOpt {
 	}
 }
 
+// kernelPacingSendConn is a sendConn that has kernel pacing (SO_TXTIME) enabled.
+type kernelPacingSendConn struct{ sendConn }
+
+func (c *kernelPacingSendConn) capabilities() connCapabilities {
+	capabilities := c.sendConn.capabilities()
+	capabilities.KernelPacing = true
+	return capabilities
+}
+
+func connectionOptKernelPacing() testConnectionOpt {
+	return func(conn *Conn) { conn.conn = &kernelPacingSendConn{sendConn: conn.conn} }
+}
+
 func connectionOptRTT(rtt time.Duration) testConnectionOpt {
 	rttStats := utils.NewRTTStats()
 	rttStats.UpdateRTT(rtt, 0)
@@ -272,6 +285,49 @@ func TestConnectionServerInvalidFrames(t *testing.T) {
 	}
 }
 
+func TestConnectionHandleAckFrequencyFrames(t *testing.T) {
+	tc := newServerTestConnection(
+		t,
+		gomock.NewController(t),
+		&Config{EnableAckFrequency: true, DisablePathMTUDiscovery: true},
+		false,
+	)
+
+	for _, f := range []wire.Frame{
+		&wire.AckFrequencyFrame{
+			SequenceNumber:        1,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    10 * time.Millisecond,
+			ReorderingThreshold:   3,
+		},
+		&wire.ImmediateAckFrame{},
+	} {
+		data, err := f.Append(nil, protocol.Version1)
+		require.NoError(t, err)
+		isAckEliciting, _, _, err := tc.conn.handleFrames(data, protocol.ConnectionID{}, protocol.Encryption1RTT, nil, monotime.Now())
+		require.NoError(t, err)
+		require.True(t, isAckEliciting)
+	}
+
+	// requesting a max_ack_delay smaller than the min_ack_delay is a protocol violation
+	_, err := tc.conn.handleFrame(
+		&wire.AckFrequencyFrame{SequenceNumber: 2, RequestMaxAckDelay: protocol.MinAckDelay - 1},
+		protocol.Encryption1RTT,
+		protocol.ConnectionID{},
+		monotime.Now(),
+	)
+	require.ErrorIs(t, err, &qerr.TransportError{ErrorCode: qerr.ProtocolViolation})
+}
+
+func TestConnectionAckFrequencyDisabled(t *testing.T) {
+	tc := newServerTestConnection(t, gomock.NewController(t), nil, false)
+
+	data, err := (&wire.ImmediateAckFrame{}).Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	_, _, _, err = tc.conn.handleFrames(data, protocol.ConnectionID{}, protocol.Encryption1RTT, nil, monotime.Now())
+	require.ErrorIs(t, err, &qerr.TransportError{ErrorCode: qerr.FrameEncodingError, FrameType: uint64(wire.FrameTypeImmediateAck)})
+}
+
 func TestConnectionClose(t *testing.T) {
 	t.Run("transport error", func(t *testing.T) {
 		expectedErr := &qerr.TransportError{
@@ -305,7 +361,7 @@ func testConnectionClose(t *testing.T, useApplicationClose bool, expectedErr err
 		} else {
 			tc.packer.EXPECT().PackConnectionClose(expectedErr, gomock.Any(), protocol.Version1).Return(&coalescedPacket{buffer: b}, nil)
 		}
-		tc.sendConn.EXPECT().Write([]byte("connection close"), gomock.Any(), gomock.Any())
+		tc.sendConn.EXPECT().Write([]byte("connection close"), gomock.Any(), gomock.Any(), monotime.Time(0))
 		tc.connRunner.EXPECT().ReplaceWithClosed(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 
 		go func() { errChan <- tc.conn.run() }()
@@ -382,7 +438,7 @@ func getLongHeaderPacket(t *testing.T, remoteAddr net.Addr, extHdr *wire.Extende
 
 func getShortHeaderPacket(t *testing.T, remoteAddr net.Addr, connID protocol.ConnectionID, pn protocol.PacketNumber, data []byte) receivedPacket {
 	t.Helper()
-	b, err := wire.AppendShortHeader(nil, connID, pn, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
+	b, err := wire.AppendShortHeader(nil, connID, pn, protocol.PacketNumberLen2, protocol.KeyPhaseOne, false)
 	require.NoError(t, err)
 	return receivedPacket{
 		remoteAddr: remoteAddr,
@@ -496,7 +552,7 @@ func TestConnectionServerInvalidPackets(t *testing.T) {
 			},
 			nil,
 		)
-		p.data[0] ^= 0x40 // unset the QUIC bit
+		p.data[5] = protocol.MaxConnIDLen + 1 // invalid destination connection ID length
 		wasProcessed, err := tc.conn.handleOnePacket(p, 42)
 		require.NoError(t, err)
 		require.False(t, wasProcessed)
@@ -659,6 +715,44 @@ func TestConnectionUnpacking(t *testing.T) {
 	require.True(t, wasProcessed)
 }
 
+func TestConnectionSpinBit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	unpacker := NewMockUnpacker(mockCtrl)
+	tc := newServerTestConnection(t, mockCtrl, nil, false, connectionOptUnpacker(unpacker))
+	// the spin bit is randomly disabled for 1 in 16 connections
+	tc.conn.spinBit.disabled = false
+
+	receive := func(t *testing.T, pn protocol.PacketNumber, spin bool) {
+		t.Helper()
+		packet := getShortHeaderPacket(t, tc.remoteAddr, tc.srcConnID, pn, nil)
+		if spin {
+			packet.data[0] |= 0x20
+		}
+		unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(
+			pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* PADDING */, nil,
+		)
+		wasProcessed, err := tc.conn.handleOnePacket(packet, 0)
+		require.NoError(t, err)
+		require.True(t, wasProcessed)
+	}
+
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	require.False(t, tc.conn.spinBit.Value(connID))
+	receive(t, 10, true)
+	require.True(t, tc.conn.spinBit.Value(connID))
+	// reordered packets don't change the spin value
+	receive(t, 9, false)
+	require.True(t, tc.conn.spinBit.Value(connID))
+	receive(t, 11, false)
+	require.False(t, tc.conn.spinBit.Value(connID))
+}
+
+func TestConnectionSpinBitDisabled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	tc := newServerTestConnection(t, mockCtrl, &Config{DisableSpinBit: true}, false)
+	require.True(t, tc.conn.spinBit.Disabled())
+}
+
 func TestConnectionUnpackCoalescedPacket(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	unpacker := NewMockUnpacker(mockCtrl)
@@ -800,7 +894,7 @@ func testConnectionUnpackFailureFatal(t *testing.T, unpackErr error) error {
 	errChan := make(chan error, 1)
 	go func() { errChan <- tc.conn.run() }()
 
-	tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+	tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0))
 	tc.conn.handlePacket(getShortHeaderPacket(t, tc.remoteAddr, tc.srcConnID, 0x42, nil))
 
 	select {
@@ -1083,6 +1177,84 @@ func TestConnectionTransportParameters(t *testing.T) {
 	)
 }
 
+func TestConnectionQUICBitGreasing(t *testing.T) {
+	t.Run("peer supports greasing", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		tc := newServerTestConnection(t, mockCtrl, nil, false)
+		tc.packer.EXPECT().EnableQUICBitGreasing()
+		require.NoError(t, tc.conn.handleTransportParameters(&wire.TransportParameters{
+			OriginalDestinationConnectionID: tc.destConnID,
+			GreaseQUICBit:                   true,
+		}))
+	})
+
+	t.Run("peer doesn't support greasing", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		tc := newServerTestConnection(t, mockCtrl, nil, false)
+		// no call to EnableQUICBitGreasing expected
+		require.NoError(t, tc.conn.handleTransportParameters(&wire.TransportParameters{
+			OriginalDestinationConnectionID: tc.destConnID,
+		}))
+	})
+
+	t.Run("accepting packets with the QUIC bit cleared", func(t *testing.T) {
+		testConnectionClearedQUICBit(t, false)
+	})
+
+	t.Run("dropping packets with the QUIC bit cleared", func(t *testing.T) {
+		testConnectionClearedQUICBit(t, true)
+	})
+}
+
+func testConnectionClearedQUICBit(t *testing.T, disableGreasing bool) {
+	mockCtrl := gomock.NewController(t)
+	unpacker := NewMockUnpacker(mockCtrl)
+	var eventRecorder events.Recorder
+	tc := newServerTestConnection(t,
+		mockCtrl,
+		&Config{DisableQUICBitGreasing: disableGreasing},
+		false,
+		connectionOptUnpacker(unpacker),
+		connectionOptTracer(&eventRecorder),
+	)
+
+	p := getLongHeaderPacket(t,
+		tc.remoteAddr,
+		&wire.ExtendedHeader{
+			Header:          wire.Header{Type: protocol.PacketTypeHandshake, Version: Version1},
+			PacketNumberLen: protocol.PacketNumberLen2,
+		},
+		nil,
+	)
+	p.data[0] &^= 0x40
+	if disableGreasing {
+		// the packet is dropped before it is unpacked
+		wasProcessed, err := tc.conn.handleOnePacket(p, 42)
+		require.NoError(t, err)
+		require.False(t, wasProcessed)
+		require.Equal(t,
+			[]qlogwriter.Event{
+				qlog.PacketDropped{
+					Raw:        qlog.RawInfo{Length: int(p.Size())},
+					DatagramID: 42,
+					Trigger:    qlog.PacketDropHeaderParseError,
+				},
+			},
+			eventRecorder.Events(qlog.PacketDropped{}),
+		)
+		return
+	}
+
+	unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any()).Return(nil, handshake.ErrDecryptionFailed)
+	wasProcessed, err := tc.conn.handleOnePacket(p, 42)
+	require.NoError(t, err)
+	require.False(t, wasProcessed)
+	// the packet was passed to the unpacker
+	dropped := eventRecorder.Events(qlog.PacketDropped{})
+	require.NotEmpty(t, dropped)
+	require.Equal(t, qlog.PacketDropPayloadDecryptError, dropped[0].(qlog.PacketDropped).Trigger)
+}
+
 func TestConnectionHandleMaxStreamsFrame(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
@@ -1310,6 +1482,45 @@ func TestConnectionHandshakeServer(t *testing.T) {
 	}
 }
 
+func TestConnectionInitiateKeyUpdate(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		cs := mocks.NewMockCryptoSetup(mockCtrl)
+		tc := newServerTestConnection(t, mockCtrl, nil, false, connectionOptCryptoSetup(cs))
+
+		cs.EXPECT().StartHandshake(gomock.Any())
+		cs.EXPECT().NextEvent().Return(handshake.Event{Kind: handshake.EventNoEvent})
+		tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(shortHeaderPacket{}, errNothingToPack).AnyTimes()
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- tc.conn.run() }()
+		synctest.Wait()
+
+		cs.EXPECT().InitiateKeyUpdate().Return(handshake.ErrKeyUpdateInProgress)
+		require.ErrorIs(t, tc.conn.InitiateKeyUpdate(), ErrKeyUpdateInProgress)
+		cs.EXPECT().InitiateKeyUpdate()
+		require.NoError(t, tc.conn.InitiateKeyUpdate())
+		synctest.Wait()
+
+		// test teardown
+		cs.EXPECT().Close()
+		tc.connRunner.EXPECT().Remove(gomock.Any()).AnyTimes()
+		tc.conn.destroy(nil)
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+		default:
+			t.Fatal("should have shut down")
+		}
+		require.ErrorIs(t, tc.conn.InitiateKeyUpdate(), context.Canceled)
+
+		// a PING frame was queued to make sure the peer learns about the key update
+		frames, _, _ := tc.conn.framer.Append(nil, nil, protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+		require.Len(t, frames, 1)
+		require.Equal(t, &wire.PingFrame{}, frames[0].Frame)
+	})
+}
+
 func TestConnectionHandshakeClient(t *testing.T) {
 	t.Run("without preferred address", func(t *testing.T) {
 		testConnectionHandshakeClient(t, false)
@@ -1324,11 +1535,16 @@ func testConnectionHandshakeClient(t *testing.T, usePreferredAddress bool) {
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
 	unpacker := NewMockUnpacker(mockCtrl)
 	tc := newClientTestConnection(t, mockCtrl, nil, false, connectionOptCryptoSetup(cs), connectionOptUnpacker(unpacker))
-	tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0)).AnyTimes()
 
 	// the state transition is driven by processing of a CRYPTO frame
 	hdr := &wire.ExtendedHeader{
-		Header:          wire.Header{Type: protocol.PacketTypeHandshake, Version: protocol.Version1},
+		Header: wire.Header{
+			Type:             protocol.PacketTypeHandshake,
+			SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+			DestConnectionID: tc.srcConnID,
+			Version:          protocol.Version1,
+		},
 		PacketNumberLen: protocol.PacketNumberLen2,
 	}
 	data, err := (&wire.CryptoFrame{Data: []byte("foobar")}).Append(nil, protocol.Version1)
@@ -1336,6 +1552,7 @@ func testConnectionHandshakeClient(t *testing.T, usePreferredAddress bool) {
 
 	tp := &wire.TransportParameters{
 		OriginalDestinationConnectionID: tc.destConnID,
+		InitialSourceConnectionID:       hdr.SrcConnectionID,
 		MaxIdleTimeout:                  time.Hour,
 	}
 	preferredAddressConnID := protocol.ParseConnectionID([]byte{10, 8, 6, 4})
@@ -1413,6 +1630,23 @@ func testConnectionHandshakeClient(t *testing.T, usePreferredAddress bool) {
 		),
 	)
 	tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(shortHeaderPacket{}, errNothingToPack).AnyTimes()
+	// Once the handshake is confirmed, the client probes the path to the server's preferred address,
+	// using the connection ID provided in the preferred_address transport parameter.
+	preferredAddr := net.UDPAddrFromAddrPort(netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), 42))
+	var pathChallenge *wire.PathChallengeFrame
+	probeSent := make(chan struct{})
+	if usePreferredAddress {
+		tc.connRunner.EXPECT().AddResetToken(preferredAddressResetToken, gomock.Any())
+		tc.packer.EXPECT().PackPathProbePacket(preferredAddressConnID, gomock.Any(), protocol.Version1).DoAndReturn(
+			func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+				pathChallenge = frames[0].Frame.(*wire.PathChallengeFrame)
+				return shortHeaderPacket{IsPathProbePacket: true}, getPacketBuffer(), nil
+			},
+		)
+		tc.sendConn.EXPECT().WriteTo(gomock.Any(), preferredAddr).DoAndReturn(
+			func([]byte, net.Addr) error { close(probeSent); return nil },
+		)
+	}
 	p = getLongHeaderPacket(t, tc.remoteAddr, hdr, nil)
 	tc.conn.handlePacket(receivedPacket{data: p.data, buffer: p.buffer, rcvTime: monotime.Now()})
 
@@ -1423,11 +1657,30 @@ func testConnectionHandshakeClient(t *testing.T, usePreferredAddress bool) {
 	}
 
 	if usePreferredAddress {
-		tc.connRunner.EXPECT().AddResetToken(preferredAddressResetToken, gomock.Any())
-	}
-	nextConnID := tc.conn.connIDManager.Get()
-	if usePreferredAddress {
-		require.Equal(t, preferredAddressConnID, nextConnID)
+		select {
+		case <-probeSent:
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+
+		// receiving the PATH_RESPONSE validates the path, and the client migrates to the preferred address
+		data, err := (&wire.PathResponseFrame{Data: pathChallenge.Data}).Append(nil, protocol.Version1)
+		require.NoError(t, err)
+		migrated := make(chan struct{})
+		gomock.InOrder(
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(
+				protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, data, nil,
+			),
+			tc.sendConn.EXPECT().ChangeRemoteAddr(preferredAddr, packetInfo{}).Do(
+				func(net.Addr, packetInfo) { close(migrated) },
+			),
+		)
+		tc.conn.handlePacket(getShortHeaderPacket(t, tc.remoteAddr, tc.srcConnID, 10, []byte("foobar")))
+		select {
+		case <-migrated:
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
 	}
 
 	// test teardown
@@ -1443,6 +1696,15 @@ func testConnectionHandshakeClient(t *testing.T, usePreferredAddress bool) {
 	case <-time.After(time.Second):
 		t.Fatal("timeout")
 	}
+
+	if usePreferredAddress {
+		// the connection ID of the preferred address is now used for all packets,
+		// and the connection ID used during the handshake was retired
+		require.Equal(t, preferredAddressConnID, tc.conn.connIDManager.activeConnectionID)
+		require.Empty(t, tc.conn.connIDManager.pathProbing)
+		frames, _, _ := tc.conn.framer.Append(nil, nil, protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+		require.Contains(t, frames, ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 0}})
+	}
 }
 
 func TestConnection0RTTTransportParameters(t *testing.T) {
@@ -1450,7 +1712,7 @@ func TestConnection0RTTTransportParameters(t *testing.T) {
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
 	unpacker := NewMockUnpacker(mockCtrl)
 	tc := newClientTestConnection(t, mockCtrl, nil, false, connectionOptCryptoSetup(cs), connectionOptUnpacker(unpacker))
-	tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0)).AnyTimes()
 
 	// the state transition is driven by processing of a CRYPTO frame
 	hdr := &wire.ExtendedHeader{
@@ -1805,15 +2067,15 @@ func TestConnectionPacketPacing(t *testing.T) {
 		gomock.InOrder(
 			// 1. allow 2 packets to be sent
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny),
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny),
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
 			// 2. become pacing limited for 25ms
 			sph.EXPECT().TimeUntilSend().DoAndReturn(func() monotime.Time { return monotime.Now().Add(step) }),
 			// 3. send another packet
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny),
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
 			// 4. become pacing limited for 25ms...
 			sph.EXPECT().TimeUntilSend().DoAndReturn(func() monotime.Time { return monotime.Now().Add(step) }),
@@ -1822,7 +2084,7 @@ func TestConnectionPacketPacing(t *testing.T) {
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
 			// 5. stop the test by becoming pacing limited forever
 			sph.EXPECT().TimeUntilSend().Return(monotime.Now().Add(time.Hour)),
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 		)
 		sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 		for i := range 3 {
@@ -1847,7 +2109,7 @@ func TestConnectionPacketPacing(t *testing.T) {
 			data []byte
 		}
 		sendChan := make(chan sentPacket, 10)
-		sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN) {
+		sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0)).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, _ monotime.Time) {
 			sendChan <- sentPacket{time: monotime.Now(), data: b.Data}
 		}).Times(4)
 
@@ -1957,6 +2219,127 @@ func TestConnectionPacingAndSendQueue(t *testing.T) {
 	})
 }
 
+func TestConnectionKernelPacing(t *testing.T) {
+	t.Run("without GSO", func(t *testing.T) {
+		testConnectionKernelPacing(t, false)
+	})
+	t.Run("with GSO", func(t *testing.T) {
+		testConnectionKernelPacing(t, true)
+	})
+}
+
+func testConnectionKernelPacing(t *testing.T, gso bool) {
+	synctest.Test(t, func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+		sender := NewMockSender(mockCtrl)
+
+		tc := newServerTestConnection(t,
+			mockCtrl,
+			nil,
+			gso,
+			connectionOptSentPacketHandler(sph),
+			connectionOptSender(sender),
+			connectionOptHandshakeConfirmed(),
+			connectionOptKernelPacing(),
+		)
+		sender.EXPECT().Run()
+		sender.EXPECT().WouldBlock().AnyTimes()
+
+		// the pacer allows sending one packet every 2ms
+		const interval = 2 * time.Millisecond
+		start := monotime.Now()
+		nextSendTime := start
+		sph.EXPECT().GetLossDetectionTimeout().Return(start.Add(time.Hour)).AnyTimes()
+		sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
+		sph.EXPECT().SendMode(gomock.Any()).DoAndReturn(func(now monotime.Time) ackhandler.SendMode {
+			if now.Before(nextSendTime) {
+				return ackhandler.SendPacingLimited
+			}
+			return ackhandler.SendAny
+		}).AnyTimes()
+		sph.EXPECT().TimeUntilSend().DoAndReturn(func() monotime.Time { return nextSendTime }).AnyTimes()
+		var sentTimes, txTimes []monotime.Time
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(t, txTime monotime.Time, _, _ protocol.PacketNumber, _ []ackhandler.StreamFrame, _ []ackhandler.Frame, _ protocol.EncryptionLevel, _ protocol.ECN, _ protocol.ByteCount, _, _ bool) {
+				sentTimes = append(sentTimes, t)
+				txTimes = append(txTimes, txTime)
+				if txTime.IsZero() {
+					txTime = t
+				}
+				nextSendTime = txTime.Add(interval)
+			},
+		).Times(4)
+
+		maxPacketSize := tc.conn.maxPacketSize()
+		for i := range 4 {
+			tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), Version1).DoAndReturn(
+				func(buf *packetBuffer, _ protocol.ByteCount, _ monotime.Time, _ protocol.Version) (shortHeaderPacket, error) {
+					buf.Data = append(buf.Data, bytes.Repeat([]byte{byte(i)}, int(maxPacketSize))...)
+					return shortHeaderPacket{PacketNumber: protocol.PacketNumber(i + 1)}, nil
+				},
+			)
+		}
+		tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), Version1).Return(shortHeaderPacket{}, errNothingToPack)
+
+		type sentPacket struct {
+			time   monotime.Time
+			txTime monotime.Time
+		}
+		sendChan := make(chan sentPacket, 10)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(b *packetBuffer, _ uint16, _ protocol.ECN, txTime monotime.Time) {
+			require.Len(t, b.Data, int(maxPacketSize)) // every packet is sent in its own GSO batch
+			sendChan <- sentPacket{time: monotime.Now(), txTime: txTime}
+		}).Times(4)
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- tc.conn.run() }()
+		tc.conn.scheduleSending()
+
+		var packets []sentPacket
+		for range 4 {
+			select {
+			case p := <-sendChan:
+				packets = append(packets, p)
+			case <-time.After(time.Hour):
+				t.Fatal("should have sent a packet")
+			}
+		}
+		// The first packet is sent right away.
+		// The next two packets are handed to the kernel right away, they're sent within the next MaxKernelPacingDelay.
+		require.Equal(t, sentPacket{time: start}, packets[0])
+		require.Equal(t, sentPacket{time: start, txTime: start.Add(interval)}, packets[1])
+		require.Equal(t, sentPacket{time: start, txTime: start.Add(2 * interval)}, packets[2])
+		// The connection wakes up MaxKernelPacingDelay before the next packet is due.
+		require.Equal(t,
+			sentPacket{time: start.Add(3*interval - protocol.MaxKernelPacingDelay), txTime: start.Add(3 * interval)},
+			packets[3],
+		)
+		// The packets are registered as sent when they're handed to the kernel,
+		// but the time the kernel sends them at is used for pacing.
+		require.Equal(t, []monotime.Time{start, start, start, start.Add(3*interval - protocol.MaxKernelPacingDelay)}, sentTimes)
+		require.Equal(t, []monotime.Time{0, start.Add(interval), start.Add(2 * interval), start.Add(3 * interval)}, txTimes)
+
+		// wait for the connection to wake up again, and find nothing to send
+		time.Sleep(4 * interval)
+		synctest.Wait()
+		require.True(t, mockCtrl.Satisfied())
+
+		// test teardown
+		sender.EXPECT().Close()
+		tc.connRunner.EXPECT().Remove(gomock.Any()).AnyTimes()
+		tc.conn.destroy(nil)
+
+		synctest.Wait()
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+		default:
+			t.Fatal("should have timed out")
+		}
+	})
+}
+
 func TestConnectionIdleTimeout(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
@@ -1977,7 +2360,7 @@ func TestConnectionIdleTimeout(t *testing.T) {
 
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 		var lastSendTime monotime.Time
 		tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
@@ -1988,7 +2371,7 @@ func TestConnectionIdleTimeout(t *testing.T) {
 			},
 		)
 		tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(shortHeaderPacket{}, errNothingToPack)
-		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0))
 		tc.connRunner.EXPECT().Remove(gomock.Any()).AnyTimes()
 
 		errChan := make(chan error, 1)
@@ -2044,7 +2427,7 @@ func testConnectionKeepAlive(t *testing.T, enable, expectKeepAlive bool) {
 		// Receive a packet. This starts the keep-alive timer.
 		buf := getPacketBuffer()
 		var err error
-		buf.Data, err = wire.AppendShortHeader(buf.Data, tc.srcConnID, 1, protocol.PacketNumberLen1, protocol.KeyPhaseZero)
+		buf.Data, err = wire.AppendShortHeader(buf.Data, tc.srcConnID, 1, protocol.PacketNumberLen1, protocol.KeyPhaseZero, false)
 		require.NoError(t, err)
 		buf.Data = append(buf.Data, []byte("packet")...)
 
@@ -2122,9 +2505,9 @@ func TestConnectionACKTimer(t *testing.T) {
 
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 		sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
-		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0)).AnyTimes()
 
 		// Set initial alarm timeout far in the future
 		_ = rph.ReceivedPacket(1, protocol.ECNNon, protocol.Encryption1RTT, monotime.Now().Add(time.Hour), true)
@@ -2207,7 +2590,7 @@ func TestConnectionGSOBatch(t *testing.T) {
 		// allow packets to be sent
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().ECNMode(gomock.Any()).Return(protocol.ECT1).AnyTimes()
 
@@ -2226,8 +2609,8 @@ func TestConnectionGSOBatch(t *testing.T) {
 		}
 		done := make(chan struct{})
 		tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(shortHeaderPacket{}, errNothingToPack)
-		tc.sendConn.EXPECT().Write(expectedData, uint16(maxPacketSize), protocol.ECT1).DoAndReturn(
-			func([]byte, uint16, protocol.ECN) error { close(done); return nil },
+		tc.sendConn.EXPECT().Write(expectedData, uint16(maxPacketSize), protocol.ECT1, monotime.Time(0)).DoAndReturn(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error { close(done); return nil },
 		)
 
 		errChan := make(chan error, 1)
@@ -2273,7 +2656,7 @@ func TestConnectionGSOBatchPacketSize(t *testing.T) {
 		// allow packets to be sent
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().ECNMode(gomock.Any()).Return(protocol.ECT1).AnyTimes()
 
@@ -2313,9 +2696,9 @@ func TestConnectionGSOBatchPacketSize(t *testing.T) {
 
 		done := make(chan struct{})
 		gomock.InOrder(
-			tc.sendConn.EXPECT().Write(expectedData, uint16(maxPacketSize), protocol.ECT1),
-			tc.sendConn.EXPECT().Write([]byte("foobar"), uint16(maxPacketSize), protocol.ECT1).DoAndReturn(
-				func([]byte, uint16, protocol.ECN) error { close(done); return nil },
+			tc.sendConn.EXPECT().Write(expectedData, uint16(maxPacketSize), protocol.ECT1, monotime.Time(0)),
+			tc.sendConn.EXPECT().Write([]byte("foobar"), uint16(maxPacketSize), protocol.ECT1, monotime.Time(0)).DoAndReturn(
+				func([]byte, uint16, protocol.ECN, monotime.Time) error { close(done); return nil },
 			),
 		)
 		errChan := make(chan error, 1)
@@ -2361,7 +2744,7 @@ func TestConnectionGSOBatchECN(t *testing.T) {
 		ecnMode := protocol.ECT1
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().ECNMode(gomock.Any()).DoAndReturn(func(bool) protocol.ECN { return ecnMode }).AnyTimes()
 
@@ -2399,9 +2782,9 @@ func TestConnectionGSOBatchECN(t *testing.T) {
 		gomock.InOrder(calls...)
 
 		done3 := make(chan struct{})
-		tc.sendConn.EXPECT().Write(expectedData, uint16(maxPacketSize), protocol.ECT1)
-		tc.sendConn.EXPECT().Write([]byte("foobar"), uint16(maxPacketSize), protocol.ECNCE).DoAndReturn(
-			func([]byte, uint16, protocol.ECN) error { close(done3); return nil },
+		tc.sendConn.EXPECT().Write(expectedData, uint16(maxPacketSize), protocol.ECT1, monotime.Time(0))
+		tc.sendConn.EXPECT().Write([]byte("foobar"), uint16(maxPacketSize), protocol.ECNCE, monotime.Time(0)).DoAndReturn(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error { close(done3); return nil },
 		)
 
 		errChan := make(chan error, 1)
@@ -2470,7 +2853,7 @@ func testConnectionPTOProbePackets(t *testing.T, encLevel protocol.EncryptionLev
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
 		sph.EXPECT().ECNMode(gomock.Any())
 		sph.EXPECT().QueueProbePacket(encLevel).Return(false)
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 
 		tc.packer.EXPECT().PackPTOProbePacket(encLevel, gomock.Any(), true, gomock.Any(), protocol.Version1).DoAndReturn(
 			func(protocol.EncryptionLevel, protocol.ByteCount, bool, monotime.Time, protocol.Version) (*coalescedPacket, error) {
@@ -2481,8 +2864,8 @@ func testConnectionPTOProbePackets(t *testing.T, encLevel protocol.EncryptionLev
 			},
 		)
 		done := make(chan struct{})
-		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func([]byte, uint16, protocol.ECN) error { close(done); return nil },
+		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0)).Do(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error { close(done); return nil },
 		)
 
 		errChan := make(chan error, 1)
@@ -2527,7 +2910,7 @@ func TestConnectionCongestionControl(t *testing.T) {
 		sph.EXPECT().ECNMode(true).AnyTimes()
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(2)
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAck).MaxTimes(1)
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 		// Since we're already sending out packets, we don't expect any calls to PackAckOnlyPacket
 		for i := range 2 {
 			tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
@@ -2537,10 +2920,10 @@ func TestConnectionCongestionControl(t *testing.T) {
 				},
 			)
 		}
-		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0))
 		done1 := make(chan struct{})
-		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func([]byte, uint16, protocol.ECN) error { close(done1); return nil },
+		tc.sendConn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0)).Do(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error { close(done1); return nil },
 		)
 
 		errChan := make(chan error, 1)
@@ -2631,13 +3014,13 @@ func testConnectionSendQueue(t *testing.T, enableGSO bool) {
 			},
 		)
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 		sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 		tc.packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 			shortHeaderPacket{PacketNumber: protocol.PacketNumber(1)}, nil,
 		)
-		sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any())
+		sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), monotime.Time(0))
 
 		errChan := make(chan error, 1)
 		go func() { errChan <- tc.conn.run() }()
@@ -2855,6 +3238,69 @@ func TestConnectionVersionNegotiationInvalidPackets(t *testing.T) {
 	)
 }
 
+func TestConnectionServerAcceptsOriginalVersionAfterUpgrade(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	unpacker := NewMockUnpacker(mockCtrl)
+	var eventRecorder events.Recorder
+	tc := newServerTestConnection(t,
+		mockCtrl,
+		&Config{Versions: []protocol.Version{protocol.Version2, protocol.Version1}},
+		false,
+		connectionOptUnpacker(unpacker),
+		connectionOptTracer(&eventRecorder),
+	)
+	require.Equal(t, protocol.Version1, tc.conn.originalVersion)
+	tc.conn.upgradeVersion(protocol.Version2)
+
+	getInitial := func(pn protocol.PacketNumber) receivedPacket {
+		hdr := &wire.ExtendedHeader{
+			Header: wire.Header{
+				Type:             protocol.PacketTypeInitial,
+				DestConnectionID: tc.srcConnID,
+				Version:          protocol.Version1,
+				Length:           1,
+			},
+			PacketNumber:    pn,
+			PacketNumberLen: protocol.PacketNumberLen1,
+		}
+		return getLongHeaderPacket(t, tc.remoteAddr, hdr, nil)
+	}
+
+	// a retransmission of the client's Initial, using the original version
+	unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(hdr *wire.Header, _ []byte) (*unpackedPacket, error) {
+			require.Equal(t, protocol.Version1, hdr.Version)
+			return &unpackedPacket{
+				encryptionLevel: protocol.EncryptionInitial,
+				hdr:             &wire.ExtendedHeader{Header: *hdr, PacketNumber: 1, PacketNumberLen: protocol.PacketNumberLen1},
+				data:            []byte{0}, // one PADDING frame
+			}, nil
+		},
+	)
+	wasProcessed, err := tc.conn.handleOnePacket(getInitial(1), 0)
+	require.NoError(t, err)
+	require.True(t, wasProcessed)
+	require.Empty(t, eventRecorder.Events(qlog.PacketDropped{}))
+	require.Equal(t, protocol.Version2, tc.conn.version)
+
+	// once the handshake is confirmed, packets using the original version are dropped
+	tc.conn.handshakeConfirmed = true
+	p := getInitial(2)
+	wasProcessed, err = tc.conn.handleOnePacket(p, 1)
+	require.NoError(t, err)
+	require.False(t, wasProcessed)
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.PacketDropped{
+				Raw:        qlog.RawInfo{Length: int(p.Size())},
+				DatagramID: 1,
+				Trigger:    qlog.PacketDropUnexpectedVersion,
+			},
+		},
+		eventRecorder.Events(qlog.PacketDropped{}),
+	)
+}
+
 func getRetryPacket(t *testing.T, src, dest, origDest protocol.ConnectionID, token []byte) receivedPacket {
 	hdr := wire.Header{
 		Type:             protocol.PacketTypeRetry,
@@ -3216,6 +3662,7 @@ func testConnectionPathValidation(t *testing.T, isNATRebinding bool) {
 	synctest.Test(t, func(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
 		unpacker := NewMockUnpacker(mockCtrl)
+		var eventRecorder events.Recorder
 		tc := newServerTestConnection(
 			t,
 			mockCtrl,
@@ -3224,6 +3671,7 @@ func testConnectionPathValidation(t *testing.T, isNATRebinding bool) {
 			connectionOptUnpacker(unpacker),
 			connectionOptHandshakeConfirmed(),
 			connectionOptRTT(time.Second),
+			connectionOptTracer(&eventRecorder),
 		)
 		require.NoError(t, tc.conn.handleTransportParameters(&wire.TransportParameters{MaxUDPPayloadSize: 1456}))
 
@@ -3349,6 +3797,7 @@ func testConnectionPathValidation(t *testing.T, isNATRebinding bool) {
 		default:
 			t.Fatal("should have migrated")
 		}
+		require.Len(t, eventRecorder.Events(qlog.MigrationStateUpdated{}), 1)
 
 		// test teardown
 		tc.connRunner.EXPECT().Remove(gomock.Any()).AnyTimes()
@@ -0,0 +1,55 @@
+package quic
+
+import (
+	"fmt"
+	"time"
+)
+
+// DatagramStatus is the delivery status of a datagram sent using Conn.SendDatagramWithOptions.
+type DatagramStatus uint8
+
+const (
+	// DatagramAcked means that the packet containing the datagram was acknowledged by the peer.
+	DatagramAcked DatagramStatus = iota + 1
+	// DatagramLost means that the packet containing the datagram was declared lost.
+	// Datagrams are never retransmitted.
+	DatagramLost
+	// DatagramExpired means that the datagram was not sent, since its deadline passed before it could be sent.
+	DatagramExpired
+	// DatagramDropped means that the datagram was not sent, since it didn't fit into a packet anymore.
+	// This can happen if the available packet size decreased after the datagram was queued.
+	DatagramDropped
+)
+
+func (s DatagramStatus) String() string {
+	switch s {
+	case DatagramAcked:
+		return "acked"
+	case DatagramLost:
+		return "lost"
+	case DatagramExpired:
+		return "expired"
+	case DatagramDropped:
+		return "dropped"
+	default:
+		return fmt.Sprintf("unknown datagram status: %d", uint8(s))
+	}
+}
+
+// DatagramSendOptions are the options used for sending a datagram using Conn.SendDatagramWithOptions.
+type DatagramSendOptions struct {
+	// Deadline is the time after which the datagram is not sent anymore.
+	// Datagrams are checked for expiry right before packing them into a packet,
+	// so an expired datagram is never sent.
+	// The zero value means that the datagram doesn't expire.
+	Deadline time.Time
+	// Urgency is the priority of the datagram relative to stream data,
+	// on the same scale as StreamPriority.Urgency, from 0 (most urgent) to 7 (least urgent).
+	// A datagram is sent before stream data, unless there's a stream with a lower urgency value that has data to send.
+	// Datagrams are always sent in the order in which they were queued.
+	Urgency uint8
+	// OnStatus, if set, is called once the delivery status of the datagram is known.
+	// It is not called if the connection is closed before that.
+	// It is usually called from the connection's run loop, and must not block.
+	OnStatus func(DatagramStatus)
+}
@@ -3,7 +3,10 @@ package quic
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -14,9 +17,41 @@ const (
 	maxDatagramRcvQueueLen  = 128
 )
 
+type queuedDatagram struct {
+	frame    *wire.DatagramFrame
+	deadline monotime.Time // zero if the datagram doesn't expire
+	urgency  uint8
+	onStatus func(DatagramStatus) // nil if the application isn't interested in the delivery status
+}
+
+func (d *queuedDatagram) expired(now monotime.Time) bool {
+	return !d.deadline.IsZero() && !now.Before(d.deadline)
+}
+
+func (d *queuedDatagram) reportStatus(status DatagramStatus) {
+	if d.onStatus != nil {
+		d.onStatus(status)
+	}
+}
+
+// AckHandler returns the handler that reports if the DATAGRAM frame was acknowledged or lost.
+func (d *queuedDatagram) AckHandler() ackhandler.FrameHandler {
+	if d.onStatus == nil {
+		return nil
+	}
+	return (*datagramAckHandler)(d)
+}
+
+type datagramAckHandler queuedDatagram
+
+var _ ackhandler.FrameHandler = &datagramAckHandler{}
+
+func (h *datagramAckHandler) OnAcked(wire.Frame) { (*queuedDatagram)(h).reportStatus(DatagramAcked) }
+func (h *datagramAckHandler) OnLost(wire.Frame)  { (*queuedDatagram)(h).reportStatus(DatagramLost) }
+
 type datagramQueue struct {
 	sendMx    sync.Mutex
-	sendQueue ringbuffer.RingBuffer[*wire.DatagramFrame]
+	sendQueue ringbuffer.RingBuffer[*queuedDatagram]
 	sent      chan struct{} // used to notify Add that a datagram was dequeued
 
 	rcvMx    sync.Mutex
@@ -45,11 +80,27 @@ func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
 // Up to 32 DATAGRAM frames will be queued.
 // Once that limit is reached, Add blocks until the queue size has reduced.
 func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
+	return h.AddWithOptions(f, DatagramSendOptions{})
+}
+
+// AddWithOptions queues a new DATAGRAM frame for sending, like Add.
+// If the datagram expires while Add is blocked, it is not queued.
+func (h *datagramQueue) AddWithOptions(f *wire.DatagramFrame, opts DatagramSendOptions) error {
+	d := &queuedDatagram{
+		frame:    f,
+		urgency:  min(opts.Urgency, maxStreamUrgency),
+		onStatus: opts.OnStatus,
+	}
+	if !opts.Deadline.IsZero() {
+		d.deadline = monotime.FromTime(opts.Deadline)
+	}
+	var deadlineTimer *time.Timer
+
 	h.sendMx.Lock()
 
 	for {
 		if h.sendQueue.Len() < maxDatagramSendQueueLen {
-			h.sendQueue.PushBack(f)
+			h.sendQueue.PushBack(d)
 			h.sendMx.Unlock()
 			h.hasData()
 			return nil
@@ -59,30 +110,72 @@ func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
 		default:
 		}
 		h.sendMx.Unlock()
+		var deadlineChan <-chan time.Time
+		if !opts.Deadline.IsZero() {
+			if deadlineTimer == nil {
+				deadlineTimer = time.NewTimer(time.Until(opts.Deadline))
+				defer deadlineTimer.Stop()
+			}
+			deadlineChan = deadlineTimer.C
+		}
 		select {
 		case <-h.closed:
 			return h.closeErr
 		case <-h.sent:
+		case <-deadlineChan:
+			d.reportStatus(DatagramExpired)
+			return nil
 		}
 		h.sendMx.Lock()
 	}
 }
 
 // Peek gets the next DATAGRAM frame for sending.
+// Datagrams that expired are dropped.
 // If actually sent out, Pop needs to be called before the next call to Peek.
-func (h *datagramQueue) Peek() *wire.DatagramFrame {
+func (h *datagramQueue) Peek(now monotime.Time) *queuedDatagram {
 	h.sendMx.Lock()
-	defer h.sendMx.Unlock()
-	if h.sendQueue.Empty() {
-		return nil
+	var expired []*queuedDatagram
+	for !h.sendQueue.Empty() && h.sendQueue.PeekFront().expired(now) {
+		expired = append(expired, h.sendQueue.PopFront())
+	}
+	var d *queuedDatagram
+	if !h.sendQueue.Empty() {
+		d = h.sendQueue.PeekFront()
+	}
+	if len(expired) > 0 {
+		h.signalSent()
 	}
-	return h.sendQueue.PeekFront()
+	h.sendMx.Unlock()
+
+	for _, e := range expired {
+		if h.logger.Debug() {
+			h.logger.Debugf("Dropping expired DATAGRAM frame (%d bytes payload)", len(e.frame.Data))
+		}
+		e.reportStatus(DatagramExpired)
+	}
+	return d
 }
 
+// Pop removes the DATAGRAM frame returned by Peek from the queue after it was sent out.
 func (h *datagramQueue) Pop() {
 	h.sendMx.Lock()
 	defer h.sendMx.Unlock()
 	_ = h.sendQueue.PopFront()
+	h.signalSent()
+}
+
+// Discard removes the DATAGRAM frame returned by Peek from the queue without sending it.
+func (h *datagramQueue) Discard() {
+	h.sendMx.Lock()
+	d := h.sendQueue.PopFront()
+	h.signalSent()
+	h.sendMx.Unlock()
+	d.reportStatus(DatagramDropped)
+}
+
+// must be called with the sendMx held
+func (h *datagramQueue) signalSent() {
 	select {
 	case h.sent <- struct{}{}:
 	default:
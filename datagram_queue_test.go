@@ -3,7 +3,9 @@ package quic
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/synctest"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -15,15 +17,15 @@ import (
 func TestDatagramQueuePeekAndPop(t *testing.T) {
 	var queued []struct{}
 	queue := newDatagramQueue(func() { queued = append(queued, struct{}{}) }, utils.DefaultLogger)
-	require.Nil(t, queue.Peek())
+	require.Nil(t, queue.Peek(monotime.Now()))
 	require.Empty(t, queued)
 	require.NoError(t, queue.Add(&wire.DatagramFrame{Data: []byte("foo")}))
 	require.Len(t, queued, 1)
-	require.Equal(t, &wire.DatagramFrame{Data: []byte("foo")}, queue.Peek())
+	require.Equal(t, &wire.DatagramFrame{Data: []byte("foo")}, queue.Peek(monotime.Now()).frame)
 	// calling peek again returns the same datagram
-	require.Equal(t, &wire.DatagramFrame{Data: []byte("foo")}, queue.Peek())
+	require.Equal(t, &wire.DatagramFrame{Data: []byte("foo")}, queue.Peek(monotime.Now()).frame)
 	queue.Pop()
-	require.Nil(t, queue.Peek())
+	require.Nil(t, queue.Peek(monotime.Now()))
 }
 
 func TestDatagramQueueSendQueueLength(t *testing.T) {
@@ -45,7 +47,7 @@ func TestDatagramQueueSendQueueLength(t *testing.T) {
 		}
 
 		// peeking doesn't remove the datagram from the queue...
-		require.NotNil(t, queue.Peek())
+		require.NotNil(t, queue.Peek(monotime.Now()))
 		synctest.Wait()
 		select {
 		case <-errChan:
@@ -66,9 +68,82 @@ func TestDatagramQueueSendQueueLength(t *testing.T) {
 		for range maxDatagramSendQueueLen - 1 {
 			queue.Pop()
 		}
-		f := queue.Peek()
-		require.NotNil(t, f)
-		require.Equal(t, &wire.DatagramFrame{Data: []byte("foobar")}, f)
+		d := queue.Peek(monotime.Now())
+		require.NotNil(t, d)
+		require.Equal(t, &wire.DatagramFrame{Data: []byte("foobar")}, d.frame)
+	})
+}
+
+func TestDatagramQueueExpiry(t *testing.T) {
+	queue := newDatagramQueue(func() {}, utils.DefaultLogger)
+	now := monotime.Now()
+	var status []string
+	addDatagram := func(data string, deadline monotime.Time) {
+		var opts DatagramSendOptions
+		if !deadline.IsZero() {
+			opts.Deadline = deadline.ToTime()
+		}
+		opts.OnStatus = func(s DatagramStatus) { status = append(status, data+": "+s.String()) }
+		require.NoError(t, queue.AddWithOptions(&wire.DatagramFrame{Data: []byte(data)}, opts))
+	}
+	addDatagram("foo", now.Add(time.Second))
+	addDatagram("bar", now.Add(2*time.Second))
+	addDatagram("baz", 0)
+
+	require.Equal(t, []byte("foo"), queue.Peek(now.Add(time.Second-1)).frame.Data)
+	require.Empty(t, status)
+	// the first datagram expired
+	require.Equal(t, []byte("bar"), queue.Peek(now.Add(time.Second)).frame.Data)
+	require.Equal(t, []string{"foo: expired"}, status)
+	// datagrams without a deadline never expire
+	require.Equal(t, []byte("baz"), queue.Peek(now.Add(time.Hour)).frame.Data)
+	require.Equal(t, []string{"foo: expired", "bar: expired"}, status)
+	queue.Discard()
+	require.Equal(t, []string{"foo: expired", "bar: expired", "baz: dropped"}, status)
+	require.Nil(t, queue.Peek(now.Add(time.Hour)))
+}
+
+func TestDatagramQueueExpiryWhileBlocked(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		queue := newDatagramQueue(func() {}, utils.DefaultLogger)
+
+		for range maxDatagramSendQueueLen {
+			require.NoError(t, queue.Add(&wire.DatagramFrame{Data: []byte{0}}))
+		}
+		statusChan := make(chan DatagramStatus, 1)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- queue.AddWithOptions(
+				&wire.DatagramFrame{Data: []byte("foobar")},
+				DatagramSendOptions{
+					Deadline: time.Now().Add(time.Second),
+					OnStatus: func(s DatagramStatus) { statusChan <- s },
+				},
+			)
+		}()
+
+		synctest.Wait()
+		select {
+		case <-errChan:
+			t.Fatal("expected Add to block")
+		default:
+		}
+
+		time.Sleep(time.Second)
+		synctest.Wait()
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+		default:
+			t.Fatal("expected Add to return")
+		}
+		require.Equal(t, DatagramExpired, <-statusChan)
+		// the expired datagram was not queued
+		for range maxDatagramSendQueueLen {
+			require.Equal(t, []byte{0}, queue.Peek(monotime.Now()).frame.Data)
+			queue.Pop()
+		}
+		require.Nil(t, queue.Peek(monotime.Now()))
 	})
 }
 
@@ -69,6 +69,8 @@ const (
 	AEADLimitReached = qerr.AEADLimitReached
 	// NoViablePathError is the NO_VIABLE_PATH_ERROR transport error code.
 	NoViablePathError = qerr.NoViablePathError
+	// VersionNegotiationErrorErrorCode is the VERSION_NEGOTIATION_ERROR transport error code (RFC 9368).
+	VersionNegotiationErrorErrorCode = qerr.VersionNegotiationErrorErrorCode
 )
 
 // A StreamError is used to signal stream cancellations.
@@ -30,12 +30,34 @@ type streamControlFrameGetter interface {
 	getControlFrame(monotime.Time) (_ ackhandler.Frame, ok, hasMore bool)
 }
 
+// A streamFrameGetter that doesn't implement this interface is scheduled using the DefaultStreamPriority.
+type streamPrioritizer interface {
+	Priority() StreamPriority
+}
+
+type activeStream struct {
+	str      streamFrameGetter
+	priority StreamPriority
+}
+
+// streamQueue contains the active streams of one urgency, either the incremental or the non-incremental ones.
+type streamQueue struct {
+	queue ringbuffer.RingBuffer[protocol.StreamID]
+	// the number of STREAM frames popped from the stream at the front of the queue
+	framesPopped int
+}
+
 type framer struct {
 	mutex sync.Mutex
 
-	activeStreams            map[protocol.StreamID]streamFrameGetter
-	streamQueue              ringbuffer.RingBuffer[protocol.StreamID]
+	activeStreams map[protocol.StreamID]activeStream
+	// Streams are scheduled by strict priority of their urgency.
+	// For every urgency, there's one queue for the non-incremental streams, followed by one for the incremental streams.
+	streamQueues             [2 * (maxStreamUrgency + 1)]streamQueue
 	streamsWithControlFrames map[protocol.StreamID]streamControlFrameGetter
+	// Streams are removed from the queues lazily, so a stream ID can be contained in multiple queues.
+	// For every stream ID, bit i is set if the stream ID is contained in streamQueues[i].
+	queuedStreams map[protocol.StreamID]uint16
 
 	controlFrameMutex          sync.Mutex
 	controlFrames              []wire.Frame
@@ -46,7 +68,8 @@ type framer struct {
 
 func newFramer(connFlowController flowcontrol.ConnectionFlowController) *framer {
 	return &framer{
-		activeStreams:            make(map[protocol.StreamID]streamFrameGetter),
+		activeStreams:            make(map[protocol.StreamID]activeStream),
+		queuedStreams:            make(map[protocol.StreamID]uint16),
 		streamsWithControlFrames: make(map[protocol.StreamID]streamControlFrameGetter),
 		connFlowController:       connFlowController,
 	}
@@ -54,7 +77,7 @@ func newFramer(connFlowController flowcontrol.ConnectionFlowController) *framer
 
 func (f *framer) HasData() bool {
 	f.mutex.Lock()
-	hasData := !f.streamQueue.Empty()
+	hasData := f.numQueuedStreams() > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -101,8 +124,8 @@ func (f *framer) Append(
 	var streamFrameLen protocol.ByteCount
 	f.mutex.Lock()
 	// pop STREAM frames, until less than 128 bytes are left in the packet
-	numActiveStreams := f.streamQueue.Len()
-	for i := 0; i < numActiveStreams; i++ {
+	numQueuedStreams := f.numQueuedStreams()
+	for i := 0; i < numQueuedStreams; i++ {
 		if protocol.MinStreamFrameSize > maxLen {
 			break
 		}
@@ -221,12 +244,63 @@ func (f *framer) QueuedTooManyControlFrames() bool {
 func (f *framer) AddActiveStream(id protocol.StreamID, str streamFrameGetter) {
 	f.mutex.Lock()
 	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue.PushBack(id)
-		f.activeStreams[id] = str
+		priority := DefaultStreamPriority
+		if p, ok := str.(streamPrioritizer); ok {
+			priority = p.Priority()
+		}
+		f.enqueueStream(priority.queueIndex(), id)
+		f.activeStreams[id] = activeStream{str: str, priority: priority}
 	}
 	f.mutex.Unlock()
 }
 
+// UpdateStreamPriority is called when the priority of a stream changes.
+func (f *framer) UpdateStreamPriority(id protocol.StreamID) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	as, ok := f.activeStreams[id]
+	if !ok {
+		// The new priority will be used once the stream has data to send.
+		return
+	}
+	p, ok := as.str.(streamPrioritizer)
+	if !ok {
+		return
+	}
+	priority := p.Priority()
+	oldIndex := as.priority.queueIndex()
+	as.priority = priority
+	f.activeStreams[id] = as
+	// As in RemoveActiveStream, we don't remove the stream from the old queue.
+	// Instead, it is skipped when it doesn't belong to that queue anymore.
+	if newIndex := priority.queueIndex(); newIndex != oldIndex {
+		f.enqueueStream(newIndex, id)
+	}
+}
+
+// enqueueStream adds a stream to the end of a queue, unless the queue already contains the stream.
+// This happens when a stream that was removed lazily is added again,
+// for example when its priority is changed back and forth.
+func (f *framer) enqueueStream(queueIndex int, id protocol.StreamID) {
+	if f.queuedStreams[id]&(1<<queueIndex) != 0 {
+		return
+	}
+	f.queuedStreams[id] |= 1 << queueIndex
+	f.streamQueues[queueIndex].queue.PushBack(id)
+}
+
+// dequeueStream removes the stream at the front of a queue.
+func (f *framer) dequeueStream(queueIndex int) {
+	id := f.streamQueues[queueIndex].queue.PopFront()
+	if mask := f.queuedStreams[id] &^ (1 << queueIndex); mask != 0 {
+		f.queuedStreams[id] = mask
+	} else {
+		delete(f.queuedStreams, id)
+	}
+	f.streamQueues[queueIndex].framesPopped = 0
+}
+
 func (f *framer) AddStreamWithControlFrames(id protocol.StreamID, str streamControlFrameGetter) {
 	f.controlFrameMutex.Lock()
 	if _, ok := f.streamsWithControlFrames[id]; !ok {
@@ -245,25 +319,62 @@ func (f *framer) RemoveActiveStream(id protocol.StreamID) {
 	f.mutex.Unlock()
 }
 
+// StreamUrgency returns the urgency of the most urgent stream that has data to send.
+// Since streams are removed from the queues lazily, this is an approximation:
+// The stream might have been removed or have changed its priority in the meantime.
+func (f *framer) StreamUrgency() (uint8, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i := range f.streamQueues {
+		if !f.streamQueues[i].queue.Empty() {
+			return uint8(i / 2), true
+		}
+	}
+	return 0, false
+}
+
+func (f *framer) numQueuedStreams() int {
+	var n int
+	for i := range f.streamQueues {
+		n += f.streamQueues[i].queue.Len()
+	}
+	return n
+}
+
 func (f *framer) getNextStreamFrame(maxLen protocol.ByteCount, v protocol.Version) (ackhandler.StreamFrame, *wire.StreamDataBlockedFrame) {
-	id := f.streamQueue.PopFront()
-	// This should never return an error. Better check it anyway.
-	// The stream will only be in the streamQueue, if it enqueued itself there.
-	str, ok := f.activeStreams[id]
-	// The stream might have been removed after being enqueued.
-	if !ok {
+	var queueIndex int
+	for queueIndex < len(f.streamQueues) && f.streamQueues[queueIndex].queue.Empty() {
+		queueIndex++
+	}
+	if queueIndex == len(f.streamQueues) {
+		return ackhandler.StreamFrame{}, nil
+	}
+	q := &f.streamQueues[queueIndex]
+	id := q.queue.PeekFront()
+	as, ok := f.activeStreams[id]
+	// The stream might have been removed, or its priority might have changed after being enqueued.
+	if !ok || as.priority.queueIndex() != queueIndex {
+		f.dequeueStream(queueIndex)
 		return ackhandler.StreamFrame{}, nil
 	}
 	// For the last STREAM frame, we'll remove the DataLen field later.
 	// Therefore, we can pretend to have more bytes available when popping
 	// the STREAM frame (which will always have the DataLen set).
 	maxLen += protocol.ByteCount(quicvarint.Len(uint64(maxLen)))
-	frame, blocked, hasMoreData := str.popStreamFrame(maxLen, v)
-	if hasMoreData { // put the stream back in the queue (at the end)
-		f.streamQueue.PushBack(id)
-	} else { // no more data to send. Stream is not active
+	frame, blocked, hasMoreData := as.str.popStreamFrame(maxLen, v)
+	q.framesPopped++
+	switch {
+	case !hasMoreData: // no more data to send. Stream is not active
+		f.dequeueStream(queueIndex)
 		delete(f.activeStreams, id)
+	case as.priority.Incremental && q.framesPopped >= as.priority.weight():
+		// put the stream back in the queue (at the end)
+		q.queue.PopFront()
+		q.queue.PushBack(id)
+		q.framesPopped = 0
 	}
+	// Non-incremental streams stay at the front of the queue until they have no more data to send.
 	// Note that the frame.Frame can be nil:
 	// * if the stream was canceled after it said it had data
 	// * the remaining size doesn't allow us to add another STREAM frame
@@ -276,7 +387,11 @@ func (f *framer) Handle0RTTRejection() {
 	f.controlFrameMutex.Lock()
 	defer f.controlFrameMutex.Unlock()
 
-	f.streamQueue.Clear()
+	for i := range f.streamQueues {
+		f.streamQueues[i].queue.Clear()
+		f.streamQueues[i].framesPopped = 0
+	}
+	clear(f.queuedStreams)
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
 	}
@@ -474,3 +474,144 @@ func TestFramer0RTTRejection(t *testing.T) {
 	require.Contains(t, controlFrames, ackhandler.Frame{Frame: ping})
 	require.Contains(t, controlFrames, ackhandler.Frame{Frame: ncid})
 }
+
+type testPrioritizedStream struct {
+	id       protocol.StreamID
+	priority StreamPriority
+	frames   int // the number of STREAM frames left to send
+}
+
+var _ streamPrioritizer = &testPrioritizedStream{}
+
+func (s *testPrioritizedStream) Priority() StreamPriority { return s.priority }
+
+// popStreamFrame returns a STREAM frame that fills the packet
+func (s *testPrioritizedStream) popStreamFrame(maxBytes protocol.ByteCount, _ protocol.Version) (ackhandler.StreamFrame, *wire.StreamDataBlockedFrame, bool) {
+	s.frames--
+	f := &wire.StreamFrame{StreamID: s.id, Data: make([]byte, maxBytes-16), DataLenPresent: true}
+	return ackhandler.StreamFrame{Frame: f}, nil, s.frames > 0
+}
+
+// appendStreamFramesForPackets packs STREAM frames into numPackets packets,
+// and returns the stream IDs in the order they were sent
+func appendStreamFramesForPackets(t *testing.T, framer *framer, numPackets int) []protocol.StreamID {
+	t.Helper()
+	var ids []protocol.StreamID
+	for range numPackets {
+		_, fs, _ := framer.Append(nil, nil, 1000, monotime.Now(), protocol.Version1)
+		require.Len(t, fs, 1)
+		ids = append(ids, fs[0].Frame.StreamID)
+	}
+	return ids
+}
+
+func TestFramerStreamPriorityUrgency(t *testing.T) {
+	framer := newFramer(flowcontrol.NewConnectionFlowController(0, 0, nil, nil, nil))
+	framer.AddActiveStream(1, &testPrioritizedStream{id: 1, priority: DefaultStreamPriority, frames: 2})
+	framer.AddActiveStream(2, &testPrioritizedStream{id: 2, priority: StreamPriority{Urgency: 7, Incremental: true}, frames: 2})
+	framer.AddActiveStream(3, &testPrioritizedStream{id: 3, priority: StreamPriority{Urgency: 1, Incremental: true}, frames: 2})
+	// urgencies larger than 7 are treated as 7
+	framer.AddActiveStream(4, &testPrioritizedStream{id: 4, priority: StreamPriority{Urgency: 100}, frames: 1})
+
+	require.Equal(t,
+		[]protocol.StreamID{3, 3, 1, 1, 4, 2, 2},
+		appendStreamFramesForPackets(t, framer, 7),
+	)
+	require.False(t, framer.HasData())
+}
+
+func TestFramerStreamPriorityIncremental(t *testing.T) {
+	framer := newFramer(flowcontrol.NewConnectionFlowController(0, 0, nil, nil, nil))
+	framer.AddActiveStream(1, &testPrioritizedStream{id: 1, priority: DefaultStreamPriority, frames: 2})
+	framer.AddActiveStream(2, &testPrioritizedStream{id: 2, priority: DefaultStreamPriority, frames: 2})
+	framer.AddActiveStream(3, &testPrioritizedStream{id: 3, priority: StreamPriority{Urgency: 3}, frames: 3})
+	framer.AddActiveStream(4, &testPrioritizedStream{id: 4, priority: StreamPriority{Urgency: 3}, frames: 2})
+
+	// Non-incremental streams are sent one after the other, before the incremental streams.
+	// Incremental streams are interleaved.
+	require.Equal(t,
+		[]protocol.StreamID{3, 3, 3, 4, 4, 1, 2, 1, 2},
+		appendStreamFramesForPackets(t, framer, 9),
+	)
+	require.False(t, framer.HasData())
+}
+
+func TestFramerStreamPriorityWeights(t *testing.T) {
+	framer := newFramer(flowcontrol.NewConnectionFlowController(0, 0, nil, nil, nil))
+	framer.AddActiveStream(1, &testPrioritizedStream{id: 1, priority: StreamPriority{Urgency: 3, Incremental: true, Weight: 3}, frames: 6})
+	framer.AddActiveStream(2, &testPrioritizedStream{id: 2, priority: StreamPriority{Urgency: 3, Incremental: true, Weight: 1}, frames: 3})
+	// a weight of 0 is treated as 1
+	framer.AddActiveStream(3, &testPrioritizedStream{id: 3, priority: StreamPriority{Urgency: 3, Incremental: true}, frames: 3})
+
+	require.Equal(t,
+		[]protocol.StreamID{1, 1, 1, 2, 3, 1, 1, 1, 2, 3, 2, 3},
+		appendStreamFramesForPackets(t, framer, 12),
+	)
+	require.False(t, framer.HasData())
+}
+
+func TestFramerStreamUrgency(t *testing.T) {
+	framer := newFramer(flowcontrol.NewConnectionFlowController(0, 0, nil, nil, nil))
+	_, ok := framer.StreamUrgency()
+	require.False(t, ok)
+	framer.AddActiveStream(1, &testPrioritizedStream{id: 1, priority: StreamPriority{Urgency: 5}, frames: 1})
+	framer.AddActiveStream(2, &testPrioritizedStream{id: 2, priority: StreamPriority{Urgency: 2, Incremental: true}, frames: 1})
+	urgency, ok := framer.StreamUrgency()
+	require.True(t, ok)
+	require.Equal(t, uint8(2), urgency)
+
+	require.Equal(t, []protocol.StreamID{2}, appendStreamFramesForPackets(t, framer, 1))
+	urgency, ok = framer.StreamUrgency()
+	require.True(t, ok)
+	require.Equal(t, uint8(5), urgency)
+	require.Equal(t, []protocol.StreamID{1}, appendStreamFramesForPackets(t, framer, 1))
+	_, ok = framer.StreamUrgency()
+	require.False(t, ok)
+}
+
+func TestFramerStreamPriorityChange(t *testing.T) {
+	framer := newFramer(flowcontrol.NewConnectionFlowController(0, 0, nil, nil, nil))
+	str1 := &testPrioritizedStream{id: 1, priority: DefaultStreamPriority, frames: 3}
+	str2 := &testPrioritizedStream{id: 2, priority: DefaultStreamPriority, frames: 3}
+	framer.AddActiveStream(1, str1)
+	framer.AddActiveStream(2, str2)
+	require.Equal(t, []protocol.StreamID{1, 2}, appendStreamFramesForPackets(t, framer, 2))
+
+	str2.priority = StreamPriority{Urgency: 0}
+	framer.UpdateStreamPriority(2)
+	require.Equal(t, []protocol.StreamID{2, 2, 1, 1}, appendStreamFramesForPackets(t, framer, 4))
+	require.False(t, framer.HasData())
+
+	// the priority of inactive streams is read when they become active
+	str1.frames = 1
+	str2.frames = 1
+	str1.priority = StreamPriority{Urgency: 5}
+	framer.UpdateStreamPriority(1) // no-op, since the stream is not active
+	framer.AddActiveStream(1, str1)
+	framer.AddActiveStream(2, str2)
+	require.Equal(t, []protocol.StreamID{2, 1}, appendStreamFramesForPackets(t, framer, 2))
+	require.False(t, framer.HasData())
+}
+
+func TestFramerStreamPriorityChangeBackAndForth(t *testing.T) {
+	framer := newFramer(flowcontrol.NewConnectionFlowController(0, 0, nil, nil, nil))
+	str1 := &testPrioritizedStream{id: 1, priority: StreamPriority{Urgency: 3, Incremental: true}, frames: 4}
+	str2 := &testPrioritizedStream{id: 2, priority: StreamPriority{Urgency: 3, Incremental: true}, frames: 4}
+	framer.AddActiveStream(1, str1)
+	framer.AddActiveStream(2, str2)
+
+	// Changing the priority and changing it back doesn't enqueue the stream twice,
+	// which would give it twice its share of the bandwidth.
+	for range 3 {
+		str1.priority = StreamPriority{Urgency: 0}
+		framer.UpdateStreamPriority(1)
+		str1.priority = StreamPriority{Urgency: 3, Incremental: true}
+		framer.UpdateStreamPriority(1)
+	}
+	require.Equal(t,
+		[]protocol.StreamID{1, 2, 1, 2, 1, 2, 1, 2},
+		appendStreamFramesForPackets(t, framer, 8),
+	)
+	require.False(t, framer.HasData())
+	require.Empty(t, framer.queuedStreams)
+}
@@ -29,6 +29,8 @@ func main() {
 			ClientSessionCache: tls.NewLRUClientSessionCache(1),
 		},
 		false,
+		protocol.KeyUpdateInterval,
+		0,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -44,6 +46,8 @@ func main() {
 		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
 		config,
 		false,
+		protocol.KeyUpdateInterval,
+		0,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
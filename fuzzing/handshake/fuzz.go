@@ -281,6 +281,8 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		clientTP,
 		clientConf,
 		enable0RTTClient,
+		protocol.KeyUpdateInterval,
+		0,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -298,6 +300,8 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		serverTP,
 		serverConf,
 		enable0RTTServer,
+		protocol.KeyUpdateInterval,
+		0,
 		&utils.RTTStats{},
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -0,0 +1,221 @@
+package quic
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/qlog"
+)
+
+// Packets forwarded between two Transports during a socket handoff are encapsulated:
+//
+//	Forwarded Packet {
+//	  ECN (8),
+//	  Address Length (8),
+//	  Client Address (..),
+//	  QUIC Packet (..),
+//	}
+//
+// The client address is encoded using netip.AddrPort.MarshalBinary.
+
+var errTransportHandedOff = errors.New("quic: transport handed off its socket")
+
+// maxForwardQueueLen is the maximum number of packets queued for forwarding.
+// If the queue is full, packets are dropped.
+const maxForwardQueueLen = 256
+
+func appendForwardedPacket(b []byte, p receivedPacket) ([]byte, error) {
+	udpAddr, ok := p.remoteAddr.(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("cannot forward packet from non-UDP address %s", p.remoteAddr)
+	}
+	addr, err := udpAddr.AddrPort().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, byte(p.ecn), byte(len(addr)))
+	b = append(b, addr...)
+	return append(b, p.data...), nil
+}
+
+func parseForwardedPacket(b []byte) (addr *net.UDPAddr, ecn protocol.ECN, data []byte, _ error) {
+	if len(b) < 2 {
+		return nil, 0, nil, errors.New("forwarded packet too short")
+	}
+	ecn = protocol.ECN(b[0])
+	addrLen := int(b[1])
+	b = b[2:]
+	if len(b) < addrLen {
+		return nil, 0, nil, errors.New("forwarded packet too short")
+	}
+	var addrPort netip.AddrPort
+	if err := addrPort.UnmarshalBinary(b[:addrLen]); err != nil {
+		return nil, 0, nil, err
+	}
+	return net.UDPAddrFromAddrPort(addrPort), ecn, b[addrLen:], nil
+}
+
+// HandOff is used when a different process takes over the Transport's socket,
+// for example when upgrading the server binary without dropping existing connections.
+// The new process uses a Transport that shares the socket (e.g. by inheriting its file descriptor),
+// and forwards packets it can't associate with any of its own connections, see Transport.ForwardConn.
+//
+// After calling HandOff, the Transport stops reading packets from its Conn,
+// and instead reads the packets forwarded to the forwarded connection.
+// Only packets sent by forwarder, the address of the other Transport's ForwardConn, are accepted.
+// All other packets received on the forwarded connection are dropped.
+// Packets are still sent on the Conn.
+// It is not possible to start a new server or dial new connections after HandOff was called.
+// Calling Shutdown allows waiting for all connections to close.
+// Afterwards, the new Transport should call StopForwarding.
+//
+// Handshakes that are still in progress at the time of the handoff might fail.
+func (t *Transport) HandOff(forwarded net.PacketConn, forwarder net.Addr) error {
+	if err := t.init(false); err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.closeErr != nil {
+		return t.closeErr
+	}
+	if t.handoffConn != nil {
+		return errTransportHandedOff
+	}
+	t.handoffConn = forwarded
+	t.handoffForwarder = forwarder
+	if t.server != nil {
+		t.server.drain()
+	}
+	// make the listen loop switch to reading from the forwarded connection
+	return t.conn.SetReadDeadline(time.Now())
+}
+
+// listenForwarded reads packets forwarded by the Transport that took over the socket.
+func (t *Transport) listenForwarded(conn net.PacketConn, forwarder net.Addr) {
+	for {
+		// forwarded packets are slightly larger than the packets read from the socket
+		buffer := getLargePacketBuffer()
+		buffer.Data = buffer.Data[:cap(buffer.Data)]
+		n, addr, err := conn.ReadFrom(buffer.Data)
+		if err != nil {
+			buffer.Release()
+			t.mutex.Lock()
+			closed := t.closeErr != nil
+			t.mutex.Unlock()
+			if closed {
+				return
+			}
+			//nolint:staticcheck // SA1019 ignore this!
+			if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+				t.logger.Debugf("Temporary error reading forwarded packets: %s", err)
+				continue
+			}
+			t.close(err)
+			return
+		}
+		if !isSameAddr(addr, forwarder) {
+			t.logger.Debugf("Dropping forwarded packet from unexpected address %s", addr)
+			buffer.Release()
+			continue
+		}
+		remoteAddr, ecn, data, err := parseForwardedPacket(buffer.Data[:n])
+		if err != nil {
+			t.logger.Debugf("Error parsing forwarded packet: %s", err)
+			buffer.Release()
+			continue
+		}
+		t.handlePacket(receivedPacket{
+			remoteAddr: remoteAddr,
+			rcvTime:    monotime.Now(),
+			data:       data,
+			ecn:        ecn,
+			buffer:     buffer,
+		})
+	}
+}
+
+// StopForwarding stops forwarding packets using the ForwardConn.
+// It is called once the Transport that previously owned the socket has shut down.
+// Afterwards, packets that can't be associated with any connection are handled as if no ForwardConn was set,
+// i.e. they are dropped or replied to with a stateless reset.
+// The ForwardConn is not closed, but it is not used after StopForwarding returns,
+// except for packets that are being forwarded concurrently.
+//
+// It is safe to call this method concurrently with all other methods of the Transport.
+func (t *Transport) StopForwarding() {
+	t.forwardingStopped.Store(true)
+}
+
+func (t *Transport) forwarding() bool {
+	// the forward queue is only created if ForwardConn and ForwardAddr are set
+	return t.forwardQueue != nil && !t.forwardingStopped.Load()
+}
+
+func isSameAddr(a, b net.Addr) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if ua, ok := a.(*net.UDPAddr); ok {
+		if ub, ok := b.(*net.UDPAddr); ok {
+			return ua.AddrPort() == ub.AddrPort()
+		}
+	}
+	return a.Network() == b.Network() && a.String() == b.String()
+}
+
+// maybeForwardPacket queues a packet for forwarding to the Transport that previously owned the socket.
+// It returns false if the packet can't be forwarded.
+// If the forwarding queue is full, the packet is dropped.
+func (t *Transport) maybeForwardPacket(p receivedPacket) (forwarded bool) {
+	if !t.forwarding() {
+		return false
+	}
+	buf := getLargePacketBuffer()
+	b, err := appendForwardedPacket(buf.Data, p)
+	if err != nil {
+		t.logger.Debugf("Not forwarding packet: %s", err)
+		buf.Release()
+		return false
+	}
+	buf.Data = b
+	p.buffer.Release()
+
+	select {
+	case t.forwardQueue <- buf:
+	default:
+		t.logger.Debugf("Dropping packet from %s, forwarding queue full", p.remoteAddr)
+		if t.Tracer != nil {
+			t.Tracer.RecordEvent(qlog.PacketDropped{
+				Header:  qlog.PacketHeader{PacketType: qlog.PacketType1RTT},
+				Raw:     qlog.RawInfo{Length: int(p.Size())},
+				Trigger: qlog.PacketDropDOSPrevention,
+			})
+		}
+		buf.Release()
+	}
+	return true
+}
+
+// runForwardQueue writes the packets queued for forwarding to the ForwardConn.
+// It is run in a separate Go routine, such that a slow ForwardConn doesn't block reading from the socket.
+func (t *Transport) runForwardQueue() {
+	for {
+		select {
+		case <-t.listening:
+			return
+		case buf := <-t.forwardQueue:
+			if _, err := t.ForwardConn.WriteTo(buf.Data, t.ForwardAddr); err != nil {
+				t.logger.Debugf("Error forwarding packet to %s: %s", t.ForwardAddr, err)
+			}
+			buf.Release()
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package quic
+
+import (
+	"net"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardedPacketEncoding(t *testing.T) {
+	for _, addr := range []*net.UDPAddr{
+		{IP: net.IPv4(192, 0, 2, 1).To4(), Port: 1234},
+		{IP: net.ParseIP("2001:db8::1"), Port: 4321},
+	} {
+		t.Run(addr.String(), func(t *testing.T) {
+			b, err := appendForwardedPacket([]byte("foo"), receivedPacket{
+				remoteAddr: addr,
+				data:       []byte("foobar"),
+				ecn:        protocol.ECT1,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []byte("foo"), b[:3])
+
+			parsedAddr, ecn, data, err := parseForwardedPacket(b[3:])
+			require.NoError(t, err)
+			require.Equal(t, addr.String(), parsedAddr.String())
+			require.Equal(t, protocol.ECT1, ecn)
+			require.Equal(t, []byte("foobar"), data)
+
+			for i := range len(b) - 3 - len(data) {
+				_, _, _, err := parseForwardedPacket(b[3 : 3+i])
+				require.Error(t, err)
+			}
+		})
+	}
+
+	_, err := appendForwardedPacket(nil, receivedPacket{remoteAddr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}})
+	require.EqualError(t, err, "cannot forward packet from non-UDP address 192.0.2.1:1234")
+}
@@ -0,0 +1,107 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/internal/synctest"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/testutils/events"
+	"github.com/quic-go/quic-go/testutils/simnet"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAckFrequency(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		numAckFrequencyFrames, numAcks, numPackets := runAckFrequencyTest(t, true)
+		require.NotZero(t, numAckFrequencyFrames)
+		// The client sends significantly fewer ACKs than it would without ACK_FREQUENCY,
+		// where it acknowledges every other packet.
+		require.Less(t, numAcks, numPackets/4)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		numAckFrequencyFrames, _, _ := runAckFrequencyTest(t, false)
+		require.Zero(t, numAckFrequencyFrames)
+	})
+}
+
+func runAckFrequencyTest(t *testing.T, enable bool) (numAckFrequencyFrames, numAcks, numPackets int) {
+	t.Helper()
+
+	synctest.Test(t, func(t *testing.T) {
+		const rtt = 20 * time.Millisecond
+		clientPacketConn, serverPacketConn, closeFn := newSimnetLinkWithRouter(t, rtt, &droppingRouter{
+			Drop: func(p simnet.Packet) bool {
+				// drop a few 1-RTT packets, so that the congestion controller exits slow start
+				return !wire.IsLongHeaderPacket(p.Data[0]) && rand.IntN(200) == 0
+			},
+		})
+		defer closeFn(t)
+
+		var serverRecorder, clientRecorder events.Recorder
+		ln, err := quic.Listen(
+			serverPacketConn,
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: enable, Tracer: newTracer(&serverRecorder)}),
+		)
+		require.NoError(t, err)
+		defer ln.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		conn, err := quic.Dial(
+			ctx,
+			clientPacketConn,
+			ln.Addr(),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: enable, Tracer: newTracer(&clientRecorder)}),
+		)
+		require.NoError(t, err)
+		defer conn.CloseWithError(0, "")
+
+		data := GeneratePRData(5 << 20)
+		serverConn, err := ln.Accept(ctx)
+		require.NoError(t, err)
+		defer serverConn.CloseWithError(0, "")
+		go func() {
+			str, err := serverConn.OpenUniStream()
+			if err != nil {
+				return
+			}
+			str.Write(data)
+			str.Close()
+		}()
+
+		str, err := conn.AcceptUniStream(ctx)
+		require.NoError(t, err)
+		b, err := io.ReadAll(str)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, b))
+
+		for _, ev := range serverRecorder.Events(qlog.PacketSent{}) {
+			for _, f := range ev.(qlog.PacketSent).Frames {
+				if _, ok := f.Frame.(*qlog.AckFrequencyFrame); ok {
+					numAckFrequencyFrames++
+				}
+			}
+		}
+		for _, ev := range clientRecorder.Events(qlog.PacketSent{}) {
+			for _, f := range ev.(qlog.PacketSent).Frames {
+				if _, ok := f.Frame.(*qlog.AckFrame); ok {
+					numAcks++
+				}
+			}
+		}
+		numPackets = len(clientRecorder.Events(qlog.PacketReceived{}))
+		t.Logf("client received %d packets and sent %d ACKs", numPackets, numAcks)
+	})
+	return numAckFrequencyFrames, numAcks, numPackets
+}
@@ -3,6 +3,7 @@ package self_test
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -228,3 +229,110 @@ func testTransportClose(t *testing.T, conn net.PacketConn, closeFn func(), expec
 		require.ErrorIs(t, err, expectedErr)
 	}
 }
+
+func TestTransportShutdown(t *testing.T) {
+	t.Run("all connections closed", func(t *testing.T) {
+		testTransportShutdown(t, false)
+	})
+	t.Run("context expired", func(t *testing.T) {
+		testTransportShutdown(t, true)
+	})
+}
+
+func testTransportShutdown(t *testing.T, contextExpires bool) {
+	synctest.Test(t, func(t *testing.T) {
+		const rtt = 10 * time.Millisecond
+		n := &simnet.Simnet{Router: &simnet.PerfectRouter{}}
+		settings := simnet.NodeBiDiLinkSettings{Latency: rtt / 2}
+		clientConn1 := n.NewEndpoint(&net.UDPAddr{IP: net.ParseIP("1.0.0.1"), Port: 9001}, settings)
+		clientConn2 := n.NewEndpoint(&net.UDPAddr{IP: net.ParseIP("1.0.0.3"), Port: 9003}, settings)
+		serverConn := n.NewEndpoint(&net.UDPAddr{IP: net.ParseIP("1.0.0.2"), Port: 9002}, settings)
+		require.NoError(t, n.Start())
+		defer n.Close()
+
+		tr := &quic.Transport{
+			Conn: serverConn,
+			// announce the shutdown on a unidirectional stream
+			OnShutdown: func(conn *quic.Conn) {
+				str, err := conn.OpenUniStream()
+				if err != nil {
+					return
+				}
+				str.Write([]byte("goaway"))
+				str.Close()
+			},
+		}
+		defer tr.Close()
+		server, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		conn, err := quic.Dial(ctx, clientConn1, server.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+		require.NoError(t, err)
+		defer conn.CloseWithError(0, "")
+		sconn, err := server.Accept(ctx)
+		require.NoError(t, err)
+
+		// use a timeout shorter than the idle timeout
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		shutdownErr := make(chan error, 1)
+		go func() { shutdownErr <- tr.Shutdown(shutdownCtx) }()
+
+		// the client receives the shutdown announcement, and the connection is still usable
+		str, err := conn.AcceptUniStream(ctx)
+		require.NoError(t, err)
+		data, err := io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, []byte("goaway"), data)
+		sstr, err := sconn.OpenUniStream()
+		require.NoError(t, err)
+		_, err = sstr.Write([]byte("foobar"))
+		require.NoError(t, err)
+		require.NoError(t, sstr.Close())
+		str, err = conn.AcceptUniStream(ctx)
+		require.NoError(t, err)
+		data, err = io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, []byte("foobar"), data)
+
+		// new connection attempts are refused
+		_, err = quic.Dial(ctx, clientConn2, server.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+		require.ErrorIs(t, err, &quic.TransportError{Remote: true, ErrorCode: quic.ConnectionRefused})
+
+		select {
+		case <-shutdownErr:
+			t.Fatal("Shutdown returned before the connection was closed")
+		default:
+		}
+
+		if contextExpires {
+			time.Sleep(5 * time.Second)
+			select {
+			case err := <-shutdownErr:
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+			case <-time.After(time.Second):
+				t.Fatal("timeout")
+			}
+			select {
+			case <-sconn.Context().Done():
+			default:
+				t.Fatal("connection should have been closed")
+			}
+			return
+		}
+
+		require.NoError(t, conn.CloseWithError(0, ""))
+		select {
+		case err := <-shutdownErr:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+
+		// it's not possible to dial new connections
+		_, err = tr.Dial(ctx, clientConn1.LocalAddr(), getTLSClientConfig(), getQuicConfig(nil))
+		require.ErrorIs(t, err, quic.ErrTransportClosed)
+	})
+}
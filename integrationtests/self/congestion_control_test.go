@@ -0,0 +1,93 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/internal/synctest"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/testutils/events"
+	"github.com/quic-go/quic-go/testutils/simnet"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCongestionControllers(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		factory func(*quic.CongestionControlInfo) quic.CongestionController
+		state   qlog.CongestionState // a state that is expected to be reached during the transfer
+	}{
+		{name: "Reno", factory: quic.NewRenoCongestionController, state: qlog.CongestionStateCongestionAvoidance},
+		{name: "Cubic", factory: quic.NewCubicCongestionController, state: qlog.CongestionStateCongestionAvoidance},
+		{name: "BBR", factory: quic.NewBBRCongestionController, state: qlog.CongestionStateProbeBW},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testCongestionController(t, tc.factory, tc.state)
+		})
+	}
+}
+
+func testCongestionController(t *testing.T, factory func(*quic.CongestionControlInfo) quic.CongestionController, state qlog.CongestionState) {
+	synctest.Test(t, func(t *testing.T) {
+		const rtt = 20 * time.Millisecond
+		clientPacketConn, serverPacketConn, closeFn := newSimnetLinkWithRouter(t, rtt, &droppingRouter{
+			Drop: func(p simnet.Packet) bool {
+				// drop 2% of the 1-RTT packets
+				return !wire.IsLongHeaderPacket(p.Data[0]) && rand.IntN(50) == 0
+			},
+		})
+		defer closeFn(t)
+
+		var eventRecorder events.Recorder
+		ln, err := quic.Listen(
+			serverPacketConn,
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				CongestionControl: factory,
+				Tracer:            newTracer(&eventRecorder),
+			}),
+		)
+		require.NoError(t, err)
+		defer ln.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		conn, err := quic.Dial(ctx, clientPacketConn, ln.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+		require.NoError(t, err)
+		defer conn.CloseWithError(0, "")
+
+		data := GeneratePRData(5 << 20)
+		serverConn, err := ln.Accept(ctx)
+		require.NoError(t, err)
+		defer serverConn.CloseWithError(0, "")
+		go func() {
+			str, err := serverConn.OpenUniStream()
+			if err != nil {
+				return
+			}
+			str.Write(data)
+			str.Close()
+		}()
+
+		str, err := conn.AcceptUniStream(ctx)
+		require.NoError(t, err)
+		b, err := io.ReadAll(str)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, b))
+
+		stats := serverConn.ConnectionStats()
+		require.NotZero(t, stats.PacketsLost)
+		require.NotZero(t, stats.CongestionWindow)
+		require.NotZero(t, stats.PacingRate)
+		require.False(t, stats.InSlowStart)
+		require.Nil(t, stats.Paths)
+		require.Contains(t, eventRecorder.Events(qlog.CongestionStateUpdated{}), qlog.CongestionStateUpdated{State: state})
+	})
+}
@@ -248,3 +248,108 @@ func TestDatagramLoss(t *testing.T) {
 		assert.EqualValues(t, numDatagrams-numDroppedToClient, clientDatagrams, "datagrams received by the client")
 	})
 }
+
+func TestDatagramDeliveryStatus(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		const rtt = 100 * time.Millisecond
+		const numDatagrams = 50
+		const datagramSize = 500
+
+		clientAddr := &net.UDPAddr{IP: net.ParseIP("1.0.0.1"), Port: 9001}
+		serverAddr := &net.UDPAddr{IP: net.ParseIP("1.0.0.2"), Port: 9002}
+		var dropped atomic.Int32
+		n := &simnet.Simnet{
+			Router: &directionAwareDroppingRouter{
+				ClientAddr: clientAddr,
+				ServerAddr: serverAddr,
+				Drop: func(d direction, p simnet.Packet) bool {
+					// only drop Short Header packets with DATAGRAM frames sent by the client
+					if d != directionToServer || wire.IsLongHeaderPacket(p.Data[0]) || len(p.Data) < datagramSize {
+						return false
+					}
+					if mrand.Int()%4 == 0 {
+						dropped.Add(1)
+						return true
+					}
+					return false
+				},
+			},
+		}
+		settings := simnet.NodeBiDiLinkSettings{Latency: rtt / 2}
+		clientPacketConn := n.NewEndpoint(clientAddr, settings)
+		defer clientPacketConn.Close()
+		serverPacketConn := n.NewEndpoint(serverAddr, settings)
+		defer serverPacketConn.Close()
+		require.NoError(t, n.Start())
+		defer n.Close()
+
+		server, err := quic.Listen(
+			serverPacketConn,
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{DisablePathMTUDiscovery: true, EnableDatagrams: true}),
+		)
+		require.NoError(t, err)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		clientConn, err := quic.Dial(
+			ctx,
+			clientPacketConn,
+			serverPacketConn.LocalAddr(),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{DisablePathMTUDiscovery: true, EnableDatagrams: true}),
+		)
+		require.NoError(t, err)
+		defer clientConn.CloseWithError(0, "")
+
+		serverConn, err := server.Accept(ctx)
+		require.NoError(t, err)
+		defer serverConn.CloseWithError(0, "")
+
+		var received atomic.Int32
+		go func() {
+			for {
+				if _, err := serverConn.ReceiveDatagram(ctx); err != nil {
+					return
+				}
+				received.Add(1)
+			}
+		}()
+
+		statusChan := make(chan quic.DatagramStatus, numDatagrams+1)
+		for i := range numDatagrams {
+			require.NoError(t, clientConn.SendDatagramWithOptions(
+				bytes.Repeat([]byte{uint8(i)}, datagramSize),
+				quic.DatagramSendOptions{OnStatus: func(s quic.DatagramStatus) { statusChan <- s }},
+			))
+			time.Sleep(rtt)
+		}
+		// this datagram expires before it can be sent
+		require.NoError(t, clientConn.SendDatagramWithOptions(
+			[]byte("expired"),
+			quic.DatagramSendOptions{
+				Deadline: time.Now(),
+				OnStatus: func(s quic.DatagramStatus) { statusChan <- s },
+			},
+		))
+
+		statuses := make(map[quic.DatagramStatus]int)
+		for range numDatagrams + 1 {
+			select {
+			case s := <-statusChan:
+				statuses[s]++
+			case <-ctx.Done():
+				t.Fatal("timeout")
+			}
+		}
+		t.Logf("dropped %d out of %d datagrams", dropped.Load(), numDatagrams)
+		require.NotZero(t, dropped.Load())
+		require.Equal(t, map[quic.DatagramStatus]int{
+			quic.DatagramAcked:   numDatagrams - int(dropped.Load()),
+			quic.DatagramLost:    int(dropped.Load()),
+			quic.DatagramExpired: 1,
+		}, statuses)
+		require.EqualValues(t, numDatagrams-dropped.Load(), received.Load())
+	})
+}
@@ -0,0 +1,98 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGreaseQUICBit(t *testing.T) {
+	numIncoming, numIncomingGreased, numOutgoing, numOutgoingGreased := runGreaseQUICBitTest(t, false)
+	t.Logf("greased %d of %d packets sent by the client, %d of %d packets sent by the server",
+		numIncomingGreased, numIncoming, numOutgoingGreased, numOutgoing)
+	// the QUIC bit is set randomly on about half of the packets
+	require.Greater(t, numIncomingGreased, numIncoming/5)
+	require.Greater(t, numOutgoingGreased, numOutgoing/5)
+}
+
+func TestGreaseQUICBitDisabled(t *testing.T) {
+	// Only the server disables greasing.
+	// The client still allows the server to grease the QUIC bit.
+	numIncoming, numIncomingGreased, numOutgoing, numOutgoingGreased := runGreaseQUICBitTest(t, true)
+	t.Logf("greased %d of %d packets sent by the client, %d of %d packets sent by the server",
+		numIncomingGreased, numIncoming, numOutgoingGreased, numOutgoing)
+	require.Zero(t, numIncomingGreased)
+	require.Greater(t, numOutgoingGreased, numOutgoing/5)
+}
+
+func runGreaseQUICBitTest(t *testing.T, disableOnServer bool) (numIncoming, numIncomingGreased, numOutgoing, numOutgoingGreased int64) {
+	t.Helper()
+
+	ln, err := quic.Listen(
+		newUDPConnLocalhost(t),
+		getTLSConfig(),
+		getQuicConfig(&quic.Config{DisableQUICBitGreasing: disableOnServer}),
+	)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	var (
+		incoming, incomingGreased atomic.Int64
+		outgoing, outgoingGreased atomic.Int64
+	)
+	proxy := &quicproxy.Proxy{
+		Conn:       newUDPConnLocalhost(t),
+		ServerAddr: ln.Addr().(*net.UDPAddr),
+		DelayPacket: func(dir quicproxy.Direction, _, _ net.Addr, data []byte) time.Duration {
+			greased := data[0]&0x40 == 0
+			switch dir {
+			case quicproxy.DirectionIncoming:
+				incoming.Add(1)
+				if greased {
+					incomingGreased.Add(1)
+				}
+			case quicproxy.DirectionOutgoing:
+				outgoing.Add(1)
+				if greased {
+					outgoingGreased.Add(1)
+				}
+			}
+			return 5 * time.Millisecond
+		},
+	}
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			return
+		}
+		str, err := conn.OpenUniStream()
+		if err != nil {
+			return
+		}
+		str.Write(PRData)
+		str.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := quic.Dial(ctx, newUDPConnLocalhost(t), proxy.LocalAddr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	str, err := conn.AcceptUniStream(ctx)
+	require.NoError(t, err)
+	data, err := io.ReadAll(str)
+	require.NoError(t, err)
+	require.Equal(t, PRData, data)
+	return incoming.Load(), incomingGreased.Load(), outgoing.Load(), outgoingGreased.Load()
+}
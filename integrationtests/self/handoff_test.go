@@ -0,0 +1,99 @@
+//go:build unix
+
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSocketHandoff simulates a hot restart: A new Transport takes over the socket of a running Transport,
+// and forwards packets for the old Transport's connections, until these connections are closed.
+func TestSocketHandoff(t *testing.T) {
+	udpConn := newUDPConnLocalhost(t)
+	oldTr := &quic.Transport{Conn: udpConn}
+	defer oldTr.Close()
+	oldLn, err := oldTr.Listen(getTLSConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	conn1, err := quic.Dial(ctx, newUDPConnLocalhost(t), udpConn.LocalAddr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn1.CloseWithError(0, "")
+	sconn1, err := oldLn.Accept(ctx)
+	require.NoError(t, err)
+
+	// The new process inherits a duplicate of the socket's file descriptor.
+	f, err := udpConn.File()
+	require.NoError(t, err)
+	defer f.Close()
+	newConn, err := net.FilePacketConn(f)
+	require.NoError(t, err)
+	defer newConn.Close()
+
+	forwarded := newUDPConnLocalhost(t)
+	forwardConn := newUDPConnLocalhost(t)
+	newTr := &quic.Transport{
+		Conn:        newConn,
+		ForwardConn: forwardConn,
+		ForwardAddr: forwarded.LocalAddr(),
+	}
+	defer newTr.Close()
+	newLn, err := newTr.Listen(getTLSConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	require.NoError(t, oldTr.HandOff(forwarded, forwardConn.LocalAddr()))
+
+	// the existing connection is still served by the old Transport
+	testEcho := func(t *testing.T, client, server *quic.Conn) {
+		t.Helper()
+		str, err := client.OpenStreamSync(ctx)
+		require.NoError(t, err)
+		_, err = str.Write([]byte("foobar"))
+		require.NoError(t, err)
+		require.NoError(t, str.Close())
+		sstr, err := server.AcceptStream(ctx)
+		require.NoError(t, err)
+		data, err := io.ReadAll(sstr)
+		require.NoError(t, err)
+		require.Equal(t, []byte("foobar"), data)
+		_, err = sstr.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, sstr.Close())
+		data, err = io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, []byte("foobar"), data)
+	}
+	testEcho(t, conn1, sconn1)
+
+	// new connections are handled by the new Transport
+	conn2, err := quic.Dial(ctx, newUDPConnLocalhost(t), udpConn.LocalAddr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn2.CloseWithError(0, "")
+	sconn2, err := newLn.Accept(ctx)
+	require.NoError(t, err)
+	testEcho(t, conn2, sconn2)
+
+	// the old Transport shuts down once its connections are closed
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- oldTr.Shutdown(ctx) }()
+	testEcho(t, conn1, sconn1)
+	require.NoError(t, conn1.CloseWithError(0, ""))
+	select {
+	case err := <-shutdownErr:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("timeout")
+	}
+	// the old process is done, so there's no need to forward packets anymore
+	newTr.StopForwarding()
+
+	testEcho(t, conn2, sconn2)
+}
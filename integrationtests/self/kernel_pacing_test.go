@@ -0,0 +1,46 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKernelPacing(t *testing.T) {
+	server := &quic.Transport{Conn: newUDPConnLocalhost(t), EnableKernelPacing: true}
+	defer server.Close()
+	ln, err := server.Listen(getTLSConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			return
+		}
+		str, err := conn.OpenUniStream()
+		if err != nil {
+			return
+		}
+		str.Write(PRDataLong)
+		str.Close()
+	}()
+
+	client := &quic.Transport{Conn: newUDPConnLocalhost(t), EnableKernelPacing: true}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := client.Dial(ctx, ln.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	str, err := conn.AcceptUniStream(ctx)
+	require.NoError(t, err)
+	data, err := io.ReadAll(str)
+	require.NoError(t, err)
+	require.Equal(t, PRDataLong, data)
+}
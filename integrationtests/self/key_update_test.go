@@ -3,11 +3,11 @@ package self_test
 import (
 	"context"
 	"io"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/quic-go/quic-go"
-	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/qlog"
 	"github.com/quic-go/quic-go/qlogwriter"
@@ -18,9 +18,6 @@ import (
 )
 
 func TestKeyUpdates(t *testing.T) {
-	reset := handshake.SetKeyUpdateInterval(1) // update keys as frequently as possible
-	t.Cleanup(reset)
-
 	countKeyPhases := func(events []qlogwriter.Event) (sent, received int) {
 		lastKeyPhaseSend := protocol.KeyPhaseOne
 		lastKeyPhaseReceive := protocol.KeyPhaseOne
@@ -41,7 +38,8 @@ func TestKeyUpdates(t *testing.T) {
 		return
 	}
 
-	server, err := quic.Listen(newUDPConnLocalhost(t), getTLSConfig(), nil)
+	// update keys as frequently as possible
+	server, err := quic.Listen(newUDPConnLocalhost(t), getTLSConfig(), getQuicConfig(&quic.Config{KeyUpdatePacketInterval: 1}))
 	require.NoError(t, err)
 	defer server.Close()
 
@@ -53,7 +51,7 @@ func TestKeyUpdates(t *testing.T) {
 		newUDPConnLocalhost(t),
 		server.Addr(),
 		getTLSClientConfig(),
-		getQuicConfig(&quic.Config{Tracer: newTracer(&eventRecorder)}),
+		getQuicConfig(&quic.Config{KeyUpdatePacketInterval: 1, Tracer: newTracer(&eventRecorder)}),
 	)
 	require.NoError(t, err)
 	defer conn.CloseWithError(0, "")
@@ -91,3 +89,64 @@ func TestKeyUpdates(t *testing.T) {
 	assert.Greater(t, keyPhasesReceived, 10)
 	assert.InDelta(t, keyPhasesSent, keyPhasesReceived, 2)
 }
+
+func TestInitiateKeyUpdate(t *testing.T) {
+	server, err := quic.Listen(newUDPConnLocalhost(t), getTLSConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var eventRecorder events.Recorder
+	conn, err := quic.Dial(
+		ctx,
+		newUDPConnLocalhost(t),
+		server.Addr(),
+		getTLSClientConfig(),
+		getQuicConfig(&quic.Config{Tracer: newTracer(&eventRecorder)}),
+	)
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+
+	serverConn, err := server.Accept(ctx)
+	require.NoError(t, err)
+	defer serverConn.CloseWithError(0, "")
+
+	// the client's handshake is confirmed when it receives the HANDSHAKE_DONE frame
+	select {
+	case <-conn.HandshakeComplete():
+	case <-ctx.Done():
+		t.Fatal("timeout")
+	}
+	require.Eventually(t, func() bool { return conn.InitiateKeyUpdate() == nil }, time.Second, 5*time.Millisecond)
+
+	// exchange some data, then initiate the next key update
+	str, err := conn.OpenStream()
+	require.NoError(t, err)
+	_, err = str.Write([]byte("foobar"))
+	require.NoError(t, err)
+	serverStr, err := serverConn.AcceptStream(ctx)
+	require.NoError(t, err)
+	_, err = io.ReadFull(serverStr, make([]byte, 6))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return conn.InitiateKeyUpdate() == nil }, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		var keyUpdates []qlog.KeyUpdated
+		for _, ev := range eventRecorder.Events(qlog.KeyUpdated{}) {
+			if ku := ev.(qlog.KeyUpdated); ku.Trigger == qlog.KeyUpdateLocal && ku.KeyType == qlog.KeyTypeClient1RTT {
+				keyUpdates = append(keyUpdates, ku)
+			}
+		}
+		return len(keyUpdates) == 2 && keyUpdates[1].KeyPhase == 2
+	}, time.Second, 5*time.Millisecond)
+
+	// the connection is still usable after the key updates
+	_, err = serverStr.Write([]byte("done"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(str, make([]byte, 4))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.CloseWithError(0, ""))
+	require.ErrorIs(t, conn.InitiateKeyUpdate(), net.ErrClosed)
+}
@@ -75,7 +75,7 @@ func TestMITCorruptPackets(t *testing.T) {
 func testMITMInjectRandomPackets(t *testing.T, direction quicproxy.Direction) {
 	createRandomPacketOfSameType := func(b []byte) []byte {
 		if wire.IsLongHeaderPacket(b[0]) {
-			hdr, _, _, err := wire.ParsePacket(b)
+			hdr, _, _, err := wire.ParsePacketAllowClearedQUICBit(b)
 			if err != nil {
 				return nil
 			}
@@ -104,11 +104,11 @@ func testMITMInjectRandomPackets(t *testing.T, direction quicproxy.Direction) {
 		if err != nil {
 			return nil
 		}
-		_, pn, pnLen, _, err := wire.ParseShortHeader(b, mitmTestConnIDLen)
+		_, pn, pnLen, _, err := wire.ParseShortHeaderAllowClearedQUICBit(b, mitmTestConnIDLen)
 		if err != nil && !errors.Is(err, wire.ErrInvalidReservedBits) { // normally, ParseShortHeader is called after decrypting the header
 			panic("failed to parse short header: " + err.Error())
 		}
-		data, err := wire.AppendShortHeader(nil, connID, pn, pnLen, protocol.KeyPhaseBit(mrand.IntN(2)))
+		data, err := wire.AppendShortHeader(nil, connID, pn, pnLen, protocol.KeyPhaseBit(mrand.IntN(2)), false)
 		if err != nil {
 			return nil
 		}
@@ -274,7 +274,7 @@ func TestMITMForgedVersionNegotiationPacket(t *testing.T) {
 			return rtt / 2
 		}
 		once.Do(func() {
-			hdr, _, _, err := wire.ParsePacket(raw)
+			hdr, _, _, err := wire.ParsePacketAllowClearedQUICBit(raw)
 			if err != nil {
 				panic("failed to parse packet: " + err.Error())
 			}
@@ -307,7 +307,7 @@ func TestMITMForgedRetryPacket(t *testing.T) {
 
 	var once sync.Once
 	delayCb := func(dir quicproxy.Direction, _, _ net.Addr, raw []byte) time.Duration {
-		hdr, _, _, err := wire.ParsePacket(raw)
+		hdr, _, _, err := wire.ParsePacketAllowClearedQUICBit(raw)
 		if err != nil {
 			panic("failed to parse packet: " + err.Error())
 		}
@@ -335,7 +335,7 @@ func TestMITMForgedInitialPacket(t *testing.T) {
 	var once sync.Once
 	delayCb := func(dir quicproxy.Direction, _, _ net.Addr, raw []byte) time.Duration {
 		if dir == quicproxy.DirectionIncoming {
-			hdr, _, _, err := wire.ParsePacket(raw)
+			hdr, _, _, err := wire.ParsePacketAllowClearedQUICBit(raw)
 			if err != nil {
 				panic("failed to parse packet: " + err.Error())
 			}
@@ -372,7 +372,7 @@ func TestMITMForgedInitialPacketWithAck(t *testing.T) {
 	var once sync.Once
 	delayCb := func(dir quicproxy.Direction, _, _ net.Addr, raw []byte) time.Duration {
 		if dir == quicproxy.DirectionIncoming {
-			hdr, _, _, err := wire.ParsePacket(raw)
+			hdr, _, _, err := wire.ParsePacketAllowClearedQUICBit(raw)
 			if err != nil {
 				panic("failed to parse packet: " + err.Error())
 			}
@@ -182,6 +182,7 @@ func TestPathMTUDiscovery(t *testing.T) {
 	const maxDiff = 40 // this includes the 21 bytes for the short header, 16 bytes for the encryption tag, and framing overhead
 	require.GreaterOrEqual(t, int(initialMaxDatagramSize), protocol.MinInitialPacketSize-maxDiff)
 	require.GreaterOrEqual(t, int(finalMaxDatagramSize), maxPacketSizeClient-maxDiff)
+	require.EqualValues(t, maxPacketSizeClient, conn.ConnectionStats().PathMTU)
 	// MTU discovery was disabled on the server side
 	require.Equal(t, 1234, maxPacketSizeServer)
 
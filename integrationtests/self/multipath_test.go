@@ -0,0 +1,142 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipath(t *testing.T) {
+	ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{EnableMultipath: true}))
+	require.NoError(t, err)
+	defer ln.Close()
+
+	tr1 := &quic.Transport{Conn: newUDPConnLocalhost(t)}
+	defer tr1.Close()
+	tr2 := &quic.Transport{Conn: newUDPConnLocalhost(t)}
+	defer tr2.Close()
+
+	var packetsPath1, packetsPath2 atomic.Int64
+	var dropPath2 atomic.Bool
+
+	const rtt = 5 * time.Millisecond
+	portOf := func(dir quicproxy.Direction, from, to net.Addr) int {
+		if dir == quicproxy.DirectionIncoming {
+			return from.(*net.UDPAddr).Port
+		}
+		return to.(*net.UDPAddr).Port
+	}
+	proxy := quicproxy.Proxy{
+		Conn:       newUDPConnLocalhost(t),
+		ServerAddr: ln.Addr().(*net.UDPAddr),
+		DelayPacket: func(dir quicproxy.Direction, from, to net.Addr, _ []byte) time.Duration {
+			switch portOf(dir, from, to) {
+			case tr1.Conn.LocalAddr().(*net.UDPAddr).Port:
+				packetsPath1.Add(1)
+			case tr2.Conn.LocalAddr().(*net.UDPAddr).Port:
+				packetsPath2.Add(1)
+			}
+			return rtt / 2
+		},
+		DropPacket: func(dir quicproxy.Direction, from, to net.Addr, _ []byte) bool {
+			return dropPath2.Load() && portOf(dir, from, to) == tr2.Conn.LocalAddr().(*net.UDPAddr).Port
+		},
+	}
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := tr1.Dial(ctx, proxy.LocalAddr(), getTLSClientConfig(), getQuicConfig(&quic.Config{EnableMultipath: true}))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	require.True(t, conn.ConnectionState().Multipath)
+
+	sconn, err := ln.Accept(ctx)
+	require.NoError(t, err)
+	defer sconn.CloseWithError(0, "")
+	require.True(t, sconn.ConnectionState().Multipath)
+
+	sendAndReceiveFile := func(t *testing.T) {
+		t.Helper()
+		str, err := conn.OpenUniStream()
+		require.NoError(t, err)
+
+		errChan := make(chan error, 1)
+		go func() {
+			defer close(errChan)
+			sstr, err := sconn.AcceptUniStream(ctx)
+			if err != nil {
+				errChan <- fmt.Errorf("accepting stream: %w", err)
+				return
+			}
+			data, err := io.ReadAll(sstr)
+			if err != nil {
+				errChan <- fmt.Errorf("reading stream data: %w", err)
+				return
+			}
+			if !bytes.Equal(data, PRDataLong) {
+				errChan <- errors.New("unexpected data")
+			}
+		}()
+
+		_, err = str.Write(PRDataLong)
+		require.NoError(t, err)
+		require.NoError(t, str.Close())
+
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for data")
+		}
+	}
+
+	path, err := conn.AddPath(tr2)
+	require.NoError(t, err)
+	require.NoError(t, path.Probe(ctx))
+
+	// both paths are used at the same time
+	c1, c2 := packetsPath1.Load(), packetsPath2.Load()
+	sendAndReceiveFile(t)
+	require.Greater(t, packetsPath1.Load()-c1, int64(100))
+	require.Greater(t, packetsPath2.Load()-c2, int64(100))
+	require.Equal(t, tr1.Conn.LocalAddr(), conn.LocalAddr())
+
+	// statistics are reported for every path
+	stats := conn.ConnectionStats()
+	require.Len(t, stats.Paths, 2)
+	require.Equal(t, tr1.Conn.LocalAddr(), stats.Paths[0].LocalAddr)
+	require.Equal(t, tr2.Conn.LocalAddr(), stats.Paths[1].LocalAddr)
+	for _, p := range stats.Paths {
+		require.Equal(t, proxy.LocalAddr(), p.RemoteAddr)
+		require.NotZero(t, p.CongestionWindow)
+		require.NotZero(t, p.SmoothedRTT)
+		require.NotZero(t, p.PathMTU)
+	}
+	require.Equal(t, stats.CongestionWindow, stats.Paths[0].CongestionWindow)
+	require.Len(t, sconn.ConnectionStats().Paths, 2)
+
+	// If the path fails, data is retransmitted on the remaining path.
+	dropPath2.Store(true)
+	sendAndReceiveFile(t)
+
+	// after closing the path, only the initial path is used
+	require.NoError(t, path.Close())
+	time.Sleep(5 * rtt)
+	c2 = packetsPath2.Load()
+	sendAndReceiveFile(t)
+	require.Equal(t, c2, packetsPath2.Load())
+}
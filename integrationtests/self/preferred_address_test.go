@@ -0,0 +1,93 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredAddress(t *testing.T) {
+	t.Run("migrating to the preferred address", func(t *testing.T) {
+		testPreferredAddress(t, true)
+	})
+	t.Run("preferred address unreachable", func(t *testing.T) {
+		testPreferredAddress(t, false)
+	})
+}
+
+func testPreferredAddress(t *testing.T, reachable bool) {
+	preferredTr := &quic.Transport{Conn: newUDPConnLocalhost(t)}
+	defer preferredTr.Close()
+	preferredAddr := preferredTr.Conn.LocalAddr().(*net.UDPAddr)
+	if !reachable {
+		// advertise an address that nobody is listening on
+		conn := newUDPConnLocalhost(t)
+		preferredAddr = conn.LocalAddr().(*net.UDPAddr)
+		conn.Close()
+	}
+
+	serverTr := &quic.Transport{Conn: newUDPConnLocalhost(t)}
+	defer serverTr.Close()
+	ln, err := serverTr.Listen(getTLSConfig(), getQuicConfig(&quic.Config{
+		PreferredAddress: &quic.PreferredAddress{
+			IPv4:      preferredAddr.AddrPort(),
+			Transport: preferredTr,
+		},
+	}))
+	require.NoError(t, err)
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := quic.Dial(ctx, newUDPConnLocalhost(t), ln.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	sconn, err := ln.Accept(ctx)
+	require.NoError(t, err)
+	defer sconn.CloseWithError(0, "")
+
+	sendAndReceive := func() {
+		t.Helper()
+		errChan := make(chan error, 1)
+		go func() {
+			str, err := sconn.AcceptStream(ctx)
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if _, err := io.Copy(str, str); err != nil {
+				errChan <- err
+				return
+			}
+			errChan <- str.Close()
+		}()
+		str, err := conn.OpenStream()
+		require.NoError(t, err)
+		_, err = str.Write(PRData)
+		require.NoError(t, err)
+		require.NoError(t, str.Close())
+		data, err := io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, PRData, data)
+		require.NoError(t, <-errChan)
+	}
+
+	sendAndReceive()
+	if reachable {
+		require.Eventually(t, func() bool {
+			return conn.RemoteAddr().String() == preferredAddr.String() &&
+				sconn.LocalAddr().String() == preferredAddr.String()
+		}, time.Second, 10*time.Millisecond)
+	}
+	sendAndReceive()
+	if !reachable {
+		require.Equal(t, ln.Addr().String(), conn.RemoteAddr().String())
+		require.Equal(t, ln.Addr().String(), sconn.LocalAddr().String())
+	}
+}
@@ -0,0 +1,74 @@
+package self_test
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/qlogwriter"
+	"github.com/quic-go/quic-go/quiclb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQUICLBConnectionIDs(t *testing.T) {
+	key := make([]byte, quiclb.KeyLen)
+	rand.Read(key)
+	lbConf := &quiclb.Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 6, Key: key}
+	serverID := []byte{0xde, 0xca, 0xf}
+	generator, err := quiclb.NewGenerator(lbConf, serverID)
+	require.NoError(t, err)
+	decoder, err := quiclb.NewDecoder(lbConf)
+	require.NoError(t, err)
+
+	serverTr := &quic.Transport{
+		Conn:                  newUDPConnLocalhost(t),
+		ConnectionIDGenerator: generator,
+	}
+	defer serverTr.Close()
+	serverCounter, serverTracer := newPacketTracer()
+	ln, err := serverTr.Listen(
+		getTLSConfig(),
+		getQuicConfig(&quic.Config{
+			Tracer: func(context.Context, bool, quic.ConnectionID) qlogwriter.Trace { return serverTracer },
+		}),
+	)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, err := quic.Dial(ctx, newUDPConnLocalhost(t), ln.Addr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+
+	serverConn, err := ln.Accept(ctx)
+	require.NoError(t, err)
+	go func() {
+		str, err := serverConn.OpenUniStream()
+		if err != nil {
+			return
+		}
+		str.Write(PRData)
+		str.Close()
+	}()
+	str, err := conn.AcceptUniStream(ctx)
+	require.NoError(t, err)
+	data, err := io.ReadAll(str)
+	require.NoError(t, err)
+	require.Equal(t, PRData, data)
+	conn.CloseWithError(0, "")
+	serverConn.CloseWithError(0, "")
+
+	packets := serverCounter.getRcvdShortHeaderPackets()
+	require.NotEmpty(t, packets)
+	for _, p := range packets {
+		configID, id, err := decoder.ServerID(p.hdr.DestConnectionID.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, uint8(2), configID)
+		require.Equal(t, serverID, id)
+	}
+}
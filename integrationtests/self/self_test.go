@@ -319,7 +319,7 @@ func contains0RTTPacket(data []byte) bool {
 		if !wire.IsLongHeaderPacket(data[0]) {
 			return false
 		}
-		hdr, _, rest, err := wire.ParsePacket(data)
+		hdr, _, rest, err := wire.ParsePacketAllowClearedQUICBit(data)
 		if err != nil {
 			return false
 		}
@@ -0,0 +1,107 @@
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+	"github.com/quic-go/quic-go/spinbit"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpinBit(t *testing.T) {
+	const rtt = 40 * time.Millisecond
+
+	// The spin bit is randomly disabled for 1 in 16 connections (on each side).
+	// Retry a few times, so that this test doesn't become flaky.
+	for range 5 {
+		samples := measureSpinBitRTT(t, rtt, 4)
+		t.Logf("RTT samples: %v", samples)
+		if len(samples) < 5 {
+			continue
+		}
+		slices.Sort(samples)
+		median := samples[len(samples)/2]
+		if median >= rtt && median < rtt+scaleDuration(20*time.Millisecond) {
+			return
+		}
+	}
+	t.Fatal("didn't observe the spin bit")
+}
+
+func measureSpinBitRTT(t *testing.T, rtt time.Duration, connIDLen int) []time.Duration {
+	t.Helper()
+
+	tr := &quic.Transport{
+		Conn:               newUDPConnLocalhost(t),
+		ConnectionIDLength: connIDLen,
+	}
+	defer tr.Close()
+	ln, err := tr.Listen(getTLSConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer ln.Close()
+
+	var mx sync.Mutex
+	var samples []time.Duration
+	observer := spinbit.NewObserver(connIDLen)
+	proxy := &quicproxy.Proxy{
+		Conn:       newUDPConnLocalhost(t),
+		ServerAddr: ln.Addr().(*net.UDPAddr),
+		DelayPacket: func(dir quicproxy.Direction, _, _ net.Addr, data []byte) time.Duration {
+			if dir == quicproxy.DirectionIncoming {
+				mx.Lock()
+				if rtt, ok := observer.Observe(data, time.Now()); ok {
+					samples = append(samples, rtt)
+				}
+				mx.Unlock()
+			}
+			return rtt / 2
+		},
+	}
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := quic.Dial(ctx, newUDPConnLocalhost(t), proxy.LocalAddr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+
+	serverConn, err := ln.Accept(ctx)
+	require.NoError(t, err)
+	defer serverConn.CloseWithError(0, "")
+	go func() {
+		str, err := serverConn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		io.Copy(str, str)
+	}()
+
+	// keep packets flowing in both directions
+	str, err := conn.OpenStream()
+	require.NoError(t, err)
+	go io.Copy(io.Discard, str)
+	ticker := time.NewTicker(2 * time.Millisecond)
+	defer ticker.Stop()
+	timer := time.NewTimer(scaleDuration(15 * rtt))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_, err := str.Write([]byte("foobar"))
+			require.NoError(t, err)
+		case <-timer.C:
+			mx.Lock()
+			defer mx.Unlock()
+			return slices.Clone(samples)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	quicproxy "github.com/quic-go/quic-go/integrationtests/tools/proxy"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamPriorities(t *testing.T) {
+	// make sure that flow control doesn't limit the transfer
+	ln, err := quic.ListenAddr(
+		"localhost:0",
+		getTLSConfig(),
+		getQuicConfig(&quic.Config{
+			InitialStreamReceiveWindow:     10 << 20,
+			InitialConnectionReceiveWindow: 20 << 20,
+		}),
+	)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	const rtt = 10 * time.Millisecond
+	proxy := quicproxy.Proxy{
+		Conn:        newUDPConnLocalhost(t),
+		ServerAddr:  ln.Addr().(*net.UDPAddr),
+		DelayPacket: func(quicproxy.Direction, net.Addr, net.Addr, []byte) time.Duration { return rtt / 2 },
+	}
+	require.NoError(t, proxy.Start())
+	defer proxy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := quic.Dial(ctx, newUDPConnLocalhost(t), proxy.LocalAddr(), getTLSClientConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "")
+	sconn, err := ln.Accept(ctx)
+	require.NoError(t, err)
+	defer sconn.CloseWithError(0, "")
+
+	bulkStr, err := conn.OpenUniStream()
+	require.NoError(t, err)
+	bulkStr.SetPriority(quic.StreamPriority{Urgency: 7, Incremental: true})
+	urgentStr, err := conn.OpenUniStream()
+	require.NoError(t, err)
+	urgentStr.SetPriority(quic.StreamPriority{Urgency: 0})
+	require.Equal(t, quic.StreamPriority{Urgency: 0}, urgentStr.Priority())
+
+	for _, str := range []*quic.SendStream{bulkStr, urgentStr} {
+		go func() {
+			defer str.Close()
+			if _, err := str.Write(PRData); err != nil {
+				t.Errorf("write failed: %v", err)
+			}
+		}()
+	}
+
+	completed := make(chan quic.StreamID, 2)
+	for range 2 {
+		str, err := sconn.AcceptUniStream(ctx)
+		require.NoError(t, err)
+		go func() {
+			data, err := io.ReadAll(str)
+			if err != nil || !bytes.Equal(data, PRData) {
+				t.Errorf("receiving data failed: %v", err)
+			}
+			completed <- str.StreamID()
+		}()
+	}
+
+	for _, expected := range []quic.StreamID{urgentStr.StreamID(), bulkStr.StreamID()} {
+		select {
+		case id := <-completed:
+			require.Equal(t, expected, id)
+		case <-ctx.Done():
+			t.Fatal("timeout")
+		}
+	}
+}
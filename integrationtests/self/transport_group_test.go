@@ -0,0 +1,92 @@
+//go:build darwin || freebsd || linux
+
+package self_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportGroup(t *testing.T) {
+	const numTransports = 4
+	var statelessResetKey quic.StatelessResetKey
+	g, err := quic.NewTransportGroup(
+		&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)},
+		numTransports,
+		func(tr *quic.Transport) { tr.StatelessResetKey = &statelessResetKey },
+	)
+	require.NoError(t, err)
+	defer g.Close()
+	require.Len(t, g.Transports(), numTransports)
+	addr := g.Transports()[0].Conn.LocalAddr()
+	for _, tr := range g.Transports() {
+		require.Equal(t, addr, tr.Conn.LocalAddr())
+	}
+
+	lns, err := g.Listen(getTLSConfig(), getQuicConfig(nil))
+	require.NoError(t, err)
+	for _, ln := range lns {
+		go func() {
+			for {
+				conn, err := ln.Accept(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					for {
+						str, err := conn.AcceptStream(context.Background())
+						if err != nil {
+							return
+						}
+						go func() {
+							io.Copy(str, str)
+							str.Close()
+						}()
+					}
+				}()
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for range 2 * numTransports {
+		tr1 := &quic.Transport{Conn: newUDPConnLocalhost(t)}
+		defer tr1.Close()
+		conn, err := tr1.Dial(ctx, addr, getTLSClientConfig(), getQuicConfig(nil))
+		require.NoError(t, err)
+		str, err := conn.OpenStreamSync(ctx)
+		require.NoError(t, err)
+		_, err = str.Write([]byte("foobar"))
+		require.NoError(t, err)
+		require.NoError(t, str.Close())
+		data, err := io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, []byte("foobar"), data)
+
+		// simulate a NAT rebinding:
+		// the new 4-tuple is likely hashed to a different socket of the group
+		tr2 := &quic.Transport{Conn: newUDPConnLocalhost(t)}
+		defer tr2.Close()
+		path, err := conn.AddPath(tr2)
+		require.NoError(t, err)
+		require.NoError(t, path.Probe(ctx))
+		require.NoError(t, path.Switch())
+		str, err = conn.OpenStreamSync(ctx)
+		require.NoError(t, err)
+		_, err = str.Write([]byte("rebinding"))
+		require.NoError(t, err)
+		require.NoError(t, str.Close())
+		data, err = io.ReadAll(str)
+		require.NoError(t, err)
+		require.Equal(t, []byte("rebinding"), data)
+		conn.CloseWithError(0, "")
+	}
+}
@@ -388,7 +388,7 @@ func Test0RTTDataLoss(t *testing.T) {
 				if !wire.IsLongHeaderPacket(p.Data[0]) {
 					return false
 				}
-				hdr, _, _, _ := wire.ParsePacket(p.Data)
+				hdr, _, _, _ := wire.ParsePacketAllowClearedQUICBit(p.Data)
 				if hdr.Type == protocol.PacketType0RTT {
 					count := num0RTTPackets.Add(1)
 					// drop 25% of the 0-RTT packets
@@ -451,7 +451,7 @@ func test0RTTRetransmitOnRetry(t *testing.T, useRetry bool) {
 		var connIDToCounter []*connIDCounter
 		countZeroRTTBytes := func(data []byte) (n protocol.ByteCount) {
 			for len(data) > 0 {
-				hdr, _, rest, err := wire.ParsePacket(data)
+				hdr, _, rest, err := wire.ParsePacketAllowClearedQUICBit(data)
 				if err != nil {
 					return
 				}
@@ -1033,7 +1033,7 @@ func Test0RTTPacketQueueing(t *testing.T) {
 			LatencyFunc: func(p simnet.Packet) time.Duration {
 				if p.To.String() == serverAddr.String() {
 					if wire.IsLongHeaderPacket(p.Data[0]) {
-						hdr, _, _, err := wire.ParsePacket(p.Data)
+						hdr, _, _, err := wire.ParsePacketAllowClearedQUICBit(p.Data)
 						if err == nil && hdr.Type == protocol.PacketTypeInitial {
 							return rtt * 3 / 2
 						}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"testing"
 	"time"
@@ -190,3 +191,81 @@ func TestServerDisablesVersionNegotiation(t *testing.T) {
 	require.True(t, nerr.Timeout())
 	require.Empty(t, clientEventTracer.Events(qlog.VersionNegotiationReceived{}))
 }
+
+func TestCompatibleVersionNegotiation(t *testing.T) {
+	var serverEventTracer events.Recorder
+	serverConfig := &quic.Config{
+		Versions: []protocol.Version{quic.Version2, quic.Version1},
+		Tracer: func(context.Context, bool, quic.ConnectionID) qlogwriter.Trace {
+			return &events.Trace{Recorder: &serverEventTracer}
+		},
+	}
+	server, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+	require.NoError(t, err)
+	defer server.Close()
+
+	// The client starts the handshake using QUIC v1, and is upgraded to QUIC v2 by the server.
+	clientVersions := []protocol.Version{quic.Version1, quic.Version2}
+	var clientEventTracer events.Recorder
+	conn, err := quic.DialAddr(
+		context.Background(),
+		fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+		getTLSClientConfig(),
+		maybeAddQLOGTracer(&quic.Config{
+			Versions: clientVersions,
+			Tracer: func(context.Context, bool, quic.ConnectionID) qlogwriter.Trace {
+				return &events.Trace{Recorder: &clientEventTracer}
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	sconn, err := server.Accept(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, quic.Version2, sconn.ConnectionState().Version)
+	require.Equal(t, quic.Version2, conn.ConnectionState().Version)
+
+	// make sure that the connection is usable
+	str, err := conn.OpenUniStream()
+	require.NoError(t, err)
+	_, err = str.Write([]byte("foobar"))
+	require.NoError(t, err)
+	require.NoError(t, str.Close())
+	sstr, err := sconn.AcceptUniStream(context.Background())
+	require.NoError(t, err)
+	data, err := io.ReadAll(sstr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("foobar"), data)
+
+	require.NoError(t, conn.CloseWithError(0, ""))
+	select {
+	case <-sconn.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for connection to close")
+	}
+
+	// no Version Negotiation packet was needed
+	require.Empty(t, clientEventTracer.Events(qlog.VersionNegotiationReceived{}))
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.VersionInformation{
+				ClientVersions: clientVersions,
+				ChosenVersion:  quic.Version2,
+			},
+		},
+		clientEventTracer.Events(qlog.VersionInformation{}),
+	)
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.VersionInformation{
+				ServerVersions: serverConfig.Versions,
+				ChosenVersion:  quic.Version1,
+			},
+			qlog.VersionInformation{
+				ServerVersions: serverConfig.Versions,
+				ChosenVersion:  quic.Version2,
+			},
+		},
+		serverEventTracer.Events(qlog.VersionInformation{}),
+	)
+}
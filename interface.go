@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"net/netip"
 	"slices"
 	"time"
 
@@ -58,6 +59,13 @@ type TokenStore interface {
 // when the server rejects a 0-RTT connection attempt.
 var Err0RTTRejected = errors.New("0-RTT rejected")
 
+var (
+	// ErrHandshakeNotConfirmed is returned by Conn.InitiateKeyUpdate if the handshake is not yet confirmed.
+	ErrHandshakeNotConfirmed = handshake.ErrHandshakeNotConfirmed
+	// ErrKeyUpdateInProgress is returned by Conn.InitiateKeyUpdate if the previous key update hasn't completed yet.
+	ErrKeyUpdateInProgress = handshake.ErrKeyUpdateInProgress
+)
+
 // ConnectionTracingKey can be used to associate a [logging.ConnectionTracer] with a [Conn].
 // It is set on the Conn.Context() context,
 // as well as on the context passed to logging.Tracer.NewConnectionTracer.
@@ -181,6 +189,29 @@ type Config struct {
 	// This allows the sending of QUIC packets that fully utilize the available MTU of the path.
 	// Path MTU discovery is only available on systems that allow setting of the Don't Fragment (DF) bit.
 	DisablePathMTUDiscovery bool
+	// DisableSpinBit disables the latency spin bit (see section 17.4 of RFC 9000).
+	// The spin bit allows on-path observers to measure the RTT of the connection.
+	// Independently of this value, the spin bit is disabled for a random 1 in 16 connections.
+	// When disabled, a random value is sent.
+	DisableSpinBit bool
+	// DisableQUICBitGreasing stops advertising support for greasing of the QUIC bit (RFC 9287).
+	// By default, peers are allowed to send packets with the second-most significant bit of the first byte cleared.
+	// When set, such packets are dropped.
+	// This should be set when demultiplexing QUIC and non-QUIC packets on the same socket
+	// (see Transport.ReadNonQUICPacket and RFC 9443), since these packets can't reliably be told apart from non-QUIC packets.
+	DisableQUICBitGreasing bool
+	// KeyUpdatePacketInterval is the maximum number of packets sent or received with the same 1-RTT keys.
+	// Once this number is reached, a key update is initiated (see section 6 of RFC 9001).
+	// Independently of this value, the first key update is initiated shortly after the handshake,
+	// and key updates can be initiated at any time using Conn.InitiateKeyUpdate.
+	// If not set, it will default to 100,000.
+	// Values larger than 2^23 (the confidentiality limit of AES-GCM) will be clipped to that value.
+	KeyUpdatePacketInterval uint64
+	// KeyUpdatePeriod is the maximum amount of time the same 1-RTT keys are used for.
+	// Once it has elapsed, a key update is initiated when the next packet is sent.
+	// This happens in addition to the key updates triggered by KeyUpdatePacketInterval.
+	// If not set, keys are not updated based on time.
+	KeyUpdatePeriod time.Duration
 	// Allow0RTT allows the application to decide if a 0-RTT connection attempt should be accepted.
 	// Only valid for the server.
 	Allow0RTT bool
@@ -189,10 +220,46 @@ type Config struct {
 	// Enable QUIC Stream Resets with Partial Delivery.
 	// See https://datatracker.ietf.org/doc/html/draft-ietf-quic-reliable-stream-reset-07.
 	EnableStreamResetPartialDelivery bool
+	// Enable the QUIC ACK Frequency extension.
+	// See https://datatracker.ietf.org/doc/html/draft-ietf-quic-ack-frequency-11.
+	// The extension is only used if both endpoints enable it.
+	EnableAckFrequency bool
+	// Enable the QUIC multipath extension.
+	// See https://datatracker.ietf.org/doc/html/draft-ietf-quic-multipath-14.
+	// Multipath is only used if both endpoints enable it, and both endpoints use non-zero-length connection IDs.
+	// Paths are added by the client using Conn.AddPath and Path.Probe.
+	EnableMultipath bool
+	// MultipathScheduler creates the scheduler that decides which path a packet is sent on.
+	// It is only used if the multipath extension is negotiated.
+	// If not set, packets are sent on the path with the lowest RTT that is not blocked by congestion control.
+	MultipathScheduler func() PathScheduler
+	// PreferredAddress is the address the server would like clients to migrate to after the handshake
+	// (see section 9.6 of RFC 9000).
+	// Only valid for the server.
+	PreferredAddress *PreferredAddress
+	// CongestionControl creates the congestion controller for a connection.
+	// It is called when the connection is established, and every time the connection migrates to a new path.
+	// If not set, NewReno is used (see NewRenoCongestionController).
+	CongestionControl func(*CongestionControlInfo) CongestionController
 
 	Tracer func(ctx context.Context, isClient bool, connID ConnectionID) qlogwriter.Trace
 }
 
+// PreferredAddress is a server's preferred address.
+// Clients migrate to this address once the handshake is confirmed.
+type PreferredAddress struct {
+	// IPv4 and IPv6 are the addresses advertised to the client.
+	// At least one of them needs to be set.
+	// The client only migrates if it's using the same address family for the handshake.
+	IPv4 netip.AddrPort
+	IPv6 netip.AddrPort
+	// Transport receives packets sent to the preferred address.
+	// Once the client migrated, this Transport is also used to send packets to the client.
+	// It must not be the Transport that the server is listening on,
+	// and it must use the same connection ID length.
+	Transport *Transport
+}
+
 // ClientHelloInfo contains information about an incoming connection attempt.
 //
 // Deprecated: Use ClientInfo instead.
@@ -220,6 +287,8 @@ type ConnectionState struct {
 	SupportsDatagrams bool
 	// SupportsStreamResetPartialDelivery indicates whether the peer advertised support for QUIC Stream Resets with Partial Delivery.
 	SupportsStreamResetPartialDelivery bool
+	// Multipath indicates whether the multipath extension was negotiated, see Config.EnableMultipath.
+	Multipath bool
 	// Used0RTT says if 0-RTT resumption was used.
 	Used0RTT bool
 	// Version is the QUIC version of the QUIC connection.
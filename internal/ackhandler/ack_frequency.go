@@ -0,0 +1,67 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const (
+	// When using ACK_FREQUENCY, we ask the peer to send (at least) this many ACKs per congestion window.
+	acksPerCongestionWindow = 4
+	// The maximum ack-eliciting threshold requested from the peer.
+	// Very large values make it hard to detect losses quickly.
+	maxAckElicitingThreshold = 10
+)
+
+// The ackFrequencyRequester decides if the peer should be asked to change the frequency at which it sends ACKs.
+// During slow start, the peer acknowledges every other packet, as recommended by RFC 9000.
+// After that, the number of ACKs is reduced to a few ACKs per congestion window.
+type ackFrequencyRequester struct {
+	queueFrame func(wire.Frame)
+
+	nextSequenceNumber    uint64
+	ackElicitingThreshold uint64
+}
+
+func newAckFrequencyRequester(queueFrame func(wire.Frame)) *ackFrequencyRequester {
+	return &ackFrequencyRequester{
+		queueFrame:            queueFrame,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+	}
+}
+
+// Update queues an ACK_FREQUENCY frame if the ack-eliciting threshold should be changed.
+// maxAckDelay is the max_ack_delay advertised by the peer.
+func (r *ackFrequencyRequester) Update(cwnd, maxDatagramSize protocol.ByteCount, inSlowStart bool, maxAckDelay time.Duration) {
+	threshold := uint64(defaultAckElicitingThreshold)
+	if !inSlowStart {
+		// the peer sends an ACK after receiving more than threshold ack-eliciting packets
+		if packetsPerAck := uint64(cwnd / (acksPerCongestionWindow * maxDatagramSize)); packetsPerAck > 1 {
+			threshold = min(max(packetsPerAck-1, defaultAckElicitingThreshold), maxAckElicitingThreshold)
+		}
+	}
+	if threshold == r.ackElicitingThreshold {
+		return
+	}
+	r.ackElicitingThreshold = threshold
+	reorderingThreshold := protocol.PacketNumber(defaultReorderingThreshold)
+	if threshold != defaultAckElicitingThreshold {
+		// Loss detection declares a packet lost once packetThreshold packets sent after it were acknowledged.
+		// There's no need for the peer to report reordering before that.
+		reorderingThreshold = packetThreshold
+	}
+	r.queueFrame(&wire.AckFrequencyFrame{
+		SequenceNumber:        r.nextSequenceNumber,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    maxAckDelay,
+		ReorderingThreshold:   reorderingThreshold,
+	})
+	r.nextSequenceNumber++
+}
+
+// QueueImmediateAck asks the peer to immediately acknowledge the packet.
+func (r *ackFrequencyRequester) QueueImmediateAck() {
+	r.queueFrame(&wire.ImmediateAckFrame{})
+}
@@ -0,0 +1,63 @@
+package ackhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAckFrequencyRequester(t *testing.T) {
+	const maxDatagramSize = 1000
+	var frames []wire.Frame
+	r := newAckFrequencyRequester(func(f wire.Frame) { frames = append(frames, f) })
+
+	// during slow start, the default ACK frequency is used
+	r.Update(100*maxDatagramSize, maxDatagramSize, true, 25*time.Millisecond)
+	require.Empty(t, frames)
+
+	// after slow start, the peer is asked to send fewer ACKs
+	r.Update(20*maxDatagramSize, maxDatagramSize, false, 25*time.Millisecond)
+	require.Equal(t, []wire.Frame{
+		&wire.AckFrequencyFrame{
+			SequenceNumber:        0,
+			AckElicitingThreshold: 4,
+			RequestMaxAckDelay:    25 * time.Millisecond,
+			ReorderingThreshold:   packetThreshold,
+		},
+	}, frames)
+	frames = frames[:0]
+
+	// no new frame is sent if the threshold doesn't change
+	r.Update(23*maxDatagramSize, maxDatagramSize, false, 25*time.Millisecond)
+	require.Empty(t, frames)
+
+	// the threshold is capped
+	r.Update(1000*maxDatagramSize, maxDatagramSize, false, 25*time.Millisecond)
+	require.Equal(t, []wire.Frame{
+		&wire.AckFrequencyFrame{
+			SequenceNumber:        1,
+			AckElicitingThreshold: maxAckElicitingThreshold,
+			RequestMaxAckDelay:    25 * time.Millisecond,
+			ReorderingThreshold:   packetThreshold,
+		},
+	}, frames)
+	frames = frames[:0]
+
+	// small congestion windows use the default ACK frequency
+	r.Update(5*maxDatagramSize, maxDatagramSize, false, 25*time.Millisecond)
+	require.Equal(t, []wire.Frame{
+		&wire.AckFrequencyFrame{
+			SequenceNumber:        2,
+			AckElicitingThreshold: defaultAckElicitingThreshold,
+			RequestMaxAckDelay:    25 * time.Millisecond,
+			ReorderingThreshold:   defaultReorderingThreshold,
+		},
+	}, frames)
+	frames = frames[:0]
+
+	r.QueueImmediateAck()
+	require.Equal(t, []wire.Frame{&wire.ImmediateAckFrame{}}, frames)
+}
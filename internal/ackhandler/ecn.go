@@ -62,24 +62,54 @@ type ecnTracker struct {
 	numSentECT0, numSentECT1                  int64
 	numAckedECT0, numAckedECT1, numAckedECNCE int64
 
+	stats   *PathStats // might be nil
 	qlogger qlogwriter.Recorder
 	logger  utils.Logger
 }
 
 var _ ecnHandler = &ecnTracker{}
 
-func newECNTracker(logger utils.Logger, qlogger qlogwriter.Recorder) *ecnTracker {
-	return &ecnTracker{
+func newECNTracker(logger utils.Logger, qlogger qlogwriter.Recorder, stats *PathStats) *ecnTracker {
+	e := &ecnTracker{
 		firstTestingPacket: protocol.InvalidPacketNumber,
 		lastTestingPacket:  protocol.InvalidPacketNumber,
 		firstCapablePacket: protocol.InvalidPacketNumber,
 		state:              ecnStateInitial,
+		stats:              stats,
 		logger:             logger,
 		qlogger:            qlogger,
 	}
+	e.updateStats()
+	return e
+}
+
+// updateStats publishes the ECN state and counters.
+func (e *ecnTracker) updateStats() {
+	if e.stats == nil {
+		return
+	}
+	var state ECNState
+	switch e.state {
+	case ecnStateInitial, ecnStateTesting:
+		state = ECNStateTesting
+	case ecnStateUnknown:
+		state = ECNStateUnknown
+	case ecnStateCapable:
+		state = ECNStateCapable
+	case ecnStateFailed:
+		state = ECNStateFailed
+	}
+	e.stats.ECNState.Store(uint32(state))
+	e.stats.ECT0Sent.Store(uint64(e.numSentECT0))
+	e.stats.ECT1Sent.Store(uint64(e.numSentECT1))
+	e.stats.ECT0Acked.Store(uint64(e.numAckedECT0))
+	e.stats.ECT1Acked.Store(uint64(e.numAckedECT1))
+	e.stats.ECNCEAcked.Store(uint64(e.numAckedECNCE))
 }
 
 func (e *ecnTracker) SentPacket(pn protocol.PacketNumber, ecn protocol.ECN) {
+	defer e.updateStats()
+
 	//nolint:exhaustive // These are the only ones we need to take care of.
 	switch ecn {
 	case protocol.ECNNon:
@@ -139,6 +169,8 @@ func (e *ecnTracker) Mode() protocol.ECN {
 }
 
 func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
+	defer e.updateStats()
+
 	if e.state != ecnStateTesting && e.state != ecnStateUnknown {
 		return
 	}
@@ -169,6 +201,8 @@ func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
 // It must only be called for ACK frames that increase the largest acknowledged packet number,
 // see section 13.4.2.1 of RFC 9000.
 func (e *ecnTracker) HandleNewlyAcked(packets []packetWithPacketNumber, ect0, ect1, ecnce int64) (congested bool) {
+	defer e.updateStats()
+
 	if e.state == ecnStateFailed {
 		return false
 	}
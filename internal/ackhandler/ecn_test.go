@@ -50,7 +50,7 @@ func sendECNTestingPackets(t *testing.T, ecnTracker *ecnTracker, recorder *event
 // ECN validation fails if *all* ECN testing packets are lost.
 func TestECNTestingPacketsLoss(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 
@@ -87,7 +87,7 @@ func TestECNTestingPacketsLoss(t *testing.T) {
 // This applies even if that happens before all testing packets have been sent out.
 func TestECNValidationInTestingState(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	for i := range 5 {
 		require.Equal(t, protocol.ECT0, ecnTracker.Mode())
@@ -115,7 +115,7 @@ func TestECNValidationInTestingState(t *testing.T) {
 // once an acknowledgment for any testing packet is received.
 func TestECNValidationInUnknownState(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 
@@ -160,7 +160,7 @@ func testECNValidationFailure(
 	expectedTrigger string,
 ) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -177,7 +177,7 @@ func testECNValidationFailure(
 
 func TestECNValidationNotEnoughECNCounts(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -204,7 +204,7 @@ func TestECNValidationNotEnoughECNCounts(t *testing.T) {
 
 func TestECNNonsensicalECNCountDecrease(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -234,7 +234,7 @@ func TestECNNonsensicalECNCountDecrease(t *testing.T) {
 
 func TestECNACKReordering(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -255,7 +255,7 @@ func TestECNACKReordering(t *testing.T) {
 // Mangling is detected if all testing packets are marked CE.
 func TestECNManglingAllPacketsMarkedCE(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -288,7 +288,7 @@ func TestECNManglingSomePacketsLostSomeMarkedCE(t *testing.T) {
 
 func testECNManglingSomePacketsLostSomeMarkedCE(t *testing.T, packetLossFirst bool) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -329,7 +329,7 @@ func testECNManglingSomePacketsLostSomeMarkedCE(t *testing.T, packetLossFirst bo
 
 func TestECNCongestionDetection(t *testing.T) {
 	var eventRecorder events.Recorder
-	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder)
+	ecnTracker := newECNTracker(utils.DefaultLogger, &eventRecorder, nil)
 
 	sendECNTestingPackets(t, ecnTracker, &eventRecorder)
 	for i := 10; i < 20; i++ {
@@ -351,3 +351,26 @@ func TestECNCongestionDetection(t *testing.T) {
 	require.True(t, ecnTracker.HandleNewlyAcked(getAckedPackets(7, 8, 9, 14), 7, 0, 2))
 	require.Empty(t, eventRecorder.Events())
 }
+
+func TestECNStats(t *testing.T) {
+	var stats PathStats
+	ecnTracker := newECNTracker(utils.DefaultLogger, nil, &stats)
+	require.Equal(t, uint32(ECNStateTesting), stats.ECNState.Load())
+
+	for i := range 10 {
+		require.Equal(t, protocol.ECT0, ecnTracker.Mode())
+		ecnTracker.SentPacket(protocol.PacketNumber(i), protocol.ECT0)
+	}
+	require.Equal(t, uint32(ECNStateUnknown), stats.ECNState.Load())
+	require.EqualValues(t, 10, stats.ECT0Sent.Load())
+	require.Zero(t, stats.ECT1Sent.Load())
+
+	require.True(t, ecnTracker.HandleNewlyAcked(getAckedPackets(0, 1, 2), 2, 0, 1))
+	require.Equal(t, uint32(ECNStateCapable), stats.ECNState.Load())
+	require.EqualValues(t, 2, stats.ECT0Acked.Load())
+	require.EqualValues(t, 1, stats.ECNCEAcked.Load())
+
+	// ECN counts decreasing causes validation to fail
+	require.False(t, ecnTracker.HandleNewlyAcked(getAckedPackets(3), 1, 0, 1))
+	require.Equal(t, uint32(ECNStateFailed), stats.ECNState.Load())
+}
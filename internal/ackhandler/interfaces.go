@@ -8,8 +8,11 @@ import (
 
 // SentPacketHandler handles ACKs received for outgoing packets
 type SentPacketHandler interface {
-	// SentPacket may modify the packet
-	SentPacket(t monotime.Time, pn, largestAcked protocol.PacketNumber, streamFrames []StreamFrame, frames []Frame, encLevel protocol.EncryptionLevel, ecn protocol.ECN, size protocol.ByteCount, isPathMTUProbePacket, isPathProbePacket bool)
+	// SentPacket may modify the packet.
+	// t is the time the packet was handed to the kernel, and is used for loss detection and RTT measurements.
+	// When using kernel pacing, txTime is the (later) time the kernel sends the packet at, and is used for pacing.
+	// Otherwise, txTime is zero.
+	SentPacket(t, txTime monotime.Time, pn, largestAcked protocol.PacketNumber, streamFrames []StreamFrame, frames []Frame, encLevel protocol.EncryptionLevel, ecn protocol.ECN, size protocol.ByteCount, isPathMTUProbePacket, isPathProbePacket bool)
 	// ReceivedAck processes an ACK frame.
 	// It does not store a copy of the frame.
 	ReceivedAck(f *wire.AckFrame, encLevel protocol.EncryptionLevel, rcvTime monotime.Time) (bool /* 1-RTT packet acked */, error)
@@ -36,6 +39,15 @@ type SentPacketHandler interface {
 	OnLossDetectionTimeout(now monotime.Time) error
 
 	MigratedPath(now monotime.Time, initialMaxPacketSize protocol.ByteCount)
+
+	// EnableAckFrequency is called when the peer supports the ACK Frequency extension,
+	// i.e. when it sent the min_ack_delay transport parameter.
+	// ACK_FREQUENCY and IMMEDIATE_ACK frames are then queued using queueControlFrame.
+	EnableAckFrequency(queueControlFrame func(wire.Frame))
+
+	// Stats returns the statistics of the path.
+	// They can be read from any goroutine.
+	Stats() *PathStats
 }
 
 // ReceivedPacketHandler handles ACKs needed to send for incoming packets
@@ -43,6 +55,10 @@ type ReceivedPacketHandler interface {
 	IgnorePacketsBelow(protocol.PacketNumber)
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime monotime.Time, ackEliciting bool) error
+	// ReceivedAckFrequencyFrame and ReceivedImmediateAckFrame must be called before
+	// ReceivedPacket is called for the packet that contained the frame.
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame)
+	ReceivedImmediateAckFrame()
 	DropPackets(protocol.EncryptionLevel)
 
 	GetAlarmTimeout() monotime.Time
@@ -0,0 +1,39 @@
+package ackhandler
+
+import "sync/atomic"
+
+// ECNState is the state of the ECN validation of a path, see section 13.4.2 of RFC 9000.
+type ECNState uint8
+
+const (
+	// ECNStateDisabled means that ECN is not used on this path.
+	ECNStateDisabled ECNState = iota
+	// ECNStateTesting means that the path is being tested for ECN support.
+	ECNStateTesting
+	// ECNStateUnknown means that all testing packets were sent,
+	// and we're waiting for the peer to acknowledge them.
+	ECNStateUnknown
+	// ECNStateCapable means that the path supports ECN.
+	ECNStateCapable
+	// ECNStateFailed means that the ECN validation failed.
+	ECNStateFailed
+)
+
+// PathStats are the statistics of a path maintained by the sent packet handler.
+// They are updated from the connection's run loop, and can be read concurrently.
+type PathStats struct {
+	CongestionWindow atomic.Uint64
+	BytesInFlight    atomic.Uint64
+	InSlowStart      atomic.Bool
+	InRecovery       atomic.Bool
+	PacingRate       atomic.Uint64 // in bytes/s, 0 if the congestion controller doesn't pace packets
+	PTOCount         atomic.Uint32
+	MaxDatagramSize  atomic.Uint64
+
+	ECNState   atomic.Uint32 // an ECNState
+	ECT0Sent   atomic.Uint64
+	ECT1Sent   atomic.Uint64
+	ECT0Acked  atomic.Uint64
+	ECT1Acked  atomic.Uint64
+	ECNCEAcked atomic.Uint64
+}
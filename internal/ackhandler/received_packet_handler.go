@@ -64,6 +64,14 @@ func (h *receivedPacketHandler) IgnorePacketsBelow(pn protocol.PacketNumber) {
 	h.appDataPackets.IgnoreBelow(pn)
 }
 
+func (h *receivedPacketHandler) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	h.appDataPackets.ReceivedAckFrequencyFrame(f)
+}
+
+func (h *receivedPacketHandler) ReceivedImmediateAckFrame() {
+	h.appDataPackets.ReceivedImmediateAckFrame()
+}
+
 func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	//nolint:exhaustive // 1-RTT packet number space is never dropped.
 	switch encLevel {
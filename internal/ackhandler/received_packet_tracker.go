@@ -10,8 +10,6 @@ import (
 	"github.com/quic-go/quic-go/internal/wire"
 )
 
-const reorderingThreshold = 1
-
 // The receivedPacketTracker tracks packets for the Initial and Handshake packet number space.
 // Every received packet is acknowledged immediately.
 type receivedPacketTracker struct {
@@ -75,11 +73,17 @@ func (h *receivedPacketTracker) IsPotentiallyDuplicate(pn protocol.PacketNumber)
 	return h.packetHistory.IsPotentiallyDuplicate(pn)
 }
 
-// number of ack-eliciting packets received before sending an ACK
-const packetsBeforeAck = 2
+const (
+	// The number of ack-eliciting packets that can be received without sending an ACK.
+	// By default, an ACK is sent for every second ack-eliciting packet.
+	defaultAckElicitingThreshold = 1
+	// By default, out-of-order packets are acknowledged immediately.
+	defaultReorderingThreshold = 1
+)
 
 // The appDataReceivedPacketTracker tracks packets received in the Application Data packet number space.
-// It waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// By default, it waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// The peer can change this behavior by sending an ACK_FREQUENCY frame.
 type appDataReceivedPacketTracker struct {
 	receivedPacketTracker
 
@@ -88,10 +92,15 @@ type appDataReceivedPacketTracker struct {
 	largestObserved protocol.PacketNumber
 	ignoreBelow     protocol.PacketNumber
 
-	maxAckDelay time.Duration
-	ackQueued   bool // true if we need send a new ACK
+	maxAckDelay           time.Duration
+	ackElicitingThreshold uint64
+	reorderingThreshold   protocol.PacketNumber
+	// the sequence number of the last ACK_FREQUENCY frame, -1 if no frame was received yet
+	ackFrequencySequenceNumber int64
+
+	ackQueued bool // true if we need send a new ACK
 
-	ackElicitingPacketsReceivedSinceLastAck int
+	ackElicitingPacketsReceivedSinceLastAck uint64
 	ackAlarm                                monotime.Time
 
 	logger utils.Logger
@@ -99,9 +108,12 @@ type appDataReceivedPacketTracker struct {
 
 func newAppDataReceivedPacketTracker(logger utils.Logger) *appDataReceivedPacketTracker {
 	h := &appDataReceivedPacketTracker{
-		receivedPacketTracker: *newReceivedPacketTracker(),
-		maxAckDelay:           protocol.MaxAckDelay,
-		logger:                logger,
+		receivedPacketTracker:      *newReceivedPacketTracker(),
+		maxAckDelay:                protocol.MaxAckDelay,
+		ackElicitingThreshold:      defaultAckElicitingThreshold,
+		reorderingThreshold:        defaultReorderingThreshold,
+		ackFrequencySequenceNumber: -1,
+		logger:                     logger,
 	}
 	return h
 }
@@ -133,6 +145,28 @@ func (h *appDataReceivedPacketTracker) ReceivedPacket(pn protocol.PacketNumber,
 	return nil
 }
 
+// ReceivedAckFrequencyFrame applies the parameters requested by the peer in an ACK_FREQUENCY frame.
+// Frames that were reordered, i.e. that have a lower sequence number than a frame received before, are ignored.
+func (h *appDataReceivedPacketTracker) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	if int64(f.SequenceNumber) <= h.ackFrequencySequenceNumber {
+		return
+	}
+	h.ackFrequencySequenceNumber = int64(f.SequenceNumber)
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	h.reorderingThreshold = f.ReorderingThreshold
+	// The requested max_ack_delay includes the timer granularity.
+	h.maxAckDelay = max(f.RequestMaxAckDelay-protocol.TimerGranularity, 0)
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdated ACK frequency: ack-eliciting threshold %d, reordering threshold %d, max_ack_delay %s", h.ackElicitingThreshold, h.reorderingThreshold, h.maxAckDelay)
+	}
+}
+
+// ReceivedImmediateAckFrame queues an ACK, which will be sent out without any delay.
+func (h *appDataReceivedPacketTracker) ReceivedImmediateAckFrame() {
+	h.ackQueued = true
+	h.ackAlarm = 0
+}
+
 // IgnoreBelow sets a lower limit for acknowledging packets.
 // Packets with packet numbers smaller than p will not be acked.
 func (h *appDataReceivedPacketTracker) IgnoreBelow(pn protocol.PacketNumber) {
@@ -155,13 +189,13 @@ func (h *appDataReceivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 }
 
 func (h *appDataReceivedPacketTracker) hasNewMissingPackets() bool {
-	if h.lastAck == nil {
+	if h.lastAck == nil || h.reorderingThreshold == 0 {
 		return false
 	}
-	if h.largestObserved < reorderingThreshold {
+	if h.largestObserved < h.reorderingThreshold {
 		return false
 	}
-	highestMissing := h.packetHistory.HighestMissingUpTo(h.largestObserved - reorderingThreshold)
+	highestMissing := h.packetHistory.HighestMissingUpTo(h.largestObserved - h.reorderingThreshold)
 	if highestMissing == protocol.InvalidPacketNumber {
 		return false
 	}
@@ -169,24 +203,25 @@ func (h *appDataReceivedPacketTracker) hasNewMissingPackets() bool {
 		// the packet was already reported missing in the last ACK
 		return false
 	}
-	return highestMissing > h.lastAck.LargestAcked()-reorderingThreshold
+	return highestMissing > h.lastAck.LargestAcked()-h.reorderingThreshold
 }
 
 func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber, ecn protocol.ECN, wasMissing bool) bool {
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ACK, send an ACK immediately.
-	if wasMissing {
+	// The peer can disable this by setting the reordering threshold to 0.
+	if wasMissing && h.reorderingThreshold > 0 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		return true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK once more than ackElicitingThreshold ack-eliciting packets were received
+	if h.ackElicitingPacketsReceivedSinceLastAck > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		return true
 	}
@@ -157,6 +157,80 @@ func TestAppDataReceivedPacketTrackerDelayTime(t *testing.T) {
 	require.Zero(t, ack.DelayTime)
 }
 
+func TestAppDataReceivedPacketTrackerAckFrequency(t *testing.T) {
+	tr := newAppDataReceivedPacketTracker(utils.DefaultLogger)
+
+	now := monotime.Now()
+	tr.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+		SequenceNumber:        1,
+		AckElicitingThreshold: 4,
+		RequestMaxAckDelay:    50 * time.Millisecond,
+		ReorderingThreshold:   3,
+	})
+	// an ACK is sent after receiving 5 ack-eliciting packets
+	for p := protocol.PacketNumber(1); p <= 4; p++ {
+		require.NoError(t, tr.ReceivedPacket(p, protocol.ECNNon, now, true))
+		require.Nil(t, tr.GetAckFrame(now, true))
+	}
+	require.Equal(t, now.Add(50*time.Millisecond-protocol.TimerGranularity), tr.GetAlarmTimeout())
+	require.NoError(t, tr.ReceivedPacket(5, protocol.ECNNon, now, true))
+	require.NotNil(t, tr.GetAckFrame(now, true))
+
+	// small gaps don't trigger an immediate ACK...
+	require.NoError(t, tr.ReceivedPacket(7, protocol.ECNNon, now, true))
+	require.NoError(t, tr.ReceivedPacket(8, protocol.ECNNon, now, true))
+	require.Nil(t, tr.GetAckFrame(now, true))
+	// ... but larger gaps do
+	require.NoError(t, tr.ReceivedPacket(9, protocol.ECNNon, now, true))
+	ack := tr.GetAckFrame(now, true)
+	require.NotNil(t, ack)
+	require.Equal(t, []wire.AckRange{{Smallest: 7, Largest: 9}, {Smallest: 1, Largest: 5}}, ack.AckRanges)
+
+	// reordered ACK_FREQUENCY frames are ignored
+	tr.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+		SequenceNumber:        0,
+		AckElicitingThreshold: 1,
+		RequestMaxAckDelay:    10 * time.Millisecond,
+		ReorderingThreshold:   1,
+	})
+	require.NoError(t, tr.ReceivedPacket(10, protocol.ECNNon, now, true))
+	require.NoError(t, tr.ReceivedPacket(11, protocol.ECNNon, now, true))
+	require.Nil(t, tr.GetAckFrame(now, true))
+}
+
+func TestAppDataReceivedPacketTrackerAckFrequencyIgnoreReordering(t *testing.T) {
+	tr := newAppDataReceivedPacketTracker(utils.DefaultLogger)
+
+	now := monotime.Now()
+	tr.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+		AckElicitingThreshold: 10,
+		RequestMaxAckDelay:    protocol.MaxAckDelayInclGranularity,
+		ReorderingThreshold:   0,
+	})
+	require.NoError(t, tr.ReceivedPacket(0, protocol.ECNNon, now, true))
+	require.NotNil(t, tr.GetAckFrame(now, false))
+	for _, p := range []protocol.PacketNumber{10, 5, 3, 20} {
+		require.NoError(t, tr.ReceivedPacket(p, protocol.ECNNon, now, true))
+		require.Nil(t, tr.GetAckFrame(now, true))
+	}
+	require.Equal(t, now.Add(protocol.MaxAckDelay), tr.GetAlarmTimeout())
+}
+
+func TestAppDataReceivedPacketTrackerImmediateAck(t *testing.T) {
+	tr := newAppDataReceivedPacketTracker(utils.DefaultLogger)
+
+	now := monotime.Now()
+	require.NoError(t, tr.ReceivedPacket(1, protocol.ECNNon, now, true))
+	require.Nil(t, tr.GetAckFrame(now, true))
+	// the IMMEDIATE_ACK frame is processed before the packet is registered
+	tr.ReceivedImmediateAckFrame()
+	require.NoError(t, tr.ReceivedPacket(2, protocol.ECNNon, now, true))
+	require.Zero(t, tr.GetAlarmTimeout())
+	ack := tr.GetAckFrame(now, true)
+	require.NotNil(t, ack)
+	require.Equal(t, protocol.PacketNumber(2), ack.LargestAcked())
+}
+
 func TestAppDataReceivedPacketTrackerIgnoreBelow(t *testing.T) {
 	tr := newAppDataReceivedPacketTracker(utils.DefaultLogger)
 
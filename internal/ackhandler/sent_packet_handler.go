@@ -87,9 +87,14 @@ type sentPacketHandler struct {
 
 	bytesInFlight protocol.ByteCount
 
-	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
-	connStats  *utils.ConnectionStats
+	congestion      congestion.SendAlgorithmWithDebugInfos
+	maxDatagramSize protocol.ByteCount
+	// Creates a new congestion controller.
+	// Called when the connection is established, and when it migrates to a new path.
+	newCongestion func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos
+	rttStats      *utils.RTTStats
+	connStats     *utils.ConnectionStats
+	stats         PathStats
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -104,6 +109,9 @@ type sentPacketHandler struct {
 	enableECN  bool
 	ecnTracker ecnHandler
 
+	// only set if the peer supports the ACK Frequency extension
+	ackFrequency *ackFrequencyRequester
+
 	perspective protocol.Perspective
 
 	qlogger     qlogwriter.Recorder
@@ -115,11 +123,13 @@ var _ SentPacketHandler = &sentPacketHandler{}
 
 // clientAddressValidated indicates whether the address was validated beforehand by an address validation token.
 // If the address was validated, the amplification limit doesn't apply. It has no effect for a client.
+// newCongestion is used to create the congestion controller. If nil, NewReno is used.
 func NewSentPacketHandler(
 	initialPN protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
 	connStats *utils.ConnectionStats,
+	newCongestion func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos,
 	clientAddressValidated bool,
 	enableECN bool,
 	ignorePacketsBelow func(protocol.PacketNumber),
@@ -127,14 +137,18 @@ func NewSentPacketHandler(
 	qlogger qlogwriter.Recorder,
 	logger utils.Logger,
 ) SentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		connStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		qlogger,
-	)
+	if newCongestion == nil {
+		newCongestion = func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos {
+			return congestion.NewCubicSender(
+				congestion.DefaultClock{},
+				rttStats,
+				connStats,
+				initialMaxDatagramSize,
+				true, // use Reno
+				qlogger,
+			)
+		}
+	}
 
 	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
@@ -145,7 +159,9 @@ func NewSentPacketHandler(
 		lostPackets:                    *newLostPacketTracker(64),
 		rttStats:                       rttStats,
 		connStats:                      connStats,
-		congestion:                     congestion,
+		congestion:                     newCongestion(initialMaxDatagramSize),
+		maxDatagramSize:                initialMaxDatagramSize,
+		newCongestion:                  newCongestion,
 		ignorePacketsBelow:             ignorePacketsBelow,
 		perspective:                    pers,
 		qlogger:                        qlogger,
@@ -153,17 +169,42 @@ func NewSentPacketHandler(
 	}
 	if enableECN {
 		h.enableECN = true
-		h.ecnTracker = newECNTracker(logger, qlogger)
+		h.ecnTracker = newECNTracker(logger, qlogger, &h.stats)
 	}
+	h.updateStats()
 	return h
 }
 
+// pacingRateProvider is implemented by congestion controllers that pace packets.
+type pacingRateProvider interface {
+	PacingRate() uint64 // in bytes/s
+}
+
+// updateStats publishes the statistics of the path.
+// It is called after events that potentially change the state of the congestion controller.
+func (h *sentPacketHandler) updateStats() {
+	h.stats.CongestionWindow.Store(uint64(h.congestion.GetCongestionWindow()))
+	h.stats.InSlowStart.Store(h.congestion.InSlowStart())
+	h.stats.InRecovery.Store(h.congestion.InRecovery())
+	if p, ok := h.congestion.(pacingRateProvider); ok {
+		h.stats.PacingRate.Store(p.PacingRate())
+	} else {
+		h.stats.PacingRate.Store(0)
+	}
+	h.stats.BytesInFlight.Store(uint64(h.bytesInFlight))
+	h.stats.PTOCount.Store(h.ptoCount)
+	h.stats.MaxDatagramSize.Store(uint64(h.maxDatagramSize))
+}
+
+func (h *sentPacketHandler) Stats() *PathStats { return &h.stats }
+
 func (h *sentPacketHandler) removeFromBytesInFlight(p *packet) {
 	if p.includedInBytesInFlight {
 		if p.Length > h.bytesInFlight {
 			panic("negative bytes_in_flight")
 		}
 		h.bytesInFlight -= p.Length
+		h.stats.BytesInFlight.Store(uint64(h.bytesInFlight))
 		p.includedInBytesInFlight = false
 	}
 }
@@ -214,6 +255,7 @@ func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel, now m
 		h.qlogger.RecordEvent(qlog.PTOCountUpdated{PTOCount: 0})
 	}
 	h.ptoCount = 0
+	h.stats.PTOCount.Store(0)
 	h.numProbesToSend = 0
 	h.ptoMode = SendNone
 	h.setLossDetectionTimer(now)
@@ -248,7 +290,7 @@ func (h *sentPacketHandler) packetsInFlight() int {
 }
 
 func (h *sentPacketHandler) SentPacket(
-	t monotime.Time,
+	t, txTime monotime.Time,
 	pn, largestAcked protocol.PacketNumber,
 	streamFrames []StreamFrame,
 	frames []Frame,
@@ -286,11 +328,17 @@ func (h *sentPacketHandler) SentPacket(
 	if isAckEliciting {
 		pnSpace.lastAckElicitingPacketTime = t
 		h.bytesInFlight += size
+		h.stats.BytesInFlight.Store(uint64(h.bytesInFlight))
 		if h.numProbesToSend > 0 {
 			h.numProbesToSend--
 		}
 	}
-	h.congestion.OnPacketSent(t, h.bytesInFlight, pn, size, isAckEliciting)
+	// The pacer needs to know when the packet actually leaves, otherwise it would hand out the same send time again.
+	pacingTime := t
+	if !txTime.IsZero() {
+		pacingTime = txTime
+	}
+	h.congestion.OnPacketSent(pacingTime, h.bytesInFlight, pn, size, isAckEliciting)
 
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 		h.ecnTracker.SentPacket(pn, ecn)
@@ -477,10 +525,28 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 		h.qlogMetricsUpdated()
 	}
 
+	if encLevel == protocol.Encryption1RTT {
+		h.maybeUpdateAckFrequency()
+	}
+	h.updateStats()
+
 	h.setLossDetectionTimer(rcvTime)
 	return acked1RTTPacket, nil
 }
 
+func (h *sentPacketHandler) maybeUpdateAckFrequency() {
+	// ACK_FREQUENCY frames are only sent in 1-RTT packets.
+	if h.ackFrequency == nil || !h.handshakeConfirmed {
+		return
+	}
+	h.ackFrequency.Update(
+		h.congestion.GetCongestionWindow(),
+		h.maxDatagramSize,
+		h.congestion.InSlowStart(),
+		h.rttStats.MaxAckDelay(),
+	)
+}
+
 func (h *sentPacketHandler) detectSpuriousLosses(ack *wire.AckFrame, ackTime monotime.Time) {
 	var maxPacketReordering protocol.PacketNumber
 	var maxTimeReordering time.Duration
@@ -858,6 +924,7 @@ func (h *sentPacketHandler) detectLostPackets(now monotime.Time, encLevel protoc
 }
 
 func (h *sentPacketHandler) OnLossDetectionTimeout(now monotime.Time) error {
+	defer h.updateStats()
 	defer h.setLossDetectionTimer(now)
 
 	if h.handshakeConfirmed {
@@ -930,6 +997,11 @@ func (h *sentPacketHandler) OnLossDetectionTimeout(now monotime.Time) error {
 		// skip a packet number in order to elicit an immediate ACK
 		pn := h.PopPacketNumber(protocol.Encryption1RTT)
 		h.getPacketNumberSpace(protocol.Encryption1RTT).history.SkippedPacket(pn)
+		// If the peer uses a reordering threshold larger than 1, skipping a packet number
+		// doesn't elicit an immediate ACK. Explicitly ask for one.
+		if h.ackFrequency != nil && h.handshakeConfirmed {
+			h.ackFrequency.QueueImmediateAck()
+		}
 		h.ptoMode = SendPTOAppData
 	default:
 		return fmt.Errorf("PTO timer in unexpected encryption level: %s", encLevel)
@@ -1021,7 +1093,9 @@ func (h *sentPacketHandler) TimeUntilSend() monotime.Time {
 }
 
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
+	h.maxDatagramSize = s
 	h.congestion.SetMaxDatagramSize(s)
+	h.updateStats()
 }
 
 func (h *sentPacketHandler) isAmplificationLimited() bool {
@@ -1065,6 +1139,7 @@ func (h *sentPacketHandler) queueFramesForRetransmission(p *packet) {
 
 func (h *sentPacketHandler) ResetForRetry(now monotime.Time) {
 	h.bytesInFlight = 0
+	h.stats.BytesInFlight.Store(0)
 	var firstPacketSendTime monotime.Time
 	for _, p := range h.initialPackets.history.Packets() {
 		if firstPacketSendTime.IsZero() {
@@ -1107,6 +1182,7 @@ func (h *sentPacketHandler) ResetForRetry(now monotime.Time) {
 		}
 	}
 	h.ptoCount = 0
+	h.stats.PTOCount.Store(0)
 }
 
 func (h *sentPacketHandler) MigratedPath(now monotime.Time, initialMaxDatagramSize protocol.ByteCount) {
@@ -1121,13 +1197,14 @@ func (h *sentPacketHandler) MigratedPath(now monotime.Time, initialMaxDatagramSi
 	for pn := range h.appDataPackets.history.PathProbes() {
 		h.appDataPackets.history.RemovePathProbe(pn)
 	}
-	h.congestion = congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		h.rttStats,
-		h.connStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		h.qlogger,
-	)
+	h.congestion = h.newCongestion(initialMaxDatagramSize)
+	h.maxDatagramSize = initialMaxDatagramSize
+	// The new congestion controller starts in slow start.
+	h.maybeUpdateAckFrequency()
+	h.updateStats()
 	h.setLossDetectionTimer(now)
 }
+
+func (h *sentPacketHandler) EnableAckFrequency(queueControlFrame func(wire.Frame)) {
+	h.ackFrequency = newAckFrequencyRequester(queueControlFrame)
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/congestion"
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -113,6 +114,7 @@ func testSentPacketHandlerSendAndAcknowledge(t *testing.T, encLevel protocol.Enc
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		false,
 		false,
 		nil,
@@ -131,7 +133,7 @@ func testSentPacketHandlerSendAndAcknowledge(t *testing.T, encLevel protocol.Enc
 			e = protocol.Encryption0RTT
 		}
 		pn := sph.PopPacketNumber(e)
-		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, e, protocol.ECNNon, 1200, false, false)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, e, protocol.ECNNon, 1200, false, false)
 		pns = append(pns, pn)
 	}
 
@@ -168,6 +170,7 @@ func TestSentPacketHandlerAcknowledgeSkippedPacket(t *testing.T) {
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		false,
 		false,
 		nil,
@@ -188,7 +191,7 @@ func TestSentPacketHandlerAcknowledgeSkippedPacket(t *testing.T) {
 		if pn >= 1e6 {
 			t.Fatal("expected a skipped packet number")
 		}
-		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, false)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, false)
 		lastPN = pn
 		if skippedPN != protocol.InvalidPacketNumber {
 			break
@@ -224,6 +227,7 @@ func testSentPacketHandlerRTTs(t *testing.T, encLevel protocol.EncryptionLevel,
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		false,
 		false,
 		nil,
@@ -235,7 +239,7 @@ func testSentPacketHandlerRTTs(t *testing.T, encLevel protocol.EncryptionLevel,
 	sendPacket := func(t *testing.T, ti monotime.Time) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(encLevel)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, encLevel, protocol.ECNNon, 1200, false, false)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, encLevel, protocol.ECNNon, 1200, false, false)
 		return pn
 	}
 
@@ -315,6 +319,7 @@ func testSentPacketHandlerAmplificationLimitServer(t *testing.T, addressValidate
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		addressValidated,
 		false,
 		nil,
@@ -339,7 +344,7 @@ func testSentPacketHandlerAmplificationLimitServer(t *testing.T, addressValidate
 	for i := 0; i < 4; i++ {
 		require.Equal(t, SendAny, sph.SendMode(monotime.Now()))
 		pn := sph.PopPacketNumber(protocol.EncryptionInitial)
-		sph.SentPacket(monotime.Now(), pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionInitial, protocol.ECNNon, 999, false, false)
+		sph.SentPacket(monotime.Now(), 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionInitial, protocol.ECNNon, 999, false, false)
 		if i != 3 {
 			require.NotZero(t, sph.GetLossDetectionTimeout())
 		}
@@ -354,7 +359,7 @@ func testSentPacketHandlerAmplificationLimitServer(t *testing.T, addressValidate
 	for i := 0; i < 3; i++ {
 		require.Equal(t, SendAny, sph.SendMode(monotime.Now()))
 		pn := sph.PopPacketNumber(protocol.EncryptionInitial)
-		sph.SentPacket(monotime.Now(), pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(monotime.Now(), 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
 	}
 	require.Equal(t, SendNone, sph.SendMode(monotime.Now()))
 	require.Zero(t, sph.GetLossDetectionTimeout())
@@ -386,6 +391,7 @@ func testSentPacketHandlerAmplificationLimitClient(t *testing.T, dropHandshake b
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -396,7 +402,7 @@ func testSentPacketHandlerAmplificationLimitClient(t *testing.T, dropHandshake b
 
 	require.Equal(t, SendAny, sph.SendMode(monotime.Now()))
 	pn := sph.PopPacketNumber(protocol.EncryptionInitial)
-	sph.SentPacket(monotime.Now(), pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionInitial, protocol.ECNNon, 999, false, false)
+	sph.SentPacket(monotime.Now(), 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionInitial, protocol.ECNNon, 999, false, false)
 	// it's not surprising that the loss detection timer is set, as this packet might be lost...
 	require.NotZero(t, sph.GetLossDetectionTimeout())
 	// ... but it's still set after receiving an ACK for this packet,
@@ -428,7 +434,7 @@ func testSentPacketHandlerAmplificationLimitClient(t *testing.T, dropHandshake b
 
 	// receiving an ACK for a handshake packet shows that the server completed address validation
 	pn = sph.PopPacketNumber(protocol.EncryptionHandshake)
-	sph.SentPacket(monotime.Now(), pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionHandshake, protocol.ECNNon, 999, false, false)
+	sph.SentPacket(monotime.Now(), 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.EncryptionHandshake, protocol.ECNNon, 999, false, false)
 	require.NotZero(t, sph.GetLossDetectionTimeout())
 	_, err = sph.ReceivedAck(&wire.AckFrame{AckRanges: ackRanges(pn)}, protocol.EncryptionHandshake, monotime.Now())
 	require.NoError(t, err)
@@ -442,6 +448,7 @@ func TestSentPacketHandlerDelayBasedLossDetection(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -454,7 +461,7 @@ func TestSentPacketHandlerDelayBasedLossDetection(t *testing.T) {
 	sendPacket := func(t *testing.T, ti monotime.Time, isPathMTUProbePacket bool) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(protocol.EncryptionInitial)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, isPathMTUProbePacket, false)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, isPathMTUProbePacket, false)
 		return pn
 	}
 
@@ -497,6 +504,7 @@ func TestSentPacketHandlerPacketBasedLossDetection(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -510,7 +518,7 @@ func TestSentPacketHandlerPacketBasedLossDetection(t *testing.T) {
 	var pns []protocol.PacketNumber
 	for range 5 {
 		pn := sph.PopPacketNumber(protocol.EncryptionInitial)
-		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
 		pns = append(pns, pn)
 	}
 
@@ -559,6 +567,7 @@ func testSentPacketHandlerPTO(t *testing.T, encLevel protocol.EncryptionLevel, p
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -578,7 +587,7 @@ func testSentPacketHandlerPTO(t *testing.T, encLevel protocol.EncryptionLevel, p
 
 		pn := sph.PopPacketNumber(encLevel)
 		if ackEliciting {
-			sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, encLevel, protocol.ECNNon, 1000, false, false)
+			sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, encLevel, protocol.ECNNon, 1000, false, false)
 			require.Equal(t,
 				[]qlogwriter.Event{
 					qlog.LossTimerUpdated{
@@ -592,7 +601,7 @@ func testSentPacketHandlerPTO(t *testing.T, encLevel protocol.EncryptionLevel, p
 			)
 			eventRecorder.Clear()
 		} else {
-			sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, nil, encLevel, protocol.ECNNon, 1000, true, false)
+			sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, nil, encLevel, protocol.ECNNon, 1000, true, false)
 			require.Empty(t, eventRecorder.Events(qlog.LossTimerUpdated{}))
 		}
 		return pn
@@ -757,6 +766,7 @@ func TestSentPacketHandlerPacketNumberSpacesPTO(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -768,7 +778,7 @@ func TestSentPacketHandlerPacketNumberSpacesPTO(t *testing.T) {
 	sendPacket := func(t *testing.T, ti monotime.Time, encLevel protocol.EncryptionLevel) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(encLevel)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, encLevel, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, encLevel, protocol.ECNNon, 1000, false, false)
 		return pn
 	}
 
@@ -850,6 +860,7 @@ func TestSentPacketHandler0RTT(t *testing.T) {
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -868,7 +879,7 @@ func TestSentPacketHandler0RTT(t *testing.T) {
 		} else {
 			frames = []Frame{{Frame: &wire.PingFrame{}}}
 		}
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, frames, encLevel, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, frames, encLevel, protocol.ECNNon, 1000, false, false)
 		return pn
 	}
 
@@ -902,6 +913,7 @@ func TestSentPacketHandlerCongestion(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -910,6 +922,10 @@ func TestSentPacketHandlerCongestion(t *testing.T) {
 		utils.DefaultLogger,
 	)
 	sph.(*sentPacketHandler).congestion = cong
+	// the congestion controller's state is read to update the path statistics
+	cong.EXPECT().GetCongestionWindow().AnyTimes()
+	cong.EXPECT().InSlowStart().AnyTimes()
+	cong.EXPECT().InRecovery().AnyTimes()
 
 	var packets packetTracker
 	// Send the first 5 packets: not congestion-limited, not pacing-limited.
@@ -927,7 +943,7 @@ func TestSentPacketHandlerCongestion(t *testing.T) {
 		pn := sph.PopPacketNumber(protocol.EncryptionInitial)
 		bytesInFlight += 1000
 		cong.EXPECT().OnPacketSent(now, bytesInFlight, pn, protocol.ByteCount(1000), true)
-		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, i == 1, false)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, i == 1, false)
 		pns = append(pns, pn)
 		sendTimes = append(sendTimes, now)
 		now = now.Add(100 * time.Millisecond)
@@ -980,7 +996,171 @@ func TestSentPacketHandlerCongestion(t *testing.T) {
 	now = timeout.Add(100 * time.Millisecond)
 	pn := sph.PopPacketNumber(protocol.EncryptionInitial)
 	cong.EXPECT().OnPacketSent(now, protocol.ByteCount(2000), pn, protocol.ByteCount(1000), true)
-	sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
+	sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
+}
+
+func TestSentPacketHandlerKernelPacing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+	rttStats := utils.NewRTTStats()
+	sph := NewSentPacketHandler(
+		0,
+		1200,
+		rttStats,
+		&utils.ConnectionStats{},
+		nil,
+		true,
+		false,
+		nil,
+		protocol.PerspectiveClient,
+		nil,
+		utils.DefaultLogger,
+	)
+	sph.(*sentPacketHandler).congestion = cong
+	cong.EXPECT().GetCongestionWindow().AnyTimes()
+	cong.EXPECT().InSlowStart().AnyTimes()
+	cong.EXPECT().InRecovery().AnyTimes()
+
+	var packets packetTracker
+	now := monotime.Now()
+	txTime := now.Add(3 * time.Millisecond)
+	pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+	// the congestion controller uses the time the kernel sends the packet at for pacing
+	cong.EXPECT().OnPacketSent(txTime, protocol.ByteCount(1000), pn, protocol.ByteCount(1000), true)
+	sph.SentPacket(now, txTime, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1000, false, false)
+
+	// the RTT is measured from the time the packet was handed to the kernel
+	ackTime := now.Add(10 * time.Millisecond)
+	gomock.InOrder(
+		cong.EXPECT().MaybeExitSlowStart(),
+		cong.EXPECT().OnPacketAcked(pn, protocol.ByteCount(1000), protocol.ByteCount(1000), ackTime),
+	)
+	_, err := sph.ReceivedAck(&wire.AckFrame{AckRanges: ackRanges(pn)}, protocol.Encryption1RTT, ackTime)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Millisecond, rttStats.LatestRTT())
+}
+
+func TestSentPacketHandlerStats(t *testing.T) {
+	sph := NewSentPacketHandler(
+		0,
+		1200,
+		utils.NewRTTStats(),
+		&utils.ConnectionStats{},
+		nil,
+		true,
+		true,
+		nil,
+		protocol.PerspectiveServer,
+		nil,
+		utils.DefaultLogger,
+	)
+	stats := sph.Stats()
+	require.NotZero(t, stats.CongestionWindow.Load())
+	require.True(t, stats.InSlowStart.Load())
+	require.False(t, stats.InRecovery.Load())
+	require.NotZero(t, stats.PacingRate.Load())
+	require.EqualValues(t, 1200, stats.MaxDatagramSize.Load())
+	require.Equal(t, uint32(ECNStateTesting), stats.ECNState.Load())
+
+	now := monotime.Now()
+	sph.DropPackets(protocol.EncryptionInitial, now)
+	sph.DropPackets(protocol.EncryptionHandshake, now)
+	var pns []protocol.PacketNumber
+	for range 5 {
+		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, sph.ECNMode(true), 1000, false, false)
+		pns = append(pns, pn)
+	}
+	require.EqualValues(t, 5000, stats.BytesInFlight.Load())
+	require.EqualValues(t, 5, stats.ECT0Sent.Load())
+
+	// acknowledge the last 3 packets, the first 2 packets are declared lost
+	now = now.Add(100 * time.Millisecond)
+	_, err := sph.ReceivedAck(&wire.AckFrame{AckRanges: ackRanges(pns[2:]...), ECT0: 3}, protocol.Encryption1RTT, now)
+	require.NoError(t, err)
+	require.Zero(t, stats.BytesInFlight.Load())
+	require.False(t, stats.InSlowStart.Load())
+	require.True(t, stats.InRecovery.Load())
+	require.EqualValues(t, 3, stats.ECT0Acked.Load())
+
+	pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+	sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, sph.ECNMode(true), 1000, false, false)
+	require.NoError(t, sph.OnLossDetectionTimeout(sph.GetLossDetectionTimeout()))
+	require.EqualValues(t, 1, stats.PTOCount.Load())
+
+	sph.SetMaxDatagramSize(1400)
+	require.EqualValues(t, 1400, stats.MaxDatagramSize.Load())
+}
+
+func TestSentPacketHandlerAckFrequency(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+	cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+	cong.EXPECT().InRecovery().AnyTimes()
+	// the congestion controller's state is read to initialize the path statistics
+	cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(10 * 1200))
+	cong.EXPECT().InSlowStart().Return(true)
+	rttStats := utils.NewRTTStats()
+	rttStats.SetMaxAckDelay(20 * time.Millisecond)
+	sph := NewSentPacketHandler(
+		0,
+		1200,
+		rttStats,
+		&utils.ConnectionStats{},
+		func(protocol.ByteCount) congestion.SendAlgorithmWithDebugInfos { return cong },
+		true,
+		false,
+		nil,
+		protocol.PerspectiveServer,
+		nil,
+		utils.DefaultLogger,
+	)
+	var frames []wire.Frame
+	sph.EnableAckFrequency(func(f wire.Frame) { frames = append(frames, f) })
+	sph.DropPackets(protocol.EncryptionInitial, monotime.Now())
+	sph.DropPackets(protocol.EncryptionHandshake, monotime.Now())
+
+	now := monotime.Now()
+	sendAndAcknowledge := func() {
+		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, false)
+		now = now.Add(10 * time.Millisecond)
+		_, err := sph.ReceivedAck(&wire.AckFrame{AckRanges: ackRanges(pn)}, protocol.Encryption1RTT, now)
+		require.NoError(t, err)
+	}
+
+	// During slow start, the default ACK frequency is used.
+	// The congestion controller's state is read twice: once for the ACK frequency, once for the path statistics.
+	cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(100 * 1200)).Times(2)
+	cong.EXPECT().InSlowStart().Return(true).Times(2)
+	sendAndAcknowledge()
+	require.Empty(t, frames)
+
+	// After exiting slow start, the peer is asked to reduce the ACK frequency.
+	cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(100 * 1200)).Times(2)
+	cong.EXPECT().InSlowStart().Return(false).Times(2)
+	sendAndAcknowledge()
+	require.Equal(t,
+		[]wire.Frame{&wire.AckFrequencyFrame{
+			SequenceNumber:        0,
+			AckElicitingThreshold: maxAckElicitingThreshold,
+			RequestMaxAckDelay:    20 * time.Millisecond,
+			ReorderingThreshold:   packetThreshold,
+		}},
+		frames,
+	)
+	frames = frames[:0]
+
+	// When the PTO fires, the peer is asked to acknowledge the probe packet immediately.
+	cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(100 * 1200))
+	cong.EXPECT().InSlowStart().Return(false)
+	pn := sph.PopPacketNumber(protocol.Encryption1RTT)
+	sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{{Frame: &wire.PingFrame{}}}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, false)
+	require.NoError(t, sph.OnLossDetectionTimeout(sph.GetLossDetectionTimeout()))
+	require.Equal(t, SendPTOAppData, sph.SendMode(now))
+	require.Equal(t, []wire.Frame{&wire.ImmediateAckFrame{}}, frames)
 }
 
 func TestSentPacketHandlerRetry(t *testing.T) {
@@ -1003,6 +1183,7 @@ func testSentPacketHandlerRetry(t *testing.T, rtt, expectedRTT time.Duration) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1018,12 +1199,12 @@ func testSentPacketHandlerRetry(t *testing.T, rtt, expectedRTT time.Duration) {
 	for range 2 {
 		pn := sph.PopPacketNumber(protocol.EncryptionInitial)
 		initialPNs = append(initialPNs, pn)
-		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{initialPackets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{initialPackets.NewPingFrame(pn)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
 		now = now.Add(100 * time.Millisecond)
 
 		pn = sph.PopPacketNumber(protocol.Encryption0RTT)
 		appDataPNs = append(appDataPNs, pn)
-		sph.SentPacket(now, pn, protocol.InvalidPacketNumber, nil, []Frame{appDataPackets.NewPingFrame(pn)}, protocol.Encryption0RTT, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(now, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{appDataPackets.NewPingFrame(pn)}, protocol.Encryption0RTT, protocol.ECNNon, 1000, false, false)
 		now = now.Add(100 * time.Millisecond)
 	}
 	require.Equal(t, protocol.ByteCount(4000), sph.(*sentPacketHandler).getBytesInFlight())
@@ -1055,6 +1236,7 @@ func TestSentPacketHandlerRetryAfterPTO(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1067,7 +1249,7 @@ func TestSentPacketHandlerRetryAfterPTO(t *testing.T) {
 	start := monotime.Now()
 	now := start
 	pn1 := sph.PopPacketNumber(protocol.EncryptionInitial)
-	sph.SentPacket(now, pn1, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn1)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
+	sph.SentPacket(now, 0, pn1, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn1)}, protocol.EncryptionInitial, protocol.ECNNon, 1000, false, false)
 
 	timeout := sph.GetLossDetectionTimeout()
 	require.NotZero(t, timeout)
@@ -1078,7 +1260,7 @@ func TestSentPacketHandlerRetryAfterPTO(t *testing.T) {
 	// send a retransmission for the first packet
 	now = timeout.Add(100 * time.Millisecond)
 	pn2 := sph.PopPacketNumber(protocol.EncryptionInitial)
-	sph.SentPacket(now, pn2, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn2)}, protocol.EncryptionInitial, protocol.ECNNon, 900, false, false)
+	sph.SentPacket(now, 0, pn2, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn2)}, protocol.EncryptionInitial, protocol.ECNNon, 900, false, false)
 
 	const rtt = time.Second
 	sph.ResetForRetry(now.Add(rtt))
@@ -1100,6 +1282,7 @@ func TestSentPacketHandlerECN(t *testing.T) {
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1109,18 +1292,21 @@ func TestSentPacketHandlerECN(t *testing.T) {
 	)
 	sph.(*sentPacketHandler).ecnTracker = ecnHandler
 	sph.(*sentPacketHandler).congestion = cong
+	cong.EXPECT().GetCongestionWindow().AnyTimes()
+	cong.EXPECT().InSlowStart().AnyTimes()
+	cong.EXPECT().InRecovery().AnyTimes()
 
 	// ECN marks on non-1-RTT packets are ignored
-	sph.SentPacket(monotime.Now(), sph.PopPacketNumber(protocol.EncryptionInitial), protocol.InvalidPacketNumber, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false, false)
-	sph.SentPacket(monotime.Now(), sph.PopPacketNumber(protocol.EncryptionHandshake), protocol.InvalidPacketNumber, nil, nil, protocol.EncryptionHandshake, protocol.ECT0, 1200, false, false)
-	sph.SentPacket(monotime.Now(), sph.PopPacketNumber(protocol.Encryption0RTT), protocol.InvalidPacketNumber, nil, nil, protocol.Encryption0RTT, protocol.ECNCE, 1200, false, false)
+	sph.SentPacket(monotime.Now(), 0, sph.PopPacketNumber(protocol.EncryptionInitial), protocol.InvalidPacketNumber, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false, false)
+	sph.SentPacket(monotime.Now(), 0, sph.PopPacketNumber(protocol.EncryptionHandshake), protocol.InvalidPacketNumber, nil, nil, protocol.EncryptionHandshake, protocol.ECT0, 1200, false, false)
+	sph.SentPacket(monotime.Now(), 0, sph.PopPacketNumber(protocol.Encryption0RTT), protocol.InvalidPacketNumber, nil, nil, protocol.Encryption0RTT, protocol.ECNCE, 1200, false, false)
 
 	var packets packetTracker
 	sendPacket := func(t *testing.T, ti monotime.Time, ecn protocol.ECN) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
 		ecnHandler.EXPECT().SentPacket(pn, ecn)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, ecn, 1200, false, false)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, ecn, 1200, false, false)
 		return pn
 	}
 
@@ -1205,6 +1391,7 @@ func TestSentPacketHandlerPathProbe(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1219,7 +1406,7 @@ func TestSentPacketHandlerPathProbe(t *testing.T) {
 	sendPacket := func(t *testing.T, ti monotime.Time, isPathProbe bool) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, isPathProbe)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, isPathProbe)
 		return pn
 	}
 
@@ -1286,6 +1473,7 @@ func TestSentPacketHandlerPathProbeAckAndLoss(t *testing.T) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1300,7 +1488,7 @@ func TestSentPacketHandlerPathProbeAckAndLoss(t *testing.T) {
 	sendPacket := func(t *testing.T, ti monotime.Time, isPathProbe bool) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, isPathProbe)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, isPathProbe)
 		return pn
 	}
 
@@ -1363,6 +1551,7 @@ func testSentPacketHandlerRandomized(t *testing.T, seed uint64) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1377,7 +1566,7 @@ func testSentPacketHandlerRandomized(t *testing.T, seed uint64) {
 	sendPacket := func(t *testing.T, ti monotime.Time, isPathProbe bool) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, isPathProbe)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1200, false, isPathProbe)
 		return pn
 	}
 
@@ -1433,6 +1622,7 @@ func TestSentPacketHandlerSpuriousLoss(t *testing.T) {
 		1200,
 		utils.NewRTTStats(),
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1445,7 +1635,7 @@ func TestSentPacketHandlerSpuriousLoss(t *testing.T) {
 	sendPacket := func(t *testing.T, ti monotime.Time) protocol.PacketNumber {
 		t.Helper()
 		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
-		sph.SentPacket(ti, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1000, false, false)
+		sph.SentPacket(ti, 0, pn, protocol.InvalidPacketNumber, nil, []Frame{packets.NewPingFrame(pn)}, protocol.Encryption1RTT, protocol.ECNNon, 1000, false, false)
 		return pn
 	}
 
@@ -1569,6 +1759,7 @@ func benchmarkSendAndAcknowledge(b *testing.B, ackEvery, inFlight int) {
 		1200,
 		rttStats,
 		&utils.ConnectionStats{},
+		nil,
 		true,
 		false,
 		nil,
@@ -1591,6 +1782,7 @@ func benchmarkSendAndAcknowledge(b *testing.B, ackEvery, inFlight int) {
 		pn := sph.PopPacketNumber(protocol.Encryption1RTT)
 		sph.SentPacket(
 			now,
+			0,
 			pn,
 			protocol.InvalidPacketNumber,
 			streamFrames,
@@ -0,0 +1,737 @@
+package congestion
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+)
+
+// This is an implementation of the BBR congestion control algorithm, following draft-ietf-ccwg-bbr (BBRv3).
+// Compared to the draft, it is simplified in a few places:
+//   - Rate samples are generated for every acknowledged packet, not for every ACK frame.
+//   - There's no short-term model (bw_lo and inflight_lo).
+//     Loss only reduces the long-term upper bound on the data in flight (inflight_hi).
+//   - ECN-CE marks are not used as a congestion signal.
+
+const (
+	bbrStartupPacingGain = 2.77 // 4*ln(2)
+	bbrStartupCwndGain   = 2.0
+	bbrDrainPacingGain   = 0.35
+	bbrDefaultCwndGain   = 2.0
+	// pacing and cwnd gains used in the ProbeBW phases
+	bbrProbeDownPacingGain = 0.9
+	bbrProbeUpPacingGain   = 1.25
+	bbrProbeUpCwndGain     = 2.25
+	bbrProbeRTTCwndGain    = 0.5
+	// pace at a rate slightly below the estimated bandwidth, to reduce queueing
+	bbrPacingMarginPercent = 1
+	// the maximum tolerated loss rate per round trip
+	bbrLossThresh = 0.02
+	// the multiplicative decrease applied to inflight_hi when the loss rate is too high
+	bbrBeta = 0.7
+	// the fraction of inflight_hi left unused when cruising, to leave room for other flows
+	bbrHeadroom = 0.15
+	// startup is exited when the bandwidth doesn't increase by more than this factor for bbrStartupFullBwRounds
+	bbrStartupFullBwThresh = 1.25
+	bbrStartupFullBwRounds = 3
+
+	bbrMinRTTFilterLen   = 10 * time.Second
+	bbrProbeRTTInterval  = 5 * time.Second
+	bbrProbeRTTDuration  = 200 * time.Millisecond
+	bbrMaxBwProbeUpRound = 30
+	// the number of round trips without probing for bandwidth after which BBR probes,
+	// to be fair to loss-based congestion controllers (i.e. Reno and Cubic)
+	bbrMaxRenoCoexistenceRounds   = 63
+	bbrMinCongestionWindowPackets = 4
+)
+
+type bbrMode uint8
+
+const (
+	bbrModeStartup bbrMode = iota
+	bbrModeDrain
+	bbrModeProbeBW
+	bbrModeProbeRTT
+)
+
+type bbrProbeBWPhase uint8
+
+const (
+	bbrProbeBWDown bbrProbeBWPhase = iota
+	bbrProbeBWCruise
+	bbrProbeBWRefill
+	bbrProbeBWUp
+)
+
+// bbrPacketState is the state of the connection at the time a packet was sent.
+// It is used to generate a rate sample when the packet is acknowledged,
+// see draft-cheng-iccrg-delivery-rate-estimation.
+type bbrPacketState struct {
+	sentTime      monotime.Time
+	delivered     protocol.ByteCount
+	deliveredTime monotime.Time
+	firstSentTime monotime.Time
+	lost          protocol.ByteCount
+	txInFlight    protocol.ByteCount // bytes in flight after sending this packet
+	isAppLimited  bool
+}
+
+type bbrSender struct {
+	rttStats  *utils.RTTStats
+	connStats *utils.ConnectionStats
+	pacer     *pacer
+	clock     Clock
+
+	mode         bbrMode
+	probeBWPhase bbrProbeBWPhase
+
+	sentPackets   map[protocol.PacketNumber]bbrPacketState
+	bytesInFlight protocol.ByteCount
+
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+	largestSentAtLastCutback protocol.PacketNumber
+
+	// delivery rate estimation
+	delivered     protocol.ByteCount
+	deliveredTime monotime.Time
+	firstSentTime monotime.Time
+	lost          protocol.ByteCount
+	// The value of delivered at which the application-limited phase ends.
+	// Zero if the connection is not application limited.
+	appLimitedUntil protocol.ByteCount
+
+	// round trip counting
+	roundCount         uint64
+	nextRoundDelivered protocol.ByteCount
+
+	// The maximum bandwidth measured in the current and in the previous ProbeBW cycle.
+	maxBwFilter [2]Bandwidth
+	cycleCount  uint64
+
+	minRTT           time.Duration // zero until the first RTT sample is taken
+	minRTTStamp      monotime.Time
+	probeRTTMinDelay time.Duration
+	probeRTTMinStamp monotime.Time
+	probeRTTExpired  bool
+
+	// the long-term upper bound on the data in flight
+	inflightHi protocol.ByteCount
+
+	// Startup
+	fullBw        Bandwidth
+	fullBwCount   int
+	fullBwReached bool
+
+	// ProbeBW
+	cycleStamp         monotime.Time
+	roundsSinceBwProbe uint64
+	bwProbeWait        time.Duration
+	bwProbeUpRounds    int
+	bwProbeUpAcks      protocol.ByteCount
+	probeUpCnt         protocol.ByteCount
+
+	// ProbeRTT
+	probeRTTDoneStamp monotime.Time
+	probeRTTRoundDone bool
+	priorCwnd         protocol.ByteCount
+
+	pacingGain       float64
+	cwndGain         float64
+	pacingRate       Bandwidth
+	congestionWindow protocol.ByteCount
+
+	initialCongestionWindow protocol.ByteCount
+	maxDatagramSize         protocol.ByteCount
+
+	lastState qlog.CongestionState
+	qlogger   qlogwriter.Recorder
+}
+
+var (
+	_ SendAlgorithm               = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	connStats *utils.ConnectionStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	qlogger qlogwriter.Recorder,
+) *bbrSender {
+	return newBBRSender(
+		clock,
+		rttStats,
+		connStats,
+		initialMaxDatagramSize,
+		initialCongestionWindow*initialMaxDatagramSize,
+		qlogger,
+	)
+}
+
+func newBBRSender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	connStats *utils.ConnectionStats,
+	initialMaxDatagramSize,
+	initialCongestionWindow protocol.ByteCount,
+	qlogger qlogwriter.Recorder,
+) *bbrSender {
+	c := &bbrSender{
+		rttStats:                 rttStats,
+		connStats:                connStats,
+		clock:                    clock,
+		sentPackets:              make(map[protocol.PacketNumber]bbrPacketState),
+		largestSentPacketNumber:  protocol.InvalidPacketNumber,
+		largestAckedPacketNumber: protocol.InvalidPacketNumber,
+		largestSentAtLastCutback: protocol.InvalidPacketNumber,
+		inflightHi:               protocol.MaxByteCount,
+		congestionWindow:         initialCongestionWindow,
+		initialCongestionWindow:  initialCongestionWindow,
+		maxDatagramSize:          initialMaxDatagramSize,
+		qlogger:                  qlogger,
+	}
+	c.pacer = newPacer(func() Bandwidth {
+		// The pacer adds a headroom of 25% to the bandwidth.
+		// BBR already accounts for this by the pacing gain.
+		return c.pacingRate * 4 / 5
+	})
+	c.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	c.enterStartup()
+	c.pacingRate = c.initialPacingRate()
+	return c
+}
+
+func (c *bbrSender) initialPacingRate() Bandwidth {
+	srtt := c.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		srtt = utils.DefaultInitialRTT
+	}
+	return Bandwidth(bbrStartupPacingGain * float64(BandwidthFromDelta(c.congestionWindow, srtt)))
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (c *bbrSender) TimeUntilSend(_ protocol.ByteCount) monotime.Time {
+	return c.pacer.TimeUntilSend()
+}
+
+func (c *bbrSender) HasPacingBudget(now monotime.Time) bool {
+	return c.pacer.Budget(now) >= c.maxDatagramSize
+}
+
+func (c *bbrSender) maxCongestionWindow() protocol.ByteCount {
+	return c.maxDatagramSize * protocol.MaxCongestionWindowPackets
+}
+
+func (c *bbrSender) minCongestionWindow() protocol.ByteCount {
+	return c.maxDatagramSize * bbrMinCongestionWindowPackets
+}
+
+func (c *bbrSender) OnPacketSent(
+	sentTime monotime.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	c.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	c.largestSentPacketNumber = packetNumber
+	// If nothing else is in flight, restart the delivery rate sampling.
+	if bytesInFlight <= bytes {
+		c.firstSentTime = sentTime
+		c.deliveredTime = sentTime
+	}
+	c.bytesInFlight = bytesInFlight
+	c.sentPackets[packetNumber] = bbrPacketState{
+		sentTime:      sentTime,
+		delivered:     c.delivered,
+		deliveredTime: c.deliveredTime,
+		firstSentTime: c.firstSentTime,
+		lost:          c.lost,
+		txInFlight:    bytesInFlight,
+		isAppLimited:  c.appLimitedUntil > 0,
+	}
+}
+
+func (c *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < c.GetCongestionWindow()
+}
+
+func (c *bbrSender) InRecovery() bool {
+	return c.largestAckedPacketNumber != protocol.InvalidPacketNumber && c.largestAckedPacketNumber <= c.largestSentAtLastCutback
+}
+
+func (c *bbrSender) InSlowStart() bool {
+	return c.mode == bbrModeStartup
+}
+
+func (c *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	return c.congestionWindow
+}
+
+// MaybeExitSlowStart is a no-op, since BBR exits Startup based on the bandwidth estimate.
+func (c *bbrSender) MaybeExitSlowStart() {}
+
+func (c *bbrSender) OnPacketAcked(
+	ackedPacketNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime monotime.Time,
+) {
+	c.largestAckedPacketNumber = max(ackedPacketNumber, c.largestAckedPacketNumber)
+	p, ok := c.sentPackets[ackedPacketNumber]
+	if !ok {
+		return
+	}
+	delete(c.sentPackets, ackedPacketNumber)
+	c.removeFromBytesInFlight(ackedBytes)
+
+	c.delivered += ackedBytes
+	c.deliveredTime = eventTime
+	if c.appLimitedUntil > 0 && c.delivered > c.appLimitedUntil {
+		c.appLimitedUntil = 0
+	}
+	if !c.isCwndLimited(priorInFlight) {
+		c.appLimitedUntil = max(c.delivered+c.bytesInFlight, 1)
+	}
+
+	var roundStart bool
+	if p.delivered >= c.nextRoundDelivered {
+		c.startRound()
+		c.roundCount++
+		c.roundsSinceBwProbe++
+		roundStart = true
+	}
+
+	c.updateMinRTT(eventTime.Sub(p.sentTime), eventTime)
+	c.firstSentTime = p.sentTime
+	// The sample is invalid if the interval is smaller than the min RTT,
+	// since this would overestimate the bandwidth.
+	if interval := max(p.sentTime.Sub(p.firstSentTime), eventTime.Sub(p.deliveredTime)); interval > 0 && interval >= c.minRTT {
+		bw := BandwidthFromDelta(c.delivered-p.delivered, interval)
+		if !p.isAppLimited || bw >= c.maxBw() {
+			c.maxBwFilter[c.cycleCount%2] = max(c.maxBwFilter[c.cycleCount%2], bw)
+		}
+	}
+
+	if roundStart && !c.fullBwReached && !p.isAppLimited {
+		c.checkFullBwReached()
+	}
+	c.updateStateMachine(eventTime, p, ackedBytes, roundStart)
+	c.checkProbeRTT(eventTime, roundStart)
+	c.setPacingRate()
+	c.setCongestionWindow(ackedBytes)
+	if roundStart {
+		c.removeStalePackets(eventTime)
+	}
+}
+
+func (c *bbrSender) OnCongestionEvent(packetNumber protocol.PacketNumber, lostBytes, _ protocol.ByteCount) {
+	c.connStats.PacketsLost.Add(1)
+	c.connStats.BytesLost.Add(uint64(lostBytes))
+
+	if packetNumber > c.largestSentAtLastCutback {
+		c.largestSentAtLastCutback = c.largestSentPacketNumber
+	}
+	p, ok := c.sentPackets[packetNumber]
+	if !ok {
+		return
+	}
+	delete(c.sentPackets, packetNumber)
+	c.removeFromBytesInFlight(lostBytes)
+	c.lost += lostBytes
+
+	if lostSinceSent := c.lost - p.lost; float64(lostSinceSent) > bbrLossThresh*float64(p.txInFlight) {
+		c.handleInflightTooHigh(p)
+	}
+}
+
+// OnRetransmissionTimeout is called when persistent congestion is detected.
+func (c *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	if !packetsRetransmitted {
+		return
+	}
+	c.saveCwnd()
+	c.congestionWindow = c.minCongestionWindow()
+}
+
+func (c *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < c.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", c.maxDatagramSize, s))
+	}
+	cwndIsMinCwnd := c.congestionWindow == c.minCongestionWindow()
+	c.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		c.congestionWindow = c.minCongestionWindow()
+	}
+	c.pacer.SetMaxDatagramSize(s)
+}
+
+// PacingRate returns the rate at which packets are paced, in bytes/s.
+func (c *bbrSender) PacingRate() uint64 {
+	return c.pacer.Rate()
+}
+
+// BandwidthEstimate returns the current bandwidth estimate
+func (c *bbrSender) BandwidthEstimate() Bandwidth {
+	return c.maxBw()
+}
+
+func (c *bbrSender) removeFromBytesInFlight(bytes protocol.ByteCount) {
+	if bytes > c.bytesInFlight {
+		c.bytesInFlight = 0
+		return
+	}
+	c.bytesInFlight -= bytes
+}
+
+// removeStalePackets removes packets that have neither been acknowledged nor declared lost.
+// This happens when packet number spaces are dropped, and for lost Path MTU probe packets.
+func (c *bbrSender) removeStalePackets(now monotime.Time) {
+	if len(c.sentPackets) <= 2*int(c.congestionWindow/c.maxDatagramSize) {
+		return
+	}
+	threshold := now.Add(-3 * c.rttStats.PTO(true))
+	for pn, p := range c.sentPackets {
+		if p.sentTime.Before(threshold) {
+			delete(c.sentPackets, pn)
+		}
+	}
+}
+
+func (c *bbrSender) isCwndLimited(bytesInFlight protocol.ByteCount) bool {
+	if bytesInFlight >= c.congestionWindow {
+		return true
+	}
+	availableBytes := c.congestionWindow - bytesInFlight
+	startupLimited := c.mode == bbrModeStartup && bytesInFlight > c.congestionWindow/2
+	return startupLimited || availableBytes <= maxBurstPackets*c.maxDatagramSize
+}
+
+func (c *bbrSender) startRound() {
+	c.nextRoundDelivered = c.delivered
+}
+
+func (c *bbrSender) maxBw() Bandwidth {
+	return max(c.maxBwFilter[0], c.maxBwFilter[1])
+}
+
+func (c *bbrSender) advanceMaxBwFilter() {
+	c.cycleCount++
+	c.maxBwFilter[c.cycleCount%2] = 0
+}
+
+func (c *bbrSender) updateMinRTT(rtt time.Duration, now monotime.Time) {
+	c.probeRTTExpired = !c.probeRTTMinStamp.IsZero() && now.After(c.probeRTTMinStamp.Add(bbrProbeRTTInterval))
+	if c.probeRTTMinDelay == 0 || rtt < c.probeRTTMinDelay || c.probeRTTExpired {
+		c.probeRTTMinDelay = rtt
+		c.probeRTTMinStamp = now
+	}
+	minRTTExpired := now.After(c.minRTTStamp.Add(bbrMinRTTFilterLen))
+	if c.minRTT == 0 || c.probeRTTMinDelay < c.minRTT || minRTTExpired {
+		c.minRTT = c.probeRTTMinDelay
+		c.minRTTStamp = c.probeRTTMinStamp
+	}
+}
+
+// bdp calculates the bandwidth-delay product, multiplied by gain.
+func (c *bbrSender) bdp(bw Bandwidth, gain float64) protocol.ByteCount {
+	if bw == 0 || c.minRTT == 0 {
+		return c.initialCongestionWindow
+	}
+	return protocol.ByteCount(gain * float64(bw/BytesPerSecond) * c.minRTT.Seconds())
+}
+
+// inflight is the amount of data that BBR aims to keep in flight, given a bandwidth and a gain.
+func (c *bbrSender) inflight(bw Bandwidth, gain float64) protocol.ByteCount {
+	// allow for some additional data in flight to compensate for ACK aggregation and delayed ACKs
+	return c.bdp(bw, gain) + maxBurstPackets*c.maxDatagramSize
+}
+
+func (c *bbrSender) targetInflight() protocol.ByteCount {
+	return min(c.bdp(c.maxBw(), 1), c.congestionWindow)
+}
+
+func (c *bbrSender) inflightWithHeadroom() protocol.ByteCount {
+	if c.inflightHi == protocol.MaxByteCount {
+		return protocol.MaxByteCount
+	}
+	headroom := max(c.maxDatagramSize, protocol.ByteCount(bbrHeadroom*float64(c.inflightHi)))
+	if headroom >= c.inflightHi {
+		return c.minCongestionWindow()
+	}
+	return max(c.inflightHi-headroom, c.minCongestionWindow())
+}
+
+func (c *bbrSender) checkFullBwReached() {
+	if bw := c.maxBw(); bw >= Bandwidth(bbrStartupFullBwThresh*float64(c.fullBw)) {
+		c.fullBw = bw
+		c.fullBwCount = 0
+		return
+	}
+	c.fullBwCount++
+	if c.fullBwCount >= bbrStartupFullBwRounds {
+		c.fullBwReached = true
+	}
+}
+
+func (c *bbrSender) handleInflightTooHigh(p bbrPacketState) {
+	if !p.isAppLimited {
+		c.inflightHi = max(p.txInFlight, protocol.ByteCount(bbrBeta*float64(c.targetInflight())))
+	}
+	switch c.mode {
+	case bbrModeStartup:
+		c.fullBwReached = true
+	case bbrModeProbeBW:
+		if c.probeBWPhase == bbrProbeBWUp {
+			c.startProbeBWDown(c.clock.Now())
+		}
+	}
+}
+
+func (c *bbrSender) updateStateMachine(now monotime.Time, p bbrPacketState, ackedBytes protocol.ByteCount, roundStart bool) {
+	if c.mode == bbrModeStartup && c.fullBwReached {
+		c.enterDrain()
+	}
+	if c.mode == bbrModeDrain && c.bytesInFlight <= c.inflight(c.maxBw(), 1) {
+		c.enterProbeBW(now)
+	}
+	if c.mode == bbrModeProbeBW {
+		c.updateProbeBWPhase(now, p, ackedBytes, roundStart)
+	}
+}
+
+func (c *bbrSender) enterStartup() {
+	c.mode = bbrModeStartup
+	c.pacingGain = bbrStartupPacingGain
+	c.cwndGain = bbrStartupCwndGain
+	c.maybeQlogStateChange(qlog.CongestionStateStartup)
+}
+
+func (c *bbrSender) enterDrain() {
+	c.mode = bbrModeDrain
+	c.pacingGain = bbrDrainPacingGain
+	c.cwndGain = bbrStartupCwndGain
+	c.maybeQlogStateChange(qlog.CongestionStateDrain)
+}
+
+func (c *bbrSender) enterProbeBW(now monotime.Time) {
+	c.mode = bbrModeProbeBW
+	c.maybeQlogStateChange(qlog.CongestionStateProbeBW)
+	c.startProbeBWDown(now)
+}
+
+func (c *bbrSender) startProbeBWDown(now monotime.Time) {
+	c.advanceMaxBwFilter()
+	c.roundsSinceBwProbe = 0
+	c.bwProbeWait = 2*time.Second + rand.N(time.Second)
+	c.cycleStamp = now
+	c.startRound()
+	c.probeBWPhase = bbrProbeBWDown
+	c.pacingGain = bbrProbeDownPacingGain
+	c.cwndGain = bbrDefaultCwndGain
+}
+
+func (c *bbrSender) startProbeBWCruise() {
+	c.probeBWPhase = bbrProbeBWCruise
+	c.pacingGain = 1
+	c.cwndGain = bbrDefaultCwndGain
+}
+
+func (c *bbrSender) startProbeBWRefill() {
+	c.bwProbeUpRounds = 0
+	c.bwProbeUpAcks = 0
+	c.startRound()
+	c.probeBWPhase = bbrProbeBWRefill
+	c.pacingGain = 1
+	c.cwndGain = bbrDefaultCwndGain
+}
+
+func (c *bbrSender) startProbeBWUp(now monotime.Time) {
+	c.startRound()
+	c.cycleStamp = now
+	c.probeBWPhase = bbrProbeBWUp
+	c.pacingGain = bbrProbeUpPacingGain
+	c.cwndGain = bbrProbeUpCwndGain
+	c.raiseInflightHiSlope()
+}
+
+func (c *bbrSender) updateProbeBWPhase(now monotime.Time, p bbrPacketState, ackedBytes protocol.ByteCount, roundStart bool) {
+	c.adaptUpperBounds(p, ackedBytes, roundStart)
+	switch c.probeBWPhase {
+	case bbrProbeBWDown:
+		if c.checkTimeToProbeBW(now) {
+			return
+		}
+		if c.bytesInFlight <= c.inflightWithHeadroom() && c.bytesInFlight <= c.inflight(c.maxBw(), 1) {
+			c.startProbeBWCruise()
+		}
+	case bbrProbeBWCruise:
+		c.checkTimeToProbeBW(now)
+	case bbrProbeBWRefill:
+		// After one round of refilling the pipe, start probing.
+		if roundStart {
+			c.startProbeBWUp(now)
+		}
+	case bbrProbeBWUp:
+		if now.Sub(c.cycleStamp) > c.minRTT && c.bytesInFlight > c.inflight(c.maxBw(), bbrProbeUpPacingGain) {
+			c.startProbeBWDown(now)
+		}
+	}
+}
+
+// checkTimeToProbeBW checks if it's time to probe for more bandwidth, and starts probing if it is.
+func (c *bbrSender) checkTimeToProbeBW(now monotime.Time) bool {
+	renoRounds := min(max(uint64(c.targetInflight()/c.maxDatagramSize), 1), bbrMaxRenoCoexistenceRounds)
+	if now.Sub(c.cycleStamp) > c.bwProbeWait || c.roundsSinceBwProbe >= renoRounds {
+		c.startProbeBWRefill()
+		return true
+	}
+	return false
+}
+
+// adaptUpperBounds increases inflight_hi while probing for bandwidth,
+// as long as the loss rate stays below the threshold.
+func (c *bbrSender) adaptUpperBounds(p bbrPacketState, ackedBytes protocol.ByteCount, roundStart bool) {
+	if c.inflightHi == protocol.MaxByteCount || c.probeBWPhase != bbrProbeBWUp {
+		return
+	}
+	if p.txInFlight > c.inflightHi {
+		c.inflightHi = p.txInFlight
+	}
+	if c.congestionWindow < c.inflightHi {
+		// not fully using inflight_hi, so don't grow it
+		return
+	}
+	c.bwProbeUpAcks += ackedBytes
+	if c.bwProbeUpAcks >= c.probeUpCnt {
+		delta := c.bwProbeUpAcks / c.probeUpCnt
+		c.bwProbeUpAcks -= delta * c.probeUpCnt
+		c.inflightHi += delta * c.maxDatagramSize
+	}
+	if roundStart {
+		c.raiseInflightHiSlope()
+	}
+}
+
+// raiseInflightHiSlope doubles the growth rate of inflight_hi every round trip.
+func (c *bbrSender) raiseInflightHiSlope() {
+	growthThisRound := c.maxDatagramSize << c.bwProbeUpRounds
+	c.bwProbeUpRounds = min(c.bwProbeUpRounds+1, bbrMaxBwProbeUpRound)
+	c.probeUpCnt = max(c.congestionWindow/growthThisRound, 1) * c.maxDatagramSize
+}
+
+func (c *bbrSender) checkProbeRTT(now monotime.Time, roundStart bool) {
+	if c.mode != bbrModeProbeRTT && c.probeRTTExpired {
+		c.enterProbeRTT()
+		c.saveCwnd()
+		c.probeRTTDoneStamp = 0
+	}
+	if c.mode == bbrModeProbeRTT {
+		c.handleProbeRTT(now, roundStart)
+	}
+}
+
+func (c *bbrSender) enterProbeRTT() {
+	c.mode = bbrModeProbeRTT
+	c.pacingGain = 1
+	c.cwndGain = bbrProbeRTTCwndGain
+	c.maybeQlogStateChange(qlog.CongestionStateProbeRTT)
+}
+
+func (c *bbrSender) probeRTTCwnd() protocol.ByteCount {
+	return max(c.bdp(c.maxBw(), bbrProbeRTTCwndGain), c.minCongestionWindow())
+}
+
+func (c *bbrSender) handleProbeRTT(now monotime.Time, roundStart bool) {
+	if c.probeRTTDoneStamp.IsZero() {
+		if c.bytesInFlight <= c.probeRTTCwnd() {
+			c.probeRTTDoneStamp = now.Add(bbrProbeRTTDuration)
+			c.probeRTTRoundDone = false
+			c.startRound()
+		}
+		return
+	}
+	if roundStart {
+		c.probeRTTRoundDone = true
+	}
+	if c.probeRTTRoundDone && now.After(c.probeRTTDoneStamp) {
+		c.probeRTTMinStamp = now
+		c.restoreCwnd()
+		c.exitProbeRTT(now)
+	}
+}
+
+func (c *bbrSender) exitProbeRTT(now monotime.Time) {
+	if !c.fullBwReached {
+		c.enterStartup()
+		return
+	}
+	c.enterProbeBW(now)
+	c.startProbeBWCruise()
+}
+
+func (c *bbrSender) saveCwnd() {
+	if c.mode != bbrModeProbeRTT && !c.InRecovery() {
+		c.priorCwnd = c.congestionWindow
+		return
+	}
+	c.priorCwnd = max(c.priorCwnd, c.congestionWindow)
+}
+
+func (c *bbrSender) restoreCwnd() {
+	c.congestionWindow = max(c.congestionWindow, c.priorCwnd)
+}
+
+func (c *bbrSender) setPacingRate() {
+	bw := c.maxBw()
+	if bw == 0 {
+		return
+	}
+	rate := Bandwidth(c.pacingGain * float64(bw) * (100 - bbrPacingMarginPercent) / 100)
+	// During Startup, the pacing rate is never decreased.
+	if c.fullBwReached || rate > c.pacingRate {
+		c.pacingRate = max(rate, 1)
+	}
+}
+
+func (c *bbrSender) setCongestionWindow(ackedBytes protocol.ByteCount) {
+	maxInflight := c.inflight(c.maxBw(), c.cwndGain)
+	if c.fullBwReached {
+		c.congestionWindow = min(c.congestionWindow+ackedBytes, maxInflight)
+	} else if c.congestionWindow < maxInflight || c.delivered < c.initialCongestionWindow {
+		c.congestionWindow += ackedBytes
+	}
+	c.congestionWindow = max(c.congestionWindow, c.minCongestionWindow())
+
+	// bound the congestion window by the model
+	if c.mode == bbrModeProbeBW && c.probeBWPhase != bbrProbeBWCruise {
+		c.congestionWindow = min(c.congestionWindow, max(c.inflightHi, c.minCongestionWindow()))
+	} else if c.mode == bbrModeProbeRTT || c.mode == bbrModeProbeBW {
+		c.congestionWindow = min(c.congestionWindow, c.inflightWithHeadroom())
+	}
+	if c.mode == bbrModeProbeRTT {
+		c.congestionWindow = min(c.congestionWindow, c.probeRTTCwnd())
+	}
+	c.congestionWindow = min(c.congestionWindow, c.maxCongestionWindow())
+}
+
+func (c *bbrSender) maybeQlogStateChange(new qlog.CongestionState) {
+	if c.qlogger == nil || new == c.lastState {
+		return
+	}
+	c.qlogger.RecordEvent(qlog.CongestionStateUpdated{State: new})
+	c.lastState = new
+}
@@ -0,0 +1,225 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+	"github.com/quic-go/quic-go/testutils/events"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bbrTestLink simulates a path with a single bottleneck link.
+// Every packet is acknowledged individually.
+type bbrTestLink struct {
+	sender   *bbrSender
+	clock    *mockClock
+	rttStats *utils.RTTStats
+
+	bandwidth  protocol.ByteCount // in bytes/s
+	rtt        time.Duration      // the propagation delay
+	bufferSize protocol.ByteCount // the bottleneck's buffer size, 0 means unlimited
+
+	bytesInFlight   protocol.ByteCount
+	nextPN          protocol.PacketNumber
+	linkBusyUntil   monotime.Time
+	inFlight        []bbrTestPacket // ordered by arrival time of the ACK
+	packetsLost     int
+	lostPNs         []protocol.PacketNumber
+	maxBytesInQueue protocol.ByteCount
+}
+
+type bbrTestPacket struct {
+	pn      protocol.PacketNumber
+	ackTime monotime.Time
+	lost    bool
+}
+
+func newBBRTestLink(bandwidth protocol.ByteCount, rtt time.Duration, bufferSize protocol.ByteCount, qlogger qlogwriter.Recorder) *bbrTestLink {
+	clock := mockClock(monotime.Now())
+	rttStats := utils.NewRTTStats()
+	return &bbrTestLink{
+		sender:     newBBRSender(&clock, rttStats, &utils.ConnectionStats{}, maxDatagramSize, initialCongestionWindow*maxDatagramSize, qlogger),
+		clock:      &clock,
+		rttStats:   rttStats,
+		bandwidth:  bandwidth,
+		rtt:        rtt,
+		bufferSize: bufferSize,
+	}
+}
+
+func (l *bbrTestLink) sendPacket() {
+	now := l.clock.Now()
+	pn := l.nextPN
+	l.nextPN++
+	l.bytesInFlight += maxDatagramSize
+	l.sender.OnPacketSent(now, l.bytesInFlight, pn, maxDatagramSize, true)
+
+	start := max(now, l.linkBusyUntil)
+	queued := protocol.ByteCount(start.Sub(now).Seconds() * float64(l.bandwidth))
+	if l.bufferSize > 0 && queued > l.bufferSize {
+		// The packet is dropped by the bottleneck.
+		// Declare it lost when the next packet would have been acknowledged.
+		l.inFlight = append(l.inFlight, bbrTestPacket{pn: pn, ackTime: start.Add(l.rtt), lost: true})
+		return
+	}
+	l.linkBusyUntil = start.Add(time.Duration(float64(maxDatagramSize) / float64(l.bandwidth) * float64(time.Second)))
+	l.inFlight = append(l.inFlight, bbrTestPacket{pn: pn, ackTime: l.linkBusyUntil.Add(l.rtt)})
+}
+
+// run simulates a bulk transfer for the given duration
+func (l *bbrTestLink) run(d time.Duration) {
+	end := l.clock.Now().Add(d)
+	for l.clock.Now().Before(end) {
+		now := l.clock.Now()
+		// process all ACKs
+		for len(l.inFlight) > 0 && !l.inFlight[0].ackTime.After(now) {
+			p := l.inFlight[0]
+			l.inFlight = l.inFlight[1:]
+			priorInFlight := l.bytesInFlight
+			l.bytesInFlight -= maxDatagramSize
+			if p.lost {
+				l.packetsLost++
+				l.lostPNs = append(l.lostPNs, p.pn)
+				l.sender.OnCongestionEvent(p.pn, maxDatagramSize, priorInFlight)
+				continue
+			}
+			l.rttStats.UpdateRTT(now.Sub(p.ackTime.Add(-l.rtt)), 0)
+			l.sender.OnPacketAcked(p.pn, maxDatagramSize, priorInFlight, now)
+		}
+		// send as many packets as allowed
+		for l.sender.CanSend(l.bytesInFlight) && l.sender.HasPacingBudget(now) {
+			l.sendPacket()
+		}
+		if queued := protocol.ByteCount(l.linkBusyUntil.Sub(now).Seconds() * float64(l.bandwidth)); queued > l.maxBytesInQueue {
+			l.maxBytesInQueue = queued
+		}
+		// advance the clock to the next event
+		next := end
+		if len(l.inFlight) > 0 {
+			next = min(next, l.inFlight[0].ackTime)
+		}
+		if l.sender.CanSend(l.bytesInFlight) {
+			next = min(next, max(l.sender.TimeUntilSend(l.bytesInFlight), now.Add(time.Microsecond)))
+		}
+		l.clock.Advance(max(next.Sub(now), time.Microsecond))
+	}
+}
+
+func TestBBRSenderStartup(t *testing.T) {
+	var eventRecorder events.Recorder
+	const bandwidth = 10 << 20 // 10 MB/s
+	const rtt = 50 * time.Millisecond
+	l := newBBRTestLink(bandwidth, rtt, 0, &eventRecorder)
+	require.True(t, l.sender.InSlowStart())
+	require.Equal(t, []qlogwriter.Event{qlog.CongestionStateUpdated{State: qlog.CongestionStateStartup}}, eventRecorder.Events(qlog.CongestionStateUpdated{}))
+
+	l.run(2 * time.Second)
+	require.False(t, l.sender.InSlowStart())
+	require.Equal(t, bbrModeProbeBW, l.sender.mode)
+	require.InDelta(t, bandwidth, float64(l.sender.BandwidthEstimate()/BytesPerSecond), 0.1*bandwidth)
+	require.Equal(t, rtt, l.sender.minRTT.Round(time.Millisecond))
+	// the congestion window should be roughly twice the BDP
+	bdp := protocol.ByteCount(bandwidth * rtt.Seconds())
+	require.Greater(t, l.sender.GetCongestionWindow(), bdp)
+	require.LessOrEqual(t, l.sender.GetCongestionWindow(), 3*bdp)
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateStartup},
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateDrain},
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateProbeBW},
+		},
+		eventRecorder.Events(qlog.CongestionStateUpdated{}),
+	)
+}
+
+func TestBBRSenderQueueing(t *testing.T) {
+	const bandwidth = 10 << 20 // 10 MB/s
+	const rtt = 50 * time.Millisecond
+	l := newBBRTestLink(bandwidth, rtt, 0, nil)
+	l.run(2 * time.Second)
+	// After Drain, the queue at the bottleneck shouldn't grow beyond the headroom used for probing.
+	l.maxBytesInQueue = 0
+	l.run(5 * time.Second)
+	bdp := protocol.ByteCount(bandwidth * rtt.Seconds())
+	require.Less(t, l.maxBytesInQueue, bdp/2)
+	require.InDelta(t, bandwidth, float64(l.sender.BandwidthEstimate()/BytesPerSecond), 0.1*bandwidth)
+}
+
+func TestBBRSenderProbeRTT(t *testing.T) {
+	var eventRecorder events.Recorder
+	l := newBBRTestLink(10<<20, 50*time.Millisecond, 0, &eventRecorder)
+	l.run(2 * time.Second)
+	require.Equal(t, bbrModeProbeBW, l.sender.mode)
+	eventRecorder.Clear()
+
+	// ProbeRTT is entered if the min RTT wasn't updated for 5 seconds.
+	l.run(10 * time.Second)
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateProbeRTT},
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateProbeBW},
+		},
+		eventRecorder.Events(qlog.CongestionStateUpdated{})[:2],
+	)
+	require.Equal(t, bbrModeProbeBW, l.sender.mode)
+}
+
+func TestBBRSenderLossInStartup(t *testing.T) {
+	const bandwidth = 10 << 20 // 10 MB/s
+	const rtt = 50 * time.Millisecond
+	bdp := protocol.ByteCount(bandwidth * rtt.Seconds())
+	// use a shallow buffer at the bottleneck
+	l := newBBRTestLink(bandwidth, rtt, bdp/4, nil)
+	for l.sender.InSlowStart() {
+		l.run(10 * time.Millisecond)
+		require.Less(t, l.clock.Now().Sub(monotime.Time(0)), 365*24*time.Hour)
+	}
+	require.NotZero(t, l.packetsLost)
+	require.True(t, l.sender.fullBwReached)
+	require.NotEqual(t, protocol.MaxByteCount, l.sender.inflightHi)
+
+	// after startup, the loss rate should be low
+	l.packetsLost = 0
+	l.run(5 * time.Second)
+	require.Less(t, l.packetsLost, int(5*bandwidth/maxDatagramSize)/100)
+	require.InDelta(t, bandwidth, float64(l.sender.BandwidthEstimate()/BytesPerSecond), 0.15*bandwidth)
+}
+
+func TestBBRSenderLossesAreCounted(t *testing.T) {
+	var connStats utils.ConnectionStats
+	sender := NewBBRSender(DefaultClock{}, utils.NewRTTStats(), &connStats, maxDatagramSize, nil)
+	now := monotime.Now()
+	sender.OnPacketSent(now, maxDatagramSize, 1, maxDatagramSize, true)
+	sender.OnPacketSent(now, 2*maxDatagramSize, 2, maxDatagramSize, true)
+	sender.OnCongestionEvent(1, maxDatagramSize, 2*maxDatagramSize)
+	require.EqualValues(t, 1, connStats.PacketsLost.Load())
+	require.EqualValues(t, maxDatagramSize, connStats.BytesLost.Load())
+	require.False(t, sender.InRecovery())
+	sender.OnPacketAcked(2, maxDatagramSize, maxDatagramSize, now.Add(time.Millisecond))
+	require.True(t, sender.InRecovery())
+}
+
+func TestBBRSenderRetransmissionTimeout(t *testing.T) {
+	l := newBBRTestLink(10<<20, 50*time.Millisecond, 0, nil)
+	l.run(time.Second)
+	cwnd := l.sender.GetCongestionWindow()
+	l.sender.OnRetransmissionTimeout(false)
+	require.Equal(t, cwnd, l.sender.GetCongestionWindow())
+	l.sender.OnRetransmissionTimeout(true)
+	require.Equal(t, l.sender.minCongestionWindow(), l.sender.GetCongestionWindow())
+}
+
+func TestBBRSenderMaxDatagramSize(t *testing.T) {
+	sender := NewBBRSender(DefaultClock{}, utils.NewRTTStats(), &utils.ConnectionStats{}, maxDatagramSize, nil)
+	sender.OnRetransmissionTimeout(true)
+	require.Equal(t, 4*maxDatagramSize, sender.GetCongestionWindow())
+	sender.SetMaxDatagramSize(maxDatagramSize + 100)
+	require.Equal(t, 4*(maxDatagramSize+100), sender.GetCongestionWindow())
+	require.Panics(t, func() { sender.SetMaxDatagramSize(maxDatagramSize) })
+}
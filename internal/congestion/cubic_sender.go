@@ -172,11 +172,19 @@ func (c *cubicSender) GetCongestionWindow() protocol.ByteCount {
 }
 
 func (c *cubicSender) MaybeExitSlowStart() {
-	if c.InSlowStart() &&
-		c.hybridSlowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/c.maxDatagramSize) {
+	if !c.InSlowStart() {
+		return
+	}
+	if c.hybridSlowStart.ShouldExitSlowStart(c.rttStats.LatestRTT()) {
 		// exit slow start
 		c.slowStartThreshold = c.congestionWindow
 		c.maybeQlogStateChange(qlog.CongestionStateCongestionAvoidance)
+		return
+	}
+	if c.hybridSlowStart.InConservativeSlowStart() {
+		c.maybeQlogStateChange(qlog.CongestionStateConservativeSlowStart)
+	} else {
+		c.maybeQlogStateChange(qlog.CongestionStateSlowStart)
 	}
 }
 
@@ -242,6 +250,12 @@ func (c *cubicSender) maybeIncreaseCwnd(
 		return
 	}
 	if c.InSlowStart() {
+		if c.hybridSlowStart.InConservativeSlowStart() {
+			// HyStart++ Conservative Slow Start, see RFC 9406, section 4.2.
+			c.congestionWindow += c.maxDatagramSize / hybridStartCSSGrowthDivisor
+			c.maybeQlogStateChange(qlog.CongestionStateConservativeSlowStart)
+			return
+		}
 		// TCP slow start, exponential growth, increase by one for each ACK.
 		c.congestionWindow += c.maxDatagramSize
 		c.maybeQlogStateChange(qlog.CongestionStateSlowStart)
@@ -274,6 +288,11 @@ func (c *cubicSender) isCwndLimited(bytesInFlight protocol.ByteCount) bool {
 	return slowStartLimited || availableBytes <= maxBurstPackets*c.maxDatagramSize
 }
 
+// PacingRate returns the rate at which packets are paced, in bytes/s.
+func (c *cubicSender) PacingRate() uint64 {
+	return c.pacer.Rate()
+}
+
 // BandwidthEstimate returns the current bandwidth estimate
 func (c *cubicSender) BandwidthEstimate() Bandwidth {
 	srtt := c.rttStats.SmoothedRTT()
@@ -8,6 +8,9 @@ import (
 	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+	"github.com/quic-go/quic-go/testutils/events"
 
 	"github.com/stretchr/testify/require"
 )
@@ -592,3 +595,70 @@ func TestCubicSenderLimitCwndIncreaseInCongestionAvoidance(t *testing.T) {
 	testSender.AckNPackets(2)
 	require.Equal(t, savedCwnd+maxDatagramSize, sender.GetCongestionWindow())
 }
+
+func TestCubicSenderHyStartPlusPlus(t *testing.T) {
+	var clock mockClock
+	var eventRecorder events.Recorder
+	rttStats := utils.NewRTTStats()
+	sender := newCubicSender(
+		&clock,
+		rttStats,
+		&utils.ConnectionStats{},
+		true,
+		maxDatagramSize,
+		initialCongestionWindowPackets*maxDatagramSize,
+		MaxCongestionWindow,
+		&eventRecorder,
+	)
+	testSender := &testCubicSender{sender: sender, clock: &clock, rttStats: rttStats, packetNumber: 1}
+
+	// runRound sends a full congestion window, and acknowledges all packets with the given RTT.
+	runRound := func(rtt time.Duration) {
+		testSender.SendAvailableSendWindow()
+		for range testSender.bytesInFlight / maxDatagramSize {
+			rttStats.UpdateRTT(rtt, 0)
+			sender.MaybeExitSlowStart()
+			testSender.ackedPacketNumber++
+			sender.OnPacketAcked(testSender.ackedPacketNumber, maxDatagramSize, testSender.bytesInFlight, clock.Now())
+		}
+		testSender.bytesInFlight = 0
+		clock.Advance(rtt)
+	}
+
+	const rtt = 50 * time.Millisecond
+	runRound(rtt)
+	require.Equal(t, 2*initialCongestionWindowPackets*maxDatagramSize, sender.GetCongestionWindow())
+	require.False(t, sender.hybridSlowStart.InConservativeSlowStart())
+
+	// The RTT increases. The sender enters Conservative Slow Start (CSS).
+	cwnd := sender.GetCongestionWindow()
+	runRound(rtt + 20*time.Millisecond)
+	require.True(t, sender.InSlowStart())
+	require.True(t, sender.hybridSlowStart.InConservativeSlowStart())
+	// The first hybridStartMinSamples ACKs are needed to detect the RTT increase.
+	// After that, the congestion window grows 4 times slower.
+	packetsInCSS := cwnd/maxDatagramSize - protocol.ByteCount(hybridStartMinSamples)
+	require.Equal(t,
+		cwnd+protocol.ByteCount(hybridStartMinSamples)*maxDatagramSize+packetsInCSS*(maxDatagramSize/hybridStartCSSGrowthDivisor),
+		sender.GetCongestionWindow(),
+	)
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateSlowStart},
+			qlog.CongestionStateUpdated{State: qlog.CongestionStateConservativeSlowStart},
+		},
+		eventRecorder.Events(qlog.CongestionStateUpdated{}),
+	)
+
+	// After hybridStartCSSRounds rounds in CSS, slow start is exited.
+	for range hybridStartCSSRounds - 1 {
+		runRound(rtt + 20*time.Millisecond)
+		require.True(t, sender.InSlowStart())
+	}
+	runRound(rtt + 20*time.Millisecond)
+	require.False(t, sender.InSlowStart())
+	require.Equal(t,
+		qlog.CongestionStateUpdated{State: qlog.CongestionStateCongestionAvoidance},
+		eventRecorder.Events(qlog.CongestionStateUpdated{})[2],
+	)
+}
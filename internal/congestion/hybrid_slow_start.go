@@ -6,35 +6,44 @@ import (
 	"github.com/quic-go/quic-go/internal/protocol"
 )
 
-// Note(pwestin): the magic clamping numbers come from the original code in
-// tcp_cubic.c.
-const hybridStartLowWindow = protocol.ByteCount(16)
-
-// Number of delay samples for detecting the increase of delay.
-const hybridStartMinSamples = uint32(8)
-
-// Exit slow start if the min rtt has increased by more than 1/8th.
-const hybridStartDelayFactorExp = 3 // 2^3 = 8
-// The original paper specifies 2 and 8ms, but those have changed over time.
+// The constants are the ones recommended in RFC 9406, section 4.3.
 const (
-	hybridStartDelayMinThresholdUs = int64(4000)
-	hybridStartDelayMaxThresholdUs = int64(16000)
+	// Number of RTT samples needed in a round to check for an increase of the RTT.
+	hybridStartMinSamples = uint32(8)
+	// Exit slow start if the min RTT of a round increased by more than 1/8th.
+	hybridStartMinRTTDivisor = 8
+	// The RTT increase threshold is clamped to the range [4ms, 16ms].
+	hybridStartMinRTTThresh = 4 * time.Millisecond
+	hybridStartMaxRTTThresh = 16 * time.Millisecond
+	// During Conservative Slow Start, the congestion window grows 4 times slower than in slow start.
+	hybridStartCSSGrowthDivisor = 4
+	// The number of rounds spent in Conservative Slow Start before entering congestion avoidance.
+	hybridStartCSSRounds = 5
 )
 
-// HybridSlowStart implements the TCP hybrid slow start algorithm
+// HybridSlowStart implements HyStart++ (RFC 9406).
+// When an increase of the RTT is detected, it first enters Conservative Slow Start (CSS).
+// If the RTT increase persists for hybridStartCSSRounds rounds, slow start is exited.
+// If the RTT increase turns out to be spurious, slow start is resumed.
 type HybridSlowStart struct {
 	endPacketNumber      protocol.PacketNumber
 	lastSentPacketNumber protocol.PacketNumber
 	started              bool
-	currentMinRTT        time.Duration
-	rttSampleCount       uint32
-	hystartFound         bool
+
+	lastRoundMinRTT    time.Duration // zero if not yet measured
+	currentRoundMinRTT time.Duration // zero if not yet measured
+	rttSampleCount     uint32
+
+	inCSS             bool
+	cssBaselineMinRTT time.Duration
+	cssRounds         int
 }
 
 // StartReceiveRound is called for the start of each receive round (burst) in the slow start phase.
 func (s *HybridSlowStart) StartReceiveRound(lastSent protocol.PacketNumber) {
 	s.endPacketNumber = lastSent
-	s.currentMinRTT = 0
+	s.lastRoundMinRTT = s.currentRoundMinRTT
+	s.currentRoundMinRTT = 0
 	s.rttSampleCount = 0
 	s.started = true
 }
@@ -46,44 +55,47 @@ func (s *HybridSlowStart) IsEndOfRound(ack protocol.PacketNumber) bool {
 
 // ShouldExitSlowStart should be called on every new ack frame, since a new
 // RTT measurement can be made then.
-// rtt: the RTT for this ack packet.
-// minRTT: is the lowest delay (RTT) we have seen during the session.
-// congestionWindow: the congestion window in packets.
-func (s *HybridSlowStart) ShouldExitSlowStart(latestRTT time.Duration, minRTT time.Duration, congestionWindow protocol.ByteCount) bool {
+// It returns true once Conservative Slow Start has lasted for hybridStartCSSRounds rounds,
+// at which point the sender should enter congestion avoidance.
+func (s *HybridSlowStart) ShouldExitSlowStart(latestRTT time.Duration) bool {
 	if !s.started {
-		// Time to start the hybrid slow start.
+		// Time to start the next round.
 		s.StartReceiveRound(s.lastSentPacketNumber)
 	}
-	if s.hystartFound {
+	if s.inCSS && s.cssRounds >= hybridStartCSSRounds {
 		return true
 	}
-	// Second detection parameter - delay increase detection.
-	// Compare the minimum delay (s.currentMinRTT) of the current
-	// burst of packets relative to the minimum delay during the session.
-	// Note: we only look at the first few(8) packets in each burst, since we
-	// only want to compare the lowest RTT of the burst relative to previous
-	// bursts.
 	s.rttSampleCount++
-	if s.rttSampleCount <= hybridStartMinSamples {
-		if s.currentMinRTT == 0 || s.currentMinRTT > latestRTT {
-			s.currentMinRTT = latestRTT
-		}
+	if s.currentRoundMinRTT == 0 || latestRTT < s.currentRoundMinRTT {
+		s.currentRoundMinRTT = latestRTT
 	}
-	// We only need to check this once per round.
-	if s.rttSampleCount == hybridStartMinSamples {
-		// Divide minRTT by 8 to get a rtt increase threshold for exiting.
-		minRTTincreaseThresholdUs := int64(minRTT / time.Microsecond >> hybridStartDelayFactorExp)
-		// Ensure the rtt threshold is never less than 2ms or more than 16ms.
-		minRTTincreaseThresholdUs = min(minRTTincreaseThresholdUs, hybridStartDelayMaxThresholdUs)
-		minRTTincreaseThreshold := time.Duration(max(minRTTincreaseThresholdUs, hybridStartDelayMinThresholdUs)) * time.Microsecond
-
-		if s.currentMinRTT > (minRTT + minRTTincreaseThreshold) {
-			s.hystartFound = true
+	if s.rttSampleCount < hybridStartMinSamples {
+		return false
+	}
+	if s.inCSS {
+		// The RTT decreased again, so the RTT increase that triggered CSS was spurious.
+		if s.currentRoundMinRTT < s.cssBaselineMinRTT {
+			s.inCSS = false
+			s.cssBaselineMinRTT = 0
 		}
+		return false
+	}
+	if s.lastRoundMinRTT == 0 {
+		return false
+	}
+	rttThresh := min(max(s.lastRoundMinRTT/hybridStartMinRTTDivisor, hybridStartMinRTTThresh), hybridStartMaxRTTThresh)
+	if s.currentRoundMinRTT >= s.lastRoundMinRTT+rttThresh {
+		s.inCSS = true
+		s.cssBaselineMinRTT = s.currentRoundMinRTT
+		s.cssRounds = 0
 	}
-	// Exit from slow start if the cwnd is greater than 16 and
-	// increasing delay is found.
-	return congestionWindow >= hybridStartLowWindow && s.hystartFound
+	return false
+}
+
+// InConservativeSlowStart says if the sender is in Conservative Slow Start.
+// In this phase, the congestion window should grow hybridStartCSSGrowthDivisor times slower than in slow start.
+func (s *HybridSlowStart) InConservativeSlowStart() bool {
+	return s.inCSS
 }
 
 // OnPacketSent is called when a packet was sent
@@ -95,8 +107,11 @@ func (s *HybridSlowStart) OnPacketSent(packetNumber protocol.PacketNumber) {
 // the round when the final packet of the burst is received and start it on
 // the next incoming ack.
 func (s *HybridSlowStart) OnPacketAcked(ackedPacketNumber protocol.PacketNumber) {
-	if s.IsEndOfRound(ackedPacketNumber) {
+	if s.started && s.IsEndOfRound(ackedPacketNumber) {
 		s.started = false
+		if s.inCSS {
+			s.cssRounds++
+		}
 	}
 }
 
@@ -108,5 +123,9 @@ func (s *HybridSlowStart) Started() bool {
 // Restart the slow start phase
 func (s *HybridSlowStart) Restart() {
 	s.started = false
-	s.hystartFound = false
+	s.lastRoundMinRTT = 0
+	s.currentRoundMinRTT = 0
+	s.inCSS = false
+	s.cssBaselineMinRTT = 0
+	s.cssRounds = 0
 }
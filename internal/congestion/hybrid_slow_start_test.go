@@ -41,28 +41,107 @@ func TestHybridSlowStartSimpleCase(t *testing.T) {
 	require.True(t, slowStart.IsEndOfRound(packetNumber))
 }
 
-func TestHybridSlowStartWithDelay(t *testing.T) {
-	slowStart := HybridSlowStart{}
+// runHybridSlowStartRound simulates one round of slow start, with n RTT samples
+func runHybridSlowStartRound(s *HybridSlowStart, pn *protocol.PacketNumber, n int, rtt time.Duration) (exit bool) {
+	for range n {
+		*pn++
+		s.OnPacketSent(*pn)
+	}
+	for range n {
+		if s.ShouldExitSlowStart(rtt) {
+			exit = true
+		}
+	}
+	// acknowledge the last packet sent in this round, and the first packet of the next round
+	s.OnPacketAcked(*pn)
+	s.OnPacketAcked(*pn + 1)
+	return exit
+}
+
+func TestHybridSlowStartConservativeSlowStart(t *testing.T) {
+	var s HybridSlowStart
 	const rtt = 60 * time.Millisecond
-	// We expect to detect the increase at +1/8 of the RTT; hence at a typical
-	// RTT of 60ms the detection will happen at 67.5 ms.
-	const hybridStartMinSamples = 8 // Number of acks required to trigger.
+	var pn protocol.PacketNumber
 
-	endPacketNumber := protocol.PacketNumber(1)
-	endPacketNumber++
-	slowStart.StartReceiveRound(endPacketNumber)
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt))
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt))
+	require.False(t, s.InConservativeSlowStart())
+	// An increase by 1/8th of the RTT (7.5ms) is tolerated...
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt+7*time.Millisecond))
+	require.False(t, s.InConservativeSlowStart())
+	// ... but an increase by more than that (compared to the last round) triggers Conservative Slow Start
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt+16*time.Millisecond))
+	require.True(t, s.InConservativeSlowStart())
 
-	// Will not trigger since our lowest RTT in our burst is the same as the long
-	// term RTT provided.
-	for n := 0; n < hybridStartMinSamples; n++ {
-		require.False(t, slowStart.ShouldExitSlowStart(rtt+time.Duration(n)*time.Millisecond, rtt, 100))
+	// After hybridStartCSSRounds rounds, slow start is exited.
+	for range hybridStartCSSRounds - 1 {
+		require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt+16*time.Millisecond))
+		require.True(t, s.InConservativeSlowStart())
 	}
-	endPacketNumber++
-	slowStart.StartReceiveRound(endPacketNumber)
-	for n := 1; n < hybridStartMinSamples; n++ {
-		require.False(t, slowStart.ShouldExitSlowStart(rtt+(time.Duration(n)+10)*time.Millisecond, rtt, 100))
+	require.True(t, runHybridSlowStartRound(&s, &pn, 10, rtt+16*time.Millisecond))
+}
+
+func TestHybridSlowStartSpuriousRTTIncrease(t *testing.T) {
+	var s HybridSlowStart
+	const rtt = 60 * time.Millisecond
+	var pn protocol.PacketNumber
+
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt))
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt+10*time.Millisecond))
+	require.True(t, s.InConservativeSlowStart())
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt+10*time.Millisecond))
+	require.True(t, s.InConservativeSlowStart())
+	// the RTT decreases below the CSS baseline: resume slow start
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt+5*time.Millisecond))
+	require.False(t, s.InConservativeSlowStart())
+}
+
+func TestHybridSlowStartMinSamples(t *testing.T) {
+	var s HybridSlowStart
+	const rtt = 60 * time.Millisecond
+	var pn protocol.PacketNumber
+
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt))
+	// not enough samples in this round to detect the RTT increase
+	require.False(t, runHybridSlowStartRound(&s, &pn, int(hybridStartMinSamples)-1, 2*rtt))
+	require.False(t, s.InConservativeSlowStart())
+}
+
+func TestHybridSlowStartRTTThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		rtt        time.Duration
+		noCSS, css time.Duration // RTT increases that don't / do trigger CSS
+	}{
+		{name: "low RTT", rtt: 8 * time.Millisecond, noCSS: 3 * time.Millisecond, css: 4 * time.Millisecond},
+		{name: "high RTT", rtt: 400 * time.Millisecond, noCSS: 15 * time.Millisecond, css: 16 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var s HybridSlowStart
+			var pn protocol.PacketNumber
+			require.False(t, runHybridSlowStartRound(&s, &pn, 10, tc.rtt))
+			require.False(t, runHybridSlowStartRound(&s, &pn, 10, tc.rtt+tc.noCSS))
+			require.False(t, s.InConservativeSlowStart())
+			s.Restart()
+			require.False(t, runHybridSlowStartRound(&s, &pn, 10, tc.rtt))
+			require.False(t, runHybridSlowStartRound(&s, &pn, 10, tc.rtt+tc.css))
+			require.True(t, s.InConservativeSlowStart())
+		})
 	}
-	// Expect to trigger since all packets in this burst was above the long term
-	// RTT provided.
-	require.True(t, slowStart.ShouldExitSlowStart(rtt+10*time.Millisecond, rtt, 100))
+}
+
+func TestHybridSlowStartRestart(t *testing.T) {
+	var s HybridSlowStart
+	const rtt = 60 * time.Millisecond
+	var pn protocol.PacketNumber
+
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, rtt))
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, 2*rtt))
+	require.True(t, s.InConservativeSlowStart())
+	s.Restart()
+	require.False(t, s.InConservativeSlowStart())
+	require.False(t, s.Started())
+	// the RTT samples from before the restart are not used
+	require.False(t, runHybridSlowStartRound(&s, &pn, 10, 3*rtt))
+	require.False(t, s.InConservativeSlowStart())
 }
@@ -35,6 +35,11 @@ func newPacer(getBandwidth func() Bandwidth) *pacer {
 	return p
 }
 
+// Rate returns the pacing rate, in bytes/s.
+func (p *pacer) Rate() uint64 {
+	return p.adjustedBandwidth()
+}
+
 func (p *pacer) SentPacket(sendTime monotime.Time, size protocol.ByteCount) {
 	budget := p.Budget(sendTime)
 	if size >= budget {
@@ -42,7 +47,11 @@ func (p *pacer) SentPacket(sendTime monotime.Time, size protocol.ByteCount) {
 	} else {
 		p.budgetAtLastSent = budget - size
 	}
-	p.lastSentTime = sendTime
+	// When using kernel pacing, packets are handed to the kernel ahead of time.
+	// Packets sent right away (e.g. ACK-only packets) must not move the last sent time backwards.
+	if sendTime.After(p.lastSentTime) {
+		p.lastSentTime = sendTime
+	}
 }
 
 func (p *pacer) Budget(now monotime.Time) protocol.ByteCount {
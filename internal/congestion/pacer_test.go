@@ -86,6 +86,27 @@ func TestPacerUpdatePacketSize(t *testing.T) {
 	require.Equal(t, maxBurstSizePackets*newDatagramSize, p.Budget(now.Add(time.Hour)))
 }
 
+func TestPacerPacketsSentAheadOfTime(t *testing.T) {
+	const bandwidth = 50 * initialMaxDatagramSize // 50 full-size packets per second
+	p := newPacer(func() Bandwidth { return Bandwidth(bandwidth) * BytesPerSecond * 4 / 5 })
+
+	// consume the initial budget by sending packets
+	now := monotime.Now()
+	for p.Budget(now) > 0 {
+		p.SentPacket(now, initialMaxDatagramSize)
+	}
+
+	// hand a packet to the kernel, to be sent at the time the pacer allows sending it
+	sendTime := p.TimeUntilSend()
+	require.Equal(t, time.Second/50, sendTime.Sub(now))
+	p.SentPacket(sendTime, initialMaxDatagramSize)
+	// now send a small packet right away
+	p.SentPacket(now, 100)
+	// the last sent time is not moved backwards
+	require.Zero(t, p.Budget(sendTime))
+	require.Equal(t, 2*time.Second/50, p.TimeUntilSend().Sub(now))
+}
+
 func TestPacerFastPacing(t *testing.T) {
 	const bandwidth = 10000 * initialMaxDatagramSize // 10,000 full-size packets per second
 	p := newPacer(func() Bandwidth { return Bandwidth(bandwidth) * BytesPerSecond * 4 / 5 })
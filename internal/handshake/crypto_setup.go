@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -32,6 +33,10 @@ type cryptoSetup struct {
 	events []Event
 
 	version protocol.Version
+	// The connection ID used to derive the Initial keys.
+	initialConnID protocol.ConnectionID
+	// Set by the server when it upgraded the connection to a compatible version (RFC 9368).
+	changedVersion bool
 
 	ourParams  *wire.TransportParameters
 	peerParams *wire.TransportParameters
@@ -39,6 +44,9 @@ type cryptoSetup struct {
 	zeroRTTParameters *wire.TransportParameters
 	allow0RTT         bool
 
+	keyUpdateInterval uint64
+	keyUpdatePeriod   time.Duration
+
 	rttStats *utils.RTTStats
 
 	qlogger qlogwriter.Recorder
@@ -53,6 +61,10 @@ type cryptoSetup struct {
 
 	initialOpener LongHeaderOpener
 	initialSealer LongHeaderSealer
+	// After compatible version negotiation, the server continues to accept Initial packets
+	// sent by the client using the original version.
+	originalVersion       protocol.Version
+	originalInitialOpener LongHeaderOpener // only set for the server
 
 	handshakeOpener LongHeaderOpener
 	handshakeSealer LongHeaderSealer
@@ -72,6 +84,8 @@ func NewCryptoSetupClient(
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	keyUpdateInterval uint64,
+	keyUpdatePeriod time.Duration,
 	rttStats *utils.RTTStats,
 	qlogger qlogwriter.Recorder,
 	logger utils.Logger,
@@ -80,6 +94,8 @@ func NewCryptoSetupClient(
 	cs := newCryptoSetup(
 		connID,
 		tp,
+		keyUpdateInterval,
+		keyUpdatePeriod,
 		rttStats,
 		qlogger,
 		logger,
@@ -108,6 +124,8 @@ func NewCryptoSetupServer(
 	tp *wire.TransportParameters,
 	tlsConf *tls.Config,
 	allow0RTT bool,
+	keyUpdateInterval uint64,
+	keyUpdatePeriod time.Duration,
 	rttStats *utils.RTTStats,
 	qlogger qlogwriter.Recorder,
 	logger utils.Logger,
@@ -116,6 +134,8 @@ func NewCryptoSetupServer(
 	cs := newCryptoSetup(
 		connID,
 		tp,
+		keyUpdateInterval,
+		keyUpdatePeriod,
 		rttStats,
 		qlogger,
 		logger,
@@ -137,41 +157,46 @@ func NewCryptoSetupServer(
 func newCryptoSetup(
 	connID protocol.ConnectionID,
 	tp *wire.TransportParameters,
+	keyUpdateInterval uint64,
+	keyUpdatePeriod time.Duration,
 	rttStats *utils.RTTStats,
 	qlogger qlogwriter.Recorder,
 	logger utils.Logger,
 	perspective protocol.Perspective,
 	version protocol.Version,
 ) *cryptoSetup {
-	initialSealer, initialOpener := NewInitialAEAD(connID, perspective, version)
-	if qlogger != nil {
-		qlogger.RecordEvent(qlog.KeyUpdated{
-			Trigger: qlog.KeyUpdateTLS,
-			KeyType: encLevelToKeyType(protocol.EncryptionInitial, protocol.PerspectiveClient),
-		})
-		qlogger.RecordEvent(qlog.KeyUpdated{
-			Trigger: qlog.KeyUpdateTLS,
-			KeyType: encLevelToKeyType(protocol.EncryptionInitial, protocol.PerspectiveServer),
-		})
-	}
-	return &cryptoSetup{
-		initialSealer: initialSealer,
-		initialOpener: initialOpener,
-		aead:          newUpdatableAEAD(rttStats, qlogger, logger, version),
-		events:        make([]Event, 0, 16),
-		ourParams:     tp,
-		rttStats:      rttStats,
-		qlogger:       qlogger,
-		logger:        logger,
-		perspective:   perspective,
-		version:       version,
-	}
+	h := &cryptoSetup{
+		aead:              newUpdatableAEAD(rttStats, keyUpdateInterval, keyUpdatePeriod, qlogger, logger, version),
+		events:            make([]Event, 0, 16),
+		ourParams:         tp,
+		keyUpdateInterval: keyUpdateInterval,
+		keyUpdatePeriod:   keyUpdatePeriod,
+		rttStats:          rttStats,
+		qlogger:           qlogger,
+		logger:            logger,
+		perspective:       perspective,
+		version:           version,
+		initialConnID:     connID,
+	}
+	h.deriveInitialKeys()
+	return h
 }
 
 func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
-	initialSealer, initialOpener := NewInitialAEAD(id, h.perspective, h.version)
-	h.initialSealer = initialSealer
-	h.initialOpener = initialOpener
+	h.initialConnID = id
+	h.deriveInitialKeys()
+}
+
+// ChangeVersion changes the QUIC version used to protect packets.
+// It must be called before the Handshake keys are derived.
+func (h *cryptoSetup) ChangeVersion(v protocol.Version) {
+	h.version = v
+	h.aead = newUpdatableAEAD(h.rttStats, h.keyUpdateInterval, h.keyUpdatePeriod, h.qlogger, h.logger, v)
+	h.deriveInitialKeys()
+}
+
+func (h *cryptoSetup) deriveInitialKeys() {
+	h.initialSealer, h.initialOpener = NewInitialAEAD(h.initialConnID, h.perspective, h.version)
 	if h.qlogger != nil {
 		h.qlogger.RecordEvent(qlog.KeyUpdated{
 			Trigger: qlog.KeyUpdateTLS,
@@ -319,10 +344,44 @@ func (h *cryptoSetup) handleTransportParameters(data []byte) error {
 		return err
 	}
 	h.peerParams = &tp
+	if h.perspective == protocol.PerspectiveServer {
+		h.negotiateVersion(&tp)
+	}
 	h.events = append(h.events, Event{Kind: EventReceivedTransportParameters, TransportParameters: h.peerParams})
 	return nil
 }
 
+// negotiateVersion performs compatible version negotiation (RFC 9368).
+// It is called for the server when receiving the client's transport parameters,
+// i.e. before the server's transport parameters are sent and before the Handshake keys are derived.
+func (h *cryptoSetup) negotiateVersion(tp *wire.TransportParameters) {
+	if h.ourParams.VersionInformation == nil || tp.VersionInformation == nil {
+		return
+	}
+	// If the client's chosen version doesn't match the version of its Initial packets,
+	// the connection is closed when the transport parameters are validated.
+	if tp.VersionInformation.ChosenVersion != h.version {
+		return
+	}
+	// The server's available versions are sorted by the server's preference.
+	for _, v := range h.ourParams.VersionInformation.AvailableVersions {
+		if !protocol.IsCompatibleVersion(h.version, v) || !slices.Contains(tp.VersionInformation.AvailableVersions, v) {
+			continue
+		}
+		if v == h.version {
+			return
+		}
+		h.logger.Debugf("Upgrading connection from %s to compatible version %s.", h.version, v)
+		h.originalVersion = h.version
+		h.originalInitialOpener = h.initialOpener
+		h.ChangeVersion(v)
+		h.changedVersion = true
+		h.ourParams.VersionInformation.ChosenVersion = v
+		h.events = append(h.events, Event{Kind: EventChangedVersion, Version: v})
+		return
+	}
+}
+
 // must be called after receiving the transport parameters
 func (h *cryptoSetup) marshalDataForSessionState(earlyData bool) []byte {
 	b := make([]byte, 0, 256)
@@ -427,6 +486,11 @@ func (h *cryptoSetup) handleSessionTicket(data []byte, using0RTT bool) (allowEar
 	if !using0RTT {
 		return false
 	}
+	// 0-RTT packets were sent using the original version.
+	if h.changedVersion {
+		h.logger.Debugf("Upgraded to a compatible version. Rejecting 0-RTT.")
+		return false
+	}
 	valid := h.ourParams.ValidFor0RTT(t.Parameters)
 	if !valid {
 		h.logger.Debugf("Transport parameters changed. Rejecting 0-RTT.")
@@ -569,6 +633,7 @@ func (h *cryptoSetup) DiscardInitialKeys() {
 	dropped := h.initialOpener != nil
 	h.initialOpener = nil
 	h.initialSealer = nil
+	h.originalInitialOpener = nil
 	if dropped {
 		h.logger.Debugf("Dropping Initial keys.")
 		if h.qlogger != nil {
@@ -601,6 +666,12 @@ func (h *cryptoSetup) SetHandshakeConfirmed() {
 	}
 }
 
+// InitiateKeyUpdate requests a key update of the 1-RTT keys.
+// It returns an error if the handshake is not yet confirmed, or if a key update is still in progress.
+func (h *cryptoSetup) InitiateKeyUpdate() error {
+	return h.aead.InitiateKeyUpdate()
+}
+
 func (h *cryptoSetup) GetInitialSealer() (LongHeaderSealer, error) {
 	if h.initialSealer == nil {
 		return nil, ErrKeysDropped
@@ -632,10 +703,13 @@ func (h *cryptoSetup) Get1RTTSealer() (ShortHeaderSealer, error) {
 	return h.aead, nil
 }
 
-func (h *cryptoSetup) GetInitialOpener() (LongHeaderOpener, error) {
+func (h *cryptoSetup) GetInitialOpener(v protocol.Version) (LongHeaderOpener, error) {
 	if h.initialOpener == nil {
 		return nil, ErrKeysDropped
 	}
+	if v != h.version && h.originalInitialOpener != nil && v == h.originalVersion {
+		return h.originalInitialOpener, nil
+	}
 	return h.initialOpener, nil
 }
 
@@ -70,6 +70,8 @@ func TestErrorBeforeClientHelloGeneration(t *testing.T) {
 		&wire.TransportParameters{},
 		tlsConf,
 		false,
+		protocol.KeyUpdateInterval,
+		0,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -92,6 +94,8 @@ func TestMessageReceivedAtWrongEncryptionLevel(t *testing.T) {
 		&wire.TransportParameters{StatelessResetToken: &token},
 		testdata.GetTLSConfig(),
 		false,
+		protocol.KeyUpdateInterval,
+		0,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -190,6 +194,8 @@ func handshakeWithTLSConf(
 		clientTransportParameters,
 		clientConf,
 		enable0RTT,
+		protocol.KeyUpdateInterval,
+		0,
 		clientRTTStats,
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -207,6 +213,8 @@ func handshakeWithTLSConf(
 		serverTransportParameters,
 		serverConf,
 		enable0RTT,
+		protocol.KeyUpdateInterval,
+		0,
 		serverRTTStats,
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -283,6 +291,8 @@ func TestTransportParameters(t *testing.T) {
 		cTransportParameters,
 		clientConf,
 		false,
+		protocol.KeyUpdateInterval,
+		0,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -302,6 +312,8 @@ func TestTransportParameters(t *testing.T) {
 		sTransportParameters,
 		serverConf,
 		false,
+		protocol.KeyUpdateInterval,
+		0,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -566,3 +578,141 @@ func Test0RTTRejectionOnTransportParametersChanged(t *testing.T) {
 	require.False(t, server.ConnectionState().Used0RTT)
 	require.False(t, client.ConnectionState().Used0RTT)
 }
+
+func TestCompatibleVersionNegotiation(t *testing.T) {
+	t.Run("server prefers QUIC v2", func(t *testing.T) {
+		testCompatibleVersionNegotiation(t,
+			[]protocol.Version{protocol.Version1, protocol.Version2},
+			[]protocol.Version{protocol.Version2, protocol.Version1},
+			protocol.Version2,
+		)
+	})
+
+	t.Run("server prefers QUIC v1", func(t *testing.T) {
+		testCompatibleVersionNegotiation(t,
+			[]protocol.Version{protocol.Version1, protocol.Version2},
+			[]protocol.Version{protocol.Version1, protocol.Version2},
+			protocol.Version1,
+		)
+	})
+
+	t.Run("client doesn't support QUIC v2", func(t *testing.T) {
+		testCompatibleVersionNegotiation(t,
+			[]protocol.Version{protocol.Version1},
+			[]protocol.Version{protocol.Version2, protocol.Version1},
+			protocol.Version1,
+		)
+	})
+}
+
+func testCompatibleVersionNegotiation(t *testing.T, clientVersions, serverVersions []protocol.Version, expectedVersion protocol.Version) {
+	clientConf, serverConf := getTLSConfigs()
+	_, clientEvents, clientErr, server, serverEvents, serverErr := handshakeWithTLSConf(
+		t,
+		clientConf, serverConf,
+		utils.NewRTTStats(), utils.NewRTTStats(),
+		&wire.TransportParameters{
+			ActiveConnectionIDLimit: 2,
+			VersionInformation:      &wire.VersionInformation{ChosenVersion: protocol.Version1, AvailableVersions: clientVersions},
+		},
+		&wire.TransportParameters{
+			ActiveConnectionIDLimit: 2,
+			VersionInformation:      &wire.VersionInformation{ChosenVersion: protocol.Version1, AvailableVersions: serverVersions},
+		},
+		false,
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+
+	var changedVersion bool
+	for _, ev := range serverEvents {
+		if ev.Kind == EventChangedVersion {
+			require.Equal(t, expectedVersion, ev.Version)
+			changedVersion = true
+		}
+	}
+	require.Equal(t, expectedVersion != protocol.Version1, changedVersion)
+
+	var tp *wire.TransportParameters
+	for _, ev := range clientEvents {
+		if ev.Kind == EventReceivedTransportParameters {
+			tp = ev.TransportParameters
+		}
+	}
+	require.NotNil(t, tp)
+	require.NotNil(t, tp.VersionInformation)
+	require.Equal(t, expectedVersion, tp.VersionInformation.ChosenVersion)
+	require.Equal(t, serverVersions, tp.VersionInformation.AvailableVersions)
+
+	// the server's Initial keys are derived using the negotiated version
+	sealer, err := server.GetInitialSealer()
+	require.NoError(t, err)
+	expectedSealer, _ := NewInitialAEAD(protocol.ConnectionID{}, protocol.PerspectiveServer, expectedVersion)
+	require.Equal(t,
+		expectedSealer.Seal(nil, []byte("foobar"), 42, []byte("aad")),
+		sealer.Seal(nil, []byte("foobar"), 42, []byte("aad")),
+	)
+
+	// The server still accepts Initial packets that the client sends using the original version,
+	// as well as Initial packets using the negotiated version.
+	for _, v := range []protocol.Version{protocol.Version1, expectedVersion} {
+		clientSealer, _ := NewInitialAEAD(protocol.ConnectionID{}, protocol.PerspectiveClient, v)
+		opener, err := server.GetInitialOpener(v)
+		require.NoError(t, err)
+		data, err := opener.Open(nil, clientSealer.Seal(nil, []byte("foobar"), 42, []byte("aad")), 42, []byte("aad"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("foobar"), data)
+	}
+}
+
+func Test0RTTRejectionOnVersionChange(t *testing.T) {
+	clientConf, serverConf := getTLSConfigs()
+	csc := newMockClientSessionCache()
+	clientConf.ClientSessionCache = csc
+	client, _, clientErr, server, _, serverErr := handshakeWithTLSConf(
+		t,
+		clientConf, serverConf,
+		utils.NewRTTStats(), utils.NewRTTStats(),
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		&wire.TransportParameters{ActiveConnectionIDLimit: 2},
+		true,
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	select {
+	case <-csc.puts:
+	case <-time.After(time.Second):
+		t.Fatal("didn't receive a session ticket")
+	}
+	require.False(t, server.ConnectionState().DidResume)
+	require.False(t, client.ConnectionState().DidResume)
+
+	client, _, clientErr, server, serverEvents, serverErr := handshakeWithTLSConf(
+		t,
+		clientConf, serverConf,
+		utils.NewRTTStats(), utils.NewRTTStats(),
+		&wire.TransportParameters{
+			ActiveConnectionIDLimit: 2,
+			VersionInformation: &wire.VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.Version{protocol.Version1, protocol.Version2},
+			},
+		},
+		&wire.TransportParameters{
+			ActiveConnectionIDLimit: 2,
+			VersionInformation: &wire.VersionInformation{
+				ChosenVersion:     protocol.Version1,
+				AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+			},
+		},
+		true,
+	)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+	require.Contains(t, serverEvents, Event{Kind: EventChangedVersion, Version: protocol.Version2})
+
+	require.True(t, server.ConnectionState().DidResume)
+	require.True(t, client.ConnectionState().DidResume)
+	require.False(t, server.ConnectionState().Used0RTT)
+	require.False(t, client.ConnectionState().Used0RTT)
+}
@@ -21,6 +21,11 @@ var (
 	ErrKeysDropped = errors.New("CryptoSetup: keys were already dropped")
 	// ErrDecryptionFailed is returned when the AEAD fails to open the packet.
 	ErrDecryptionFailed = errors.New("decryption failed")
+	// ErrHandshakeNotConfirmed is returned when a key update is requested before the handshake is confirmed.
+	ErrHandshakeNotConfirmed = errors.New("handshake not yet confirmed")
+	// ErrKeyUpdateInProgress is returned when a key update is requested,
+	// but the peer hasn't acknowledged a packet sent with the current key phase yet.
+	ErrKeyUpdateInProgress = errors.New("key update in progress")
 )
 
 type headerDecryptor interface {
@@ -39,6 +44,10 @@ type ShortHeaderOpener interface {
 	headerDecryptor
 	DecodePacketNumber(wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber
 	Open(dst, src []byte, rcvTime monotime.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
+	// DecodePathPacketNumber and OpenPath are used for packets received on paths
+	// other than the initial path, when using the multipath extension.
+	DecodePathPacketNumber(pathID protocol.PathID, wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber
+	OpenPath(dst, src []byte, rcvTime monotime.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error)
 }
 
 // LongHeaderSealer seals a long header packet
@@ -52,6 +61,9 @@ type LongHeaderSealer interface {
 type ShortHeaderSealer interface {
 	LongHeaderSealer
 	KeyPhase() protocol.KeyPhaseBit
+	// SealPath seals a packet sent on a path other than the initial path,
+	// when using the multipath extension.
+	SealPath(dst, src []byte, pathID protocol.PathID, packetNumber protocol.PacketNumber, associatedData []byte) []byte
 }
 
 type ConnectionState struct {
@@ -79,6 +91,8 @@ const (
 	// EventRestoredTransportParameters contains the transport parameters restored from the session ticket.
 	// It is only used for the client.
 	EventRestoredTransportParameters
+	// EventChangedVersion signals that the server upgraded the connection to a compatible QUIC version.
+	EventChangedVersion
 	// EventHandshakeComplete signals that the TLS handshake was completed.
 	EventHandshakeComplete
 )
@@ -99,6 +113,8 @@ func (k EventKind) String() string {
 		return "EventReceivedTransportParameters"
 	case EventRestoredTransportParameters:
 		return "EventRestoredTransportParameters"
+	case EventChangedVersion:
+		return "EventChangedVersion"
 	case EventHandshakeComplete:
 		return "EventHandshakeComplete"
 	default:
@@ -111,6 +127,7 @@ type Event struct {
 	Kind                EventKind
 	Data                []byte
 	TransportParameters *wire.TransportParameters
+	Version             protocol.Version
 }
 
 // CryptoSetup handles the handshake and protecting / unprotecting packets
@@ -118,6 +135,7 @@ type CryptoSetup interface {
 	StartHandshake(context.Context) error
 	io.Closer
 	ChangeConnectionID(protocol.ConnectionID)
+	ChangeVersion(protocol.Version)
 	GetSessionTicket() ([]byte, error)
 
 	HandleMessage([]byte, protocol.EncryptionLevel) error
@@ -126,9 +144,10 @@ type CryptoSetup interface {
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	DiscardInitialKeys()
 	SetHandshakeConfirmed()
+	InitiateKeyUpdate() error
 	ConnectionState() ConnectionState
 
-	GetInitialOpener() (LongHeaderOpener, error)
+	GetInitialOpener(protocol.Version) (LongHeaderOpener, error)
 	GetHandshakeOpener() (LongHeaderOpener, error)
 	Get0RTTOpener() (LongHeaderOpener, error)
 	Get1RTTOpener() (ShortHeaderOpener, error)
@@ -44,12 +44,18 @@ type token struct {
 
 // A TokenGenerator generates tokens
 type TokenGenerator struct {
-	tokenProtector tokenProtector
+	tokenProtector *tokenProtector
 }
 
 // NewTokenGenerator initializes a new TokenGenerator
 func NewTokenGenerator(key TokenProtectorKey) *TokenGenerator {
-	return &TokenGenerator{tokenProtector: *newTokenProtector(key)}
+	return &TokenGenerator{tokenProtector: newTokenProtector(key)}
+}
+
+// RotateKey replaces the key used to encrypt new tokens.
+// Tokens encrypted using the previous key are accepted for the duration of the grace period.
+func (g *TokenGenerator) RotateKey(key TokenProtectorKey, gracePeriod time.Duration) {
+	g.tokenProtector.RotateKey(key, gracePeriod)
 }
 
 // NewRetryToken generates a new token for a Retry for a given source address
@@ -7,6 +7,10 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/monotime"
 
 	"golang.org/x/crypto/hkdf"
 )
@@ -18,7 +22,15 @@ const tokenNonceSize = 32
 
 // tokenProtector is used to create and verify a token
 type tokenProtector struct {
+	mx  sync.RWMutex
 	key TokenProtectorKey
+	// previous keys are accepted when decoding tokens until they expire
+	prevKeys []expiringTokenProtectorKey
+}
+
+type expiringTokenProtectorKey struct {
+	key    TokenProtectorKey
+	expiry monotime.Time
 }
 
 // newTokenProtector creates a source for source address tokens
@@ -26,13 +38,36 @@ func newTokenProtector(key TokenProtectorKey) *tokenProtector {
 	return &tokenProtector{key: key}
 }
 
+// RotateKey replaces the key used to encode new tokens.
+// Tokens encoded using the previous key are accepted for the duration of the grace period.
+func (s *tokenProtector) RotateKey(key TokenProtectorKey, gracePeriod time.Duration) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	now := monotime.Now()
+	prevKeys := s.prevKeys[:0]
+	for _, k := range s.prevKeys {
+		if k.expiry.After(now) {
+			prevKeys = append(prevKeys, k)
+		}
+	}
+	if gracePeriod > 0 {
+		prevKeys = append(prevKeys, expiringTokenProtectorKey{key: s.key, expiry: now.Add(gracePeriod)})
+	}
+	s.prevKeys = prevKeys
+	s.key = key
+}
+
 // NewToken encodes data into a new token.
 func (s *tokenProtector) NewToken(data []byte) ([]byte, error) {
 	var nonce [tokenNonceSize]byte
 	if _, err := rand.Read(nonce[:]); err != nil {
 		return nil, err
 	}
-	aead, aeadNonce, err := s.createAEAD(nonce[:])
+	s.mx.RLock()
+	key := s.key
+	s.mx.RUnlock()
+	aead, aeadNonce, err := createTokenAEAD(key, nonce[:])
 	if err != nil {
 		return nil, err
 	}
@@ -45,15 +80,43 @@ func (s *tokenProtector) DecodeToken(p []byte) ([]byte, error) {
 		return nil, fmt.Errorf("token too short: %d", len(p))
 	}
 	nonce := p[:tokenNonceSize]
-	aead, aeadNonce, err := s.createAEAD(nonce)
+
+	s.mx.RLock()
+	key := s.key
+	var prevKeys []TokenProtectorKey
+	if len(s.prevKeys) > 0 {
+		now := monotime.Now()
+		for _, k := range s.prevKeys {
+			if k.expiry.After(now) {
+				prevKeys = append(prevKeys, k.key)
+			}
+		}
+	}
+	s.mx.RUnlock()
+
+	data, err := openToken(key, nonce, p[tokenNonceSize:])
+	if err == nil {
+		return data, nil
+	}
+	// try the previous keys, starting with the most recent one
+	for i := len(prevKeys) - 1; i >= 0; i-- {
+		if data, prevErr := openToken(prevKeys[i], nonce, p[tokenNonceSize:]); prevErr == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+func openToken(key TokenProtectorKey, nonce, ciphertext []byte) ([]byte, error) {
+	aead, aeadNonce, err := createTokenAEAD(key, nonce)
 	if err != nil {
 		return nil, err
 	}
-	return aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+	return aead.Open(nil, aeadNonce, ciphertext, nil)
 }
 
-func (s *tokenProtector) createAEAD(nonce []byte) (cipher.AEAD, []byte, error) {
-	h := hkdf.New(sha256.New, s.key[:], nonce, []byte("quic-go token source"))
+func createTokenAEAD(secret TokenProtectorKey, nonce []byte) (cipher.AEAD, []byte, error) {
+	h := hkdf.New(sha256.New, secret[:], nonce, []byte("quic-go token source"))
 	key := make([]byte, 32) // use a 32 byte key, in order to select AES-256
 	if _, err := io.ReadFull(h, key); err != nil {
 		return nil, nil, err
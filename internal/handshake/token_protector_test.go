@@ -3,6 +3,9 @@ package handshake
 import (
 	"crypto/rand"
 	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/synctest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -45,6 +48,64 @@ func TestTokenProtectorDifferentKeys(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTokenProtectorKeyRotation(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var key1, key2, key3 TokenProtectorKey
+		rand.Read(key1[:])
+		rand.Read(key2[:])
+		rand.Read(key3[:])
+		tp := newTokenProtector(key1)
+
+		t1, err := tp.NewToken([]byte("foo"))
+		require.NoError(t, err)
+
+		tp.RotateKey(key2, 10*time.Second)
+		t2, err := tp.NewToken([]byte("bar"))
+		require.NoError(t, err)
+		// the new token is encrypted using the new key
+		_, err = newTokenProtector(key1).DecodeToken(t2)
+		require.Error(t, err)
+		_, err = newTokenProtector(key2).DecodeToken(t2)
+		require.NoError(t, err)
+
+		// tokens encrypted using the previous key are accepted during the grace period
+		decoded, err := tp.DecodeToken(t1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("foo"), decoded)
+
+		time.Sleep(5 * time.Second)
+		tp.RotateKey(key3, 10*time.Second)
+		decoded, err = tp.DecodeToken(t1)
+		require.NoError(t, err)
+		require.Equal(t, []byte("foo"), decoded)
+		decoded, err = tp.DecodeToken(t2)
+		require.NoError(t, err)
+		require.Equal(t, []byte("bar"), decoded)
+
+		// the first key expires after 10 seconds
+		time.Sleep(5 * time.Second)
+		_, err = tp.DecodeToken(t1)
+		require.Error(t, err)
+		_, err = tp.DecodeToken(t2)
+		require.NoError(t, err)
+
+		// the second key expires after another 5 seconds
+		time.Sleep(5 * time.Second)
+		_, err = tp.DecodeToken(t2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "message authentication failed")
+		require.Len(t, tp.prevKeys, 2) // expired keys are only removed on rotation
+
+		// rotating without a grace period immediately invalidates tokens encrypted using the current key
+		t3, err := tp.NewToken([]byte("baz"))
+		require.NoError(t, err)
+		tp.RotateKey(key1, 0)
+		_, err = tp.DecodeToken(t3)
+		require.Error(t, err)
+		require.Empty(t, tp.prevKeys)
+	})
+}
+
 func TestTokenProtectorInvalidTokens(t *testing.T) {
 	var key TokenProtectorKey
 	rand.Read(key[:])
@@ -6,7 +6,7 @@ import (
 	"crypto/tls"
 	"encoding/binary"
 	"fmt"
-	"sync/atomic"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -16,17 +16,6 @@ import (
 	"github.com/quic-go/quic-go/qlogwriter"
 )
 
-var keyUpdateInterval atomic.Uint64
-
-func init() {
-	keyUpdateInterval.Store(protocol.KeyUpdateInterval)
-}
-
-func SetKeyUpdateInterval(v uint64) (reset func()) {
-	old := keyUpdateInterval.Swap(v)
-	return func() { keyUpdateInterval.Store(old) }
-}
-
 // FirstKeyUpdateInterval is the maximum number of packets we send or receive before initiating the first key update.
 // It's a package-level variable to allow modifying it for testing purposes.
 var FirstKeyUpdateInterval uint64 = 100
@@ -39,6 +28,15 @@ type updatableAEAD struct {
 	firstPacketNumber  protocol.PacketNumber
 	handshakeConfirmed bool
 
+	// the maximum number of packets sent or received with the same key phase before initiating a key update
+	keyUpdateInterval uint64
+	// the maximum time the same key phase is used for before initiating a key update (0 if disabled)
+	keyUpdatePeriod time.Duration
+	// the time when the current keys were installed, only set if keyUpdatePeriod is set
+	keysInstalledTime monotime.Time
+	// set when the application requested a key update, reset when the keys are rolled
+	keyUpdateRequested bool
+
 	invalidPacketLimit uint64
 	invalidPacketCount uint64
 
@@ -49,10 +47,14 @@ type updatableAEAD struct {
 	firstRcvdWithCurrentKey protocol.PacketNumber
 	firstSentWithCurrentKey protocol.PacketNumber
 	highestRcvdPN           protocol.PacketNumber // highest packet number received (which could be successfully unprotected)
-	numRcvdWithCurrentKey   uint64
-	numSentWithCurrentKey   uint64
-	rcvAEAD                 cipher.AEAD
-	sendAEAD                cipher.AEAD
+	// When using the multipath extension, every path has its own packet number space.
+	// The fields above are used for the initial path, these maps are used for all other paths.
+	firstRcvdWithCurrentKeyOnPath map[protocol.PathID]protocol.PacketNumber // initialized lazily
+	highestRcvdPNOnPath           map[protocol.PathID]protocol.PacketNumber // initialized lazily
+	numRcvdWithCurrentKey         uint64
+	numSentWithCurrentKey         uint64
+	rcvAEAD                       cipher.AEAD
+	sendAEAD                      cipher.AEAD
 	// caches cipher.AEAD.Overhead(). This speeds up calls to Overhead().
 	aeadOverhead int
 
@@ -72,6 +74,8 @@ type updatableAEAD struct {
 
 	// use a single slice to avoid allocations
 	nonceBuf []byte
+	// the nonce used on paths other than the initial path: the path ID, followed by the packet number
+	pathNonceBuf [aeadNonceLength]byte
 }
 
 var (
@@ -79,8 +83,17 @@ var (
 	_ ShortHeaderSealer = &updatableAEAD{}
 )
 
-func newUpdatableAEAD(rttStats *utils.RTTStats, qlogger qlogwriter.Recorder, logger utils.Logger, version protocol.Version) *updatableAEAD {
+func newUpdatableAEAD(
+	rttStats *utils.RTTStats,
+	keyUpdateInterval uint64,
+	keyUpdatePeriod time.Duration,
+	qlogger qlogwriter.Recorder,
+	logger utils.Logger,
+	version protocol.Version,
+) *updatableAEAD {
 	return &updatableAEAD{
+		keyUpdateInterval:       keyUpdateInterval,
+		keyUpdatePeriod:         keyUpdatePeriod,
 		firstPacketNumber:       protocol.InvalidPacketNumber,
 		largestAcked:            protocol.InvalidPacketNumber,
 		firstRcvdWithCurrentKey: protocol.InvalidPacketNumber,
@@ -109,10 +122,15 @@ func (a *updatableAEAD) rollKeys() {
 	}
 
 	a.keyPhase++
+	a.keyUpdateRequested = false
 	a.firstRcvdWithCurrentKey = protocol.InvalidPacketNumber
+	clear(a.firstRcvdWithCurrentKeyOnPath)
 	a.firstSentWithCurrentKey = protocol.InvalidPacketNumber
 	a.numRcvdWithCurrentKey = 0
 	a.numSentWithCurrentKey = 0
+	if a.keyUpdatePeriod > 0 {
+		a.keysInstalledTime = monotime.Now()
+	}
 	a.prevRcvAEAD = a.rcvAEAD
 	a.rcvAEAD = a.nextRcvAEAD
 	a.sendAEAD = a.nextSendAEAD
@@ -159,6 +177,9 @@ func (a *updatableAEAD) SetWriteKey(suite cipherSuite, trafficSecret []byte) {
 
 	a.nextSendTrafficSecret = a.getNextTrafficSecret(suite.Hash, trafficSecret)
 	a.nextSendAEAD = createAEAD(suite, a.nextSendTrafficSecret, a.version)
+	if a.keyUpdatePeriod > 0 {
+		a.keysInstalledTime = monotime.Now()
+	}
 }
 
 func (a *updatableAEAD) setAEADParameters(aead cipher.AEAD, suite cipherSuite) {
@@ -179,8 +200,23 @@ func (a *updatableAEAD) DecodePacketNumber(wirePN protocol.PacketNumber, wirePNL
 	return protocol.DecodePacketNumber(wirePNLen, a.highestRcvdPN, wirePN)
 }
 
+func (a *updatableAEAD) DecodePathPacketNumber(pathID protocol.PathID, wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber {
+	if pathID == protocol.InitialPathID {
+		return a.DecodePacketNumber(wirePN, wirePNLen)
+	}
+	highest, ok := a.highestRcvdPNOnPath[pathID]
+	if !ok {
+		highest = 0
+	}
+	return protocol.DecodePacketNumber(wirePNLen, highest, wirePN)
+}
+
 func (a *updatableAEAD) Open(dst, src []byte, rcvTime monotime.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
-	dec, err := a.open(dst, src, rcvTime, pn, kp, ad)
+	return a.OpenPath(dst, src, rcvTime, protocol.InitialPathID, pn, kp, ad)
+}
+
+func (a *updatableAEAD) OpenPath(dst, src []byte, rcvTime monotime.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+	dec, err := a.open(dst, src, rcvTime, pathID, pn, kp, ad)
 	if err == ErrDecryptionFailed {
 		a.invalidPacketCount++
 		if a.invalidPacketCount >= a.invalidPacketLimit {
@@ -188,12 +224,54 @@ func (a *updatableAEAD) Open(dst, src []byte, rcvTime monotime.Time, pn protocol
 		}
 	}
 	if err == nil {
-		a.highestRcvdPN = max(a.highestRcvdPN, pn)
+		if pathID == protocol.InitialPathID {
+			a.highestRcvdPN = max(a.highestRcvdPN, pn)
+		} else {
+			if a.highestRcvdPNOnPath == nil {
+				a.highestRcvdPNOnPath = make(map[protocol.PathID]protocol.PacketNumber)
+			}
+			a.highestRcvdPNOnPath[pathID] = max(a.highestRcvdPNOnPath[pathID], pn)
+		}
 	}
 	return dec, err
 }
 
-func (a *updatableAEAD) open(dst, src []byte, rcvTime monotime.Time, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
+func (a *updatableAEAD) getFirstRcvdWithCurrentKey(pathID protocol.PathID) protocol.PacketNumber {
+	if pathID == protocol.InitialPathID {
+		return a.firstRcvdWithCurrentKey
+	}
+	if pn, ok := a.firstRcvdWithCurrentKeyOnPath[pathID]; ok {
+		return pn
+	}
+	return protocol.InvalidPacketNumber
+}
+
+func (a *updatableAEAD) setFirstRcvdWithCurrentKey(pathID protocol.PathID, pn protocol.PacketNumber) {
+	if pathID == protocol.InitialPathID {
+		a.firstRcvdWithCurrentKey = pn
+		return
+	}
+	if a.firstRcvdWithCurrentKeyOnPath == nil {
+		a.firstRcvdWithCurrentKeyOnPath = make(map[protocol.PathID]protocol.PacketNumber)
+	}
+	a.firstRcvdWithCurrentKeyOnPath[pathID] = pn
+}
+
+// nonce returns the nonce used for a packet.
+// For the initial path, this is the packet number.
+// For all other paths, it's the path ID, followed by the packet number,
+// see section 2.4 of draft-ietf-quic-multipath.
+func (a *updatableAEAD) nonce(pathID protocol.PathID, pn protocol.PacketNumber) []byte {
+	if pathID == protocol.InitialPathID {
+		binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+		return a.nonceBuf
+	}
+	binary.BigEndian.PutUint32(a.pathNonceBuf[:4], uint32(pathID))
+	binary.BigEndian.PutUint64(a.pathNonceBuf[4:], uint64(pn))
+	return a.pathNonceBuf[:]
+}
+
+func (a *updatableAEAD) open(dst, src []byte, rcvTime monotime.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, ad []byte) ([]byte, error) {
 	if a.prevRcvAEAD != nil && !a.prevRcvAEADExpiry.IsZero() && rcvTime.After(a.prevRcvAEADExpiry) {
 		a.prevRcvAEAD = nil
 		a.logger.Debugf("Dropping key phase %d", a.keyPhase-1)
@@ -209,26 +287,27 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime monotime.Time, pn protocol
 			})
 		}
 	}
-	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
+	nonce := a.nonce(pathID, pn)
+	firstRcvdWithCurrentKey := a.getFirstRcvdWithCurrentKey(pathID)
 	if kp != a.keyPhase.Bit() {
-		if a.keyPhase > 0 && a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber || pn < a.firstRcvdWithCurrentKey {
+		if a.keyPhase > 0 && firstRcvdWithCurrentKey == protocol.InvalidPacketNumber || pn < firstRcvdWithCurrentKey {
 			if a.prevRcvAEAD == nil {
 				return nil, ErrKeysDropped
 			}
 			// we updated the key, but the peer hasn't updated yet
-			dec, err := a.prevRcvAEAD.Open(dst, a.nonceBuf, src, ad)
+			dec, err := a.prevRcvAEAD.Open(dst, nonce, src, ad)
 			if err != nil {
 				err = ErrDecryptionFailed
 			}
 			return dec, err
 		}
 		// try opening the packet with the next key phase
-		dec, err := a.nextRcvAEAD.Open(dst, a.nonceBuf, src, ad)
+		dec, err := a.nextRcvAEAD.Open(dst, nonce, src, ad)
 		if err != nil {
 			return nil, ErrDecryptionFailed
 		}
 		// Opening succeeded. Check if the peer was allowed to update.
-		if a.keyPhase > 0 && a.firstSentWithCurrentKey == protocol.InvalidPacketNumber {
+		if a.keyPhase > 0 && a.numSentWithCurrentKey == 0 {
 			return nil, &qerr.TransportError{
 				ErrorCode:    qerr.KeyUpdateError,
 				ErrorMessage: "keys updated too quickly",
@@ -251,24 +330,25 @@ func (a *updatableAEAD) open(dst, src []byte, rcvTime monotime.Time, pn protocol
 				KeyPhase: a.keyPhase,
 			})
 		}
-		a.firstRcvdWithCurrentKey = pn
+		a.setFirstRcvdWithCurrentKey(pathID, pn)
 		return dec, err
 	}
 	// The AEAD we're using here will be the qtls.aeadAESGCM13.
 	// It uses the nonce provided here and XOR it with the IV.
-	dec, err := a.rcvAEAD.Open(dst, a.nonceBuf, src, ad)
+	dec, err := a.rcvAEAD.Open(dst, nonce, src, ad)
 	if err != nil {
 		return dec, ErrDecryptionFailed
 	}
 	a.numRcvdWithCurrentKey++
-	if a.firstRcvdWithCurrentKey == protocol.InvalidPacketNumber {
+	if firstRcvdWithCurrentKey == protocol.InvalidPacketNumber {
 		// We initiated the key updated, and now we received the first packet protected with the new key phase.
 		// Therefore, we are certain that the peer rolled its keys as well. Start a timer to drop the old keys.
-		if a.keyPhase > 0 {
+		// When using multiple paths, the timer is only started for the first path on which this happens.
+		if a.keyPhase > 0 && a.prevRcvAEADExpiry.IsZero() {
 			a.logger.Debugf("Peer confirmed key update to phase %d", a.keyPhase)
 			a.startKeyDropTimer(rcvTime)
 		}
-		a.firstRcvdWithCurrentKey = pn
+		a.setFirstRcvdWithCurrentKey(pathID, pn)
 	}
 	return dec, err
 }
@@ -281,10 +361,20 @@ func (a *updatableAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byt
 		a.firstPacketNumber = pn
 	}
 	a.numSentWithCurrentKey++
-	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
 	// The AEAD we're using here will be the qtls.aeadAESGCM13.
 	// It uses the nonce provided here and XOR it with the IV.
-	return a.sendAEAD.Seal(dst, a.nonceBuf, src, ad)
+	return a.sendAEAD.Seal(dst, a.nonce(protocol.InitialPathID, pn), src, ad)
+}
+
+// SealPath seals a packet sent on a path other than the initial path.
+// Packets sent on these paths count towards the key update interval,
+// but acknowledgements for the initial path are required before initiating subsequent key updates.
+func (a *updatableAEAD) SealPath(dst, src []byte, pathID protocol.PathID, pn protocol.PacketNumber, ad []byte) []byte {
+	if pathID == protocol.InitialPathID {
+		return a.Seal(dst, src, pn, ad)
+	}
+	a.numSentWithCurrentKey++
+	return a.sendAEAD.Seal(dst, a.nonce(pathID, pn), src, ad)
 }
 
 func (a *updatableAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
@@ -319,23 +409,44 @@ func (a *updatableAEAD) shouldInitiateKeyUpdate() bool {
 	if !a.updateAllowed() {
 		return false
 	}
+	if a.keyUpdateRequested {
+		a.logger.Debugf("Initiating requested key update to the next key phase: %d", a.keyPhase+1)
+		return true
+	}
 	// Initiate the first key update shortly after the handshake, in order to exercise the key update mechanism.
 	if a.keyPhase == 0 {
 		if a.numRcvdWithCurrentKey >= FirstKeyUpdateInterval || a.numSentWithCurrentKey >= FirstKeyUpdateInterval {
 			return true
 		}
 	}
-	if a.numRcvdWithCurrentKey >= keyUpdateInterval.Load() {
+	if a.numRcvdWithCurrentKey >= a.keyUpdateInterval {
 		a.logger.Debugf("Received %d packets with current key phase. Initiating key update to the next key phase: %d", a.numRcvdWithCurrentKey, a.keyPhase+1)
 		return true
 	}
-	if a.numSentWithCurrentKey >= keyUpdateInterval.Load() {
+	if a.numSentWithCurrentKey >= a.keyUpdateInterval {
 		a.logger.Debugf("Sent %d packets with current key phase. Initiating key update to the next key phase: %d", a.numSentWithCurrentKey, a.keyPhase+1)
 		return true
 	}
+	if a.keyUpdatePeriod > 0 && monotime.Since(a.keysInstalledTime) >= a.keyUpdatePeriod {
+		a.logger.Debugf("Used current key phase for more than %s. Initiating key update to the next key phase: %d", a.keyUpdatePeriod, a.keyPhase+1)
+		return true
+	}
 	return false
 }
 
+// InitiateKeyUpdate requests a key update.
+// The keys are rolled when the next packet is sent.
+func (a *updatableAEAD) InitiateKeyUpdate() error {
+	if !a.handshakeConfirmed {
+		return ErrHandshakeNotConfirmed
+	}
+	if a.keyUpdateRequested || !a.updateAllowed() {
+		return ErrKeyUpdateInProgress
+	}
+	a.keyUpdateRequested = true
+	return nil
+}
+
 func (a *updatableAEAD) KeyPhase() protocol.KeyPhaseBit {
 	if a.shouldInitiateKeyUpdate() {
 		a.rollKeys()
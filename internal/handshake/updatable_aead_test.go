@@ -11,6 +11,7 @@ import (
 	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/synctest"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/qlog"
 	"github.com/quic-go/quic-go/qlogwriter"
@@ -26,7 +27,7 @@ const (
 
 func randomCipherSuite() cipherSuite { return cipherSuites[mrand.IntN(len(cipherSuites))] }
 
-func setupEndpoints(t *testing.T, serverRTTStats *utils.RTTStats) (client, server *updatableAEAD, serverEventRecorder *events.Recorder) {
+func setupEndpoints(t *testing.T, serverRTTStats *utils.RTTStats, keyUpdateInterval uint64, keyUpdatePeriod time.Duration) (client, server *updatableAEAD, serverEventRecorder *events.Recorder) {
 	cs := randomCipherSuite()
 	var eventRecorder events.Recorder
 
@@ -35,8 +36,8 @@ func setupEndpoints(t *testing.T, serverRTTStats *utils.RTTStats) (client, serve
 	rand.Read(trafficSecret1)
 	rand.Read(trafficSecret2)
 
-	client = newUpdatableAEAD(utils.NewRTTStats(), nil, utils.DefaultLogger, protocol.Version1)
-	server = newUpdatableAEAD(serverRTTStats, &eventRecorder, utils.DefaultLogger, protocol.Version1)
+	client = newUpdatableAEAD(utils.NewRTTStats(), keyUpdateInterval, keyUpdatePeriod, nil, utils.DefaultLogger, protocol.Version1)
+	server = newUpdatableAEAD(serverRTTStats, keyUpdateInterval, keyUpdatePeriod, &eventRecorder, utils.DefaultLogger, protocol.Version1)
 	client.SetReadKey(cs, trafficSecret2)
 	client.SetWriteKey(cs, trafficSecret1)
 	server.SetReadKey(cs, trafficSecret1)
@@ -97,7 +98,7 @@ func TestChaChaTestVector(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(fmt.Sprintf("QUIC %s", tc.version), func(t *testing.T) {
 			secret := splitHexString(t, "9ac312a7f877468ebe69422748ad00a1 5443f18203a07d6060f688f30f21632b")
-			aead := newUpdatableAEAD(utils.NewRTTStats(), nil, nil, tc.version)
+			aead := newUpdatableAEAD(utils.NewRTTStats(), protocol.KeyUpdateInterval, 0, nil, nil, tc.version)
 			chacha := cipherSuites[2]
 			require.Equal(t, tls.TLS_CHACHA20_POLY1305_SHA256, chacha.ID)
 			aead.SetWriteKey(chacha, secret)
@@ -123,8 +124,8 @@ func TestUpdatableAEADHeaderProtection(t *testing.T) {
 				rand.Read(trafficSecret1)
 				rand.Read(trafficSecret2)
 
-				client := newUpdatableAEAD(utils.NewRTTStats(), nil, utils.DefaultLogger, v)
-				server := newUpdatableAEAD(utils.NewRTTStats(), nil, utils.DefaultLogger, v)
+				client := newUpdatableAEAD(utils.NewRTTStats(), protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, v)
+				server := newUpdatableAEAD(utils.NewRTTStats(), protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, v)
 				client.SetReadKey(cs, trafficSecret2)
 				client.SetWriteKey(cs, trafficSecret1)
 				server.SetReadKey(cs, trafficSecret1)
@@ -161,8 +162,8 @@ func TestUpdatableAEADEncryptDecryptMessage(t *testing.T) {
 				rand.Read(trafficSecret1)
 				rand.Read(trafficSecret2)
 
-				client := newUpdatableAEAD(&rttStats, nil, utils.DefaultLogger, v)
-				server := newUpdatableAEAD(&rttStats, nil, utils.DefaultLogger, v)
+				client := newUpdatableAEAD(&rttStats, protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, v)
+				server := newUpdatableAEAD(&rttStats, protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, v)
 				client.SetReadKey(cs, trafficSecret2)
 				client.SetWriteKey(cs, trafficSecret1)
 				server.SetReadKey(cs, trafficSecret1)
@@ -188,7 +189,7 @@ func TestUpdatableAEADEncryptDecryptMessage(t *testing.T) {
 }
 
 func TestUpdatableAEADPacketNumbers(t *testing.T) {
-	client, server, _ := setupEndpoints(t, utils.NewRTTStats())
+	client, server, _ := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 	msg := []byte("Lorem ipsum")
 	ad := []byte("Donec in velit neque.")
 
@@ -207,8 +208,64 @@ func TestUpdatableAEADPacketNumbers(t *testing.T) {
 	require.Equal(t, protocol.PacketNumber(0x1338), client.DecodePacketNumber(0x38, protocol.PacketNumberLen1))
 }
 
+func TestUpdatableAEADMultipath(t *testing.T) {
+	client, server, _ := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
+
+	encrypted0 := server.Seal(nil, []byte(msg), 0x1337, []byte(ad))
+	encrypted1 := server.SealPath(nil, []byte(msg), 1, 0x1337, []byte(ad))
+	encrypted2 := server.SealPath(nil, []byte(msg), 2, 0x1337, []byte(ad))
+	// the path ID is part of the nonce
+	require.NotEqual(t, encrypted0, encrypted1)
+	require.NotEqual(t, encrypted1, encrypted2)
+	// sealing on path 0 is the same as sealing without a path ID
+	require.Equal(t, encrypted0, server.SealPath(nil, []byte(msg), 0, 0x1337, []byte(ad)))
+
+	_, err := client.OpenPath(nil, encrypted1, monotime.Now(), 2, 0x1337, protocol.KeyPhaseZero, []byte(ad))
+	require.Equal(t, ErrDecryptionFailed, err)
+	decrypted, err := client.OpenPath(nil, encrypted1, monotime.Now(), 1, 0x1337, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+
+	// every path has its own packet number space
+	require.Equal(t, protocol.PacketNumber(0x1338), client.DecodePathPacketNumber(1, 0x38, protocol.PacketNumberLen1))
+	require.Equal(t, protocol.PacketNumber(0x38), client.DecodePathPacketNumber(2, 0x38, protocol.PacketNumberLen1))
+	require.Equal(t, protocol.PacketNumber(0x38), client.DecodePacketNumber(0x38, protocol.PacketNumberLen1))
+}
+
+func TestUpdatableAEADMultipathKeyUpdate(t *testing.T) {
+	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
+
+	now := monotime.Now()
+	encrypted01 := client.SealPath(nil, []byte(msg), 1, 0x42, []byte(ad))
+	encrypted02 := client.SealPath(nil, []byte(msg), 1, 0x43, []byte(ad))
+	encrypted2 := client.SealPath(nil, []byte(msg), 2, 0x10, []byte(ad))
+	_, err := server.OpenPath(nil, encrypted01, now, 1, 0x42, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	_ = server.SealPath(nil, []byte(msg), 1, 0x1, []byte(ad))
+
+	// the client updates its keys, and the server receives a packet with the next key phase on path 1
+	client.rollKeys()
+	encrypted1 := client.SealPath(nil, []byte(msg), 1, 0x44, []byte(ad))
+	_, err = server.OpenPath(nil, encrypted1, now, 1, 0x44, protocol.KeyPhaseOne, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, protocol.KeyPhaseOne, server.KeyPhase())
+	require.Equal(t,
+		bothSides(qlog.KeyUpdated{Trigger: qlog.KeyUpdateRemote, KeyPhase: 1}),
+		eventRecorder.Events(),
+	)
+
+	// a reordered packet on path 1 is decrypted using the previous key phase
+	decrypted, err := server.OpenPath(nil, encrypted02, now, 1, 0x43, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+	// so is a packet on path 2, which hasn't seen a packet with the new key phase yet
+	decrypted, err = server.OpenPath(nil, encrypted2, now, 2, 0x10, protocol.KeyPhaseZero, []byte(ad))
+	require.NoError(t, err)
+	require.Equal(t, msg, string(decrypted))
+}
+
 func TestAEADLimitReached(t *testing.T) {
-	client, _, _ := setupEndpoints(t, utils.NewRTTStats())
+	client, _, _ := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 	client.invalidPacketLimit = 10
 	for i := 0; i < 9; i++ {
 		_, err := client.Open(nil, []byte("foobar"), monotime.Now(), protocol.PacketNumber(i), protocol.KeyPhaseZero, []byte("ad"))
@@ -222,7 +279,7 @@ func TestAEADLimitReached(t *testing.T) {
 }
 
 func TestKeyUpdates(t *testing.T) {
-	client, server, _ := setupEndpoints(t, utils.NewRTTStats())
+	client, server, _ := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 
 	now := monotime.Now()
 	require.Equal(t, protocol.KeyPhaseZero, server.KeyPhase())
@@ -251,8 +308,8 @@ func TestKeyUpdates(t *testing.T) {
 // 	rand.Read(trafficSecret1)
 // 	rand.Read(trafficSecret2)
 
-// 	client := newUpdatableAEAD(&rttStats, nil, utils.DefaultLogger, protocol.Version1)
-// 	server := newUpdatableAEAD(&rttStats, serverTracer, utils.DefaultLogger, protocol.Version1)
+// 	client := newUpdatableAEAD(&rttStats, protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, protocol.Version1)
+// 	server := newUpdatableAEAD(&rttStats, protocol.KeyUpdateInterval, 0, serverTracer, utils.DefaultLogger, protocol.Version1)
 // 	client.SetReadKey(cs, trafficSecret2)
 // 	client.SetWriteKey(cs, trafficSecret1)
 // 	server.SetReadKey(cs, trafficSecret1)
@@ -277,7 +334,7 @@ func TestKeyUpdates(t *testing.T) {
 // }
 
 func TestReorderedPacketAfterKeyUpdate(t *testing.T) {
-	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats())
+	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 
 	now := monotime.Now()
 	encrypted01 := client.Seal(nil, []byte(msg), 0x42, []byte(ad))
@@ -306,7 +363,7 @@ func TestReorderedPacketAfterKeyUpdate(t *testing.T) {
 
 func TestDropsKeys3PTOsAfterKeyUpdate(t *testing.T) {
 	rttStats := utils.NewRTTStats()
-	client, server, eventRecorder := setupEndpoints(t, rttStats)
+	client, server, eventRecorder := setupEndpoints(t, rttStats, protocol.KeyUpdateInterval, 0)
 
 	now := monotime.Now()
 	rttStats.UpdateRTT(10*time.Millisecond, 0)
@@ -339,7 +396,7 @@ func TestDropsKeys3PTOsAfterKeyUpdate(t *testing.T) {
 }
 
 func TestAllowsFirstKeyUpdateImmediately(t *testing.T) {
-	client, server, serverTracer := setupEndpoints(t, utils.NewRTTStats())
+	client, server, serverTracer := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 	client.rollKeys()
 	encrypted := client.Seal(nil, []byte(msg), 0x1337, []byte(ad))
 
@@ -357,7 +414,7 @@ func TestAllowsFirstKeyUpdateImmediately(t *testing.T) {
 }
 
 func TestRejectFrequentKeyUpdates(t *testing.T) {
-	client, server, _ := setupEndpoints(t, utils.NewRTTStats())
+	client, server, _ := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 
 	server.rollKeys()
 	client.rollKeys()
@@ -374,10 +431,7 @@ func TestRejectFrequentKeyUpdates(t *testing.T) {
 	}, err)
 }
 
-func setKeyUpdateIntervals(t *testing.T, firstKeyUpdateInterval, keyUpdateInterval uint64) {
-	reset := SetKeyUpdateInterval(keyUpdateInterval)
-	t.Cleanup(reset)
-
+func setFirstKeyUpdateInterval(t *testing.T, firstKeyUpdateInterval uint64) {
 	origFirstKeyUpdateInterval := FirstKeyUpdateInterval
 	FirstKeyUpdateInterval = firstKeyUpdateInterval
 
@@ -387,9 +441,9 @@ func setKeyUpdateIntervals(t *testing.T, firstKeyUpdateInterval, keyUpdateInterv
 func TestInitiateKeyUpdateAfterSendingMaxPackets(t *testing.T) {
 	const firstKeyUpdateInterval = 5
 	const keyUpdateInterval = 20
-	setKeyUpdateIntervals(t, firstKeyUpdateInterval, keyUpdateInterval)
+	setFirstKeyUpdateInterval(t, firstKeyUpdateInterval)
 
-	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats())
+	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), keyUpdateInterval, 0)
 	server.SetHandshakeConfirmed()
 
 	var pn protocol.PacketNumber
@@ -433,11 +487,48 @@ func TestInitiateKeyUpdateAfterSendingMaxPackets(t *testing.T) {
 	)
 }
 
+func TestInitiateKeyUpdateAfterPeriod(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		const keyUpdatePeriod = 10 * time.Second
+		setFirstKeyUpdateInterval(t, protocol.KeyUpdateInterval)
+
+		client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, keyUpdatePeriod)
+		server.SetHandshakeConfirmed()
+
+		server.Seal(nil, []byte(msg), 1, []byte(ad))
+		time.Sleep(keyUpdatePeriod - time.Second)
+		server.Seal(nil, []byte(msg), 2, []byte(ad))
+		require.Equal(t, protocol.KeyPhaseZero, server.KeyPhase())
+		require.Empty(t, eventRecorder.Events())
+
+		time.Sleep(time.Second)
+		require.Equal(t, protocol.KeyPhaseOne, server.KeyPhase())
+		require.Equal(t,
+			bothSides(qlog.KeyUpdated{KeyPhase: 1, Trigger: qlog.KeyUpdateLocal}),
+			eventRecorder.Events(),
+		)
+		eventRecorder.Clear()
+
+		// the timer restarts when the keys are rolled
+		server.Seal(nil, []byte(msg), 3, []byte(ad))
+		client.rollKeys()
+		b := client.Seal(nil, []byte("foobar"), 1, []byte("ad"))
+		_, err := server.Open(nil, b, monotime.Now(), 1, protocol.KeyPhaseOne, []byte("ad"))
+		require.NoError(t, err)
+		require.NoError(t, server.SetLargestAcked(3))
+		eventRecorder.Clear()
+		time.Sleep(keyUpdatePeriod - time.Second)
+		require.Equal(t, protocol.KeyPhaseOne, server.KeyPhase())
+		time.Sleep(time.Second)
+		require.Equal(t, protocol.KeyPhaseZero, server.KeyPhase())
+	})
+}
+
 func TestKeyUpdateEnforceACKKeyPhase(t *testing.T) {
 	const firstKeyUpdateInterval = 5
-	setKeyUpdateIntervals(t, firstKeyUpdateInterval, protocol.KeyUpdateInterval)
+	setFirstKeyUpdateInterval(t, firstKeyUpdateInterval)
 
-	_, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats())
+	_, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
 	server.SetHandshakeConfirmed()
 
 	// First make sure that we update our keys.
@@ -477,9 +568,9 @@ func TestKeyUpdateEnforceACKKeyPhase(t *testing.T) {
 func TestKeyUpdateAfterOpeningMaxPackets(t *testing.T) {
 	const firstKeyUpdateInterval = 5
 	const keyUpdateInterval = 20
-	setKeyUpdateIntervals(t, firstKeyUpdateInterval, keyUpdateInterval)
+	setFirstKeyUpdateInterval(t, firstKeyUpdateInterval)
 
-	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats())
+	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), keyUpdateInterval, 0)
 	server.SetHandshakeConfirmed()
 
 	msg := []byte("message")
@@ -530,11 +621,11 @@ func TestKeyUpdateAfterOpeningMaxPackets(t *testing.T) {
 func TestKeyUpdateKeyPhaseSkipping(t *testing.T) {
 	const firstKeyUpdateInterval = 5
 	const keyUpdateInterval = 20
-	setKeyUpdateIntervals(t, firstKeyUpdateInterval, keyUpdateInterval)
+	setFirstKeyUpdateInterval(t, firstKeyUpdateInterval)
 
 	rttStats := utils.NewRTTStats()
 	rttStats.UpdateRTT(10*time.Millisecond, 0)
-	client, server, eventRecorder := setupEndpoints(t, rttStats)
+	client, server, eventRecorder := setupEndpoints(t, rttStats, keyUpdateInterval, 0)
 	server.SetHandshakeConfirmed()
 
 	now := monotime.Now()
@@ -565,9 +656,9 @@ func TestKeyUpdateKeyPhaseSkipping(t *testing.T) {
 func TestFastKeyUpdatesByPeer(t *testing.T) {
 	const firstKeyUpdateInterval = 5
 	const keyUpdateInterval = 20
-	setKeyUpdateIntervals(t, firstKeyUpdateInterval, keyUpdateInterval)
+	setFirstKeyUpdateInterval(t, firstKeyUpdateInterval)
 
-	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats())
+	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), keyUpdateInterval, 0)
 	server.SetHandshakeConfirmed()
 
 	var pn protocol.PacketNumber
@@ -616,11 +707,11 @@ func TestFastKeyUpdatesByPeer(t *testing.T) {
 func TestFastKeyUpdateByUs(t *testing.T) {
 	const firstKeyUpdateInterval = 5
 	const keyUpdateInterval = 20
-	setKeyUpdateIntervals(t, firstKeyUpdateInterval, keyUpdateInterval)
+	setFirstKeyUpdateInterval(t, firstKeyUpdateInterval)
 
 	rttStats := utils.NewRTTStats()
 	rttStats.UpdateRTT(10*time.Millisecond, 0)
-	client, server, eventRecorder := setupEndpoints(t, rttStats)
+	client, server, eventRecorder := setupEndpoints(t, rttStats, keyUpdateInterval, 0)
 	server.SetHandshakeConfirmed()
 
 	// send so many packets that we initiate the first key update
@@ -678,8 +769,8 @@ func getClientAndServer() (client, server *updatableAEAD) {
 
 	cs := cipherSuites[0]
 	rttStats := utils.NewRTTStats()
-	client = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger, protocol.Version1)
-	server = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger, protocol.Version1)
+	client = newUpdatableAEAD(rttStats, protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, protocol.Version1)
+	server = newUpdatableAEAD(rttStats, protocol.KeyUpdateInterval, 0, nil, utils.DefaultLogger, protocol.Version1)
 	client.SetReadKey(cs, trafficSecret2)
 	client.SetWriteKey(cs, trafficSecret1)
 	server.SetReadKey(cs, trafficSecret1)
@@ -729,3 +820,40 @@ func BenchmarkRollKeys(b *testing.B) {
 		b.Fatal("didn't roll keys often enough")
 	}
 }
+
+func TestKeyUpdateRequestedByApplication(t *testing.T) {
+	client, server, eventRecorder := setupEndpoints(t, utils.NewRTTStats(), protocol.KeyUpdateInterval, 0)
+	require.ErrorIs(t, server.InitiateKeyUpdate(), ErrHandshakeNotConfirmed)
+	server.SetHandshakeConfirmed()
+
+	require.NoError(t, server.InitiateKeyUpdate())
+	require.ErrorIs(t, server.InitiateKeyUpdate(), ErrKeyUpdateInProgress)
+	require.Empty(t, eventRecorder.Events())
+	// the keys are rolled when the next packet is sent
+	require.Equal(t, protocol.KeyPhaseOne, server.KeyPhase())
+	require.Equal(t,
+		bothSides(qlog.KeyUpdated{KeyPhase: 1, Trigger: qlog.KeyUpdateLocal}),
+		eventRecorder.Events(),
+	)
+	eventRecorder.Clear()
+	require.Equal(t, protocol.KeyPhaseOne, server.KeyPhase())
+	server.Seal(nil, []byte(msg), 1, []byte(ad))
+
+	// no update allowed before receiving an acknowledgement for the current key phase
+	require.ErrorIs(t, server.InitiateKeyUpdate(), ErrKeyUpdateInProgress)
+	client.rollKeys()
+	b := client.Seal(nil, []byte("foobar"), 1, []byte("ad"))
+	_, err := server.Open(nil, b, monotime.Now(), 1, protocol.KeyPhaseOne, []byte("ad"))
+	require.NoError(t, err)
+	require.NoError(t, server.SetLargestAcked(1))
+
+	require.NoError(t, server.InitiateKeyUpdate())
+	require.Equal(t, protocol.KeyPhaseZero, server.KeyPhase())
+	require.Equal(t,
+		append(
+			bothSides(qlog.KeyDiscarded{KeyPhase: 0}),
+			bothSides(qlog.KeyUpdated{KeyPhase: 2, Trigger: qlog.KeyUpdateLocal})...,
+		),
+		eventRecorder.Events(),
+	)
+}
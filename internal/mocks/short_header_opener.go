@@ -79,6 +79,44 @@ func (c *MockShortHeaderOpenerDecodePacketNumberCall) DoAndReturn(f func(protoco
 	return c
 }
 
+// DecodePathPacketNumber mocks base method.
+func (m *MockShortHeaderOpener) DecodePathPacketNumber(pathID protocol.PathID, wirePN protocol.PacketNumber, wirePNLen protocol.PacketNumberLen) protocol.PacketNumber {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodePathPacketNumber", pathID, wirePN, wirePNLen)
+	ret0, _ := ret[0].(protocol.PacketNumber)
+	return ret0
+}
+
+// DecodePathPacketNumber indicates an expected call of DecodePathPacketNumber.
+func (mr *MockShortHeaderOpenerMockRecorder) DecodePathPacketNumber(pathID, wirePN, wirePNLen any) *MockShortHeaderOpenerDecodePathPacketNumberCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodePathPacketNumber", reflect.TypeOf((*MockShortHeaderOpener)(nil).DecodePathPacketNumber), pathID, wirePN, wirePNLen)
+	return &MockShortHeaderOpenerDecodePathPacketNumberCall{Call: call}
+}
+
+// MockShortHeaderOpenerDecodePathPacketNumberCall wrap *gomock.Call
+type MockShortHeaderOpenerDecodePathPacketNumberCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockShortHeaderOpenerDecodePathPacketNumberCall) Return(arg0 protocol.PacketNumber) *MockShortHeaderOpenerDecodePathPacketNumberCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockShortHeaderOpenerDecodePathPacketNumberCall) Do(f func(protocol.PathID, protocol.PacketNumber, protocol.PacketNumberLen) protocol.PacketNumber) *MockShortHeaderOpenerDecodePathPacketNumberCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockShortHeaderOpenerDecodePathPacketNumberCall) DoAndReturn(f func(protocol.PathID, protocol.PacketNumber, protocol.PacketNumberLen) protocol.PacketNumber) *MockShortHeaderOpenerDecodePathPacketNumberCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DecryptHeader mocks base method.
 func (m *MockShortHeaderOpener) DecryptHeader(sample []byte, firstByte *byte, pnBytes []byte) {
 	m.ctrl.T.Helper()
@@ -153,3 +191,42 @@ func (c *MockShortHeaderOpenerOpenCall) DoAndReturn(f func([]byte, []byte, monot
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// OpenPath mocks base method.
+func (m *MockShortHeaderOpener) OpenPath(dst, src []byte, rcvTime monotime.Time, pathID protocol.PathID, pn protocol.PacketNumber, kp protocol.KeyPhaseBit, associatedData []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenPath", dst, src, rcvTime, pathID, pn, kp, associatedData)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenPath indicates an expected call of OpenPath.
+func (mr *MockShortHeaderOpenerMockRecorder) OpenPath(dst, src, rcvTime, pathID, pn, kp, associatedData any) *MockShortHeaderOpenerOpenPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenPath", reflect.TypeOf((*MockShortHeaderOpener)(nil).OpenPath), dst, src, rcvTime, pathID, pn, kp, associatedData)
+	return &MockShortHeaderOpenerOpenPathCall{Call: call}
+}
+
+// MockShortHeaderOpenerOpenPathCall wrap *gomock.Call
+type MockShortHeaderOpenerOpenPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockShortHeaderOpenerOpenPathCall) Return(arg0 []byte, arg1 error) *MockShortHeaderOpenerOpenPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockShortHeaderOpenerOpenPathCall) Do(f func([]byte, []byte, monotime.Time, protocol.PathID, protocol.PacketNumber, protocol.KeyPhaseBit, []byte) ([]byte, error)) *MockShortHeaderOpenerOpenPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockShortHeaderOpenerOpenPathCall) DoAndReturn(f func([]byte, []byte, monotime.Time, protocol.PathID, protocol.PacketNumber, protocol.KeyPhaseBit, []byte) ([]byte, error)) *MockShortHeaderOpenerOpenPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
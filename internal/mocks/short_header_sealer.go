@@ -189,3 +189,41 @@ func (c *MockShortHeaderSealerSealCall) DoAndReturn(f func([]byte, []byte, proto
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SealPath mocks base method.
+func (m *MockShortHeaderSealer) SealPath(dst, src []byte, pathID protocol.PathID, packetNumber protocol.PacketNumber, associatedData []byte) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealPath", dst, src, pathID, packetNumber, associatedData)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// SealPath indicates an expected call of SealPath.
+func (mr *MockShortHeaderSealerMockRecorder) SealPath(dst, src, pathID, packetNumber, associatedData any) *MockShortHeaderSealerSealPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealPath", reflect.TypeOf((*MockShortHeaderSealer)(nil).SealPath), dst, src, pathID, packetNumber, associatedData)
+	return &MockShortHeaderSealerSealPathCall{Call: call}
+}
+
+// MockShortHeaderSealerSealPathCall wrap *gomock.Call
+type MockShortHeaderSealerSealPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockShortHeaderSealerSealPathCall) Return(arg0 []byte) *MockShortHeaderSealerSealPathCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockShortHeaderSealerSealPathCall) Do(f func([]byte, []byte, protocol.PathID, protocol.PacketNumber, []byte) []byte) *MockShortHeaderSealerSealPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockShortHeaderSealerSealPathCall) DoAndReturn(f func([]byte, []byte, protocol.PathID, protocol.PacketNumber, []byte) []byte) *MockShortHeaderSealerSealPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
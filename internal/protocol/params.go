@@ -55,7 +55,7 @@ const SkipPacketInitialPeriod PacketNumber = 256
 // SkipPacketMaxPeriod is the maximum period length used for packet number skipping.
 const SkipPacketMaxPeriod PacketNumber = 128 * 1024
 
-// MaxAcceptQueueSize is the maximum number of connections that the server queues for accepting.
+// MaxAcceptQueueSize is the default maximum number of connections that the server queues for accepting.
 // If the queue is full, new connection attempts will be rejected.
 const MaxAcceptQueueSize = 32
 
@@ -128,6 +128,10 @@ const MaxNumAckRanges = 32
 // Example: For a packet pacing delay of 200μs, we would send 5 packets at once, wait for 1ms, and so forth.
 const MinPacingDelay = time.Millisecond
 
+// MaxKernelPacingDelay is the maximum duration that packets are handed to the kernel ahead of time
+// when the kernel takes care of pacing (using SO_TXTIME).
+const MaxKernelPacingDelay = 5 * time.Millisecond
+
 // DefaultConnectionIDLength is the connection ID length that is used for multiplexed connections
 // if no other value is configured.
 const DefaultConnectionIDLength = 4
@@ -138,6 +142,13 @@ const MaxActiveConnectionIDs = 4
 // MaxIssuedConnectionIDs is the maximum number of connection IDs that we're issuing at the same time.
 const MaxIssuedConnectionIDs = 6
 
+// MaxMultipathPathID is the maximum path ID that we allow the peer to use when using the multipath extension.
+// This is the value advertised in the initial_max_path_id transport parameter.
+const MaxMultipathPathID PathID = 3
+
+// ConnectionIDsPerPath is the number of connection IDs we issue for every path ID, when using the multipath extension.
+const ConnectionIDsPerPath = 2
+
 // PacketsPerConnectionID is the number of packets we send using one connection ID.
 // If the peer provices us with enough new connection IDs, we switch to a new connection ID.
 const PacketsPerConnectionID = 10000
@@ -156,9 +167,17 @@ const MaxAckDelay = 25 * time.Millisecond
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
 
+// MinAckDelay is the smallest max_ack_delay the peer can request using an ACK_FREQUENCY frame.
+// This is the value advertised in the min_ack_delay transport parameter.
+const MinAckDelay = TimerGranularity
+
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
 
+// MaxKeyUpdateInterval is the largest key update interval that can be configured.
+// It is the confidentiality limit of AEAD_AES_128_GCM and AEAD_AES_256_GCM (see section 6.6 of RFC 9001).
+const MaxKeyUpdateInterval = 1 << 23
+
 // Max0RTTQueueingDuration is the maximum time that we store 0-RTT packets in order to wait for the corresponding Initial to be received.
 const Max0RTTQueueingDuration = 100 * time.Millisecond
 
@@ -104,6 +104,16 @@ const MaxByteCount = ByteCount(1<<62 - 1)
 // InvalidByteCount is an invalid byte count
 const InvalidByteCount ByteCount = -1
 
+// A PathID identifies a path when using the multipath extension.
+// The initial path has the path ID 0.
+type PathID uint32
+
+// InitialPathID is the path ID of the path used during the handshake.
+const InitialPathID PathID = 0
+
+// MaxPathID is the largest valid path ID.
+const MaxPathID = PathID(1<<32 - 1)
+
 // A StatelessResetToken is a stateless reset token.
 type StatelessResetToken [16]byte
 
@@ -116,6 +126,12 @@ const MaxPacketBufferSize = 1452
 // MaxLargePacketBufferSize is used when using GSO
 const MaxLargePacketBufferSize = 20 * 1024
 
+// MaxGROBufferSize is used when receiving packets using GRO.
+// The kernel coalesces datagrams into buffers of up to 64 KB.
+// A socket reading a batch of messages holds one such buffer per message,
+// and each buffer is only reused once all packets split from it have been processed.
+const MaxGROBufferSize = 64 * 1024
+
 // MinInitialPacketSize is the minimum size an Initial packet is required to have.
 const MinInitialPacketSize = 1200
 
@@ -94,6 +94,15 @@ func TestVersionSelection(t *testing.T) {
 	}
 }
 
+func TestCompatibleVersions(t *testing.T) {
+	require.True(t, IsCompatibleVersion(Version1, Version1))
+	require.True(t, IsCompatibleVersion(Version1, Version2))
+	require.True(t, IsCompatibleVersion(Version2, Version1))
+	require.True(t, IsCompatibleVersion(0x1234, 0x1234))
+	require.False(t, IsCompatibleVersion(Version1, 0x1234))
+	require.False(t, IsCompatibleVersion(0x1234, Version2))
+}
+
 func isReservedVersion(v Version) bool { return v&0x0f0f0f0f == 0x0a0a0a0a }
 
 func TestVersionGreasing(t *testing.T) {
@@ -23,7 +23,7 @@ type AckFrame struct {
 // parseAckFrame reads an ACK frame
 func parseAckFrame(frame *AckFrame, b []byte, typ FrameType, ackDelayExponent uint8, _ protocol.Version) (int, error) {
 	startLen := len(b)
-	ecn := typ == FrameTypeAckECN
+	ecn := typ == FrameTypeAckECN || typ == FrameTypePathAckECN
 
 	la, l, err := quicvarint.Parse(b)
 	if err != nil {
@@ -120,12 +120,20 @@ func parseAckFrame(frame *AckFrame, b []byte, typ FrameType, ackDelayExponent ui
 
 // Append appends an ACK frame.
 func (f *AckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
-	hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
-	if hasECN {
+	if f.hasECN() {
 		b = append(b, byte(FrameTypeAckECN))
 	} else {
 		b = append(b, byte(FrameTypeAck))
 	}
+	return f.appendFields(b), nil
+}
+
+func (f *AckFrame) hasECN() bool {
+	return f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
+}
+
+// appendFields appends everything but the frame type.
+func (f *AckFrame) appendFields(b []byte) []byte {
 	b = quicvarint.Append(b, uint64(f.LargestAcked()))
 	b = quicvarint.Append(b, encodeAckDelay(f.DelayTime))
 
@@ -143,12 +151,12 @@ func (f *AckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
 		b = quicvarint.Append(b, len)
 	}
 
-	if hasECN {
+	if f.hasECN() {
 		b = quicvarint.Append(b, f.ECT0)
 		b = quicvarint.Append(b, f.ECT1)
 		b = quicvarint.Append(b, f.ECNCE)
 	}
-	return b, nil
+	return b
 }
 
 // Length of a written frame
@@ -167,7 +175,7 @@ func (f *AckFrame) Length(_ protocol.Version) protocol.ByteCount {
 		length += quicvarint.Len(gap)
 		length += quicvarint.Len(len)
 	}
-	if f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0 {
+	if f.hasECN() {
 		length += quicvarint.Len(f.ECT0)
 		length += quicvarint.Len(f.ECT1)
 		length += quicvarint.Len(f.ECNCE)
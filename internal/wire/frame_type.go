@@ -38,6 +38,18 @@ const (
 
 	FrameTypeDatagramNoLength   FrameType = 0x30
 	FrameTypeDatagramWithLength FrameType = 0x31
+
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/14/
+	FrameTypePathAck                FrameType = 0x3e
+	FrameTypePathAckECN             FrameType = 0x3f
+	FrameTypePathAbandon            FrameType = 0x3e75
+	FrameTypePathStatusBackup       FrameType = 0x3e76
+	FrameTypePathStatusAvailable    FrameType = 0x3e77
+	FrameTypePathNewConnectionID    FrameType = 0x3e78
+	FrameTypePathRetireConnectionID FrameType = 0x3e79
+	FrameTypeMaxPathID              FrameType = 0x3e7a
+	FrameTypePathsBlocked           FrameType = 0x3e7b
+	FrameTypePathCIDsBlocked        FrameType = 0x3e7c
 )
 
 func (t FrameType) IsStreamFrameType() bool {
@@ -52,6 +64,10 @@ func (t FrameType) IsAckFrameType() bool {
 	return t == FrameTypeAck || t == FrameTypeAckECN
 }
 
+func (t FrameType) isMultipathFrameType() bool {
+	return t == FrameTypePathAck || t == FrameTypePathAckECN || (t >= FrameTypePathAbandon && t <= FrameTypePathCIDsBlocked)
+}
+
 func (t FrameType) IsDatagramFrameType() bool {
 	return t == FrameTypeDatagramNoLength || t == FrameTypeDatagramWithLength
 }
@@ -71,7 +87,8 @@ func (t FrameType) isAllowedAtEncLevel(encLevel protocol.EncryptionLevel) bool {
 		case FrameTypeCrypto, FrameTypeAck, FrameTypeAckECN, FrameTypeConnectionClose, FrameTypeNewToken, FrameTypePathResponse, FrameTypeRetireConnectionID:
 			return false
 		default:
-			return true
+			// The multipath extension can only be negotiated for 1-RTT packets.
+			return !t.isMultipathFrameType()
 		}
 	case protocol.Encryption1RTT:
 		return true
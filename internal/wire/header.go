@@ -131,11 +131,22 @@ type Header struct {
 // The packet is cut according to the length field.
 // If we understand the version, the packet is parsed up unto the packet number.
 // Otherwise, only the invariant part of the header is parsed.
+// Packets that have the QUIC bit cleared are rejected.
 func ParsePacket(data []byte) (*Header, []byte, []byte, error) {
+	return parsePacket(data, false)
+}
+
+// ParsePacketAllowClearedQUICBit is like ParsePacket, but it also accepts packets that have the QUIC bit cleared.
+// It must only be used if we advertised the grease_quic_bit transport parameter (RFC 9287).
+func ParsePacketAllowClearedQUICBit(data []byte) (*Header, []byte, []byte, error) {
+	return parsePacket(data, true)
+}
+
+func parsePacket(data []byte, allowClearedQUICBit bool) (*Header, []byte, []byte, error) {
 	if len(data) == 0 || !IsLongHeaderPacket(data[0]) {
 		return nil, nil, nil, errors.New("not a long header packet")
 	}
-	hdr, err := parseHeader(data)
+	hdr, err := parseHeader(data, allowClearedQUICBit)
 	if err != nil {
 		if errors.Is(err, ErrUnsupportedVersion) {
 			return hdr, nil, nil, err
@@ -152,25 +163,25 @@ func ParsePacket(data []byte) (*Header, []byte, []byte, error) {
 // ParseHeader parses the header:
 // * if we understand the version: up to the packet number
 // * if not, only the invariant part of the header
-func parseHeader(b []byte) (*Header, error) {
+func parseHeader(b []byte, allowClearedQUICBit bool) (*Header, error) {
 	if len(b) == 0 {
 		return nil, io.EOF
 	}
 	typeByte := b[0]
 
 	h := &Header{typeByte: typeByte}
-	l, err := h.parseLongHeader(b[1:])
+	l, err := h.parseLongHeader(b[1:], allowClearedQUICBit)
 	h.parsedLen = protocol.ByteCount(l) + 1
 	return h, err
 }
 
-func (h *Header) parseLongHeader(b []byte) (int, error) {
+func (h *Header) parseLongHeader(b []byte, allowClearedQUICBit bool) (int, error) {
 	startLen := len(b)
 	if len(b) < 5 {
 		return 0, io.EOF
 	}
 	h.Version = protocol.Version(binary.BigEndian.Uint32(b[:4]))
-	if h.Version != 0 && h.typeByte&0x40 == 0 {
+	if h.Version != 0 && h.typeByte&0x40 == 0 && !allowClearedQUICBit {
 		return startLen - len(b), errors.New("not a QUIC packet")
 	}
 	destConnIDLen := int(b[4])
@@ -191,6 +191,28 @@ func TestErrorIfReservedBitNotSet(t *testing.T) {
 	require.EqualError(t, err, "not a QUIC packet")
 }
 
+func TestParseLongHeaderWithoutQUICBit(t *testing.T) {
+	data := []byte{0x80 | 0x2<<4 | 0x1}
+	data = appendVersion(data, protocol.Version1)
+	data = append(data, 0x4)                               // dest conn id length
+	data = append(data, []byte{0xde, 0xca, 0xfb, 0xad}...) // dest conn ID
+	data = append(data, 0x0)                               // src conn id length
+	data = append(data, encodeVarInt(2)...)                // length
+	data = append(data, []byte{0x1, 0x23}...)
+
+	_, _, _, err := ParsePacket(data)
+	require.EqualError(t, err, "not a QUIC packet")
+
+	// the QUIC bit might be cleared by peers supporting RFC 9287
+	hdr, _, _, err := ParsePacketAllowClearedQUICBit(data)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketTypeHandshake, hdr.Type)
+	require.Equal(t, protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}), hdr.DestConnectionID)
+	extHdr, err := hdr.ParseExtended(data)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketNumber(0x123), extHdr.PacketNumber)
+}
+
 func TestStopParsingWhenEncounteringUnsupportedVersion(t *testing.T) {
 	data := []byte{
 		0xc0,
@@ -0,0 +1,45 @@
+package wire
+
+import (
+	"errors"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+var errInvalidPathID = errors.New("invalid path ID")
+
+// A MaxPathIDFrame is a MAX_PATH_ID frame
+type MaxPathIDFrame struct {
+	MaxPathID protocol.PathID
+}
+
+func parseMaxPathIDFrame(b []byte, _ protocol.Version) (*MaxPathIDFrame, int, error) {
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &MaxPathIDFrame{MaxPathID: pathID}, l, nil
+}
+
+func (f *MaxPathIDFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, uint64(FrameTypeMaxPathID))
+	return quicvarint.Append(b, uint64(f.MaxPathID)), nil
+}
+
+// Length of a written frame
+func (f *MaxPathIDFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(uint64(FrameTypeMaxPathID)) + quicvarint.Len(uint64(f.MaxPathID)))
+}
+
+// parsePathID parses a path ID, and checks that it's within the allowed range.
+func parsePathID(b []byte) (protocol.PathID, int, error) {
+	pathID, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return 0, 0, replaceUnexpectedEOF(err)
+	}
+	if pathID > uint64(protocol.MaxPathID) {
+		return 0, 0, errInvalidPathID
+	}
+	return protocol.PathID(pathID), l, nil
+}
@@ -0,0 +1,45 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaxPathIDFrame(t *testing.T) {
+	data := encodeVarInt(0xdecafbad)
+	frame, l, err := parseMaxPathIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(0xdecafbad), frame.MaxPathID)
+	require.Equal(t, len(data), l)
+}
+
+func TestParseMaxPathIDFrameInvalidPathID(t *testing.T) {
+	_, _, err := parseMaxPathIDFrame(encodeVarInt(uint64(protocol.MaxPathID)+1), protocol.Version1)
+	require.ErrorIs(t, err, errInvalidPathID)
+}
+
+func TestParseMaxPathIDErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(0xdecafbad)
+	_, l, err := parseMaxPathIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parseMaxPathIDFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWriteMaxPathIDFrame(t *testing.T) {
+	f := &MaxPathIDFrame{MaxPathID: 0x1337}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := quicvarint.Append(nil, uint64(FrameTypeMaxPathID))
+	expected = append(expected, encodeVarInt(0x1337)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+}
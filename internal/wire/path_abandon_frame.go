@@ -0,0 +1,38 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathAbandonFrame is a PATH_ABANDON frame
+type PathAbandonFrame struct {
+	PathID    protocol.PathID
+	ErrorCode uint64
+}
+
+func parsePathAbandonFrame(b []byte, _ protocol.Version) (*PathAbandonFrame, int, error) {
+	startLen := len(b)
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	b = b[l:]
+	code, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	return &PathAbandonFrame{PathID: pathID, ErrorCode: code}, startLen - len(b), nil
+}
+
+func (f *PathAbandonFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, uint64(FrameTypePathAbandon))
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return quicvarint.Append(b, f.ErrorCode), nil
+}
+
+// Length of a written frame
+func (f *PathAbandonFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(uint64(FrameTypePathAbandon)) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.ErrorCode))
+}
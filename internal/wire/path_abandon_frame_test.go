@@ -0,0 +1,51 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathAbandonFrame(t *testing.T) {
+	data := encodeVarInt(0xdecafbad)           // path ID
+	data = append(data, encodeVarInt(0x42)...) // error code
+	frame, l, err := parsePathAbandonFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(0xdecafbad), frame.PathID)
+	require.Equal(t, uint64(0x42), frame.ErrorCode)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathAbandonFrameInvalidPathID(t *testing.T) {
+	data := encodeVarInt(uint64(protocol.MaxPathID) + 1) // path ID
+	data = append(data, encodeVarInt(0x42)...)           // error code
+	_, _, err := parsePathAbandonFrame(data, protocol.Version1)
+	require.ErrorIs(t, err, errInvalidPathID)
+}
+
+func TestParsePathAbandonFrameErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(0xdecafbad)           // path ID
+	data = append(data, encodeVarInt(0x42)...) // error code
+	_, l, err := parsePathAbandonFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathAbandonFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathAbandonFrame(t *testing.T) {
+	f := &PathAbandonFrame{PathID: 0x1337, ErrorCode: 0xdeadbeef}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := quicvarint.Append(nil, uint64(FrameTypePathAbandon))
+	expected = append(expected, encodeVarInt(0x1337)...)
+	expected = append(expected, encodeVarInt(0xdeadbeef)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+}
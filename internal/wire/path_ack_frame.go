@@ -0,0 +1,42 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathAckFrame is a PATH_ACK frame.
+// It acknowledges packets sent in the packet number space of the path with the given path ID.
+type PathAckFrame struct {
+	PathID protocol.PathID
+	AckFrame
+}
+
+func parsePathAckFrame(b []byte, typ FrameType, ackDelayExponent uint8, v protocol.Version) (*PathAckFrame, int, error) {
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	frame := &PathAckFrame{PathID: pathID}
+	n, err := parseAckFrame(&frame.AckFrame, b[l:], typ, ackDelayExponent, v)
+	if err != nil {
+		return nil, 0, err
+	}
+	return frame, l + n, nil
+}
+
+func (f *PathAckFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	if f.hasECN() {
+		b = append(b, byte(FrameTypePathAckECN))
+	} else {
+		b = append(b, byte(FrameTypePathAck))
+	}
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return f.appendFields(b), nil
+}
+
+// Length of a written frame
+func (f *PathAckFrame) Length(v protocol.Version) protocol.ByteCount {
+	// The PATH_ACK frame types have the same length as the ACK frame types.
+	return f.AckFrame.Length(v) + protocol.ByteCount(quicvarint.Len(uint64(f.PathID)))
+}
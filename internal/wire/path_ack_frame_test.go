@@ -0,0 +1,112 @@
+package wire
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathAckFrame(t *testing.T) {
+	data := encodeVarInt(5)                   // path ID
+	data = append(data, encodeVarInt(100)...) // largest acked
+	data = append(data, encodeVarInt(0)...)   // delay
+	data = append(data, encodeVarInt(1)...)   // num blocks
+	data = append(data, encodeVarInt(10)...)  // first ack block
+	data = append(data, encodeVarInt(4)...)   // gap
+	data = append(data, encodeVarInt(20)...)  // ack block
+	frame, l, err := parsePathAckFrame(data, FrameTypePathAck, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(5), frame.PathID)
+	require.Equal(t, []AckRange{{Smallest: 90, Largest: 100}, {Smallest: 64, Largest: 84}}, frame.AckRanges)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathAckFrameECN(t *testing.T) {
+	data := encodeVarInt(5)                          // path ID
+	data = append(data, encodeVarInt(100)...)        // largest acked
+	data = append(data, encodeVarInt(0)...)          // delay
+	data = append(data, encodeVarInt(0)...)          // num blocks
+	data = append(data, encodeVarInt(10)...)         // first ack block
+	data = append(data, encodeVarInt(0x42)...)       // ECT(0)
+	data = append(data, encodeVarInt(0x12345)...)    // ECT(1)
+	data = append(data, encodeVarInt(0x12345678)...) // ECN-CE
+	frame, l, err := parsePathAckFrame(data, FrameTypePathAckECN, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(5), frame.PathID)
+	require.Equal(t, protocol.PacketNumber(100), frame.LargestAcked())
+	require.EqualValues(t, 0x42, frame.ECT0)
+	require.EqualValues(t, 0x12345, frame.ECT1)
+	require.EqualValues(t, 0x12345678, frame.ECNCE)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathAckFrameInvalidPathID(t *testing.T) {
+	data := encodeVarInt(uint64(protocol.MaxPathID) + 1) // path ID
+	data = append(data, encodeVarInt(100)...)            // largest acked
+	data = append(data, encodeVarInt(0)...)              // delay
+	data = append(data, encodeVarInt(0)...)              // num blocks
+	data = append(data, encodeVarInt(10)...)             // first ack block
+	_, _, err := parsePathAckFrame(data, FrameTypePathAck, protocol.AckDelayExponent, protocol.Version1)
+	require.ErrorIs(t, err, errInvalidPathID)
+}
+
+func TestParsePathAckFrameErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(5)                   // path ID
+	data = append(data, encodeVarInt(100)...) // largest acked
+	data = append(data, encodeVarInt(0)...)   // delay
+	data = append(data, encodeVarInt(0)...)   // num blocks
+	data = append(data, encodeVarInt(10)...)  // first ack block
+	_, l, err := parsePathAckFrame(data, FrameTypePathAck, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathAckFrame(data[:i], FrameTypePathAck, protocol.AckDelayExponent, protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathAckFrame(t *testing.T) {
+	f := &PathAckFrame{
+		PathID: 1337,
+		AckFrame: AckFrame{
+			AckRanges: []AckRange{{Smallest: 100, Largest: 200}, {Smallest: 10, Largest: 50}},
+			DelayTime: 18 * time.Millisecond,
+		},
+	}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+	require.Equal(t, byte(FrameTypePathAck), b[0])
+
+	// the PATH_ACK frame is the ACK frame, with the path ID inserted after the frame type
+	ackFrame, err := f.AckFrame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := []byte{byte(FrameTypePathAck)}
+	expected = append(expected, encodeVarInt(1337)...)
+	expected = append(expected, ackFrame[1:]...)
+	require.Equal(t, expected, b)
+
+	frame, l, err := parsePathAckFrame(b[1:], FrameTypePathAck, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, f, frame)
+	require.Equal(t, len(b)-1, l)
+}
+
+func TestWritePathAckFrameECN(t *testing.T) {
+	f := &PathAckFrame{
+		PathID:   42,
+		AckFrame: AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 10}}, ECT0: 1, ECT1: 2, ECNCE: 3},
+	}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+	require.Equal(t, byte(FrameTypePathAckECN), b[0])
+	frame, l, err := parsePathAckFrame(b[1:], FrameTypePathAckECN, protocol.AckDelayExponent, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, f, frame)
+	require.Equal(t, len(b)-1, l)
+}
@@ -0,0 +1,38 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathCIDsBlockedFrame is a PATH_CIDS_BLOCKED frame
+type PathCIDsBlockedFrame struct {
+	PathID             protocol.PathID
+	NextSequenceNumber uint64
+}
+
+func parsePathCIDsBlockedFrame(b []byte, _ protocol.Version) (*PathCIDsBlockedFrame, int, error) {
+	startLen := len(b)
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	b = b[l:]
+	seq, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	return &PathCIDsBlockedFrame{PathID: pathID, NextSequenceNumber: seq}, startLen - len(b), nil
+}
+
+func (f *PathCIDsBlockedFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, uint64(FrameTypePathCIDsBlocked))
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return quicvarint.Append(b, f.NextSequenceNumber), nil
+}
+
+// Length of a written frame
+func (f *PathCIDsBlockedFrame) Length(protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(uint64(FrameTypePathCIDsBlocked)) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.NextSequenceNumber))
+}
@@ -0,0 +1,44 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathCIDsBlockedFrame(t *testing.T) {
+	data := encodeVarInt(3)                      // path ID
+	data = append(data, encodeVarInt(0x1337)...) // next sequence number
+	frame, l, err := parsePathCIDsBlockedFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(3), frame.PathID)
+	require.Equal(t, uint64(0x1337), frame.NextSequenceNumber)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathCIDsBlockedErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(3)                      // path ID
+	data = append(data, encodeVarInt(0x1337)...) // next sequence number
+	_, l, err := parsePathCIDsBlockedFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathCIDsBlockedFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathCIDsBlockedFrame(t *testing.T) {
+	f := &PathCIDsBlockedFrame{PathID: 3, NextSequenceNumber: 0xdecafbad}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := quicvarint.Append(nil, uint64(FrameTypePathCIDsBlocked))
+	expected = append(expected, encodeVarInt(3)...)
+	expected = append(expected, encodeVarInt(0xdecafbad)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+}
@@ -0,0 +1,44 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathRetireConnectionIDFrame(t *testing.T) {
+	data := encodeVarInt(3)                          // path ID
+	data = append(data, encodeVarInt(0xdeadbeef)...) // sequence number
+	frame, l, err := parsePathRetireConnectionIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(3), frame.PathID)
+	require.Equal(t, uint64(0xdeadbeef), frame.SequenceNumber)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathRetireConnectionIDErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(3)                          // path ID
+	data = append(data, encodeVarInt(0xdeadbeef)...) // sequence number
+	_, l, err := parsePathRetireConnectionIDFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathRetireConnectionIDFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathRetireConnectionIDFrame(t *testing.T) {
+	frame := &PathRetireConnectionIDFrame{PathID: 3, SequenceNumber: 0x1337}
+	b, err := frame.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := quicvarint.Append(nil, uint64(FrameTypePathRetireConnectionID))
+	expected = append(expected, encodeVarInt(3)...)
+	expected = append(expected, encodeVarInt(0x1337)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(frame.Length(protocol.Version1)))
+}
@@ -0,0 +1,50 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// A PathStatusFrame is a PATH_STATUS_BACKUP or a PATH_STATUS_AVAILABLE frame
+type PathStatusFrame struct {
+	PathID         protocol.PathID
+	SequenceNumber uint64
+	Backup         bool
+}
+
+func parsePathStatusFrame(b []byte, typ FrameType, _ protocol.Version) (*PathStatusFrame, int, error) {
+	startLen := len(b)
+	pathID, l, err := parsePathID(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	b = b[l:]
+	seq, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	return &PathStatusFrame{
+		PathID:         pathID,
+		SequenceNumber: seq,
+		Backup:         typ == FrameTypePathStatusBackup,
+	}, startLen - len(b), nil
+}
+
+func (f *PathStatusFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, uint64(f.frameType()))
+	b = quicvarint.Append(b, uint64(f.PathID))
+	return quicvarint.Append(b, f.SequenceNumber), nil
+}
+
+// Length of a written frame
+func (f *PathStatusFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(uint64(f.frameType())) + quicvarint.Len(uint64(f.PathID)) + quicvarint.Len(f.SequenceNumber))
+}
+
+func (f *PathStatusFrame) frameType() FrameType {
+	if f.Backup {
+		return FrameTypePathStatusBackup
+	}
+	return FrameTypePathStatusAvailable
+}
@@ -0,0 +1,55 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathStatusFrame(t *testing.T) {
+	data := encodeVarInt(7)                      // path ID
+	data = append(data, encodeVarInt(0x1337)...) // sequence number
+
+	frame, l, err := parsePathStatusFrame(data, FrameTypePathStatusBackup, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, &PathStatusFrame{PathID: 7, SequenceNumber: 0x1337, Backup: true}, frame)
+	require.Equal(t, len(data), l)
+
+	frame, l, err = parsePathStatusFrame(data, FrameTypePathStatusAvailable, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, &PathStatusFrame{PathID: 7, SequenceNumber: 0x1337}, frame)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathStatusFrameErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(7)                      // path ID
+	data = append(data, encodeVarInt(0x1337)...) // sequence number
+	_, l, err := parsePathStatusFrame(data, FrameTypePathStatusBackup, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathStatusFrame(data[:i], FrameTypePathStatusBackup, protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathStatusFrame(t *testing.T) {
+	for _, backup := range []bool{true, false} {
+		f := &PathStatusFrame{PathID: 7, SequenceNumber: 0xdecafbad, Backup: backup}
+		b, err := f.Append(nil, protocol.Version1)
+		require.NoError(t, err)
+		frameType := FrameTypePathStatusAvailable
+		if backup {
+			frameType = FrameTypePathStatusBackup
+		}
+		expected := quicvarint.Append(nil, uint64(frameType))
+		expected = append(expected, encodeVarInt(7)...)
+		expected = append(expected, encodeVarInt(0xdecafbad)...)
+		require.Equal(t, expected, b)
+		require.Len(t, b, int(f.Length(protocol.Version1)))
+	}
+}
@@ -0,0 +1,40 @@
+package wire
+
+import (
+	"io"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathsBlockedFrame(t *testing.T) {
+	data := encodeVarInt(0x1337)
+	frame, l, err := parsePathsBlockedFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PathID(0x1337), frame.MaxPathID)
+	require.Equal(t, len(data), l)
+}
+
+func TestParsePathsBlockedErrorsOnEOFs(t *testing.T) {
+	data := encodeVarInt(0xdecafbad)
+	_, l, err := parsePathsBlockedFrame(data, protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	for i := range data {
+		_, _, err := parsePathsBlockedFrame(data[:i], protocol.Version1)
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestWritePathsBlockedFrame(t *testing.T) {
+	f := &PathsBlockedFrame{MaxPathID: 0x1337}
+	b, err := f.Append(nil, protocol.Version1)
+	require.NoError(t, err)
+	expected := quicvarint.Append(nil, uint64(FrameTypePathsBlocked))
+	expected = append(expected, encodeVarInt(0x1337)...)
+	require.Equal(t, expected, b)
+	require.Len(t, b, int(f.Length(protocol.Version1)))
+}
@@ -11,14 +11,25 @@ import (
 // ParseShortHeader parses a short header packet.
 // It must be called after header protection was removed.
 // Otherwise, the check for the reserved bits will (most likely) fail.
+// Packets that have the QUIC bit cleared are rejected.
 func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
+	return parseShortHeader(data, connIDLen, false)
+}
+
+// ParseShortHeaderAllowClearedQUICBit is like ParseShortHeader, but it also accepts packets that have the QUIC bit cleared.
+// It must only be used if we advertised the grease_quic_bit transport parameter (RFC 9287).
+func ParseShortHeaderAllowClearedQUICBit(data []byte, connIDLen int) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
+	return parseShortHeader(data, connIDLen, true)
+}
+
+func parseShortHeader(data []byte, connIDLen int, allowClearedQUICBit bool) (length int, _ protocol.PacketNumber, _ protocol.PacketNumberLen, _ protocol.KeyPhaseBit, _ error) {
 	if len(data) == 0 {
 		return 0, 0, 0, 0, io.EOF
 	}
 	if data[0]&0x80 > 0 {
 		return 0, 0, 0, 0, errors.New("not a short header packet")
 	}
-	if data[0]&0x40 == 0 {
+	if data[0]&0x40 == 0 && !allowClearedQUICBit {
 		return 0, 0, 0, 0, errors.New("not a QUIC packet")
 	}
 	pnLen := protocol.PacketNumberLen(data[0]&0b11) + 1
@@ -42,12 +53,22 @@ func ParseShortHeader(data []byte, connIDLen int) (length int, _ protocol.Packet
 	return 1 + connIDLen + int(pnLen), pn, pnLen, kp, err
 }
 
+// ShortHeaderSpinBit returns the value of the latency spin bit, see section 17.4 of RFC 9000.
+// The spin bit is not covered by header protection,
+// so it can be read before header protection is removed.
+func ShortHeaderSpinBit(firstByte byte) bool {
+	return firstByte&0x20 > 0
+}
+
 // AppendShortHeader writes a short header.
-func AppendShortHeader(b []byte, connID protocol.ConnectionID, pn protocol.PacketNumber, pnLen protocol.PacketNumberLen, kp protocol.KeyPhaseBit) ([]byte, error) {
+func AppendShortHeader(b []byte, connID protocol.ConnectionID, pn protocol.PacketNumber, pnLen protocol.PacketNumberLen, kp protocol.KeyPhaseBit, spinBit bool) ([]byte, error) {
 	typeByte := 0x40 | uint8(pnLen-1)
 	if kp == protocol.KeyPhaseOne {
 		typeByte |= byte(1 << 2)
 	}
+	if spinBit {
+		typeByte |= 0x20
+	}
 	b = append(b, typeByte)
 	b = append(b, connID.Bytes()...)
 	return appendPacketNumber(b, pn, pnLen)
@@ -32,6 +32,14 @@ func TestParseShortHeaderNoQUICBit(t *testing.T) {
 	}
 	_, _, _, _, err := ParseShortHeader(data, 4)
 	require.EqualError(t, err, "not a QUIC packet")
+
+	// the QUIC bit might be cleared by peers supporting RFC 9287
+	l, pn, pnLen, kp, err := ParseShortHeaderAllowClearedQUICBit(data, 4)
+	require.NoError(t, err)
+	require.Equal(t, len(data), l)
+	require.Equal(t, protocol.KeyPhaseOne, kp)
+	require.Equal(t, protocol.PacketNumber(0x1337), pn)
+	require.Equal(t, protocol.PacketNumberLen2, pnLen)
 }
 
 func TestParseShortHeaderReservedBitsSet(t *testing.T) {
@@ -71,7 +79,7 @@ func TestShortHeaderLen(t *testing.T) {
 
 func TestWriteShortHeaderPacket(t *testing.T) {
 	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
-	b, err := AppendShortHeader(nil, connID, 1337, 4, protocol.KeyPhaseOne)
+	b, err := AppendShortHeader(nil, connID, 1337, 4, protocol.KeyPhaseOne, true)
 	require.NoError(t, err)
 	l, pn, pnLen, kp, err := ParseShortHeader(b, 4)
 	require.NoError(t, err)
@@ -79,6 +87,23 @@ func TestWriteShortHeaderPacket(t *testing.T) {
 	require.Equal(t, protocol.PacketNumberLen4, pnLen)
 	require.Equal(t, protocol.KeyPhaseOne, kp)
 	require.Equal(t, len(b), l)
+	require.True(t, ShortHeaderSpinBit(b[0]))
+}
+
+func TestShortHeaderSpinBit(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	b, err := AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseZero, false)
+	require.NoError(t, err)
+	require.False(t, ShortHeaderSpinBit(b[0]))
+	b, err = AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseZero, true)
+	require.NoError(t, err)
+	require.True(t, ShortHeaderSpinBit(b[0]))
+	// the spin bit doesn't affect parsing of the rest of the header
+	_, pn, pnLen, kp, err := ParseShortHeader(b, 4)
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketNumber(1337), pn)
+	require.Equal(t, protocol.PacketNumberLen2, pnLen)
+	require.Equal(t, protocol.KeyPhaseZero, kp)
 }
 
 func TestLogShortHeaderWithConnectionID(t *testing.T) {
@@ -97,7 +122,7 @@ func BenchmarkWriteShortHeader(b *testing.B) {
 
 	for b.Loop() {
 		var err error
-		buf, err = AppendShortHeader(buf, connID, 1337, protocol.PacketNumberLen4, protocol.KeyPhaseOne)
+		buf, err = AppendShortHeader(buf, connID, 1337, protocol.PacketNumberLen4, protocol.KeyPhaseOne, false)
 		if err != nil {
 			b.Fatalf("failed to write short header: %s", err)
 		}
@@ -33,6 +33,7 @@ func appendInitialSourceConnectionID(b []byte) []byte {
 func TestTransportParametersStringRepresentation(t *testing.T) {
 	rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
 	minAckDelay := 42 * time.Millisecond
+	maxPathID := protocol.PathID(7)
 	p := &TransportParameters{
 		InitialMaxStreamDataBidiLocal:   1234,
 		InitialMaxStreamDataBidiRemote:  2345,
@@ -49,10 +50,16 @@ func TestTransportParametersStringRepresentation(t *testing.T) {
 		StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 		ActiveConnectionIDLimit:         123,
 		MaxDatagramFrameSize:            876,
+		GreaseQUICBit:                   true,
 		EnableResetStreamAt:             true,
 		MinAckDelay:                     &minAckDelay,
+		InitialMaxPathID:                &maxPathID,
+		VersionInformation: &VersionInformation{
+			ChosenVersion:     protocol.Version2,
+			AvailableVersions: []protocol.Version{protocol.Version2, protocol.Version1},
+		},
 	}
-	expected := "&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, EnableResetStreamAt: true, MinAckDelay: 42ms}"
+	expected := "&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, GreaseQUICBit: true, EnableResetStreamAt: true, MinAckDelay: 42ms, InitialMaxPathID: 7, ChosenVersion: v2, AvailableVersions: [v2 v1]}"
 	require.Equal(t, expected, p.String())
 }
 
@@ -72,7 +79,7 @@ func TestTransportParametersStringRepresentationWithoutOptionalFields(t *testing
 		ActiveConnectionIDLimit:         89,
 		MaxDatagramFrameSize:            protocol.InvalidByteCount,
 	}
-	expected := "&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: (empty), InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37s, ActiveConnectionIDLimit: 89, EnableResetStreamAt: false}"
+	expected := "&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: (empty), InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37s, ActiveConnectionIDLimit: 89, GreaseQUICBit: false, EnableResetStreamAt: false}"
 	require.Equal(t, expected, p.String())
 }
 
@@ -81,6 +88,7 @@ func TestMarshalAndUnmarshalTransportParameters(t *testing.T) {
 	rand.Read(token[:])
 	rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
 	minAckDelay := 42 * time.Millisecond
+	maxPathID := protocol.PathID(getRandomValueUpTo(uint64(protocol.MaxPathID)))
 	params := &TransportParameters{
 		InitialMaxStreamDataBidiLocal:   protocol.ByteCount(getRandomValue()),
 		InitialMaxStreamDataBidiRemote:  protocol.ByteCount(getRandomValue()),
@@ -99,8 +107,14 @@ func TestMarshalAndUnmarshalTransportParameters(t *testing.T) {
 		ActiveConnectionIDLimit:         2 + getRandomValueUpTo(quicvarint.Max-2),
 		MaxUDPPayloadSize:               1200 + protocol.ByteCount(getRandomValueUpTo(quicvarint.Max-1200)),
 		MaxDatagramFrameSize:            protocol.ByteCount(getRandomValue()),
+		GreaseQUICBit:                   getRandomValue()%2 == 0,
 		EnableResetStreamAt:             getRandomValue()%2 == 0,
 		MinAckDelay:                     &minAckDelay,
+		InitialMaxPathID:                &maxPathID,
+		VersionInformation: &VersionInformation{
+			ChosenVersion:     protocol.Version1,
+			AvailableVersions: []protocol.Version{protocol.Version1, protocol.Version2, 0x1a2a3a4a},
+		},
 	}
 	data := params.Marshal(protocol.PerspectiveServer)
 
@@ -123,9 +137,13 @@ func TestMarshalAndUnmarshalTransportParameters(t *testing.T) {
 	require.Equal(t, params.ActiveConnectionIDLimit, p.ActiveConnectionIDLimit)
 	require.Equal(t, params.MaxUDPPayloadSize, p.MaxUDPPayloadSize)
 	require.Equal(t, params.MaxDatagramFrameSize, p.MaxDatagramFrameSize)
+	require.Equal(t, params.GreaseQUICBit, p.GreaseQUICBit)
 	require.Equal(t, params.EnableResetStreamAt, p.EnableResetStreamAt)
 	require.NotNil(t, p.MinAckDelay)
 	require.Equal(t, minAckDelay, *p.MinAckDelay)
+	require.NotNil(t, p.InitialMaxPathID)
+	require.Equal(t, maxPathID, *p.InitialMaxPathID)
+	require.Equal(t, params.VersionInformation, p.VersionInformation)
 }
 
 func TestMarshalAdditionalTransportParameters(t *testing.T) {
@@ -391,6 +409,17 @@ func TestTransportParameterErrors(t *testing.T) {
 			perspective:    protocol.PerspectiveClient,
 			expectedErrMsg: "wrong length for reset_stream_at: 1 (expected empty)",
 		},
+		{
+			name: "invalid value for grease_quic_bit",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(greaseQUICBitParameterID))
+				b = quicvarint.Append(b, 1)
+				b = quicvarint.Append(b, 1)
+				return appendInitialSourceConnectionID(b)
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "wrong length for grease_quic_bit: 1 (expected empty)",
+		},
 		{
 			name: "min ack delay is greater than max ack delay",
 			data: func() []byte {
@@ -419,6 +448,36 @@ func TestTransportParameterErrors(t *testing.T) {
 			perspective:    protocol.PerspectiveClient,
 			expectedErrMsg: "min_ack_delay (2562047h47m16.854775807s) is greater than max_ack_delay (42ms)",
 		},
+		{
+			name: "version information with invalid length",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+				b = quicvarint.Append(b, 6)
+				return appendInitialSourceConnectionID(append(b, 0, 0, 0, 1, 0, 0))
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "invalid length for version_information: 6",
+		},
+		{
+			name: "version information with chosen version 0",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+				b = quicvarint.Append(b, 4)
+				return appendInitialSourceConnectionID(append(b, 0, 0, 0, 0))
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "version_information: chosen version is 0",
+		},
+		{
+			name: "version information with available version 0",
+			data: func() []byte {
+				b := quicvarint.Append(nil, uint64(versionInformationParameterID))
+				b = quicvarint.Append(b, 12)
+				return appendInitialSourceConnectionID(append(b, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0))
+			}(),
+			perspective:    protocol.PerspectiveClient,
+			expectedErrMsg: "version_information: available version is 0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -44,12 +44,18 @@ const (
 	activeConnectionIDLimitParameterID         transportParameterID = 0xe
 	initialSourceConnectionIDParameterID       transportParameterID = 0xf
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
+	// RFC 9368
+	versionInformationParameterID transportParameterID = 0x11
 	// RFC 9221
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// RFC 9287
+	greaseQUICBitParameterID transportParameterID = 0x2ab2
 	// https://datatracker.ietf.org/doc/draft-ietf-quic-reliable-stream-reset/06/
 	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
 	// https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/11/
 	minAckDelayParameterID transportParameterID = 0xff04de1b
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/14/
+	initialMaxPathIDParameterID transportParameterID = 0x0f739bbc1b666d0d
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -59,6 +65,13 @@ type PreferredAddress struct {
 	StatelessResetToken protocol.StatelessResetToken
 }
 
+// VersionInformation is the value encoded in the version_information transport parameter (RFC 9368)
+type VersionInformation struct {
+	ChosenVersion protocol.Version
+	// AvailableVersions are the versions supported by the endpoint, in order of preference.
+	AvailableVersions []protocol.Version
+}
+
 // TransportParameters are parameters sent to the peer during the handshake
 type TransportParameters struct {
 	InitialMaxStreamDataBidiLocal  protocol.ByteCount
@@ -88,8 +101,11 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount // RFC 9221
+	GreaseQUICBit        bool               // RFC 9287
 	EnableResetStreamAt  bool               // https://datatracker.ietf.org/doc/draft-ietf-quic-reliable-stream-reset/06/
 	MinAckDelay          *time.Duration
+	InitialMaxPathID     *protocol.PathID    // https://datatracker.ietf.org/doc/draft-ietf-quic-multipath/14/
+	VersionInformation   *VersionInformation // RFC 9368
 }
 
 // Unmarshal the transport parameters
@@ -146,7 +162,8 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 			maxDatagramFrameSizeParameterID,
 			ackDelayExponentParameterID,
 			activeConnectionIDLimitParameterID,
-			minAckDelayParameterID:
+			minAckDelayParameterID,
+			initialMaxPathIDParameterID:
 			if err := p.readNumericTransportParameter(b, paramID, int(paramLen)); err != nil {
 				return err
 			}
@@ -205,11 +222,21 @@ func (p *TransportParameters) unmarshal(b []byte, sentBy protocol.Perspective, f
 			connID := protocol.ParseConnectionID(b[:paramLen])
 			b = b[paramLen:]
 			p.RetrySourceConnectionID = &connID
+		case versionInformationParameterID:
+			if err := p.readVersionInformation(b[:paramLen]); err != nil {
+				return err
+			}
+			b = b[paramLen:]
 		case resetStreamAtParameterID:
 			if paramLen != 0 {
 				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
 			}
 			p.EnableResetStreamAt = true
+		case greaseQUICBitParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for grease_quic_bit: %d (expected empty)", paramLen)
+			}
+			p.GreaseQUICBit = true
 		default:
 			b = b[paramLen:]
 		}
@@ -286,6 +313,30 @@ func (p *TransportParameters) readPreferredAddress(b []byte, expectedLen int) er
 	return nil
 }
 
+func (p *TransportParameters) readVersionInformation(b []byte) error {
+	if len(b) < 4 || len(b)%4 != 0 {
+		return fmt.Errorf("invalid length for version_information: %d", len(b))
+	}
+	vi := &VersionInformation{ChosenVersion: protocol.Version(binary.BigEndian.Uint32(b))}
+	if vi.ChosenVersion == 0 {
+		return errors.New("version_information: chosen version is 0")
+	}
+	b = b[4:]
+	if len(b) > 0 {
+		vi.AvailableVersions = make([]protocol.Version, 0, len(b)/4)
+	}
+	for len(b) > 0 {
+		v := protocol.Version(binary.BigEndian.Uint32(b))
+		if v == 0 {
+			return errors.New("version_information: available version is 0")
+		}
+		vi.AvailableVersions = append(vi.AvailableVersions, v)
+		b = b[4:]
+	}
+	p.VersionInformation = vi
+	return nil
+}
+
 func (p *TransportParameters) readNumericTransportParameter(b []byte, paramID transportParameterID, expectedLen int) error {
 	val, l, err := quicvarint.Parse(b)
 	if err != nil {
@@ -344,6 +395,12 @@ func (p *TransportParameters) readNumericTransportParameter(b []byte, paramID tr
 			mad = math.MaxInt64
 		}
 		p.MinAckDelay = &mad
+	case initialMaxPathIDParameterID:
+		if val > uint64(protocol.MaxPathID) {
+			return fmt.Errorf("invalid value for initial_max_path_id: %d (maximum %d)", val, protocol.MaxPathID)
+		}
+		maxPathID := protocol.PathID(val)
+		p.InitialMaxPathID = &maxPathID
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -450,6 +507,11 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		b = p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// Greasing the QUIC Bit
+	if p.GreaseQUICBit {
+		b = quicvarint.Append(b, uint64(greaseQUICBitParameterID))
+		b = quicvarint.Append(b, 0)
+	}
 	// QUIC Stream Resets with Partial Delivery
 	if p.EnableResetStreamAt {
 		b = quicvarint.Append(b, uint64(resetStreamAtParameterID))
@@ -458,6 +520,18 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MinAckDelay != nil {
 		b = p.marshalVarintParam(b, minAckDelayParameterID, uint64(*p.MinAckDelay/time.Microsecond))
 	}
+	if p.InitialMaxPathID != nil {
+		b = p.marshalVarintParam(b, initialMaxPathIDParameterID, uint64(*p.InitialMaxPathID))
+	}
+	// version_information
+	if p.VersionInformation != nil {
+		b = quicvarint.Append(b, uint64(versionInformationParameterID))
+		b = quicvarint.Append(b, uint64(4*(1+len(p.VersionInformation.AvailableVersions))))
+		b = binary.BigEndian.AppendUint32(b, uint32(p.VersionInformation.ChosenVersion))
+		for _, v := range p.VersionInformation.AvailableVersions {
+			b = binary.BigEndian.AppendUint32(b, uint32(v))
+		}
+	}
 
 	if pers == protocol.PerspectiveClient && len(AdditionalTransportParametersClient) > 0 {
 		for k, v := range AdditionalTransportParametersClient {
@@ -572,12 +646,20 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
-	logString += ", EnableResetStreamAt: %t"
-	logParams = append(logParams, p.EnableResetStreamAt)
+	logString += ", GreaseQUICBit: %t, EnableResetStreamAt: %t"
+	logParams = append(logParams, p.GreaseQUICBit, p.EnableResetStreamAt)
 	if p.MinAckDelay != nil {
 		logString += ", MinAckDelay: %s"
 		logParams = append(logParams, *p.MinAckDelay)
 	}
+	if p.InitialMaxPathID != nil {
+		logString += ", InitialMaxPathID: %d"
+		logParams = append(logParams, *p.InitialMaxPathID)
+	}
+	if p.VersionInformation != nil {
+		logString += ", ChosenVersion: %s, AvailableVersions: %s"
+		logParams = append(logParams, p.VersionInformation.ChosenVersion, p.VersionInformation.AvailableVersions)
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	connStarted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "connections_started_total",
+			Help:      "Connections Started",
+		},
+		[]string{"dir", "ip_version"},
+	)
+	connClosed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "connections_closed_total",
+			Help:      "Connections Closed",
+		},
+		[]string{"dir", "reason"},
+	)
+	connDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "connection_duration_seconds",
+			Help:      "Duration of a Connection",
+			Buckets:   prometheus.ExponentialBuckets(1.0/16, 2, 25), // up to 24 days
+		},
+		[]string{"dir"},
+	)
+	connHandshakeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "handshake_duration_seconds",
+			Help:      "Duration of the QUIC Handshake",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 1.3, 35),
+		},
+		[]string{"dir"},
+	)
+	packetsSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "packets_sent_total",
+			Help:      "Packets Sent",
+		},
+		[]string{"dir", "type"},
+	)
+	packetsReceived = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "packets_received_total",
+			Help:      "Packets Received",
+		},
+		[]string{"dir", "type"},
+	)
+	connPacketsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "packets_dropped_total",
+			Help:      "Packets Dropped",
+		},
+		[]string{"dir", "type", "reason"},
+	)
+	packetsLost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "packets_lost_total",
+			Help:      "Packets Lost",
+		},
+		[]string{"dir", "type", "reason"},
+	)
+	rttSamples = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "rtt_seconds",
+			Help:      "RTT samples",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 1.5, 25),
+		},
+		[]string{"dir"},
+	)
+	smoothedRTT = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "smoothed_rtt_seconds",
+			Help:      "Smoothed RTT of a Connection, at the time the connection was closed",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 1.5, 25),
+		},
+		[]string{"dir"},
+	)
+	congestionWindow = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricNamespace,
+			Name:      "congestion_window_bytes",
+			Help:      "Congestion Window updates",
+			Buckets:   prometheus.ExponentialBuckets(1<<12, 2, 14), // 4 KB to 32 MB
+		},
+		[]string{"dir"},
+	)
+)
+
+var registerDefaultOnce sync.Once
+
+// DefaultConnectionTracer returns a qlogwriter.Trace that collects metrics
+// using the default Prometheus registerer.
+// It is intended to be used as the Config.Tracer.
+func DefaultConnectionTracer(ctx context.Context, isClient bool, connID qlogwriter.ConnectionID) qlogwriter.Trace {
+	registerDefaultOnce.Do(func() {
+		registerConnectionCollectors(prometheus.DefaultRegisterer)
+	})
+	return newConnectionTrace(isClient)
+}
+
+// NewConnectionTracerWithRegisterer returns a function that can be used as the Config.Tracer.
+// The metrics are registered with the given Prometheus registerer.
+func NewConnectionTracerWithRegisterer(registerer prometheus.Registerer) func(context.Context, bool, qlogwriter.ConnectionID) qlogwriter.Trace {
+	registerConnectionCollectors(registerer)
+	return func(_ context.Context, isClient bool, _ qlogwriter.ConnectionID) qlogwriter.Trace {
+		return newConnectionTrace(isClient)
+	}
+}
+
+func registerConnectionCollectors(registerer prometheus.Registerer) {
+	register(registerer,
+		connStarted,
+		connClosed,
+		connDuration,
+		connHandshakeDuration,
+		packetsSent,
+		packetsReceived,
+		connPacketsDropped,
+		packetsLost,
+		rttSamples,
+		smoothedRTT,
+		congestionWindow,
+	)
+}
+
+// connectionTrace is both the qlogwriter.Trace and the qlogwriter.Recorder of a connection.
+type connectionTrace struct {
+	dir       string
+	startTime time.Time
+
+	mx          sync.Mutex
+	started     bool
+	closed      bool
+	smoothedRTT time.Duration
+}
+
+var (
+	_ qlogwriter.Trace    = &connectionTrace{}
+	_ qlogwriter.Recorder = &connectionTrace{}
+)
+
+func newConnectionTrace(isClient bool) *connectionTrace {
+	dir := "incoming"
+	if isClient {
+		dir = "outgoing"
+	}
+	return &connectionTrace{dir: dir, startTime: time.Now()}
+}
+
+func (t *connectionTrace) AddProducer() qlogwriter.Recorder { return t }
+
+func (t *connectionTrace) SupportsSchemas(schema string) bool { return schema == qlog.EventSchema }
+
+func (t *connectionTrace) RecordEvent(ev qlogwriter.Event) {
+	switch ev := ev.(type) {
+	case qlog.StartedConnection:
+		t.mx.Lock()
+		started := t.started
+		t.started = true
+		t.mx.Unlock()
+		if !started {
+			connStarted.WithLabelValues(t.dir, ipVersion(ev.Remote)).Inc()
+		}
+	case qlog.ALPNInformation:
+		// The ALPN is logged when the handshake completes.
+		connHandshakeDuration.WithLabelValues(t.dir).Observe(time.Since(t.startTime).Seconds())
+	case qlog.PacketSent:
+		packetsSent.WithLabelValues(t.dir, qlog.PacketTypeLabel(ev.Header.PacketType)).Inc()
+	case qlog.PacketReceived:
+		packetsReceived.WithLabelValues(t.dir, qlog.PacketTypeLabel(ev.Header.PacketType)).Inc()
+	case qlog.VersionNegotiationReceived:
+		packetsReceived.WithLabelValues(t.dir, string(qlog.PacketTypeVersionNegotiation)).Inc()
+	case qlog.PacketDropped:
+		connPacketsDropped.WithLabelValues(t.dir, qlog.PacketTypeLabel(ev.Header.PacketType), string(ev.Trigger)).Inc()
+	case qlog.PacketLost:
+		packetsLost.WithLabelValues(t.dir, qlog.PacketTypeLabel(ev.Header.PacketType), string(ev.Trigger)).Inc()
+	case qlog.MetricsUpdated:
+		// Only the values that changed are set.
+		if ev.LatestRTT != 0 {
+			rttSamples.WithLabelValues(t.dir).Observe(ev.LatestRTT.Seconds())
+		}
+		if ev.CongestionWindow != 0 {
+			congestionWindow.WithLabelValues(t.dir).Observe(float64(ev.CongestionWindow))
+		}
+		if ev.SmoothedRTT != 0 {
+			t.mx.Lock()
+			t.smoothedRTT = ev.SmoothedRTT
+			t.mx.Unlock()
+		}
+	case qlog.ConnectionClosed:
+		t.mx.Lock()
+		if !t.started || t.closed {
+			t.mx.Unlock()
+			return
+		}
+		t.closed = true
+		rtt := t.smoothedRTT
+		t.mx.Unlock()
+
+		connClosed.WithLabelValues(t.dir, qlog.CloseReasonLabel(ev)).Inc()
+		connDuration.WithLabelValues(t.dir).Observe(time.Since(t.startTime).Seconds())
+		if rtt != 0 {
+			smoothedRTT.WithLabelValues(t.dir).Observe(rtt.Seconds())
+		}
+	}
+}
+
+func (t *connectionTrace) Close() error { return nil }
+
+func ipVersion(info qlog.PathEndpointInfo) string {
+	switch {
+	case info.IPv4.IsValid():
+		return "ipv4"
+	case info.IPv6.IsValid():
+		return "ipv6"
+	default:
+		return "unknown"
+	}
+}
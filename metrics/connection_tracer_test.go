@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/qlog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func histogramSampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestConnectionTracerSchemas(t *testing.T) {
+	trace := NewConnectionTracerWithRegisterer(prometheus.NewRegistry())(context.Background(), true, qlog.ConnectionID{})
+	require.True(t, trace.SupportsSchemas(qlog.EventSchema))
+	require.False(t, trace.SupportsSchemas("urn:ietf:params:qlog:events:http3-12"))
+}
+
+func TestConnectionTracerLifecycle(t *testing.T) {
+	tracer := NewConnectionTracerWithRegisterer(prometheus.NewRegistry())
+
+	started := testutil.ToFloat64(connStarted.WithLabelValues("incoming", "ipv4"))
+	closed := testutil.ToFloat64(connClosed.WithLabelValues("incoming", "idle_timeout"))
+	handshakes := histogramSampleCount(t, connHandshakeDuration.WithLabelValues("incoming"))
+	durations := histogramSampleCount(t, connDuration.WithLabelValues("incoming"))
+	rtts := histogramSampleCount(t, smoothedRTT.WithLabelValues("incoming"))
+
+	r := tracer(context.Background(), false, qlog.ConnectionID{}).AddProducer()
+	r.RecordEvent(qlog.StartedConnection{
+		Remote: qlog.PathEndpointInfo{IPv4: netip.MustParseAddrPort("1.2.3.4:1234")},
+	})
+	r.RecordEvent(qlog.ALPNInformation{ChosenALPN: "h3"})
+	r.RecordEvent(qlog.MetricsUpdated{SmoothedRTT: 10 * time.Millisecond})
+	r.RecordEvent(qlog.ConnectionClosed{
+		Initiator: qlog.InitiatorLocal,
+		Trigger:   qlog.ConnectionCloseTriggerIdleTimeout,
+	})
+	// the connection is only counted once
+	r.RecordEvent(qlog.ConnectionClosed{Trigger: qlog.ConnectionCloseTriggerIdleTimeout})
+	require.NoError(t, r.Close())
+
+	require.Equal(t, started+1, testutil.ToFloat64(connStarted.WithLabelValues("incoming", "ipv4")))
+	require.Equal(t, closed+1, testutil.ToFloat64(connClosed.WithLabelValues("incoming", "idle_timeout")))
+	require.Equal(t, handshakes+1, histogramSampleCount(t, connHandshakeDuration.WithLabelValues("incoming")))
+	require.Equal(t, durations+1, histogramSampleCount(t, connDuration.WithLabelValues("incoming")))
+	require.Equal(t, rtts+1, histogramSampleCount(t, smoothedRTT.WithLabelValues("incoming")))
+}
+
+func TestConnectionTracerCloseReasons(t *testing.T) {
+	tracer := NewConnectionTracerWithRegisterer(prometheus.NewRegistry())
+
+	appErrorCode := qlog.ApplicationErrorCode(42)
+	transportErrorCode := qlog.TransportErrorCode(quic.FlowControlError)
+	for _, tc := range []struct {
+		name   string
+		event  qlog.ConnectionClosed
+		reason string
+	}{
+		{name: "stateless reset", event: qlog.ConnectionClosed{Trigger: qlog.ConnectionCloseTriggerStatelessReset}, reason: "stateless_reset"},
+		{name: "application error", event: qlog.ConnectionClosed{ApplicationError: &appErrorCode}, reason: "application_error"},
+		{name: "transport error", event: qlog.ConnectionClosed{ConnectionError: &transportErrorCode}, reason: "flow_control_error"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := testutil.ToFloat64(connClosed.WithLabelValues("outgoing", tc.reason))
+			r := tracer(context.Background(), true, qlog.ConnectionID{}).AddProducer()
+			r.RecordEvent(qlog.StartedConnection{})
+			r.RecordEvent(tc.event)
+			require.Equal(t, before+1, testutil.ToFloat64(connClosed.WithLabelValues("outgoing", tc.reason)))
+		})
+	}
+}
+
+func TestConnectionTracerPackets(t *testing.T) {
+	tracer := NewConnectionTracerWithRegisterer(prometheus.NewRegistry())
+
+	sent := testutil.ToFloat64(packetsSent.WithLabelValues("outgoing", "1RTT"))
+	received := testutil.ToFloat64(packetsReceived.WithLabelValues("outgoing", "handshake"))
+	vnReceived := testutil.ToFloat64(packetsReceived.WithLabelValues("outgoing", "version_negotiation"))
+	dropped := testutil.ToFloat64(connPacketsDropped.WithLabelValues("outgoing", "1RTT", string(qlog.PacketDropDuplicate)))
+	lost := testutil.ToFloat64(packetsLost.WithLabelValues("outgoing", "initial", string(qlog.PacketLossTimeThreshold)))
+	rttCount := histogramSampleCount(t, rttSamples.WithLabelValues("outgoing"))
+	cwndCount := histogramSampleCount(t, congestionWindow.WithLabelValues("outgoing"))
+
+	r := tracer(context.Background(), true, qlog.ConnectionID{}).AddProducer()
+	r.RecordEvent(qlog.PacketSent{Header: qlog.PacketHeader{PacketType: qlog.PacketType1RTT}})
+	r.RecordEvent(qlog.PacketSent{Header: qlog.PacketHeader{PacketType: qlog.PacketType1RTT}})
+	r.RecordEvent(qlog.PacketReceived{Header: qlog.PacketHeader{PacketType: qlog.PacketTypeHandshake}})
+	r.RecordEvent(qlog.VersionNegotiationReceived{})
+	r.RecordEvent(qlog.PacketDropped{
+		Header:  qlog.PacketHeader{PacketType: qlog.PacketType1RTT},
+		Trigger: qlog.PacketDropDuplicate,
+	})
+	r.RecordEvent(qlog.PacketLost{
+		Header:  qlog.PacketHeader{PacketType: qlog.PacketTypeInitial},
+		Trigger: qlog.PacketLossTimeThreshold,
+	})
+	r.RecordEvent(qlog.MetricsUpdated{LatestRTT: 5 * time.Millisecond, CongestionWindow: 12000})
+	// only the congestion window changed
+	r.RecordEvent(qlog.MetricsUpdated{CongestionWindow: 24000})
+
+	require.Equal(t, sent+2, testutil.ToFloat64(packetsSent.WithLabelValues("outgoing", "1RTT")))
+	require.Equal(t, received+1, testutil.ToFloat64(packetsReceived.WithLabelValues("outgoing", "handshake")))
+	require.Equal(t, vnReceived+1, testutil.ToFloat64(packetsReceived.WithLabelValues("outgoing", "version_negotiation")))
+	require.Equal(t, dropped+1, testutil.ToFloat64(connPacketsDropped.WithLabelValues("outgoing", "1RTT", string(qlog.PacketDropDuplicate))))
+	require.Equal(t, lost+1, testutil.ToFloat64(packetsLost.WithLabelValues("outgoing", "initial", string(qlog.PacketLossTimeThreshold))))
+	require.Equal(t, rttCount+1, histogramSampleCount(t, rttSamples.WithLabelValues("outgoing")))
+	require.Equal(t, cwndCount+2, histogramSampleCount(t, congestionWindow.WithLabelValues("outgoing")))
+}
@@ -0,0 +1,141 @@
+package metrics_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func getTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	root := x509.NewCertPool()
+	root.AddCert(cert)
+	server = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{certDER}, PrivateKey: priv}},
+		NextProtos:   []string{"metrics-test"},
+	}
+	client = &tls.Config{
+		ServerName: "localhost",
+		RootCAs:    root,
+		NextProtos: []string{"metrics-test"},
+	}
+	return server, client
+}
+
+func newUDPConnLocalhost(t *testing.T) *net.UDPConn {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// gatherMetric sums up all samples of a counter or histogram that match the given labels.
+func gatherMetric(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var sum float64
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+					continue metrics
+				}
+			}
+			switch f.GetType() {
+			case dto.MetricType_COUNTER:
+				sum += m.GetCounter().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				sum += float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return sum
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	connTracer := metrics.NewConnectionTracerWithRegisterer(reg)
+	tr := &quic.Transport{Conn: newUDPConnLocalhost(t), Tracer: metrics.NewTracerWithRegisterer(reg)}
+	defer tr.Close()
+
+	serverTLSConf, clientTLSConf := getTLSConfigs(t)
+	ln, err := tr.Listen(serverTLSConf, &quic.Config{Tracer: connTracer})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	startedIncoming := gatherMetric(t, reg, "quicgo_connections_started_total", map[string]string{"dir": "incoming"})
+	startedOutgoing := gatherMetric(t, reg, "quicgo_connections_started_total", map[string]string{"dir": "outgoing"})
+	closedIncoming := gatherMetric(t, reg, "quicgo_connections_closed_total", map[string]string{"dir": "incoming", "reason": "application_error"})
+	handshakes := gatherMetric(t, reg, "quicgo_handshake_duration_seconds", map[string]string{"dir": "outgoing"})
+	packetsSent := gatherMetric(t, reg, "quicgo_packets_sent_total", map[string]string{"dir": "outgoing", "type": "initial"})
+	packetsReceived := gatherMetric(t, reg, "quicgo_packets_received_total", map[string]string{"dir": "incoming", "type": "1RTT"})
+	rttSamples := gatherMetric(t, reg, "quicgo_rtt_seconds", map[string]string{"dir": "outgoing"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := quic.Dial(ctx, newUDPConnLocalhost(t), ln.Addr(), clientTLSConf, &quic.Config{Tracer: connTracer})
+	require.NoError(t, err)
+	sconn, err := ln.Accept(ctx)
+	require.NoError(t, err)
+	str, err := conn.OpenUniStream()
+	require.NoError(t, err)
+	_, err = str.Write([]byte("foobar"))
+	require.NoError(t, err)
+	require.NoError(t, str.Close())
+	sstr, err := sconn.AcceptUniStream(ctx)
+	require.NoError(t, err)
+	data, err := io.ReadAll(sstr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("foobar"), data)
+
+	conn.CloseWithError(0, "")
+	select {
+	case <-sconn.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	require.Equal(t, startedIncoming+1, gatherMetric(t, reg, "quicgo_connections_started_total", map[string]string{"dir": "incoming"}))
+	require.Equal(t, startedOutgoing+1, gatherMetric(t, reg, "quicgo_connections_started_total", map[string]string{"dir": "outgoing"}))
+	require.Equal(t, closedIncoming+1, gatherMetric(t, reg, "quicgo_connections_closed_total", map[string]string{"dir": "incoming", "reason": "application_error"}))
+	require.Equal(t, handshakes+1, gatherMetric(t, reg, "quicgo_handshake_duration_seconds", map[string]string{"dir": "outgoing"}))
+	require.Greater(t, gatherMetric(t, reg, "quicgo_packets_sent_total", map[string]string{"dir": "outgoing", "type": "initial"}), packetsSent)
+	require.Greater(t, gatherMetric(t, reg, "quicgo_packets_received_total", map[string]string{"dir": "incoming", "type": "1RTT"}), packetsReceived)
+	require.Greater(t, gatherMetric(t, reg, "quicgo_rtt_seconds", map[string]string{"dir": "outgoing"}), rttSamples)
+}
@@ -0,0 +1,88 @@
+// Package metrics exposes Prometheus metrics for QUIC connections.
+//
+// The metrics are derived from the qlog events emitted by quic-go.
+// Use [NewTracer] as the Transport.Tracer to collect metrics about packets
+// that don't belong to a connection, and [DefaultConnectionTracer] as the
+// Config.Tracer to collect metrics about connections.
+package metrics
+
+import (
+	"errors"
+
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricNamespace = "quicgo"
+
+var (
+	connsRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "server_connections_rejected_total",
+			Help:      "Connections Rejected",
+		},
+		[]string{"reason"},
+	)
+	packetsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "server_received_packets_dropped_total",
+			Help:      "Packets dropped before they could be associated with a connection",
+		},
+		[]string{"type", "reason"},
+	)
+)
+
+func register(registerer prometheus.Registerer, collectors ...prometheus.Collector) {
+	for _, c := range collectors {
+		if err := registerer.Register(c); err != nil {
+			if ok := errors.As(err, &prometheus.AlreadyRegisteredError{}); !ok {
+				panic(err)
+			}
+		}
+	}
+}
+
+// NewTracer creates a new tracer using the default Prometheus registerer.
+// It is intended to be used as the Transport.Tracer.
+func NewTracer() qlogwriter.Recorder {
+	return NewTracerWithRegisterer(prometheus.DefaultRegisterer)
+}
+
+// NewTracerWithRegisterer creates a new tracer using a given Prometheus registerer.
+// It is intended to be used as the Transport.Tracer.
+func NewTracerWithRegisterer(registerer prometheus.Registerer) qlogwriter.Recorder {
+	register(registerer, connsRejected, packetsDropped)
+	return &tracer{}
+}
+
+type tracer struct{}
+
+var _ qlogwriter.Recorder = &tracer{}
+
+func (t *tracer) RecordEvent(ev qlogwriter.Event) {
+	switch ev := ev.(type) {
+	case qlog.PacketSent:
+		switch ev.Header.PacketType {
+		case qlog.PacketTypeRetry:
+			connsRejected.WithLabelValues("retry").Inc()
+		case qlog.PacketTypeInitial:
+			// The server sends an Initial packet containing a CONNECTION_CLOSE frame
+			// when it refuses a connection attempt before creating a connection.
+			for _, f := range ev.Frames {
+				if ccf, ok := f.Frame.(*qlog.ConnectionCloseFrame); ok && !ccf.IsApplicationError {
+					connsRejected.WithLabelValues(qlog.TransportErrorLabel(qlog.TransportErrorCode(ccf.ErrorCode))).Inc()
+				}
+			}
+		}
+	case qlog.VersionNegotiationSent:
+		connsRejected.WithLabelValues("version_negotiation").Inc()
+	case qlog.PacketDropped:
+		packetsDropped.WithLabelValues(qlog.PacketTypeLabel(ev.Header.PacketType), string(ev.Trigger)).Inc()
+	}
+}
+
+func (t *tracer) Close() error { return nil }
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/qlog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracerRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	NewTracerWithRegisterer(reg)
+	// registering the collectors a second time is a no-op
+	NewTracerWithRegisterer(reg)
+}
+
+func TestTracerConnectionsRejected(t *testing.T) {
+	tr := NewTracerWithRegisterer(prometheus.NewRegistry())
+
+	retries := testutil.ToFloat64(connsRejected.WithLabelValues("retry"))
+	vns := testutil.ToFloat64(connsRejected.WithLabelValues("version_negotiation"))
+	refused := testutil.ToFloat64(connsRejected.WithLabelValues("connection_refused"))
+
+	tr.RecordEvent(qlog.PacketSent{Header: qlog.PacketHeader{PacketType: qlog.PacketTypeRetry}})
+	tr.RecordEvent(qlog.VersionNegotiationSent{})
+	tr.RecordEvent(qlog.PacketSent{
+		Header: qlog.PacketHeader{PacketType: qlog.PacketTypeInitial},
+		Frames: []qlog.Frame{{Frame: &qlog.ConnectionCloseFrame{ErrorCode: uint64(quic.ConnectionRefused)}}},
+	})
+	// Initial packets without a CONNECTION_CLOSE frame are not counted
+	tr.RecordEvent(qlog.PacketSent{
+		Header: qlog.PacketHeader{PacketType: qlog.PacketTypeInitial},
+		Frames: []qlog.Frame{{Frame: &qlog.PingFrame{}}},
+	})
+
+	require.Equal(t, retries+1, testutil.ToFloat64(connsRejected.WithLabelValues("retry")))
+	require.Equal(t, vns+1, testutil.ToFloat64(connsRejected.WithLabelValues("version_negotiation")))
+	require.Equal(t, refused+1, testutil.ToFloat64(connsRejected.WithLabelValues("connection_refused")))
+}
+
+func TestTracerPacketsDropped(t *testing.T) {
+	tr := NewTracerWithRegisterer(prometheus.NewRegistry())
+
+	unknown := testutil.ToFloat64(packetsDropped.WithLabelValues("unknown", string(qlog.PacketDropHeaderParseError)))
+	initial := testutil.ToFloat64(packetsDropped.WithLabelValues("initial", string(qlog.PacketDropDOSPrevention)))
+
+	tr.RecordEvent(qlog.PacketDropped{Trigger: qlog.PacketDropHeaderParseError})
+	tr.RecordEvent(qlog.PacketDropped{
+		Header:  qlog.PacketHeader{PacketType: qlog.PacketTypeInitial},
+		Trigger: qlog.PacketDropDOSPrevention,
+	})
+
+	require.Equal(t, unknown+1, testutil.ToFloat64(packetsDropped.WithLabelValues("unknown", string(qlog.PacketDropHeaderParseError))))
+	require.Equal(t, initial+1, testutil.ToFloat64(packetsDropped.WithLabelValues("initial", string(qlog.PacketDropDOSPrevention))))
+}
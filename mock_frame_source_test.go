@@ -119,3 +119,42 @@ func (c *MockFrameSourceHasDataCall) DoAndReturn(f func() bool) *MockFrameSource
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// StreamUrgency mocks base method.
+func (m *MockFrameSource) StreamUrgency() (uint8, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamUrgency")
+	ret0, _ := ret[0].(uint8)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// StreamUrgency indicates an expected call of StreamUrgency.
+func (mr *MockFrameSourceMockRecorder) StreamUrgency() *MockFrameSourceStreamUrgencyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamUrgency", reflect.TypeOf((*MockFrameSource)(nil).StreamUrgency))
+	return &MockFrameSourceStreamUrgencyCall{Call: call}
+}
+
+// MockFrameSourceStreamUrgencyCall wrap *gomock.Call
+type MockFrameSourceStreamUrgencyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockFrameSourceStreamUrgencyCall) Return(arg0 uint8, arg1 bool) *MockFrameSourceStreamUrgencyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockFrameSourceStreamUrgencyCall) Do(f func() (uint8, bool)) *MockFrameSourceStreamUrgencyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockFrameSourceStreamUrgencyCall) DoAndReturn(f func() (uint8, bool)) *MockFrameSourceStreamUrgencyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
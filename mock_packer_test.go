@@ -82,6 +82,81 @@ func (c *MockPackerAppendPacketCall) DoAndReturn(f func(*packetBuffer, protocol.
 	return c
 }
 
+// AppendPathPacket mocks base method.
+func (m *MockPacker) AppendPathPacket(arg0 *packetBuffer, arg1 packerPath, onlyAck bool, maxPacketSize protocol.ByteCount, now monotime.Time, v protocol.Version) (shortHeaderPacket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendPathPacket", arg0, arg1, onlyAck, maxPacketSize, now, v)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendPathPacket indicates an expected call of AppendPathPacket.
+func (mr *MockPackerMockRecorder) AppendPathPacket(arg0, arg1, onlyAck, maxPacketSize, now, v any) *MockPackerAppendPathPacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendPathPacket", reflect.TypeOf((*MockPacker)(nil).AppendPathPacket), arg0, arg1, onlyAck, maxPacketSize, now, v)
+	return &MockPackerAppendPathPacketCall{Call: call}
+}
+
+// MockPackerAppendPathPacketCall wrap *gomock.Call
+type MockPackerAppendPathPacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerAppendPathPacketCall) Return(arg0 shortHeaderPacket, arg1 error) *MockPackerAppendPathPacketCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerAppendPathPacketCall) Do(f func(*packetBuffer, packerPath, bool, protocol.ByteCount, monotime.Time, protocol.Version) (shortHeaderPacket, error)) *MockPackerAppendPathPacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerAppendPathPacketCall) DoAndReturn(f func(*packetBuffer, packerPath, bool, protocol.ByteCount, monotime.Time, protocol.Version) (shortHeaderPacket, error)) *MockPackerAppendPathPacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// EnableQUICBitGreasing mocks base method.
+func (m *MockPacker) EnableQUICBitGreasing() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableQUICBitGreasing")
+}
+
+// EnableQUICBitGreasing indicates an expected call of EnableQUICBitGreasing.
+func (mr *MockPackerMockRecorder) EnableQUICBitGreasing() *MockPackerEnableQUICBitGreasingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableQUICBitGreasing", reflect.TypeOf((*MockPacker)(nil).EnableQUICBitGreasing))
+	return &MockPackerEnableQUICBitGreasingCall{Call: call}
+}
+
+// MockPackerEnableQUICBitGreasingCall wrap *gomock.Call
+type MockPackerEnableQUICBitGreasingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerEnableQUICBitGreasingCall) Return() *MockPackerEnableQUICBitGreasingCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerEnableQUICBitGreasingCall) Do(f func()) *MockPackerEnableQUICBitGreasingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerEnableQUICBitGreasingCall) DoAndReturn(f func()) *MockPackerEnableQUICBitGreasingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // PackAckOnlyPacket mocks base method.
 func (m *MockPacker) PackAckOnlyPacket(maxPacketSize protocol.ByteCount, now monotime.Time, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
 	m.ctrl.T.Helper()
@@ -318,6 +393,46 @@ func (c *MockPackerPackPTOProbePacketCall) DoAndReturn(f func(protocol.Encryptio
 	return c
 }
 
+// PackPathFramesPacket mocks base method.
+func (m *MockPacker) PackPathFramesPacket(arg0 packerPath, arg1 []ackhandler.Frame, isPathProbe bool, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PackPathFramesPacket", arg0, arg1, isPathProbe, v)
+	ret0, _ := ret[0].(shortHeaderPacket)
+	ret1, _ := ret[1].(*packetBuffer)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PackPathFramesPacket indicates an expected call of PackPathFramesPacket.
+func (mr *MockPackerMockRecorder) PackPathFramesPacket(arg0, arg1, isPathProbe, v any) *MockPackerPackPathFramesPacketCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PackPathFramesPacket", reflect.TypeOf((*MockPacker)(nil).PackPathFramesPacket), arg0, arg1, isPathProbe, v)
+	return &MockPackerPackPathFramesPacketCall{Call: call}
+}
+
+// MockPackerPackPathFramesPacketCall wrap *gomock.Call
+type MockPackerPackPathFramesPacketCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPackerPackPathFramesPacketCall) Return(arg0 shortHeaderPacket, arg1 *packetBuffer, arg2 error) *MockPackerPackPathFramesPacketCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPackerPackPathFramesPacketCall) Do(f func(packerPath, []ackhandler.Frame, bool, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathFramesPacketCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPackerPackPathFramesPacketCall) DoAndReturn(f func(packerPath, []ackhandler.Frame, bool, protocol.Version) (shortHeaderPacket, *packetBuffer, error)) *MockPackerPackPathFramesPacketCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // PackPathProbePacket mocks base method.
 func (m *MockPacker) PackPathProbePacket(arg0 protocol.ConnectionID, arg1 []ackhandler.Frame, arg2 protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
 	m.ctrl.T.Helper()
@@ -234,6 +234,45 @@ func (c *MockRawConnWritePacketCall) DoAndReturn(f func([]byte, net.Addr, []byte
 	return c
 }
 
+// WritePackets mocks base method.
+func (m *MockRawConn) WritePackets(arg0 []outgoingPacket) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WritePackets", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WritePackets indicates an expected call of WritePackets.
+func (mr *MockRawConnMockRecorder) WritePackets(arg0 any) *MockRawConnWritePacketsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WritePackets", reflect.TypeOf((*MockRawConn)(nil).WritePackets), arg0)
+	return &MockRawConnWritePacketsCall{Call: call}
+}
+
+// MockRawConnWritePacketsCall wrap *gomock.Call
+type MockRawConnWritePacketsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRawConnWritePacketsCall) Return(arg0 int, arg1 error) *MockRawConnWritePacketsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRawConnWritePacketsCall) Do(f func([]outgoingPacket) (int, error)) *MockRawConnWritePacketsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRawConnWritePacketsCall) DoAndReturn(f func([]outgoingPacket) (int, error)) *MockRawConnWritePacketsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // capabilities mocks base method.
 func (m *MockRawConn) capabilities() connCapabilities {
 	m.ctrl.T.Helper()
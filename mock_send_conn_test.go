@@ -13,6 +13,7 @@ import (
 	net "net"
 	reflect "reflect"
 
+	monotime "github.com/quic-go/quic-go/internal/monotime"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -192,17 +193,17 @@ func (c *MockSendConnRemoteAddrCall) DoAndReturn(f func() net.Addr) *MockSendCon
 }
 
 // Write mocks base method.
-func (m *MockSendConn) Write(b []byte, gsoSize uint16, ecn protocol.ECN) error {
+func (m *MockSendConn) Write(b []byte, gsoSize uint16, ecn protocol.ECN, txTime monotime.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Write", b, gsoSize, ecn)
+	ret := m.ctrl.Call(m, "Write", b, gsoSize, ecn, txTime)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Write indicates an expected call of Write.
-func (mr *MockSendConnMockRecorder) Write(b, gsoSize, ecn any) *MockSendConnWriteCall {
+func (mr *MockSendConnMockRecorder) Write(b, gsoSize, ecn, txTime any) *MockSendConnWriteCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSendConn)(nil).Write), b, gsoSize, ecn)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSendConn)(nil).Write), b, gsoSize, ecn, txTime)
 	return &MockSendConnWriteCall{Call: call}
 }
 
@@ -218,13 +219,52 @@ func (c *MockSendConnWriteCall) Return(arg0 error) *MockSendConnWriteCall {
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockSendConnWriteCall) Do(f func([]byte, uint16, protocol.ECN) error) *MockSendConnWriteCall {
+func (c *MockSendConnWriteCall) Do(f func([]byte, uint16, protocol.ECN, monotime.Time) error) *MockSendConnWriteCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockSendConnWriteCall) DoAndReturn(f func([]byte, uint16, protocol.ECN) error) *MockSendConnWriteCall {
+func (c *MockSendConnWriteCall) DoAndReturn(f func([]byte, uint16, protocol.ECN, monotime.Time) error) *MockSendConnWriteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WriteBatch mocks base method.
+func (m *MockSendConn) WriteBatch(arg0 []outgoingPacket) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBatch", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteBatch indicates an expected call of WriteBatch.
+func (mr *MockSendConnMockRecorder) WriteBatch(arg0 any) *MockSendConnWriteBatchCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBatch", reflect.TypeOf((*MockSendConn)(nil).WriteBatch), arg0)
+	return &MockSendConnWriteBatchCall{Call: call}
+}
+
+// MockSendConnWriteBatchCall wrap *gomock.Call
+type MockSendConnWriteBatchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendConnWriteBatchCall) Return(arg0 int, arg1 error) *MockSendConnWriteBatchCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendConnWriteBatchCall) Do(f func([]outgoingPacket) (int, error)) *MockSendConnWriteBatchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendConnWriteBatchCall) DoAndReturn(f func([]outgoingPacket) (int, error)) *MockSendConnWriteBatchCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
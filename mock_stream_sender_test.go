@@ -183,3 +183,39 @@ func (c *MockStreamSenderonStreamCompletedCall) DoAndReturn(f func(protocol.Stre
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// onStreamPriorityChanged mocks base method.
+func (m *MockStreamSender) onStreamPriorityChanged(arg0 protocol.StreamID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "onStreamPriorityChanged", arg0)
+}
+
+// onStreamPriorityChanged indicates an expected call of onStreamPriorityChanged.
+func (mr *MockStreamSenderMockRecorder) onStreamPriorityChanged(arg0 any) *MockStreamSenderonStreamPriorityChangedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onStreamPriorityChanged", reflect.TypeOf((*MockStreamSender)(nil).onStreamPriorityChanged), arg0)
+	return &MockStreamSenderonStreamPriorityChangedCall{Call: call}
+}
+
+// MockStreamSenderonStreamPriorityChangedCall wrap *gomock.Call
+type MockStreamSenderonStreamPriorityChangedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSenderonStreamPriorityChangedCall) Return() *MockStreamSenderonStreamPriorityChangedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSenderonStreamPriorityChangedCall) Do(f func(protocol.StreamID)) *MockStreamSenderonStreamPriorityChangedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSenderonStreamPriorityChangedCall) DoAndReturn(f func(protocol.StreamID)) *MockStreamSenderonStreamPriorityChangedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -81,6 +81,48 @@ func (c *MockUnpackerUnpackLongHeaderCall) DoAndReturn(f func(*wire.Header, []by
 	return c
 }
 
+// UnpackPathShortHeader mocks base method.
+func (m *MockUnpacker) UnpackPathShortHeader(rcvTime monotime.Time, pathID protocol.PathID, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpackPathShortHeader", rcvTime, pathID, data)
+	ret0, _ := ret[0].(protocol.PacketNumber)
+	ret1, _ := ret[1].(protocol.PacketNumberLen)
+	ret2, _ := ret[2].(protocol.KeyPhaseBit)
+	ret3, _ := ret[3].([]byte)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// UnpackPathShortHeader indicates an expected call of UnpackPathShortHeader.
+func (mr *MockUnpackerMockRecorder) UnpackPathShortHeader(rcvTime, pathID, data any) *MockUnpackerUnpackPathShortHeaderCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpackPathShortHeader", reflect.TypeOf((*MockUnpacker)(nil).UnpackPathShortHeader), rcvTime, pathID, data)
+	return &MockUnpackerUnpackPathShortHeaderCall{Call: call}
+}
+
+// MockUnpackerUnpackPathShortHeaderCall wrap *gomock.Call
+type MockUnpackerUnpackPathShortHeaderCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUnpackerUnpackPathShortHeaderCall) Return(arg0 protocol.PacketNumber, arg1 protocol.PacketNumberLen, arg2 protocol.KeyPhaseBit, arg3 []byte, arg4 error) *MockUnpackerUnpackPathShortHeaderCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2, arg3, arg4)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUnpackerUnpackPathShortHeaderCall) Do(f func(monotime.Time, protocol.PathID, []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)) *MockUnpackerUnpackPathShortHeaderCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUnpackerUnpackPathShortHeaderCall) DoAndReturn(f func(monotime.Time, protocol.PathID, []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error)) *MockUnpackerUnpackPathShortHeaderCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UnpackShortHeader mocks base method.
 func (m *MockUnpacker) UnpackShortHeader(rcvTime monotime.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
 	m.ctrl.T.Helper()
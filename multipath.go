@@ -0,0 +1,1024 @@
+package quic
+
+import (
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/qerr"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/qlog"
+)
+
+// multipathPath is a path other than the initial path, used by the multipath extension.
+// Every path has its own packet number space, RTT estimate and congestion controller.
+type multipathPath struct {
+	id protocol.PathID
+
+	conn      sendConn // nil until the client sends the first packet on this path
+	sendQueue sender
+	// set when the send queue was full, so we wake up the run loop once it drains
+	sendQueueBlocked atomic.Bool
+	sendErr          atomic.Pointer[error]
+
+	rttStats              *utils.RTTStats
+	sentPacketHandler     ackhandler.SentPacketHandler
+	receivedPacketHandler ackhandler.ReceivedPacketHandler
+
+	spinBit *spinBit
+
+	pathChallenges [][8]byte
+	validated      bool
+	backup         bool   // set by the peer using PATH_STATUS frames
+	statusSeq      uint64 // the sequence number of the last PATH_STATUS frame + 1
+	// set when the PTO fired, cleared when an ACK for this path is received
+	unresponsive bool
+
+	// PATH_CHALLENGE and PATH_RESPONSE frames need to be sent on the path they belong to
+	queuedFrames []ackhandler.Frame
+}
+
+func (p *multipathPath) canSend() bool {
+	return p.conn != nil && p.sendErr.Load() == nil
+}
+
+// multipathManager manages the paths of a connection that negotiated the multipath extension
+// (see https://datatracker.ietf.org/doc/html/draft-ietf-quic-multipath-14).
+// The initial path (path ID 0) is handled by the connection itself,
+// the multipathManager keeps track of all other paths.
+type multipathManager struct {
+	// the highest path ID we allow the peer to use
+	localMaxPathID protocol.PathID
+	// the highest path ID the peer allows us to use
+	peerMaxPathID protocol.PathID
+	// the next path ID to open (only used by the client)
+	nextPathID protocol.PathID
+
+	paths          map[protocol.PathID]*multipathPath
+	abandonedPaths map[protocol.PathID]struct{}
+	initialBackup  bool
+	initialSeq     uint64
+	// set when the PTO of the initial path fired, cleared when an ACK for the initial path is received
+	initialUnresponsive bool
+
+	// connection IDs issued by the peer, using PATH_NEW_CONNECTION_ID frames
+	peerConnIDs   map[protocol.PathID][]newConnID
+	retirePriorTo map[protocol.PathID]uint64
+
+	scheduler PathScheduler
+	pathInfos []PathInfo
+
+	// The client opens a multipath path for every Path created by Conn.AddPath.
+	// Paths are closed from the application's goroutine, so access is protected by a mutex.
+	mx             sync.Mutex
+	clientPaths    map[pathID]protocol.PathID
+	pathsToAbandon []protocol.PathID
+	// the paths reported by Conn.ConnectionStats, once they're used for sending
+	statsPaths map[protocol.PathID]*multipathPath
+}
+
+func newMultipathManager(peerMaxPathID protocol.PathID, scheduler PathScheduler) *multipathManager {
+	return &multipathManager{
+		localMaxPathID: protocol.MaxMultipathPathID,
+		peerMaxPathID:  peerMaxPathID,
+		nextPathID:     protocol.InitialPathID + 1,
+		paths:          make(map[protocol.PathID]*multipathPath),
+		abandonedPaths: make(map[protocol.PathID]struct{}),
+		peerConnIDs:    make(map[protocol.PathID][]newConnID),
+		retirePriorTo:  make(map[protocol.PathID]uint64),
+		clientPaths:    make(map[pathID]protocol.PathID),
+		statsPaths:     make(map[protocol.PathID]*multipathPath),
+		scheduler:      scheduler,
+	}
+}
+
+func (m *multipathManager) maxPathID() protocol.PathID {
+	return min(m.localMaxPathID, m.peerMaxPathID)
+}
+
+func (m *multipathManager) destConnID(id protocol.PathID) (protocol.ConnectionID, bool) {
+	connIDs := m.peerConnIDs[id]
+	if len(connIDs) == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	return connIDs[0].ConnectionID, true
+}
+
+func (m *multipathManager) pathForDestConnID(connID protocol.ConnectionID) *multipathPath {
+	for id, p := range m.paths {
+		if c, ok := m.destConnID(id); ok && c == connID {
+			return p
+		}
+	}
+	return nil
+}
+
+func (m *multipathManager) isActiveStatelessResetToken(token protocol.StatelessResetToken) bool {
+	for _, connIDs := range m.peerConnIDs {
+		for _, c := range connIDs {
+			if c.StatelessResetToken == token {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *multipathManager) handlePathResponse(f *wire.PathResponseFrame) bool {
+	for _, p := range m.paths {
+		if slices.Contains(p.pathChallenges, f.Data) {
+			p.validated = true
+			p.pathChallenges = nil
+			return true
+		}
+	}
+	return false
+}
+
+// queueAbandonClientPath is called when the application closes a path.
+// It returns false if no multipath path was opened for this path.
+func (m *multipathManager) queueAbandonClientPath(id pathID) bool {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	mpID, ok := m.clientPaths[id]
+	if !ok {
+		return false
+	}
+	delete(m.clientPaths, id)
+	m.pathsToAbandon = append(m.pathsToAbandon, mpID)
+	return true
+}
+
+func (m *multipathManager) popPathsToAbandon() []protocol.PathID {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	paths := m.pathsToAbandon
+	m.pathsToAbandon = nil
+	return paths
+}
+
+// pathStats returns the statistics of all paths other than the initial path, sorted by path ID.
+func (m *multipathManager) pathStats() []PathStats {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	ids := slices.Sorted(maps.Keys(m.statsPaths))
+	stats := make([]PathStats, 0, len(ids))
+	for _, id := range ids {
+		p := m.statsPaths[id]
+		stats = append(stats, newPathStats(p.conn.LocalAddr(), p.conn.RemoteAddr(), p.rttStats, p.sentPacketHandler.Stats()))
+	}
+	return stats
+}
+
+func (m *multipathManager) nextAlarm() monotime.Time {
+	var alarm monotime.Time
+	for _, p := range m.paths {
+		if t := p.receivedPacketHandler.GetAlarmTimeout(); !t.IsZero() && (alarm.IsZero() || t.Before(alarm)) {
+			alarm = t
+		}
+		if t := p.sentPacketHandler.GetLossDetectionTimeout(); !t.IsZero() && (alarm.IsZero() || t.Before(alarm)) {
+			alarm = t
+		}
+	}
+	return alarm
+}
+
+func (m *multipathManager) Close() {
+	for _, p := range m.paths {
+		if p.sendQueue != nil {
+			p.sendQueue.Close()
+		}
+	}
+}
+
+// pathSendConn sends packets on a path other than the initial path,
+// using the socket that the initial path uses.
+// It is used by the server, which receives packets for all paths on the same socket.
+type pathSendConn struct {
+	sendConn
+
+	remoteAddr atomic.Pointer[net.Addr]
+}
+
+var _ sendConn = &pathSendConn{}
+
+func newPathSendConn(c sendConn, remoteAddr net.Addr) *pathSendConn {
+	sc := &pathSendConn{sendConn: c}
+	sc.remoteAddr.Store(&remoteAddr)
+	return sc
+}
+
+func (c *pathSendConn) Write(b []byte, _ uint16, _ protocol.ECN, _ monotime.Time) error {
+	return c.sendConn.WriteTo(b, *c.remoteAddr.Load())
+}
+
+func (c *pathSendConn) WriteBatch(packets []outgoingPacket) (int, error) {
+	for i, p := range packets {
+		if err := c.Write(p.data, 0, p.ecn, 0); err != nil {
+			return i, err
+		}
+	}
+	return len(packets), nil
+}
+
+func (c *pathSendConn) RemoteAddr() net.Addr { return *c.remoteAddr.Load() }
+
+func (c *pathSendConn) ChangeRemoteAddr(addr net.Addr, _ packetInfo) { c.remoteAddr.Store(&addr) }
+
+func (c *pathSendConn) capabilities() connCapabilities { return connCapabilities{} }
+
+// The connection is only closed when the initial path is closed.
+func (c *pathSendConn) Close() error { return nil }
+
+func (c *Conn) negotiateMultipath(params *wire.TransportParameters) {
+	if !c.config.EnableMultipath || params.InitialMaxPathID == nil {
+		return
+	}
+	// The multipath extension requires non-zero-length connection IDs.
+	if c.srcConnIDLen == 0 || c.connIDManager.Get().Len() == 0 {
+		return
+	}
+	scheduler := NewMinRTTPathScheduler()
+	if c.config.MultipathScheduler != nil {
+		scheduler = c.config.MultipathScheduler()
+	}
+	c.multipath = newMultipathManager(*params.InitialMaxPathID, scheduler)
+	c.connStateMutex.Lock()
+	c.connState.Multipath = true
+	c.connStateMutex.Unlock()
+	c.connIDGenerator.IssuePathConnIDs(c.multipath.maxPathID())
+}
+
+func (c *Conn) newMultipathPath(id protocol.PathID, conn sendConn) *multipathPath {
+	rttStats := utils.NewRTTStats()
+	rttStats.SetMaxAckDelay(c.peerParams.MaxAckDelay)
+	rph := ackhandler.NewReceivedPacketHandler(c.logger)
+	initialPacketSize := c.multipathMaxPacketSize()
+	sph := ackhandler.NewSentPacketHandler(
+		0,
+		initialPacketSize,
+		rttStats,
+		&c.connStats,
+		newCongestionControllerFactory(c.config.CongestionControl, rttStats, &c.connStats, nil),
+		true, // the handshake is already confirmed
+		false,
+		rph.IgnorePacketsBelow,
+		c.perspective,
+		nil,
+		c.logger,
+	)
+	now := monotime.Now()
+	sph.DropPackets(protocol.EncryptionInitial, now)
+	sph.DropPackets(protocol.EncryptionHandshake, now)
+	rph.DropPackets(protocol.EncryptionInitial)
+	rph.DropPackets(protocol.EncryptionHandshake)
+	p := &multipathPath{
+		id:                    id,
+		rttStats:              rttStats,
+		sentPacketHandler:     sph,
+		receivedPacketHandler: rph,
+		spinBit:               newSpinBit(c.perspective, c.spinBit.Disabled()),
+	}
+	if conn != nil {
+		c.startMultipathPath(p, conn)
+	}
+	c.multipath.paths[id] = p
+	return p
+}
+
+func (c *Conn) startMultipathPath(p *multipathPath, conn sendConn) {
+	p.conn = conn
+	p.sendQueue = newSendQueue(conn)
+	c.multipath.mx.Lock()
+	c.multipath.statsPaths[p.id] = p
+	c.multipath.mx.Unlock()
+	go func() {
+		// An error sending on a path doesn't close the connection.
+		// The path is abandoned by the run loop.
+		if err := p.sendQueue.Run(); err != nil {
+			p.sendErr.Store(&err)
+			c.scheduleSending()
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-p.sendQueue.Available():
+				if p.sendQueueBlocked.CompareAndSwap(true, false) {
+					c.scheduleSending()
+				}
+			}
+		}
+	}()
+}
+
+// multipathMaxPacketSize is the packet size used on paths other than the initial path.
+// We don't perform Path MTU discovery on these paths.
+func (c *Conn) multipathMaxPacketSize() protocol.ByteCount {
+	size := protocol.ByteCount(c.config.InitialPacketSize)
+	if c.peerParams.MaxUDPPayloadSize > 0 && c.peerParams.MaxUDPPayloadSize < size {
+		size = c.peerParams.MaxUDPPayloadSize
+	}
+	return size
+}
+
+// openClientPath is called by the path manager when the client probes a new path.
+// Every Path created by Conn.AddPath is mapped to a new path ID.
+func (c *Conn) openClientPath(id pathID) (protocol.ConnectionID, bool) {
+	m := c.multipath
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if mpID, ok := m.clientPaths[id]; ok {
+		if _, ok := m.paths[mpID]; !ok {
+			return protocol.ConnectionID{}, false
+		}
+		return m.destConnID(mpID)
+	}
+	mpID := m.nextPathID
+	if mpID > m.maxPathID() {
+		return protocol.ConnectionID{}, false
+	}
+	// The peer might not have issued connection IDs for this path yet.
+	connID, ok := m.destConnID(mpID)
+	if !ok {
+		return protocol.ConnectionID{}, false
+	}
+	m.nextPathID++
+	m.clientPaths[id] = mpID
+	c.newMultipathPath(mpID, nil)
+	return connID, true
+}
+
+func (c *Conn) sendMultipathPathProbe(connID protocol.ConnectionID, frame ackhandler.Frame, tr *Transport, now monotime.Time) error {
+	p := c.multipath.pathForDestConnID(connID)
+	if p == nil {
+		return nil
+	}
+	if p.conn == nil {
+		c.startMultipathPath(p, newSendConn(tr.conn, c.conn.RemoteAddr(), packetInfo{}, c.logger))
+	}
+	p.pathChallenges = append(p.pathChallenges, frame.Frame.(*wire.PathChallengeFrame).Data)
+	c.logger.Debugf("sending path probe packet on path %d from %s", p.id, tr.conn.LocalAddr())
+	return c.sendMultipathFrames(p, []ackhandler.Frame{frame}, now)
+}
+
+func (c *Conn) multipathPackerPath(p *multipathPath) (packerPath, bool) {
+	connID, ok := c.multipath.destConnID(p.id)
+	if !ok {
+		return packerPath{}, false
+	}
+	return packerPath{
+		ID:        p.id,
+		ConnID:    connID,
+		PNManager: p.sentPacketHandler,
+		Acks:      p.receivedPacketHandler,
+		SpinBit:   p.spinBit,
+	}, true
+}
+
+// sendMultipathFrames sends a path probe packet, containing PATH_CHALLENGE and PATH_RESPONSE frames.
+func (c *Conn) sendMultipathFrames(p *multipathPath, frames []ackhandler.Frame, now monotime.Time) error {
+	path, ok := c.multipathPackerPath(p)
+	if !ok || !p.canSend() {
+		return nil
+	}
+	packet, buf, err := c.packer.PackPathFramesPacket(path, frames, true, c.version)
+	if err != nil {
+		return err
+	}
+	c.logShortHeaderPacket(packet, protocol.ECNNon, buf.Len())
+	c.registerMultipathPacket(p, packet, now)
+	c.sendOnMultipathPath(p, buf)
+	return nil
+}
+
+func (c *Conn) sendOnMultipathPath(p *multipathPath, buf *packetBuffer) {
+	if p.sendQueue.WouldBlock() {
+		// drop the packet, it will be declared lost
+		buf.Release()
+		p.sendQueueBlocked.Store(true)
+		return
+	}
+	p.sendQueue.Send(buf, 0, protocol.ECNNon, 0)
+	if p.sendQueue.WouldBlock() {
+		p.sendQueueBlocked.Store(true)
+	}
+}
+
+func (c *Conn) registerMultipathPacket(p *multipathPath, packet shortHeaderPacket, now monotime.Time) {
+	if packet.IsPathProbePacket {
+		p.sentPacketHandler.SentPacket(
+			now,
+			0,
+			packet.PacketNumber,
+			protocol.InvalidPacketNumber,
+			packet.StreamFrames,
+			packet.Frames,
+			protocol.Encryption1RTT,
+			protocol.ECNNon,
+			packet.Length,
+			false,
+			true,
+		)
+		return
+	}
+	if c.firstAckElicitingPacketAfterIdleSentTime.IsZero() && (len(packet.StreamFrames) > 0 || ackhandler.HasAckElicitingFrames(packet.Frames)) {
+		c.firstAckElicitingPacketAfterIdleSentTime = now
+	}
+	largestAcked := protocol.InvalidPacketNumber
+	if packet.Ack != nil {
+		largestAcked = packet.Ack.LargestAcked()
+	}
+	p.sentPacketHandler.SentPacket(
+		now,
+		0,
+		packet.PacketNumber,
+		largestAcked,
+		packet.StreamFrames,
+		packet.Frames,
+		protocol.Encryption1RTT,
+		protocol.ECNNon,
+		packet.Length,
+		false,
+		false,
+	)
+}
+
+func (c *Conn) handleMultipathPacket(
+	p receivedPacket,
+	id protocol.PathID,
+	destConnID protocol.ConnectionID,
+	pn protocol.PacketNumber,
+	pnLen protocol.PacketNumberLen,
+	keyPhase protocol.KeyPhaseBit,
+	data []byte,
+	datagramID qlog.DatagramID,
+) (wasProcessed bool, _ error) {
+	path, ok := c.multipath.paths[id]
+	if !ok {
+		if _, abandoned := c.multipath.abandonedPaths[id]; abandoned || c.perspective == protocol.PerspectiveClient {
+			c.logger.Debugf("Dropping packet for unknown path %d.", id)
+			return false, nil
+		}
+		// The client opened a new path.
+		path = c.newMultipathPath(id, newPathSendConn(c.conn, p.remoteAddr))
+		c.logger.Debugf("client opened path %d from %s", id, p.remoteAddr)
+	}
+
+	if c.logger.Debug() {
+		c.logger.Debugf("<- Reading packet %d (%d bytes) for connection %s, 1-RTT, path %d", pn, p.Size(), destConnID, id)
+		wire.LogShortHeader(c.logger, destConnID, pn, pnLen, keyPhase)
+	}
+
+	if path.receivedPacketHandler.IsPotentiallyDuplicate(pn, protocol.Encryption1RTT) {
+		c.logger.Debugf("Dropping (potentially) duplicate packet.")
+		if c.qlogger != nil {
+			c.qlogger.RecordEvent(qlog.PacketDropped{
+				Header: qlog.PacketHeader{
+					PacketType:   qlog.PacketType1RTT,
+					PacketNumber: pn,
+				},
+				Raw:        qlog.RawInfo{Length: int(p.Size())},
+				DatagramID: datagramID,
+				Trigger:    qlog.PacketDropDuplicate,
+			})
+		}
+		return false, nil
+	}
+
+	var log func([]qlog.Frame)
+	if c.qlogger != nil {
+		log = func(frames []qlog.Frame) {
+			c.qlogger.RecordEvent(qlog.PacketReceived{
+				Header: qlog.PacketHeader{
+					PacketType:       qlog.PacketType1RTT,
+					DestConnectionID: destConnID,
+					PacketNumber:     pn,
+					KeyPhaseBit:      keyPhase,
+				},
+				Raw: qlog.RawInfo{
+					Length:        int(p.Size()),
+					PayloadLength: int(p.Size() - wire.ShortHeaderLen(destConnID, pnLen)),
+				},
+				DatagramID: datagramID,
+				Frames:     frames,
+				ECN:        toQlogECN(p.ecn),
+			})
+		}
+	}
+
+	c.lastPacketReceivedTime = p.rcvTime
+	c.firstAckElicitingPacketAfterIdleSentTime = 0
+	c.keepAlivePingSent = false
+
+	isAckEliciting, _, pathChallenge, err := c.handleFrames(data, destConnID, protocol.Encryption1RTT, log, p.rcvTime)
+	if err != nil {
+		return false, err
+	}
+	// The peer might have abandoned the path.
+	if _, ok := c.multipath.paths[id]; !ok {
+		return true, nil
+	}
+	path.sentPacketHandler.ReceivedPacket(protocol.Encryption1RTT, p.rcvTime)
+	path.spinBit.ReceivedPacket(pn, wire.ShortHeaderSpinBit(p.data[0]))
+	if err := path.receivedPacketHandler.ReceivedPacket(pn, p.ecn, protocol.Encryption1RTT, p.rcvTime, isAckEliciting); err != nil {
+		return false, err
+	}
+
+	if path.conn != nil && !addrsEqual(p.remoteAddr, path.conn.RemoteAddr()) {
+		path.conn.ChangeRemoteAddr(p.remoteAddr, p.info)
+	}
+	if pathChallenge != nil {
+		path.queuedFrames = append(path.queuedFrames, ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: pathChallenge.Data}})
+	}
+	// The server validates every new path, see section 8.2 of RFC 9000.
+	if c.perspective == protocol.PerspectiveServer && !path.validated && len(path.pathChallenges) == 0 {
+		var b [8]byte
+		_, _ = rand.Read(b[:])
+		path.pathChallenges = append(path.pathChallenges, b)
+		path.queuedFrames = append(path.queuedFrames, ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: b}})
+	}
+	return true, nil
+}
+
+func (c *Conn) checkMultipathNegotiated(f wire.Frame) error {
+	if c.multipath == nil {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("received %T, but multipath was not negotiated", f),
+		}
+	}
+	return nil
+}
+
+func (c *Conn) checkPathID(id protocol.PathID) error {
+	if id > c.multipath.localMaxPathID {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("invalid path ID %d (maximum: %d)", id, c.multipath.localMaxPathID),
+		}
+	}
+	return nil
+}
+
+func (c *Conn) handleMultipathFrame(f wire.Frame, destConnID protocol.ConnectionID, rcvTime monotime.Time) error {
+	if err := c.checkMultipathNegotiated(f); err != nil {
+		return err
+	}
+	switch frame := f.(type) {
+	case *wire.PathAckFrame:
+		return c.handlePathAckFrame(frame, rcvTime)
+	case *wire.PathAbandonFrame:
+		if err := c.checkPathID(frame.PathID); err != nil {
+			return err
+		}
+		if frame.PathID == protocol.InitialPathID {
+			// We don't support abandoning the initial path.
+			return nil
+		}
+		c.abandonMultipathPath(frame.PathID, true, rcvTime)
+	case *wire.PathStatusFrame:
+		if err := c.checkPathID(frame.PathID); err != nil {
+			return err
+		}
+		c.handlePathStatusFrame(frame)
+	case *wire.PathNewConnectionIDFrame:
+		return c.handlePathNewConnectionIDFrame(frame)
+	case *wire.PathRetireConnectionIDFrame:
+		if err := c.checkPathID(frame.PathID); err != nil {
+			return err
+		}
+		return c.connIDGenerator.RetirePathConnID(frame.PathID, frame.SequenceNumber, destConnID, rcvTime.Add(3*c.rttStats.PTO(false)))
+	case *wire.MaxPathIDFrame:
+		if frame.MaxPathID > c.multipath.peerMaxPathID {
+			c.multipath.peerMaxPathID = frame.MaxPathID
+			return c.connIDGenerator.IssuePathConnIDs(c.multipath.maxPathID())
+		}
+	case *wire.PathsBlockedFrame, *wire.PathCIDsBlockedFrame:
+		// We issue connection IDs for all paths we allow, and raise the limit when a path is abandoned.
+	default:
+		panic(fmt.Sprintf("unexpected frame: %T", f))
+	}
+	return nil
+}
+
+func (c *Conn) handlePathAckFrame(f *wire.PathAckFrame, rcvTime monotime.Time) error {
+	if err := c.checkPathID(f.PathID); err != nil {
+		return err
+	}
+	if f.PathID == protocol.InitialPathID {
+		return c.handleAckFrame(&f.AckFrame, protocol.Encryption1RTT, rcvTime)
+	}
+	p, ok := c.multipath.paths[f.PathID]
+	if !ok {
+		// the path might have been abandoned
+		return nil
+	}
+	p.unresponsive = false
+	_, err := p.sentPacketHandler.ReceivedAck(&f.AckFrame, protocol.Encryption1RTT, c.lastPacketReceivedTime)
+	return err
+}
+
+func (c *Conn) handlePathStatusFrame(f *wire.PathStatusFrame) {
+	m := c.multipath
+	if f.PathID == protocol.InitialPathID {
+		if f.SequenceNumber+1 > m.initialSeq {
+			m.initialSeq = f.SequenceNumber + 1
+			m.initialBackup = f.Backup
+		}
+		return
+	}
+	p, ok := m.paths[f.PathID]
+	if !ok || f.SequenceNumber+1 <= p.statusSeq {
+		return
+	}
+	p.statusSeq = f.SequenceNumber + 1
+	p.backup = f.Backup
+}
+
+func (c *Conn) handlePathNewConnectionIDFrame(f *wire.PathNewConnectionIDFrame) error {
+	if err := c.checkPathID(f.PathID); err != nil {
+		return err
+	}
+	m := c.multipath
+	if f.PathID == protocol.InitialPathID {
+		return c.connIDManager.Add(&wire.NewConnectionIDFrame{
+			SequenceNumber:      f.SequenceNumber,
+			RetirePriorTo:       f.RetirePriorTo,
+			ConnectionID:        f.ConnectionID,
+			StatelessResetToken: f.StatelessResetToken,
+		})
+	}
+	if _, ok := m.abandonedPaths[f.PathID]; ok {
+		return nil
+	}
+	connIDs := m.peerConnIDs[f.PathID]
+	if f.RetirePriorTo > m.retirePriorTo[f.PathID] {
+		m.retirePriorTo[f.PathID] = f.RetirePriorTo
+		// connIDs is sorted by sequence number
+		var n int
+		for ; n < len(connIDs) && connIDs[n].SequenceNumber < f.RetirePriorTo; n++ {
+			c.queueControlFrame(&wire.PathRetireConnectionIDFrame{PathID: f.PathID, SequenceNumber: connIDs[n].SequenceNumber})
+		}
+		connIDs = connIDs[n:]
+	}
+	if f.SequenceNumber < m.retirePriorTo[f.PathID] {
+		m.peerConnIDs[f.PathID] = connIDs
+		c.queueControlFrame(&wire.PathRetireConnectionIDFrame{PathID: f.PathID, SequenceNumber: f.SequenceNumber})
+		return nil
+	}
+	idx := slices.IndexFunc(connIDs, func(c newConnID) bool { return c.SequenceNumber >= f.SequenceNumber })
+	if idx == -1 {
+		idx = len(connIDs)
+	}
+	if idx < len(connIDs) && connIDs[idx].SequenceNumber == f.SequenceNumber {
+		if connIDs[idx].ConnectionID != f.ConnectionID {
+			return &qerr.TransportError{
+				ErrorCode:    qerr.ProtocolViolation,
+				ErrorMessage: fmt.Sprintf("received conflicting connection IDs for sequence number %d on path %d", f.SequenceNumber, f.PathID),
+			}
+		}
+		m.peerConnIDs[f.PathID] = connIDs
+		return nil
+	}
+	if len(connIDs) >= protocol.MaxActiveConnectionIDs {
+		return &qerr.TransportError{ErrorCode: qerr.ConnectionIDLimitError}
+	}
+	m.peerConnIDs[f.PathID] = slices.Insert(connIDs, idx, newConnID{
+		SequenceNumber:      f.SequenceNumber,
+		ConnectionID:        f.ConnectionID,
+		StatelessResetToken: f.StatelessResetToken,
+	})
+	return nil
+}
+
+// abandonMultipathPath closes a path.
+// All frames sent on this path that haven't been acknowledged yet are retransmitted on the remaining paths.
+func (c *Conn) abandonMultipathPath(id protocol.PathID, peerInitiated bool, now monotime.Time) {
+	m := c.multipath
+	if _, ok := m.abandonedPaths[id]; ok {
+		return
+	}
+	m.abandonedPaths[id] = struct{}{}
+	if p, ok := m.paths[id]; ok {
+		// declare all packets lost, and queue their frames for retransmission
+		p.sentPacketHandler.MigratedPath(now, c.multipathMaxPacketSize())
+		if p.sendQueue != nil {
+			p.sendQueue.Close()
+		}
+		delete(m.paths, id)
+		m.mx.Lock()
+		delete(m.statsPaths, id)
+		m.mx.Unlock()
+	}
+	if peerInitiated {
+		c.logger.Debugf("peer abandoned path %d", id)
+	} else {
+		c.logger.Debugf("abandoning path %d", id)
+	}
+	// An endpoint that receives a PATH_ABANDON frame also abandons the path.
+	c.queueControlFrame(&wire.PathAbandonFrame{PathID: id, ErrorCode: uint64(qerr.NoError)})
+	c.connIDGenerator.RemovePath(id, now.Add(3*c.rttStats.PTO(false)))
+	delete(m.peerConnIDs, id)
+	delete(m.retirePriorTo, id)
+
+	// Allow the peer to open a new path instead.
+	m.localMaxPathID++
+	c.queueControlFrame(&wire.MaxPathIDFrame{MaxPathID: m.localMaxPathID})
+	if err := c.connIDGenerator.IssuePathConnIDs(m.maxPathID()); err != nil {
+		c.closeLocal(err)
+	}
+}
+
+// handleMultipathTimers handles loss detection timeouts and abandons paths that failed.
+func (c *Conn) handleMultipathTimers(now monotime.Time) error {
+	for _, id := range c.multipath.popPathsToAbandon() {
+		c.abandonMultipathPath(id, false, now)
+	}
+	for id, p := range c.multipath.paths {
+		if errPtr := p.sendErr.Load(); errPtr != nil {
+			c.logger.Debugf("sending on path %d failed: %s", id, *errPtr)
+			c.abandonMultipathPath(id, false, now)
+			continue
+		}
+		if timeout := p.sentPacketHandler.GetLossDetectionTimeout(); !timeout.IsZero() && !timeout.After(now) {
+			if err := p.sentPacketHandler.OnLossDetectionTimeout(now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type multipathSendPath struct {
+	path              packerPath
+	mp                *multipathPath // nil for the initial path
+	sentPacketHandler ackhandler.SentPacketHandler
+	sendQueue         sender
+	maxPacketSize     protocol.ByteCount
+	sentPacket        bool
+}
+
+func (p *multipathSendPath) unresponsive(m *multipathManager) *bool {
+	if p.mp == nil {
+		return &m.initialUnresponsive
+	}
+	return &p.mp.unresponsive
+}
+
+func (c *Conn) multipathSendPaths() []multipathSendPath {
+	paths := make([]multipathSendPath, 0, len(c.multipath.paths)+1)
+	paths = append(paths, multipathSendPath{
+		path: packerPath{
+			ID:        protocol.InitialPathID,
+			ConnID:    c.connIDManager.Get(),
+			PNManager: c.sentPacketHandler,
+			Acks:      c.receivedPacketHandler,
+			SpinBit:   c.spinBit,
+		},
+		sentPacketHandler: c.sentPacketHandler,
+		sendQueue:         c.sendQueue,
+		maxPacketSize:     c.maxPacketSize(),
+	})
+	for _, p := range c.multipath.paths {
+		if !p.canSend() {
+			continue
+		}
+		path, ok := c.multipathPackerPath(p)
+		if !ok {
+			continue
+		}
+		paths = append(paths, multipathSendPath{
+			path:              path,
+			mp:                p,
+			sentPacketHandler: p.sentPacketHandler,
+			sendQueue:         p.sendQueue,
+			maxPacketSize:     c.multipathMaxPacketSize(),
+		})
+	}
+	// sort by path ID, so that path schedulers get a stable order
+	slices.SortFunc(paths, func(a, b multipathSendPath) int { return int(a.path.ID) - int(b.path.ID) })
+	return paths
+}
+
+func (c *Conn) sendOnMultipathSendPath(p *multipathSendPath, onlyAck bool, now monotime.Time) error {
+	buf := getPacketBuffer()
+	ecn := protocol.ECNNon
+	if p.mp == nil {
+		ecn = c.sentPacketHandler.ECNMode(true)
+	}
+	packet, err := c.packer.AppendPathPacket(buf, p.path, onlyAck, p.maxPacketSize, now, c.version)
+	if err != nil {
+		buf.Release()
+		return err
+	}
+	c.logShortHeaderPacket(packet, ecn, buf.Len())
+	p.sentPacket = true
+	if p.mp == nil {
+		c.registerPackedShortHeaderPacket(packet, ecn, now, 0)
+		c.sendQueue.Send(buf, 0, ecn, 0)
+		return nil
+	}
+	c.registerMultipathPacket(p.mp, packet, now)
+	c.sendOnMultipathPath(p.mp, buf)
+	return nil
+}
+
+// sendMultipathPTOProbe sends a probe packet when the PTO of a path fired.
+// If other paths are available, the data in flight on this path is retransmitted on these paths,
+// and no new data is sent on this path until it is acknowledged again.
+func (c *Conn) sendMultipathPTOProbe(p *multipathSendPath, paths []multipathSendPath, now monotime.Time) error {
+	*p.unresponsive(c.multipath) = true
+	var haveOtherPath bool
+	for i := range paths {
+		if o := &paths[i]; o != p && !*o.unresponsive(c.multipath) && (o.mp == nil || o.mp.validated) {
+			haveOtherPath = true
+			break
+		}
+	}
+	if haveOtherPath {
+		// queue the frames of all packets in flight on this path for retransmission
+		for p.sentPacketHandler.QueueProbePacket(protocol.Encryption1RTT) {
+		}
+	}
+	for p.sentPacketHandler.QueueProbePacket(protocol.Encryption1RTT) {
+		err := c.sendOnMultipathSendPath(p, false, now)
+		if err == nil {
+			return nil
+		}
+		if err != errNothingToPack {
+			return err
+		}
+	}
+	packet, buf, err := c.packer.PackPathFramesPacket(p.path, []ackhandler.Frame{{Frame: &wire.PingFrame{}}}, false, c.version)
+	if err != nil {
+		return err
+	}
+	c.logShortHeaderPacket(packet, protocol.ECNNon, buf.Len())
+	p.sentPacket = true
+	if p.mp == nil {
+		c.registerPackedShortHeaderPacket(packet, protocol.ECNNon, now, 0)
+		c.sendQueue.Send(buf, 0, protocol.ECNNon, 0)
+		return nil
+	}
+	c.registerMultipathPacket(p.mp, packet, now)
+	c.sendOnMultipathPath(p.mp, buf)
+	return nil
+}
+
+func (c *Conn) triggerSendingMultipath(now monotime.Time) error {
+	if c.perspective == protocol.PerspectiveClient {
+		if pm := c.pathManagerOutgoing.Load(); pm != nil {
+			if connID, frame, tr, ok := pm.NextPathToProbe(); ok {
+				if err := c.sendMultipathPathProbe(connID, frame, tr, now); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return c.sendPacketsMultipath(now)
+}
+
+// sendPacketsMultipath sends packets once the handshake is confirmed and more than one path is in use.
+// The PathScheduler decides which path packets containing application data are sent on.
+func (c *Conn) sendPacketsMultipath(now monotime.Time) error {
+	c.pacingDeadline = 0
+
+	// Path MTU Discovery is only performed on the initial path.
+	if c.mtuDiscoverer != nil && c.sentPacketHandler.SendMode(now) == ackhandler.SendAny && c.mtuDiscoverer.ShouldSendProbe(now) {
+		ping, size := c.mtuDiscoverer.GetPing(now)
+		p, buf, err := c.packer.PackMTUProbePacket(ping, size, c.version)
+		if err != nil {
+			return err
+		}
+		ecn := c.sentPacketHandler.ECNMode(true)
+		c.logShortHeaderPacket(p, ecn, buf.Len())
+		c.registerPackedShortHeaderPacket(p, ecn, now, 0)
+		c.sendQueue.Send(buf, 0, ecn, 0)
+	}
+
+	if offset := c.connFlowController.GetWindowUpdate(now); offset > 0 {
+		c.framer.QueueControlFrame(&wire.MaxDataFrame{MaximumData: offset})
+	}
+	if cf := c.cryptoStreamManager.GetPostHandshakeData(protocol.MaxPostHandshakeCryptoFrameSize); cf != nil {
+		c.queueControlFrame(cf)
+	}
+
+	// path validation
+	for _, p := range c.multipath.paths {
+		if len(p.queuedFrames) == 0 || !p.canSend() || p.sendQueue.WouldBlock() {
+			continue
+		}
+		frames := p.queuedFrames
+		p.queuedFrames = nil
+		if err := c.sendMultipathFrames(p, frames, now); err != nil {
+			return err
+		}
+	}
+
+	paths := c.multipathSendPaths()
+	// PTO probe packets
+	for i := range paths {
+		p := &paths[i]
+		if p.sendQueue.WouldBlock() {
+			continue
+		}
+		if p.sentPacketHandler.SendMode(now) == ackhandler.SendPTOAppData {
+			if err := c.sendMultipathPTOProbe(p, paths, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	// application data
+	idxs := make([]int, 0, len(paths))
+	for {
+		c.multipath.pathInfos = c.multipath.pathInfos[:0]
+		idxs = idxs[:0]
+		for i, p := range paths {
+			info := PathInfo{
+				CanSend: !p.sendQueue.WouldBlock() &&
+					!*p.unresponsive(c.multipath) &&
+					p.sentPacketHandler.SendMode(now) == ackhandler.SendAny,
+			}
+			if p.mp == nil {
+				info.LocalAddr = c.conn.LocalAddr()
+				info.RemoteAddr = c.conn.RemoteAddr()
+				info.SmoothedRTT = c.rttStats.SmoothedRTT()
+				info.Backup = c.multipath.initialBackup
+			} else {
+				if !p.mp.validated {
+					continue
+				}
+				info.LocalAddr = p.mp.conn.LocalAddr()
+				info.RemoteAddr = p.mp.conn.RemoteAddr()
+				info.SmoothedRTT = p.mp.rttStats.SmoothedRTT()
+				info.Backup = p.mp.backup
+			}
+			c.multipath.pathInfos = append(c.multipath.pathInfos, info)
+			idxs = append(idxs, i)
+		}
+		selected := c.multipath.scheduler.SelectPath(c.multipath.pathInfos)
+		if selected < 0 || selected >= len(idxs) || !c.multipath.pathInfos[selected].CanSend {
+			break
+		}
+		if err := c.sendOnMultipathSendPath(&paths[idxs[selected]], false, now); err != nil {
+			if err == errNothingToPack {
+				break
+			}
+			return err
+		}
+		// Prioritize receiving of packets over sending out more packets.
+		c.receivedPacketMx.Lock()
+		hasPackets := !c.receivedPackets.Empty()
+		c.receivedPacketMx.Unlock()
+		if hasPackets {
+			c.pacingDeadline = deadlineSendImmediately
+			break
+		}
+	}
+
+	// acknowledgements, and pacing
+	allCongestionLimited := true
+	for i := range paths {
+		p := &paths[i]
+		sendMode := p.sentPacketHandler.SendMode(now)
+		if !p.sentPacket && !p.sendQueue.WouldBlock() && sendMode != ackhandler.SendNone {
+			if err := c.sendOnMultipathSendPath(p, true, now); err != nil && err != errNothingToPack {
+				return err
+			}
+		}
+		switch sendMode {
+		case ackhandler.SendPacingLimited:
+			allCongestionLimited = false
+			deadline := p.sentPacketHandler.TimeUntilSend()
+			if deadline.IsZero() {
+				deadline = deadlineSendImmediately
+			}
+			if c.pacingDeadline.IsZero() || deadline.Before(c.pacingDeadline) {
+				c.pacingDeadline = deadline
+			}
+		case ackhandler.SendAck, ackhandler.SendNone:
+		default:
+			allCongestionLimited = false
+		}
+	}
+	if allCongestionLimited {
+		c.blocked = blockModeCongestionLimited
+	}
+	return nil
+}
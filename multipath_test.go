@@ -0,0 +1,75 @@
+package quic
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipathPathNewConnectionIDRetirePriorTo(t *testing.T) {
+	tc := newClientTestConnection(t, nil, &Config{EnableMultipath: true}, false)
+	tc.conn.multipath = newMultipathManager(protocol.MaxMultipathPathID, NewMinRTTPathScheduler())
+
+	const pathID protocol.PathID = 1
+	connIDs := make([]protocol.ConnectionID, 6)
+	for i := range connIDs {
+		connIDs[i] = protocol.ParseConnectionID([]byte{1, 2, 3, 4, byte(i)})
+	}
+	for seq := range 4 {
+		require.NoError(t, tc.conn.handlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+			PathID:              pathID,
+			SequenceNumber:      uint64(seq),
+			ConnectionID:        connIDs[seq],
+			StatelessResetToken: protocol.StatelessResetToken{byte(seq)},
+		}))
+	}
+	connID, ok := tc.conn.multipath.destConnID(pathID)
+	require.True(t, ok)
+	require.Equal(t, connIDs[0], connID)
+
+	getRetiredSeqs := func() []uint64 {
+		var seqs []uint64
+		frames, _, _ := tc.conn.framer.Append(nil, nil, protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+		for _, f := range frames {
+			if rf, ok := f.Frame.(*wire.PathRetireConnectionIDFrame); ok {
+				require.Equal(t, pathID, rf.PathID)
+				seqs = append(seqs, rf.SequenceNumber)
+			}
+		}
+		return seqs
+	}
+	require.Empty(t, getRetiredSeqs())
+
+	// retire the first 3 connection IDs at once
+	require.NoError(t, tc.conn.handlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+		PathID:              pathID,
+		SequenceNumber:      4,
+		RetirePriorTo:       3,
+		ConnectionID:        connIDs[4],
+		StatelessResetToken: protocol.StatelessResetToken{4},
+	}))
+	require.ElementsMatch(t, []uint64{0, 1, 2}, getRetiredSeqs())
+	connID, ok = tc.conn.multipath.destConnID(pathID)
+	require.True(t, ok)
+	require.Equal(t, connIDs[3], connID)
+	require.Equal(t, []newConnID{
+		{SequenceNumber: 3, ConnectionID: connIDs[3], StatelessResetToken: protocol.StatelessResetToken{3}},
+		{SequenceNumber: 4, ConnectionID: connIDs[4], StatelessResetToken: protocol.StatelessResetToken{4}},
+	}, tc.conn.multipath.peerConnIDs[pathID])
+
+	// a connection ID below RetirePriorTo is retired immediately
+	require.NoError(t, tc.conn.handlePathNewConnectionIDFrame(&wire.PathNewConnectionIDFrame{
+		PathID:              pathID,
+		SequenceNumber:      5,
+		RetirePriorTo:       6,
+		ConnectionID:        connIDs[5],
+		StatelessResetToken: protocol.StatelessResetToken{5},
+	}))
+	require.ElementsMatch(t, []uint64{3, 4, 5}, getRetiredSeqs())
+	_, ok = tc.conn.multipath.destConnID(pathID)
+	require.False(t, ok)
+}
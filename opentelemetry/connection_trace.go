@@ -0,0 +1,370 @@
+package opentelemetry
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	h3qlog "github.com/quic-go/quic-go/http3/qlog"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	attrPerspective      = attribute.Key("quic.perspective")
+	attrConnectionID     = attribute.Key("quic.original_destination_connection_id")
+	attrVersion          = attribute.Key("quic.version")
+	attrPacketType       = attribute.Key("quic.packet.type")
+	attrDropReason       = attribute.Key("quic.packet.drop_reason")
+	attrLossReason       = attribute.Key("quic.packet.loss_reason")
+	attrKeyPhase         = attribute.Key("quic.key_phase")
+	attrKeyUpdateTrigger = attribute.Key("quic.key_update.trigger")
+	attrCloseInitiator   = attribute.Key("quic.close.initiator")
+	attrCloseReason      = attribute.Key("quic.close.reason")
+	attrCloseMessage     = attribute.Key("quic.close.message")
+	attrStreamID         = attribute.Key("quic.stream.id")
+)
+
+// connectionTrace is both the qlogwriter.Trace and the qlogwriter.Recorder of a connection.
+// It receives the events of the QUIC connection, and of the HTTP/3 layer (if used).
+type connectionTrace struct {
+	tracer      trace.Tracer
+	instruments *instruments
+	isClient    bool
+	startTime   time.Time
+
+	// ctx contains the connection span
+	ctx  context.Context
+	span trace.Span
+
+	perspective attribute.KeyValue
+	// perspectiveOption is used for metrics that only carry the perspective attribute
+	perspectiveOption metric.MeasurementOption
+
+	mx        sync.Mutex
+	producers int
+	started   bool
+	ended     bool
+	requests  map[qlog.StreamID]*request
+}
+
+// request is an HTTP/3 request.
+type request struct {
+	span trace.Span
+	// the HEADERS frame of the response was sent (server) or parsed (client)
+	hasResponse bool
+	// the server closed its side of the stream
+	responseComplete bool
+}
+
+var (
+	_ qlogwriter.Trace    = &connectionTrace{}
+	_ qlogwriter.Recorder = &connectionTrace{}
+)
+
+func newConnectionTrace(
+	ctx context.Context,
+	tracer trace.Tracer,
+	instruments *instruments,
+	isClient bool,
+	connID qlogwriter.ConnectionID,
+) *connectionTrace {
+	kind := trace.SpanKindServer
+	perspective := attrPerspective.String("server")
+	if isClient {
+		kind = trace.SpanKindClient
+		perspective = attrPerspective.String("client")
+	}
+	ctx, span := tracer.Start(ctx, "quic.connection",
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(
+			semconv.NetworkTransportQUIC,
+			attrConnectionID.String(connID.String()),
+		),
+	)
+	return &connectionTrace{
+		tracer:            tracer,
+		instruments:       instruments,
+		isClient:          isClient,
+		startTime:         time.Now(),
+		ctx:               ctx,
+		span:              span,
+		perspective:       perspective,
+		perspectiveOption: metric.WithAttributeSet(attribute.NewSet(perspective)),
+		requests:          make(map[qlog.StreamID]*request),
+	}
+}
+
+func (t *connectionTrace) AddProducer() qlogwriter.Recorder {
+	t.mx.Lock()
+	t.producers++
+	t.mx.Unlock()
+	return t
+}
+
+func (t *connectionTrace) SupportsSchemas(schema string) bool {
+	return schema == qlog.EventSchema || schema == h3qlog.EventSchema
+}
+
+// Close is called when a producer is done recording events.
+// When the last producer is closed, the connection span is ended,
+// even if the connection_closed event was never recorded.
+func (t *connectionTrace) Close() error {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.producers--
+	if t.producers <= 0 && !t.ended {
+		t.endSpans()
+	}
+	return nil
+}
+
+func (t *connectionTrace) RecordEvent(ev qlogwriter.Event) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.ended {
+		return
+	}
+
+	switch ev := ev.(type) {
+	case qlog.StartedConnection:
+		t.span.SetAttributes(endpointAttributes(ev.Local, ev.Remote)...)
+		if !t.started {
+			t.started = true
+			t.instruments.connsStarted.Add(t.ctx, 1, t.perspectiveOption)
+		}
+	case qlog.VersionInformation:
+		t.span.SetAttributes(attrVersion.String(ev.ChosenVersion.String()))
+	case qlog.ALPNInformation:
+		// The ALPN is logged when the handshake completes.
+		t.span.AddEvent("handshake_complete", trace.WithAttributes(semconv.TLSNextProtocol(ev.ChosenALPN)))
+		t.instruments.handshakeDuration.Record(t.ctx, time.Since(t.startTime).Seconds(), t.perspectiveOption)
+	case qlog.KeyUpdated:
+		// Both the client's and the server's 1-RTT keys are updated at the same time.
+		// The keys derived during the handshake are not logged as a key update.
+		if ev.KeyType == qlog.KeyTypeClient1RTT && ev.Trigger != qlog.KeyUpdateTLS {
+			t.span.AddEvent("key_update", trace.WithAttributes(
+				attrKeyPhase.Int64(int64(ev.KeyPhase)),
+				attrKeyUpdateTrigger.String(string(ev.Trigger)),
+			))
+		}
+	case qlog.MigrationStateUpdated:
+		t.span.AddEvent("migration", trace.WithAttributes(endpointAttributes(ev.Local, ev.Remote)...))
+	case qlog.PacketSent:
+		t.instruments.packetsSent.Add(t.ctx, 1, metric.WithAttributes(t.perspective, attrPacketType.String(qlog.PacketTypeLabel(ev.Header.PacketType))))
+		t.handleStreamFrames(ev.Frames, true)
+	case qlog.PacketReceived:
+		t.instruments.packetsReceived.Add(t.ctx, 1, metric.WithAttributes(t.perspective, attrPacketType.String(qlog.PacketTypeLabel(ev.Header.PacketType))))
+		t.handleStreamFrames(ev.Frames, false)
+	case qlog.VersionNegotiationReceived:
+		t.instruments.packetsReceived.Add(t.ctx, 1, metric.WithAttributes(t.perspective, attrPacketType.String(qlog.PacketTypeLabel(qlog.PacketTypeVersionNegotiation))))
+	case qlog.PacketDropped:
+		t.instruments.packetsDropped.Add(t.ctx, 1, metric.WithAttributes(
+			t.perspective,
+			attrPacketType.String(qlog.PacketTypeLabel(ev.Header.PacketType)),
+			attrDropReason.String(string(ev.Trigger)),
+		))
+	case qlog.PacketLost:
+		t.instruments.packetsLost.Add(t.ctx, 1, metric.WithAttributes(
+			t.perspective,
+			attrPacketType.String(qlog.PacketTypeLabel(ev.Header.PacketType)),
+			attrLossReason.String(string(ev.Trigger)),
+		))
+	case qlog.MetricsUpdated:
+		// Only the values that changed are set.
+		if ev.LatestRTT != 0 {
+			t.instruments.rtt.Record(t.ctx, ev.LatestRTT.Seconds(), t.perspectiveOption)
+		}
+		if ev.CongestionWindow != 0 {
+			t.instruments.congestionWindow.Record(t.ctx, int64(ev.CongestionWindow), t.perspectiveOption)
+		}
+	case qlog.ConnectionClosed:
+		t.handleConnectionClosed(ev)
+	case h3qlog.FrameCreated:
+		t.handleHTTP3Frame(ev.StreamID, ev.Frame, true)
+	case h3qlog.FrameParsed:
+		t.handleHTTP3Frame(ev.StreamID, ev.Frame, false)
+	}
+}
+
+func (t *connectionTrace) handleConnectionClosed(ev qlog.ConnectionClosed) {
+	reason := qlog.CloseReasonLabel(ev)
+	attrs := []attribute.KeyValue{
+		attrCloseInitiator.String(string(ev.Initiator)),
+		attrCloseReason.String(reason),
+	}
+	if ev.Reason != "" {
+		attrs = append(attrs, attrCloseMessage.String(ev.Reason))
+	}
+	t.span.AddEvent("connection_closed", trace.WithAttributes(attrs...))
+	if ev.ConnectionError != nil && *ev.ConnectionError != quic.NoError {
+		t.span.SetStatus(codes.Error, reason)
+	}
+	if t.started {
+		t.instruments.connsClosed.Add(t.ctx, 1, metric.WithAttributes(t.perspective, attrCloseReason.String(reason)))
+		t.instruments.connDuration.Record(t.ctx, time.Since(t.startTime).Seconds(), t.perspectiveOption)
+	}
+	t.endSpans()
+}
+
+// endSpans ends all spans of outstanding HTTP/3 requests, and the connection span.
+func (t *connectionTrace) endSpans() {
+	for id, r := range t.requests {
+		r.span.End()
+		delete(t.requests, id)
+	}
+	t.span.End()
+	t.ended = true
+}
+
+func isRequestStream(id qlog.StreamID) bool {
+	// HTTP/3 requests are sent on client-initiated bidirectional streams
+	return id%4 == 0
+}
+
+// handleHTTP3Frame starts a span when the HEADERS frame of a request is sent (client) or received (server),
+// and records the status code from the HEADERS frame of the response.
+func (t *connectionTrace) handleHTTP3Frame(id qlog.StreamID, frame h3qlog.Frame, created bool) {
+	hf, ok := frame.Frame.(h3qlog.HeadersFrame)
+	if !ok || !isRequestStream(id) {
+		return
+	}
+	r, ok := t.requests[id]
+	if created == t.isClient { // HEADERS frame sent by the client
+		if ok { // trailers
+			return
+		}
+		t.requests[id] = &request{span: t.startRequestSpan(id, hf)}
+		return
+	}
+	if !ok || r.hasResponse {
+		return
+	}
+	status, ok := headerValue(hf, ":status")
+	if !ok {
+		return
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil || code < 200 { // ignore informational responses
+		return
+	}
+	r.hasResponse = true
+	r.span.SetAttributes(semconv.HTTPResponseStatusCode(code))
+	// see https://opentelemetry.io/docs/specs/semconv/http/http-spans/#status
+	if code >= 500 || (t.isClient && code >= 400) {
+		r.span.SetStatus(codes.Error, "")
+	}
+	if r.responseComplete {
+		t.endRequestSpan(id)
+	}
+}
+
+func (t *connectionTrace) startRequestSpan(id qlog.StreamID, hf h3qlog.HeadersFrame) trace.Span {
+	kind := trace.SpanKindServer
+	if t.isClient {
+		kind = trace.SpanKindClient
+	}
+	attrs := []attribute.KeyValue{
+		semconv.NetworkProtocolName("http"),
+		semconv.NetworkProtocolVersion("3"),
+		attrStreamID.Int64(int64(id)),
+	}
+	name := "HTTP"
+	if method, ok := headerValue(hf, ":method"); ok {
+		name = method
+		attrs = append(attrs, semconv.HTTPRequestMethodKey.String(method))
+	}
+	if scheme, ok := headerValue(hf, ":scheme"); ok {
+		attrs = append(attrs, semconv.URLScheme(scheme))
+	}
+	if authority, ok := headerValue(hf, ":authority"); ok {
+		attrs = append(attrs, semconv.ServerAddress(authority))
+	}
+	if path, ok := headerValue(hf, ":path"); ok {
+		path, _, _ = strings.Cut(path, "?")
+		attrs = append(attrs, semconv.URLPath(path))
+	}
+	_, span := t.tracer.Start(t.ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	return span
+}
+
+// handleStreamFrames ends the span of an HTTP/3 request once the response is complete,
+// i.e. once the server's side of the stream was closed.
+// The client might receive the FIN before the HTTP/3 layer parsed the HEADERS frame of the response.
+// In that case, the span is ended once the HEADERS frame was parsed.
+// If either side resets the stream, the span is ended with an error status.
+func (t *connectionTrace) handleStreamFrames(frames []qlog.Frame, sent bool) {
+	if len(t.requests) == 0 {
+		return
+	}
+	fromClient := sent == t.isClient
+	for _, f := range frames {
+		switch frame := f.Frame.(type) {
+		case *qlog.StreamFrame:
+			if !frame.Fin || fromClient {
+				continue
+			}
+			if r, ok := t.requests[frame.StreamID]; ok {
+				r.responseComplete = true
+				if r.hasResponse {
+					t.endRequestSpan(frame.StreamID)
+				}
+			}
+		case *qlog.ResetStreamFrame:
+			if r, ok := t.requests[frame.StreamID]; ok {
+				if fromClient {
+					r.span.SetStatus(codes.Error, "request canceled")
+				} else {
+					r.span.SetStatus(codes.Error, "response stream reset")
+				}
+			}
+			t.endRequestSpan(frame.StreamID)
+		}
+	}
+}
+
+func (t *connectionTrace) endRequestSpan(id qlog.StreamID) {
+	if r, ok := t.requests[id]; ok {
+		r.span.End()
+		delete(t.requests, id)
+	}
+}
+
+func headerValue(hf h3qlog.HeadersFrame, name string) (string, bool) {
+	for _, f := range hf.HeaderFields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+func endpointAttributes(local, remote qlog.PathEndpointInfo) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if addr := endpointAddr(local); addr.IsValid() {
+		attrs = append(attrs, semconv.NetworkLocalAddress(addr.Addr().String()), semconv.NetworkLocalPort(int(addr.Port())))
+	}
+	if addr := endpointAddr(remote); addr.IsValid() {
+		attrs = append(attrs, semconv.NetworkPeerAddress(addr.Addr().String()), semconv.NetworkPeerPort(int(addr.Port())))
+	}
+	return attrs
+}
+
+func endpointAddr(info qlog.PathEndpointInfo) netip.AddrPort {
+	if info.IPv4.IsValid() {
+		return info.IPv4
+	}
+	return info.IPv6
+}
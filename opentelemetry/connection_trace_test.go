@@ -0,0 +1,284 @@
+package opentelemetry
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	h3qlog "github.com/quic-go/quic-go/http3/qlog"
+	"github.com/quic-go/quic-go/qlog"
+	"github.com/quic-go/quic-go/qlogwriter"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testTracer struct {
+	spans   *tracetest.SpanRecorder
+	metrics *sdkmetric.ManualReader
+	tracer  func(context.Context, bool, qlogwriter.ConnectionID) qlogwriter.Trace
+}
+
+func newTestTracer() *testTracer {
+	spans := tracetest.NewSpanRecorder()
+	metrics := sdkmetric.NewManualReader()
+	return &testTracer{
+		spans:   spans,
+		metrics: metrics,
+		tracer: NewConnectionTracer(&Options{
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+			MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(metrics)),
+		}),
+	}
+}
+
+func (tt *testTracer) collect(t *testing.T) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, tt.metrics.Collect(context.Background(), &rm))
+	m := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			m[metric.Name] = metric.Data
+		}
+	}
+	return m
+}
+
+func spanEventNames(span sdktrace.ReadOnlySpan) []string {
+	var names []string
+	for _, ev := range span.Events() {
+		names = append(names, ev.Name)
+	}
+	return names
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestConnectionSpan(t *testing.T) {
+	tt := newTestTracer()
+
+	parentCtx, parent := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "parent")
+	tr := tt.tracer(parentCtx, true, quic.ConnectionIDFromBytes([]byte{1, 2, 3, 4}))
+	require.True(t, tr.SupportsSchemas(qlog.EventSchema))
+	require.True(t, tr.SupportsSchemas(h3qlog.EventSchema))
+	r := tr.AddProducer()
+
+	r.RecordEvent(qlog.StartedConnection{
+		Local:  qlog.PathEndpointInfo{IPv4: netip.MustParseAddrPort("127.0.0.1:1234")},
+		Remote: qlog.PathEndpointInfo{IPv6: netip.MustParseAddrPort("[::1]:443")},
+	})
+	r.RecordEvent(qlog.ALPNInformation{ChosenALPN: "h3"})
+	// only one span event is recorded per key update
+	for _, kt := range []qlog.KeyType{qlog.KeyTypeClient1RTT, qlog.KeyTypeServer1RTT} {
+		r.RecordEvent(qlog.KeyUpdated{Trigger: qlog.KeyUpdateTLS, KeyType: kt})
+		r.RecordEvent(qlog.KeyUpdated{Trigger: qlog.KeyUpdateLocal, KeyType: kt, KeyPhase: 1})
+	}
+	r.RecordEvent(qlog.MigrationStateUpdated{
+		State:  qlog.MigrationStateComplete,
+		Local:  qlog.PathEndpointInfo{IPv4: netip.MustParseAddrPort("127.0.0.1:4321")},
+		Remote: qlog.PathEndpointInfo{IPv6: netip.MustParseAddrPort("[::1]:443")},
+	})
+	require.Empty(t, tt.spans.Ended())
+
+	errorCode := qlog.TransportErrorCode(quic.ProtocolViolation)
+	r.RecordEvent(qlog.ConnectionClosed{
+		Initiator:       qlog.InitiatorRemote,
+		ConnectionError: &errorCode,
+		Reason:          "foobar",
+	})
+	// events recorded after the connection was closed are ignored
+	r.RecordEvent(qlog.ALPNInformation{ChosenALPN: "h3"})
+	require.NoError(t, r.Close())
+
+	spans := tt.spans.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "quic.connection", span.Name())
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.Equal(t, parent.SpanContext().TraceID(), span.Parent().TraceID())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	require.Equal(t, "01020304", spanAttribute(span, attrConnectionID).AsString())
+	require.Equal(t, "127.0.0.1", spanAttribute(span, "network.local.address").AsString())
+	require.Equal(t, int64(1234), spanAttribute(span, "network.local.port").AsInt64())
+	require.Equal(t, "::1", spanAttribute(span, "network.peer.address").AsString())
+	require.Equal(t, int64(443), spanAttribute(span, "network.peer.port").AsInt64())
+	require.Equal(t, []string{"handshake_complete", "key_update", "migration", "connection_closed"}, spanEventNames(span))
+	require.Equal(t, codes.Error, span.Status().Code)
+	require.Equal(t, "protocol_violation", span.Status().Description)
+
+	closeEvent := span.Events()[3]
+	require.Contains(t, closeEvent.Attributes, attrCloseInitiator.String("remote"))
+	require.Contains(t, closeEvent.Attributes, attrCloseMessage.String("foobar"))
+
+	m := tt.collect(t)
+	require.Equal(t, int64(1), m["quic.connections.started"].(metricdata.Sum[int64]).DataPoints[0].Value)
+	closed := m["quic.connections.closed"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, closed, 1)
+	require.Equal(t, int64(1), closed[0].Value)
+	reason, ok := closed[0].Attributes.Value(attrCloseReason)
+	require.True(t, ok)
+	require.Equal(t, "protocol_violation", reason.AsString())
+	require.Equal(t, uint64(1), m["quic.handshake.duration"].(metricdata.Histogram[float64]).DataPoints[0].Count)
+	require.Equal(t, uint64(1), m["quic.connection.duration"].(metricdata.Histogram[float64]).DataPoints[0].Count)
+}
+
+func TestConnectionSpanEndedOnClose(t *testing.T) {
+	tt := newTestTracer()
+
+	tr := tt.tracer(context.Background(), false, quic.ConnectionID{})
+	r1 := tr.AddProducer()
+	r2 := tr.AddProducer()
+	require.NoError(t, r1.Close())
+	require.Empty(t, tt.spans.Ended())
+	require.NoError(t, r2.Close())
+	require.Len(t, tt.spans.Ended(), 1)
+	require.Equal(t, codes.Unset, tt.spans.Ended()[0].Status().Code)
+}
+
+func TestConnectionMetrics(t *testing.T) {
+	tt := newTestTracer()
+
+	r := tt.tracer(context.Background(), false, quic.ConnectionID{}).AddProducer()
+	r.RecordEvent(qlog.PacketSent{Header: qlog.PacketHeader{PacketType: qlog.PacketType1RTT}})
+	r.RecordEvent(qlog.PacketSent{Header: qlog.PacketHeader{PacketType: qlog.PacketType1RTT}})
+	r.RecordEvent(qlog.PacketReceived{Header: qlog.PacketHeader{PacketType: qlog.PacketTypeHandshake}})
+	r.RecordEvent(qlog.PacketDropped{Trigger: qlog.PacketDropDuplicate})
+	r.RecordEvent(qlog.PacketLost{
+		Header:  qlog.PacketHeader{PacketType: qlog.PacketTypeInitial},
+		Trigger: qlog.PacketLossTimeThreshold,
+	})
+	r.RecordEvent(qlog.MetricsUpdated{LatestRTT: 10 * time.Millisecond, CongestionWindow: 12000})
+	r.RecordEvent(qlog.MetricsUpdated{CongestionWindow: 24000})
+
+	m := tt.collect(t)
+	sent := m["quic.packets.sent"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, sent, 1)
+	require.Equal(t, int64(2), sent[0].Value)
+	require.Equal(t, attribute.NewSet(attrPerspective.String("server"), attrPacketType.String("1RTT")), sent[0].Attributes)
+	received := m["quic.packets.received"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, received, 1)
+	require.Equal(t, attribute.NewSet(attrPerspective.String("server"), attrPacketType.String("handshake")), received[0].Attributes)
+	dropped := m["quic.packets.dropped"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, dropped, 1)
+	require.Equal(t,
+		attribute.NewSet(attrPerspective.String("server"), attrPacketType.String("unknown"), attrDropReason.String("duplicate")),
+		dropped[0].Attributes,
+	)
+	lost := m["quic.packets.lost"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, lost, 1)
+	require.Equal(t,
+		attribute.NewSet(attrPerspective.String("server"), attrPacketType.String("initial"), attrLossReason.String("time_threshold")),
+		lost[0].Attributes,
+	)
+	require.Equal(t, uint64(1), m["quic.rtt"].(metricdata.Histogram[float64]).DataPoints[0].Count)
+	require.Equal(t, uint64(2), m["quic.congestion_window"].(metricdata.Histogram[int64]).DataPoints[0].Count)
+}
+
+func requestHeaders(method, path string) h3qlog.Frame {
+	return h3qlog.Frame{Frame: h3qlog.HeadersFrame{HeaderFields: []h3qlog.HeaderField{
+		{Name: ":method", Value: method},
+		{Name: ":scheme", Value: "https"},
+		{Name: ":authority", Value: "example.com"},
+		{Name: ":path", Value: path},
+	}}}
+}
+
+func responseHeaders(status string) h3qlog.Frame {
+	return h3qlog.Frame{Frame: h3qlog.HeadersFrame{HeaderFields: []h3qlog.HeaderField{
+		{Name: ":status", Value: status},
+	}}}
+}
+
+func TestHTTP3RequestSpansClient(t *testing.T) {
+	tt := newTestTracer()
+
+	r := tt.tracer(context.Background(), true, quic.ConnectionID{}).AddProducer()
+	r.RecordEvent(h3qlog.FrameCreated{StreamID: 0, Frame: requestHeaders("GET", "/foo?bar=baz")})
+	r.RecordEvent(h3qlog.FrameCreated{StreamID: 4, Frame: requestHeaders("POST", "/upload")})
+	r.RecordEvent(qlog.PacketSent{Frames: []qlog.Frame{{Frame: &qlog.StreamFrame{StreamID: 0, Fin: true}}}})
+	r.RecordEvent(h3qlog.FrameParsed{StreamID: 0, Frame: responseHeaders("103")})
+	r.RecordEvent(h3qlog.FrameParsed{StreamID: 0, Frame: responseHeaders("404")})
+	require.Empty(t, tt.spans.Ended())
+	// the response stream is closed
+	r.RecordEvent(qlog.PacketReceived{Frames: []qlog.Frame{{Frame: &qlog.StreamFrame{StreamID: 0, Fin: true}}}})
+	require.Len(t, tt.spans.Ended(), 1)
+	// the client cancels the request
+	r.RecordEvent(qlog.PacketSent{Frames: []qlog.Frame{{Frame: &qlog.ResetStreamFrame{StreamID: 4}}}})
+	require.Len(t, tt.spans.Ended(), 2)
+
+	spans := tt.spans.Ended()
+	get := spans[0]
+	require.Equal(t, "GET", get.Name())
+	require.Equal(t, trace.SpanKindClient, get.SpanKind())
+	require.Equal(t, "GET", spanAttribute(get, "http.request.method").AsString())
+	require.Equal(t, "https", spanAttribute(get, "url.scheme").AsString())
+	require.Equal(t, "example.com", spanAttribute(get, "server.address").AsString())
+	require.Equal(t, "/foo", spanAttribute(get, "url.path").AsString())
+	require.Equal(t, int64(404), spanAttribute(get, "http.response.status_code").AsInt64())
+	require.Equal(t, codes.Error, get.Status().Code)
+
+	post := spans[1]
+	require.Equal(t, "POST", post.Name())
+	require.Equal(t, int64(4), spanAttribute(post, attrStreamID).AsInt64())
+	require.Equal(t, codes.Error, post.Status().Code)
+	require.Equal(t, "request canceled", post.Status().Description)
+
+	// the request spans are children of the connection span
+	require.NoError(t, r.Close())
+	require.Len(t, tt.spans.Ended(), 3)
+	conn := tt.spans.Ended()[2]
+	require.Equal(t, "quic.connection", conn.Name())
+	require.Equal(t, conn.SpanContext().SpanID(), get.Parent().SpanID())
+	require.Equal(t, conn.SpanContext().SpanID(), post.Parent().SpanID())
+}
+
+func TestHTTP3RequestSpansServer(t *testing.T) {
+	tt := newTestTracer()
+
+	r := tt.tracer(context.Background(), false, quic.ConnectionID{}).AddProducer()
+	// The FIN is received before the HTTP/3 layer parses the HEADERS frame.
+	r.RecordEvent(qlog.PacketReceived{Frames: []qlog.Frame{{Frame: &qlog.StreamFrame{StreamID: 8, Fin: true}}}})
+	r.RecordEvent(h3qlog.FrameParsed{StreamID: 8, Frame: requestHeaders("GET", "/")})
+	// Control streams and unidirectional streams don't carry requests.
+	r.RecordEvent(h3qlog.FrameParsed{StreamID: 2, Frame: requestHeaders("GET", "/")})
+	r.RecordEvent(h3qlog.FrameCreated{StreamID: 8, Frame: responseHeaders("500")})
+	r.RecordEvent(qlog.PacketSent{Frames: []qlog.Frame{{Frame: &qlog.StreamFrame{StreamID: 8, Fin: true}}}})
+
+	spans := tt.spans.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	require.Equal(t, int64(500), spanAttribute(spans[0], "http.response.status_code").AsInt64())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestHTTP3RequestSpansResponseFINBeforeHeaders(t *testing.T) {
+	tt := newTestTracer()
+
+	r := tt.tracer(context.Background(), true, quic.ConnectionID{}).AddProducer()
+	r.RecordEvent(h3qlog.FrameCreated{StreamID: 0, Frame: requestHeaders("GET", "/")})
+	// The QUIC layer processes the FIN before the HTTP/3 layer parses the HEADERS frame.
+	r.RecordEvent(qlog.PacketReceived{Frames: []qlog.Frame{{Frame: &qlog.StreamFrame{StreamID: 0, Fin: true}}}})
+	require.Empty(t, tt.spans.Ended())
+	r.RecordEvent(h3qlog.FrameParsed{StreamID: 0, Frame: responseHeaders("200")})
+
+	spans := tt.spans.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, int64(200), spanAttribute(spans[0], "http.response.status_code").AsInt64())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+}
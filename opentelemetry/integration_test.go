@@ -0,0 +1,147 @@
+package opentelemetry_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/quic-go/opentelemetry"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func getTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	root := x509.NewCertPool()
+	root.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{certDER}, PrivateKey: priv}}}
+	client = &tls.Config{RootCAs: root}
+	return server, client
+}
+
+func startHTTPServer(t *testing.T, tlsConf *tls.Config, mux *http.ServeMux, quicConf *quic.Config) (port int) {
+	t.Helper()
+
+	server := &http3.Server{Handler: mux, TLSConfig: tlsConf, QUICConfig: quicConf}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.Serve(conn)
+	}()
+	t.Cleanup(func() {
+		conn.Close()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("server didn't shut down")
+		}
+	})
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func findEndedSpan(spans *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	for _, s := range spans.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestHTTPOpenTelemetry(t *testing.T) {
+	serverSpans := tracetest.NewSpanRecorder()
+	clientSpans := tracetest.NewSpanRecorder()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "Hello, World!\n")
+	})
+	serverTLSConf, clientTLSConf := getTLSConfigs(t)
+	port := startHTTPServer(t, serverTLSConf, mux, &quic.Config{
+		Tracer: opentelemetry.NewConnectionTracer(&opentelemetry.Options{
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(serverSpans)),
+		}),
+	})
+	tr := &http3.Transport{
+		TLSClientConfig: clientTLSConf,
+		QUICConfig: &quic.Config{
+			Tracer: opentelemetry.NewConnectionTracer(&opentelemetry.Options{
+				TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(clientSpans)),
+			}),
+		},
+	}
+	defer tr.Close()
+	cl := &http.Client{Transport: tr, Timeout: 2 * time.Second}
+
+	resp, err := cl.Get(fmt.Sprintf("https://localhost:%d/hello", port))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "Hello, World!\n", string(body))
+
+	for _, spans := range []*tracetest.SpanRecorder{clientSpans, serverSpans} {
+		require.Eventually(t, func() bool { return findEndedSpan(spans, "GET") != nil }, time.Second, 5*time.Millisecond)
+		// the connection is still open
+		require.Nil(t, findEndedSpan(spans, "quic.connection"))
+		started := spans.Started()
+		require.Len(t, started, 2)
+		conn := started[0]
+		require.Equal(t, "quic.connection", conn.Name())
+		req := findEndedSpan(spans, "GET")
+		require.Equal(t, conn.SpanContext().SpanID(), req.Parent().SpanID())
+		var status int64
+		for _, attr := range req.Attributes() {
+			if attr.Key == "http.response.status_code" {
+				status = attr.Value.AsInt64()
+			}
+		}
+		require.Equal(t, int64(http.StatusOK), status)
+	}
+	require.Equal(t, trace.SpanKindClient, findEndedSpan(clientSpans, "GET").SpanKind())
+	require.Equal(t, trace.SpanKindServer, findEndedSpan(serverSpans, "GET").SpanKind())
+
+	// closing the connection ends the connection span
+	tr.Close()
+	require.Eventually(t, func() bool { return findEndedSpan(clientSpans, "quic.connection") != nil }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return findEndedSpan(serverSpans, "quic.connection") != nil }, time.Second, 5*time.Millisecond)
+	require.Contains(t, spanEventNames(findEndedSpan(clientSpans, "quic.connection")), "handshake_complete")
+	require.Contains(t, spanEventNames(findEndedSpan(serverSpans, "quic.connection")), "connection_closed")
+}
+
+func spanEventNames(span sdktrace.ReadOnlySpan) []string {
+	var names []string
+	for _, ev := range span.Events() {
+		names = append(names, ev.Name)
+	}
+	return names
+}
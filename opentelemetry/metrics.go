@@ -0,0 +1,96 @@
+package opentelemetry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// instruments are the metric instruments shared by all connections created by the same tracer.
+type instruments struct {
+	connsStarted      metric.Int64Counter
+	connsClosed       metric.Int64Counter
+	connDuration      metric.Float64Histogram
+	handshakeDuration metric.Float64Histogram
+	packetsSent       metric.Int64Counter
+	packetsReceived   metric.Int64Counter
+	packetsDropped    metric.Int64Counter
+	packetsLost       metric.Int64Counter
+	rtt               metric.Float64Histogram
+	congestionWindow  metric.Int64Histogram
+}
+
+// newInstruments creates the metric instruments.
+// Errors are passed to the global OpenTelemetry error handler.
+// In that case, the meter returns a no-op instrument, so it's safe to continue.
+func newInstruments(meter metric.Meter) *instruments {
+	handle := func(err error) {
+		if err != nil {
+			otel.Handle(err)
+		}
+	}
+	var (
+		i   instruments
+		err error
+	)
+	i.connsStarted, err = meter.Int64Counter(
+		"quic.connections.started",
+		metric.WithDescription("Number of QUIC connections started."),
+		metric.WithUnit("{connection}"),
+	)
+	handle(err)
+	i.connsClosed, err = meter.Int64Counter(
+		"quic.connections.closed",
+		metric.WithDescription("Number of QUIC connections closed."),
+		metric.WithUnit("{connection}"),
+	)
+	handle(err)
+	i.connDuration, err = meter.Float64Histogram(
+		"quic.connection.duration",
+		metric.WithDescription("Duration of QUIC connections."),
+		metric.WithUnit("s"),
+	)
+	handle(err)
+	i.handshakeDuration, err = meter.Float64Histogram(
+		"quic.handshake.duration",
+		metric.WithDescription("Duration of the QUIC handshake."),
+		metric.WithUnit("s"),
+	)
+	handle(err)
+	i.packetsSent, err = meter.Int64Counter(
+		"quic.packets.sent",
+		metric.WithDescription("Number of QUIC packets sent."),
+		metric.WithUnit("{packet}"),
+	)
+	handle(err)
+	i.packetsReceived, err = meter.Int64Counter(
+		"quic.packets.received",
+		metric.WithDescription("Number of QUIC packets received."),
+		metric.WithUnit("{packet}"),
+	)
+	handle(err)
+	i.packetsDropped, err = meter.Int64Counter(
+		"quic.packets.dropped",
+		metric.WithDescription("Number of received QUIC packets that were dropped."),
+		metric.WithUnit("{packet}"),
+	)
+	handle(err)
+	i.packetsLost, err = meter.Int64Counter(
+		"quic.packets.lost",
+		metric.WithDescription("Number of sent QUIC packets that were declared lost."),
+		metric.WithUnit("{packet}"),
+	)
+	handle(err)
+	i.rtt, err = meter.Float64Histogram(
+		"quic.rtt",
+		metric.WithDescription("RTT samples."),
+		metric.WithUnit("s"),
+	)
+	handle(err)
+	i.congestionWindow, err = meter.Int64Histogram(
+		"quic.congestion_window",
+		metric.WithDescription("Updates of the congestion window."),
+		metric.WithUnit("By"),
+	)
+	handle(err)
+	return &i
+}
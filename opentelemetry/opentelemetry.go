@@ -0,0 +1,52 @@
+// Package opentelemetry maps the qlog events emitted by quic-go onto OpenTelemetry spans and metrics.
+//
+// Every QUIC connection is represented by a span. Completion of the handshake, key updates,
+// connection migrations and the closing of the connection are recorded as span events.
+// If HTTP/3 is used on top of the connection, every HTTP/3 request becomes a child span of the connection span.
+//
+// The function returned by [NewConnectionTracer] is intended to be used as the Config.Tracer.
+package opentelemetry
+
+import (
+	"context"
+
+	"github.com/quic-go/quic-go/qlogwriter"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/quic-go/quic-go/opentelemetry"
+
+// Options configures the OpenTelemetry bridge.
+type Options struct {
+	// TracerProvider is used to create spans.
+	// If nil, the global TracerProvider is used.
+	TracerProvider trace.TracerProvider
+	// MeterProvider is used to create metric instruments.
+	// If nil, the global MeterProvider is used.
+	MeterProvider metric.MeterProvider
+}
+
+// NewConnectionTracer returns a function that can be used as the Config.Tracer.
+// If the context passed to the function contains a span, it is used as the parent of the connection span.
+// For outgoing connections, this is the context passed to Dial.
+func NewConnectionTracer(opts *Options) func(context.Context, bool, qlogwriter.ConnectionID) qlogwriter.Trace {
+	if opts == nil {
+		opts = &Options{}
+	}
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	mp := opts.MeterProvider
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	tracer := tp.Tracer(instrumentationName)
+	instruments := newInstruments(mp.Meter(instrumentationName))
+	return func(ctx context.Context, isClient bool, connID qlogwriter.ConnectionID) qlogwriter.Trace {
+		return newConnectionTrace(ctx, tracer, instruments, isClient, connID)
+	}
+}
@@ -26,8 +26,11 @@ type packer interface {
 	PackApplicationClose(*qerr.ApplicationError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackPathProbePacket(protocol.ConnectionID, []ackhandler.Frame, protocol.Version) (shortHeaderPacket, *packetBuffer, error)
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	AppendPathPacket(_ *packetBuffer, _ packerPath, onlyAck bool, maxPacketSize protocol.ByteCount, now monotime.Time, v protocol.Version) (shortHeaderPacket, error)
+	PackPathFramesPacket(_ packerPath, _ []ackhandler.Frame, isPathProbe bool, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
 
 	SetToken([]byte)
+	EnableQUICBitGreasing()
 }
 
 type sealer interface {
@@ -38,6 +41,7 @@ type payload struct {
 	streamFrames []ackhandler.StreamFrame
 	frames       []ackhandler.Frame
 	ack          *wire.AckFrame
+	ackPathID    protocol.PathID // if non-zero, the ACK is serialized as a PATH_ACK frame
 	length       protocol.ByteCount
 }
 
@@ -51,6 +55,7 @@ type longHeaderPacket struct {
 }
 
 type shortHeaderPacket struct {
+	PathID               protocol.PathID
 	PacketNumber         protocol.PacketNumber
 	Frames               []ackhandler.Frame
 	StreamFrames         []ackhandler.StreamFrame
@@ -108,6 +113,7 @@ type sealingManager interface {
 
 type frameSource interface {
 	HasData() bool
+	StreamUrgency() (uint8, bool)
 	Append([]ackhandler.Frame, []ackhandler.StreamFrame, protocol.ByteCount, monotime.Time, protocol.Version) ([]ackhandler.Frame, []ackhandler.StreamFrame, protocol.ByteCount)
 }
 
@@ -115,6 +121,34 @@ type ackFrameSource interface {
 	GetAckFrame(_ protocol.EncryptionLevel, now monotime.Time, onlyIfQueued bool) *wire.AckFrame
 }
 
+// A packerPath is a path that 1-RTT packets are sent on.
+// When using the multipath extension, every path has its own packet number space,
+// and acknowledgements for paths other than the initial path are sent in PATH_ACK frames.
+type packerPath struct {
+	ID        protocol.PathID
+	ConnID    protocol.ConnectionID
+	PNManager packetNumberManager
+	Acks      ackFrameSource
+	SpinBit   *spinBit // nil for path probe packets, which are sent with a spin value of 0
+}
+
+// pathSealer seals packets sent on a path other than the initial path.
+type pathSealer struct {
+	handshake.ShortHeaderSealer
+	pathID protocol.PathID
+}
+
+func (s *pathSealer) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byte) []byte {
+	return s.ShortHeaderSealer.SealPath(dst, src, s.pathID, pn, ad)
+}
+
+func ackFrameLength(ack *wire.AckFrame, pathID protocol.PathID, v protocol.Version) protocol.ByteCount {
+	if pathID == protocol.InitialPathID {
+		return ack.Length(v)
+	}
+	return (&wire.PathAckFrame{PathID: pathID, AckFrame: *ack}).Length(v)
+}
+
 type packetPacker struct {
 	srcConnID     protocol.ConnectionID
 	getDestConnID func() protocol.ConnectionID
@@ -132,13 +166,27 @@ type packetPacker struct {
 	acks                ackFrameSource
 	datagramQueue       *datagramQueue
 	retransmissionQueue *retransmissionQueue
+	spinBit             *spinBit
 	rand                rand.Rand
 
+	// set once the peer advertised the grease_quic_bit transport parameter (RFC 9287)
+	greaseQUICBit bool
+
 	numNonAckElicitingAcks int
 }
 
 var _ packer = &packetPacker{}
 
+func (p *packetPacker) initialPath(connID protocol.ConnectionID) packerPath {
+	return packerPath{
+		ID:        protocol.InitialPathID,
+		ConnID:    connID,
+		PNManager: p.pnManager,
+		Acks:      p.acks,
+		SpinBit:   p.spinBit,
+	}
+}
+
 func newPacketPacker(
 	srcConnID protocol.ConnectionID,
 	getDestConnID func() protocol.ConnectionID,
@@ -150,6 +198,7 @@ func newPacketPacker(
 	framer frameSource,
 	acks ackFrameSource,
 	datagramQueue *datagramQueue,
+	spinBit *spinBit,
 	perspective protocol.Perspective,
 ) *packetPacker {
 	var b [16]byte
@@ -163,6 +212,7 @@ func newPacketPacker(
 		handshakeStream:     handshakeStream,
 		retransmissionQueue: retransmissionQueue,
 		datagramQueue:       datagramQueue,
+		spinBit:             spinBit,
 		perspective:         perspective,
 		framer:              framer,
 		acks:                acks,
@@ -272,7 +322,7 @@ func (p *packetPacker) packConnectionClose(
 			continue
 		}
 		if encLevel == protocol.Encryption1RTT {
-			shp, err := p.appendShortHeaderPacket(buffer, connID, oneRTTPacketNumber, oneRTTPacketNumberLen, keyPhase, payloads[i], 0, maxPacketSize, sealers[i], false, v)
+			shp, err := p.appendShortHeaderPacket(buffer, p.initialPath(connID), oneRTTPacketNumber, oneRTTPacketNumberLen, keyPhase, payloads[i], 0, maxPacketSize, sealers[i], false, v)
 			if err != nil {
 				return nil, err
 			}
@@ -399,7 +449,7 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool, maxSize protocol.ByteCo
 			connID = p.getDestConnID()
 			oneRTTPacketNumber, oneRTTPacketNumberLen = p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 			hdrLen := wire.ShortHeaderLen(connID, oneRTTPacketNumberLen)
-			oneRTTPayload = p.maybeGetShortHeaderPacket(oneRTTSealer, hdrLen, maxSize-size, onlyAck, size == 0, now, v)
+			oneRTTPayload = p.maybeGetShortHeaderPacket(p.initialPath(connID), oneRTTSealer, hdrLen, maxSize-size, onlyAck, size == 0, now, v)
 			if oneRTTPayload.length > 0 {
 				size += p.shortHeaderPacketLength(connID, oneRTTPacketNumberLen, oneRTTPayload) + protocol.ByteCount(oneRTTSealer.Overhead())
 			}
@@ -449,7 +499,7 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool, maxSize protocol.ByteCo
 		}
 		packet.longHdrPackets = append(packet.longHdrPackets, longHdrPacket)
 	} else if oneRTTPayload.length > 0 {
-		shp, err := p.appendShortHeaderPacket(buffer, connID, oneRTTPacketNumber, oneRTTPacketNumberLen, kp, oneRTTPayload, 0, maxSize, oneRTTSealer, false, v)
+		shp, err := p.appendShortHeaderPacket(buffer, p.initialPath(connID), oneRTTPacketNumber, oneRTTPacketNumberLen, kp, oneRTTPayload, 0, maxSize, oneRTTSealer, false, v)
 		if err != nil {
 			return nil, err
 		}
@@ -462,37 +512,61 @@ func (p *packetPacker) PackCoalescedPacket(onlyAck bool, maxSize protocol.ByteCo
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) PackAckOnlyPacket(maxSize protocol.ByteCount, now monotime.Time, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
 	buf := getPacketBuffer()
-	packet, err := p.appendPacket(buf, true, maxSize, now, v)
+	packet, err := p.appendPacket(buf, p.initialPath(p.getDestConnID()), true, maxSize, now, v)
 	return packet, buf, err
 }
 
 // AppendPacket packs a packet in the application data packet number space.
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) AppendPacket(buf *packetBuffer, maxSize protocol.ByteCount, now monotime.Time, v protocol.Version) (shortHeaderPacket, error) {
-	return p.appendPacket(buf, false, maxSize, now, v)
+	return p.appendPacket(buf, p.initialPath(p.getDestConnID()), false, maxSize, now, v)
+}
+
+// AppendPathPacket packs a packet in the application data packet number space of the given path.
+// It is used by the multipath extension, and should be called after the handshake is confirmed.
+func (p *packetPacker) AppendPathPacket(
+	buf *packetBuffer,
+	path packerPath,
+	onlyAck bool,
+	maxSize protocol.ByteCount,
+	now monotime.Time,
+	v protocol.Version,
+) (shortHeaderPacket, error) {
+	return p.appendPacket(buf, path, onlyAck, maxSize, now, v)
+}
+
+func (p *packetPacker) get1RTTSealer(pathID protocol.PathID) (handshake.ShortHeaderSealer, sealer, error) {
+	s, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return nil, nil, err
+	}
+	if pathID == protocol.InitialPathID {
+		return s, s, nil
+	}
+	return s, &pathSealer{ShortHeaderSealer: s, pathID: pathID}, nil
 }
 
 func (p *packetPacker) appendPacket(
 	buf *packetBuffer,
+	path packerPath,
 	onlyAck bool,
 	maxPacketSize protocol.ByteCount,
 	now monotime.Time,
 	v protocol.Version,
 ) (shortHeaderPacket, error) {
-	sealer, err := p.cryptoSetup.Get1RTTSealer()
+	s, sealer, err := p.get1RTTSealer(path.ID)
 	if err != nil {
 		return shortHeaderPacket{}, err
 	}
-	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
-	connID := p.getDestConnID()
-	hdrLen := wire.ShortHeaderLen(connID, pnLen)
-	pl := p.maybeGetShortHeaderPacket(sealer, hdrLen, maxPacketSize, onlyAck, true, now, v)
+	pn, pnLen := path.PNManager.PeekPacketNumber(protocol.Encryption1RTT)
+	hdrLen := wire.ShortHeaderLen(path.ConnID, pnLen)
+	pl := p.maybeGetShortHeaderPacket(path, s, hdrLen, maxPacketSize, onlyAck, true, now, v)
 	if pl.length == 0 {
 		return shortHeaderPacket{}, errNothingToPack
 	}
-	kp := sealer.KeyPhase()
+	kp := s.KeyPhase()
 
-	return p.appendShortHeaderPacket(buf, connID, pn, pnLen, kp, pl, 0, maxPacketSize, sealer, false, v)
+	return p.appendShortHeaderPacket(buf, path, pn, pnLen, kp, pl, 0, maxPacketSize, sealer, false, v)
 }
 
 func (p *packetPacker) maybeGetCryptoPacket(
@@ -585,10 +659,11 @@ func (p *packetPacker) maybeGetAppDataPacketFor0RTT(sealer sealer, maxSize proto
 
 	hdr := p.getLongHeader(protocol.Encryption0RTT, v)
 	maxPayloadSize := maxSize - hdr.GetLength(v) - protocol.ByteCount(sealer.Overhead())
-	return hdr, p.maybeGetAppDataPacket(maxPayloadSize, false, false, now, v)
+	return hdr, p.maybeGetAppDataPacket(p.initialPath(hdr.DestConnectionID), maxPayloadSize, false, false, now, v)
 }
 
 func (p *packetPacker) maybeGetShortHeaderPacket(
+	path packerPath,
 	sealer handshake.ShortHeaderSealer,
 	hdrLen, maxPacketSize protocol.ByteCount,
 	onlyAck, ackAllowed bool,
@@ -596,16 +671,17 @@ func (p *packetPacker) maybeGetShortHeaderPacket(
 	v protocol.Version,
 ) payload {
 	maxPayloadSize := maxPacketSize - hdrLen - protocol.ByteCount(sealer.Overhead())
-	return p.maybeGetAppDataPacket(maxPayloadSize, onlyAck, ackAllowed, now, v)
+	return p.maybeGetAppDataPacket(path, maxPayloadSize, onlyAck, ackAllowed, now, v)
 }
 
 func (p *packetPacker) maybeGetAppDataPacket(
+	path packerPath,
 	maxPayloadSize protocol.ByteCount,
 	onlyAck, ackAllowed bool,
 	now monotime.Time,
 	v protocol.Version,
 ) payload {
-	pl := p.composeNextPacket(maxPayloadSize, onlyAck, ackAllowed, now, v)
+	pl := p.composeNextPacket(path, maxPayloadSize, onlyAck, ackAllowed, now, v)
 
 	// check if we have anything to send
 	if len(pl.frames) == 0 && len(pl.streamFrames) == 0 {
@@ -628,14 +704,15 @@ func (p *packetPacker) maybeGetAppDataPacket(
 }
 
 func (p *packetPacker) composeNextPacket(
+	path packerPath,
 	maxPayloadSize protocol.ByteCount,
 	onlyAck, ackAllowed bool,
 	now monotime.Time,
 	v protocol.Version,
 ) payload {
 	if onlyAck {
-		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, now, true); ack != nil {
-			return payload{ack: ack, length: ack.Length(v)}
+		if ack := path.Acks.GetAckFrame(protocol.Encryption1RTT, now, true); ack != nil {
+			return payload{ack: ack, ackPathID: path.ID, length: ackFrameLength(ack, path.ID, v)}
 		}
 		return payload{}
 	}
@@ -646,27 +723,34 @@ func (p *packetPacker) composeNextPacket(
 	var hasAck bool
 	var pl payload
 	if ackAllowed {
-		if ack := p.acks.GetAckFrame(protocol.Encryption1RTT, now, !hasRetransmission && !hasData); ack != nil {
+		if ack := path.Acks.GetAckFrame(protocol.Encryption1RTT, now, !hasRetransmission && !hasData); ack != nil {
 			pl.ack = ack
-			pl.length += ack.Length(v)
+			pl.ackPathID = path.ID
+			pl.length += ackFrameLength(ack, path.ID, v)
 			hasAck = true
 		}
 	}
 
+	// A DATAGRAM frame is sent before stream data, unless a more urgent stream has data to send.
+	var deferredDatagram *queuedDatagram
 	if p.datagramQueue != nil {
-		if f := p.datagramQueue.Peek(); f != nil {
-			size := f.Length(v)
-			if size <= maxPayloadSize-pl.length { // DATAGRAM frame fits
-				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f})
-				pl.length += size
-				p.datagramQueue.Pop()
-			} else if !hasAck {
-				// The DATAGRAM frame doesn't fit, and the packet doesn't contain an ACK.
-				// Discard this frame. There's no point in retrying this in the next packet,
-				// as it's unlikely that the available packet size will increase.
-				p.datagramQueue.Pop()
+		if d := p.datagramQueue.Peek(now); d != nil {
+			if hasData && p.hasMoreUrgentStreamData(d.urgency) {
+				deferredDatagram = d
+			} else {
+				size := d.frame.Length(v)
+				if size <= maxPayloadSize-pl.length { // DATAGRAM frame fits
+					pl.frames = append(pl.frames, ackhandler.Frame{Frame: d.frame, Handler: d.AckHandler()})
+					pl.length += size
+					p.datagramQueue.Pop()
+				} else if !hasAck {
+					// The DATAGRAM frame doesn't fit, and the packet doesn't contain an ACK.
+					// Discard this frame. There's no point in retrying this in the next packet,
+					// as it's unlikely that the available packet size will increase.
+					p.datagramQueue.Discard()
+				}
+				// If the DATAGRAM frame was too large and the packet contained an ACK, we'll try to send it out later.
 			}
-			// If the DATAGRAM frame was too large and the packet contained an ACK, we'll try to send it out later.
 		}
 	}
 
@@ -703,15 +787,32 @@ func (p *packetPacker) composeNextPacket(
 			case *wire.PathChallengeFrame, *wire.PathResponseFrame:
 				// Path probing is currently not supported, therefore we don't need to set the OnAcked callback yet.
 				// PATH_CHALLENGE and PATH_RESPONSE are never retransmitted.
+			case *wire.ImmediateAckFrame:
+				// IMMEDIATE_ACK frames are never retransmitted.
 			default:
 				// we might be packing a 0-RTT packet, but we need to use the 1-RTT ack handler anyway
 				pl.frames[i].Handler = p.retransmissionQueue.AckHandler(protocol.Encryption1RTT)
 			}
 		}
 	}
+
+	// If there's space left after packing the stream data, the DATAGRAM frame is sent in this packet.
+	// Otherwise, it's sent in one of the next packets (unless it expires).
+	if deferredDatagram != nil {
+		if size := deferredDatagram.frame.Length(v); size <= maxPayloadSize-pl.length {
+			pl.frames = append(pl.frames, ackhandler.Frame{Frame: deferredDatagram.frame, Handler: deferredDatagram.AckHandler()})
+			pl.length += size
+			p.datagramQueue.Pop()
+		}
+	}
 	return pl
 }
 
+func (p *packetPacker) hasMoreUrgentStreamData(urgency uint8) bool {
+	streamUrgency, ok := p.framer.StreamUrgency()
+	return ok && streamUrgency < urgency
+}
+
 func (p *packetPacker) PackPTOProbePacket(
 	encLevel protocol.EncryptionLevel,
 	maxPacketSize protocol.ByteCount,
@@ -778,7 +879,7 @@ func (p *packetPacker) packPTOProbePacket1RTT(maxPacketSize protocol.ByteCount,
 	connID := p.getDestConnID()
 	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 	hdrLen := wire.ShortHeaderLen(connID, pnLen)
-	pl := p.maybeGetAppDataPacket(maxPacketSize-protocol.ByteCount(s.Overhead())-hdrLen, false, true, now, v)
+	pl := p.maybeGetAppDataPacket(p.initialPath(connID), maxPacketSize-protocol.ByteCount(s.Overhead())-hdrLen, false, true, now, v)
 	if pl.length == 0 {
 		if !addPingIfEmpty {
 			return nil, nil
@@ -789,7 +890,7 @@ func (p *packetPacker) packPTOProbePacket1RTT(maxPacketSize protocol.ByteCount,
 	}
 	buffer := getPacketBuffer()
 	packet := &coalescedPacket{buffer: buffer}
-	shp, err := p.appendShortHeaderPacket(buffer, connID, pn, pnLen, kp, pl, 0, maxPacketSize, s, false, v)
+	shp, err := p.appendShortHeaderPacket(buffer, p.initialPath(connID), pn, pnLen, kp, pl, 0, maxPacketSize, s, false, v)
 	if err != nil {
 		return nil, err
 	}
@@ -811,14 +912,23 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
 	padding := size - p.shortHeaderPacketLength(connID, pnLen, pl) - protocol.ByteCount(s.Overhead())
 	kp := s.KeyPhase()
-	packet, err := p.appendShortHeaderPacket(buffer, connID, pn, pnLen, kp, pl, padding, size, s, true, v)
+	packet, err := p.appendShortHeaderPacket(buffer, p.initialPath(connID), pn, pnLen, kp, pl, padding, size, s, true, v)
 	return packet, buffer, err
 }
 
 func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
-	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
+	path := p.initialPath(connID)
+	// The probe is sent on a different network path, which doesn't share the spin value of the current path.
+	path.SpinBit = nil
+	return p.PackPathFramesPacket(path, frames, true, v)
+}
+
+// PackPathFramesPacket packs a packet containing the given frames, to be sent on the given path.
+// Path probe packets are padded to 1200 bytes, see section 8.2.1 of RFC 9000.
+func (p *packetPacker) PackPathFramesPacket(path packerPath, frames []ackhandler.Frame, isPathProbe bool, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	pn, pnLen := path.PNManager.PeekPacketNumber(protocol.Encryption1RTT)
 	buf := getPacketBuffer()
-	s, err := p.cryptoSetup.Get1RTTSealer()
+	s, sealer, err := p.get1RTTSealer(path.ID)
 	if err != nil {
 		return shortHeaderPacket{}, nil, err
 	}
@@ -830,12 +940,17 @@ func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames
 		frames: frames,
 		length: l,
 	}
-	padding := protocol.MinInitialPacketSize - p.shortHeaderPacketLength(connID, pnLen, payload) - protocol.ByteCount(s.Overhead())
-	packet, err := p.appendShortHeaderPacket(buf, connID, pn, pnLen, s.KeyPhase(), payload, padding, protocol.MinInitialPacketSize, s, false, v)
+	maxPacketSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
+	var padding protocol.ByteCount
+	if isPathProbe {
+		maxPacketSize = protocol.MinInitialPacketSize
+		padding = protocol.MinInitialPacketSize - p.shortHeaderPacketLength(path.ConnID, pnLen, payload) - protocol.ByteCount(s.Overhead())
+	}
+	packet, err := p.appendShortHeaderPacket(buf, path, pn, pnLen, s.KeyPhase(), payload, padding, maxPacketSize, sealer, false, v)
 	if err != nil {
 		return shortHeaderPacket{}, nil, err
 	}
-	packet.IsPathProbePacket = true
+	packet.IsPathProbePacket = isPathProbe
 	return packet, buf, err
 }
 
@@ -877,6 +992,7 @@ func (p *packetPacker) appendLongHeaderPacket(buffer *packetBuffer, header *wire
 	if err != nil {
 		return nil, err
 	}
+	p.maybeGreaseQUICBit(raw)
 	payloadOffset := protocol.ByteCount(len(raw))
 
 	raw, err = p.appendPacketPayload(raw, pl, paddingLen, v)
@@ -898,9 +1014,30 @@ func (p *packetPacker) appendLongHeaderPacket(buffer *packetBuffer, header *wire
 	}, nil
 }
 
+// maybeGreaseQUICBit randomly clears the QUIC bit of a packet, if the peer supports it (RFC 9287).
+// The QUIC bit is not protected by header protection, but it is part of the associated data,
+// so this needs to happen before the packet is sealed.
+func (p *packetPacker) maybeGreaseQUICBit(hdr []byte) {
+	if p.greaseQUICBit && p.rand.IntN(2) == 0 {
+		hdr[0] &^= 0x40
+	}
+}
+
+// spinValue returns the value of the latency spin bit for the next packet sent on the given path.
+func (p *packetPacker) spinValue(path packerPath) bool {
+	if path.SpinBit == nil {
+		return false
+	}
+	// If the spin bit is disabled, RFC 9000 recommends sending random values.
+	if path.SpinBit.Disabled() {
+		return p.rand.IntN(2) == 0
+	}
+	return path.SpinBit.Value(path.ConnID)
+}
+
 func (p *packetPacker) appendShortHeaderPacket(
 	buffer *packetBuffer,
-	connID protocol.ConnectionID,
+	path packerPath,
 	pn protocol.PacketNumber,
 	pnLen protocol.PacketNumberLen,
 	kp protocol.KeyPhaseBit,
@@ -918,10 +1055,11 @@ func (p *packetPacker) appendShortHeaderPacket(
 
 	startLen := len(buffer.Data)
 	raw := buffer.Data[startLen:]
-	raw, err := wire.AppendShortHeader(raw, connID, pn, pnLen, kp)
+	raw, err := wire.AppendShortHeader(raw, path.ConnID, pn, pnLen, kp, p.spinValue(path))
 	if err != nil {
 		return shortHeaderPacket{}, err
 	}
+	p.maybeGreaseQUICBit(raw)
 	payloadOffset := protocol.ByteCount(len(raw))
 
 	raw, err = p.appendPacketPayload(raw, pl, paddingLen, v)
@@ -936,10 +1074,11 @@ func (p *packetPacker) appendShortHeaderPacket(
 	raw = p.encryptPacket(raw, sealer, pn, payloadOffset, protocol.ByteCount(pnLen))
 	buffer.Data = buffer.Data[:len(buffer.Data)+len(raw)]
 
-	if newPN := p.pnManager.PopPacketNumber(protocol.Encryption1RTT); newPN != pn {
+	if newPN := path.PNManager.PopPacketNumber(protocol.Encryption1RTT); newPN != pn {
 		return shortHeaderPacket{}, fmt.Errorf("packetPacker BUG: Peeked and Popped packet numbers do not match: expected %d, got %d", pn, newPN)
 	}
 	return shortHeaderPacket{
+		PathID:               path.ID,
 		PacketNumber:         pn,
 		PacketNumberLen:      pnLen,
 		KeyPhase:             kp,
@@ -947,7 +1086,7 @@ func (p *packetPacker) appendShortHeaderPacket(
 		Frames:               pl.frames,
 		Ack:                  pl.ack,
 		Length:               protocol.ByteCount(len(raw)),
-		DestConnID:           connID,
+		DestConnID:           path.ConnID,
 		IsPathMTUProbePacket: isMTUProbePacket,
 	}, nil
 }
@@ -958,7 +1097,11 @@ func (p *packetPacker) appendPacketPayload(raw []byte, pl payload, paddingLen pr
 	payloadOffset := len(raw)
 	if pl.ack != nil {
 		var err error
-		raw, err = pl.ack.Append(raw, v)
+		if pl.ackPathID != protocol.InitialPathID {
+			raw, err = (&wire.PathAckFrame{PathID: pl.ackPathID, AckFrame: *pl.ack}).Append(raw, v)
+		} else {
+			raw, err = pl.ack.Append(raw, v)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -1005,6 +1148,12 @@ func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
 
+// EnableQUICBitGreasing is called once the peer advertised the grease_quic_bit transport parameter.
+// From then on, the QUIC bit of outgoing packets is set randomly.
+func (p *packetPacker) EnableQUICBitGreasing() {
+	p.greaseQUICBit = true
+}
+
 type emptyHandler struct{}
 
 var _ ackhandler.FrameHandler = emptyHandler{}
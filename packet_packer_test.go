@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/handshake"
@@ -32,6 +33,7 @@ type testPacketPacker struct {
 	framer              *MockFrameSource
 	ackFramer           *MockAckFrameSource
 	retransmissionQueue *retransmissionQueue
+	spinBit             *spinBit
 }
 
 func newTestPacketPacker(t *testing.T, mockCtrl *gomock.Controller, pers protocol.Perspective) *testPacketPacker {
@@ -45,6 +47,7 @@ func newTestPacketPacker(t *testing.T, mockCtrl *gomock.Controller, pers protoco
 	sealingManager := NewMockSealingManager(mockCtrl)
 	datagramQueue := newDatagramQueue(func() {}, utils.DefaultLogger)
 	retransmissionQueue := newRetransmissionQueue()
+	spinBit := newSpinBit(pers, false)
 	return &testPacketPacker{
 		pnManager:           pnManager,
 		initialStream:       initialStream,
@@ -54,6 +57,7 @@ func newTestPacketPacker(t *testing.T, mockCtrl *gomock.Controller, pers protoco
 		ackFramer:           ackFramer,
 		datagramQueue:       datagramQueue,
 		retransmissionQueue: retransmissionQueue,
+		spinBit:             spinBit,
 		packer: newPacketPacker(
 			protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
 			func() protocol.ConnectionID { return destConnID },
@@ -65,6 +69,7 @@ func newTestPacketPacker(t *testing.T, mockCtrl *gomock.Controller, pers protoco
 			framer,
 			ackFramer,
 			datagramQueue,
+			spinBit,
 			pers,
 		),
 	}
@@ -524,6 +529,103 @@ func TestPack1RTTPacketWithACK(t *testing.T) {
 	require.Equal(t, ack, p.Ack)
 }
 
+func TestPack1RTTPacketSpinBit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
+	pack := func(t *testing.T) *packetBuffer {
+		t.Helper()
+		tp.pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+		tp.pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+		tp.framer.EXPECT().HasData()
+		tp.ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, gomock.Any(), true).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 1}}})
+		tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil)
+		buf := getPacketBuffer()
+		_, err := tp.packer.AppendPacket(buf, protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+		require.NoError(t, err)
+		return buf
+	}
+
+	require.False(t, wire.ShortHeaderSpinBit(pack(t).Data[0]))
+	tp.spinBit.ReceivedPacket(1, true)
+	require.True(t, wire.ShortHeaderSpinBit(pack(t).Data[0]))
+
+	// path probe packets are sent with a spin value of 0,
+	// and don't affect the spin value of the current path
+	tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil)
+	tp.pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+	tp.pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+	_, buf, err := tp.packer.PackPathProbePacket(
+		protocol.ParseConnectionID([]byte{5, 6, 7, 8}),
+		[]ackhandler.Frame{{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}},
+		protocol.Version1,
+	)
+	require.NoError(t, err)
+	require.False(t, wire.ShortHeaderSpinBit(buf.Data[0]))
+	require.True(t, wire.ShortHeaderSpinBit(pack(t).Data[0]))
+
+	// when the spin bit is disabled, random values are sent
+	tp.spinBit.disabled = true
+	var numSet int
+	for range 100 {
+		if wire.ShortHeaderSpinBit(pack(t).Data[0]) {
+			numSet++
+		}
+	}
+	require.Greater(t, numSet, 20)
+	require.Less(t, numSet, 80)
+}
+
+func TestPackQUICBitGreasing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
+	tp.pnManager.EXPECT().PeekPacketNumber(gomock.Any()).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2).AnyTimes()
+	tp.pnManager.EXPECT().PopPacketNumber(gomock.Any()).Return(protocol.PacketNumber(0x42)).AnyTimes()
+	tp.framer.EXPECT().HasData().AnyTimes()
+	tp.ackFramer.EXPECT().GetAckFrame(gomock.Any(), gomock.Any(), gomock.Any()).Return(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 1}}}).AnyTimes()
+	tp.sealingManager.EXPECT().GetInitialSealer().Return(nil, handshake.ErrKeysDropped).AnyTimes()
+	tp.sealingManager.EXPECT().GetHandshakeSealer().Return(newMockShortHeaderSealer(mockCtrl), nil).AnyTimes()
+	tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil).AnyTimes()
+
+	// returns the number of short and long header packets that had the QUIC bit cleared
+	countGreased := func(t *testing.T) (shortHdr, longHdr int) {
+		t.Helper()
+		for range 50 {
+			buf := getPacketBuffer()
+			_, err := tp.packer.AppendPacket(buf, protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+			require.NoError(t, err)
+			if buf.Data[0]&0x40 == 0 {
+				shortHdr++
+			}
+
+			tp.packer.retransmissionQueue.addHandshake(&wire.PingFrame{})
+			p, err := tp.packer.PackCoalescedPacket(false, protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+			require.NoError(t, err)
+			require.Len(t, p.longHdrPackets, 1)
+			require.Equal(t, protocol.EncryptionHandshake, p.longHdrPackets[0].EncryptionLevel())
+			if p.buffer.Data[0]&0x40 == 0 {
+				longHdr++
+			}
+			// the packet is parseable by a peer that advertised grease_quic_bit
+			hdr, _, rest, err := wire.ParsePacketAllowClearedQUICBit(p.buffer.Data)
+			require.NoError(t, err)
+			require.Empty(t, rest)
+			require.Equal(t, protocol.PacketTypeHandshake, hdr.Type)
+		}
+		return shortHdr, longHdr
+	}
+
+	shortHdr, longHdr := countGreased(t)
+	require.Zero(t, shortHdr)
+	require.Zero(t, longHdr)
+
+	tp.packer.EnableQUICBitGreasing()
+	shortHdr, longHdr = countGreased(t)
+	require.Greater(t, shortHdr, 5)
+	require.Less(t, shortHdr, 45)
+	require.Greater(t, longHdr, 5)
+	require.Less(t, longHdr, 45)
+}
+
 func TestPackPathChallengeAndPathResponse(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
@@ -603,7 +705,7 @@ func TestPackLargeDatagramFrame(t *testing.T) {
 	require.NotNil(t, p.Ack)
 	require.Empty(t, p.Frames)
 	require.NotEmpty(t, buffer.Data)
-	require.Equal(t, f, tp.datagramQueue.Peek()) // make sure the frame is still there
+	require.Equal(t, f, tp.datagramQueue.Peek(monotime.Now()).frame) // make sure the frame is still there
 
 	// Now try packing again, but with a smaller packet size.
 	// The DATAGRAM frame should now be dropped, as we can't expect to ever be able tosend it out.
@@ -615,7 +717,135 @@ func TestPackLargeDatagramFrame(t *testing.T) {
 	buffer = getPacketBuffer()
 	p, err = tp.packer.AppendPacket(buffer, newMaxPacketSize, monotime.Now(), protocol.Version1)
 	require.ErrorIs(t, err, errNothingToPack)
-	require.Nil(t, tp.datagramQueue.Peek()) // make sure the frame is gone
+	require.Nil(t, tp.datagramQueue.Peek(monotime.Now())) // make sure the frame is gone
+}
+
+func TestPackExpiredDatagramFrame(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
+	now := monotime.Now()
+	var status []DatagramStatus
+	require.NoError(t, tp.datagramQueue.AddWithOptions(
+		&wire.DatagramFrame{DataLenPresent: true, Data: []byte("foo")},
+		DatagramSendOptions{
+			Deadline: now.Add(-time.Millisecond).ToTime(),
+			OnStatus: func(s DatagramStatus) { status = append(status, s) },
+		},
+	))
+	tp.ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, gomock.Any(), true)
+	tp.pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+	tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil)
+	tp.framer.EXPECT().HasData()
+	_, err := tp.packer.AppendPacket(getPacketBuffer(), protocol.MaxByteCount, now, protocol.Version1)
+	require.ErrorIs(t, err, errNothingToPack)
+	require.Equal(t, []DatagramStatus{DatagramExpired}, status)
+	require.Nil(t, tp.datagramQueue.Peek(now))
+}
+
+func TestPackDatagramFrameStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
+	var status []DatagramStatus
+	require.NoError(t, tp.datagramQueue.AddWithOptions(
+		&wire.DatagramFrame{DataLenPresent: true, Data: []byte("foo")},
+		DatagramSendOptions{OnStatus: func(s DatagramStatus) { status = append(status, s) }},
+	))
+	require.NoError(t, tp.datagramQueue.AddWithOptions(
+		&wire.DatagramFrame{DataLenPresent: true, Data: []byte("bar")},
+		DatagramSendOptions{OnStatus: func(s DatagramStatus) { status = append(status, s) }},
+	))
+
+	var frames []ackhandler.Frame
+	for i := range 2 {
+		tp.ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, gomock.Any(), true)
+		tp.pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(i), protocol.PacketNumberLen2)
+		tp.pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(i))
+		tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil)
+		tp.framer.EXPECT().HasData()
+		p, err := tp.packer.AppendPacket(getPacketBuffer(), protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+		require.NoError(t, err)
+		require.Len(t, p.Frames, 1)
+		require.NotNil(t, p.Frames[0].Handler)
+		frames = append(frames, p.Frames[0])
+	}
+	require.Empty(t, status)
+	frames[0].Handler.OnLost(frames[0].Frame)
+	require.Equal(t, []DatagramStatus{DatagramLost}, status)
+	frames[1].Handler.OnAcked(frames[1].Frame)
+	require.Equal(t, []DatagramStatus{DatagramLost, DatagramAcked}, status)
+}
+
+func TestPackDatagramFramePriority(t *testing.T) {
+	t.Run("datagram more urgent than stream data", func(t *testing.T) {
+		testPackDatagramFramePriority(t, 2, 3, false)
+	})
+	t.Run("datagram as urgent as stream data", func(t *testing.T) {
+		testPackDatagramFramePriority(t, 3, 3, false)
+	})
+	t.Run("datagram less urgent than stream data", func(t *testing.T) {
+		testPackDatagramFramePriority(t, 4, 3, true)
+	})
+}
+
+func testPackDatagramFramePriority(t *testing.T, datagramUrgency, streamUrgency uint8, expectDatagramLast bool) {
+	mockCtrl := gomock.NewController(t)
+	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
+	require.NoError(t, tp.datagramQueue.AddWithOptions(
+		&wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar")},
+		DatagramSendOptions{Urgency: datagramUrgency},
+	))
+	tp.ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, gomock.Any(), false)
+	tp.pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+	tp.pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+	tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil)
+	tp.framer.EXPECT().HasData().Return(true)
+	tp.framer.EXPECT().StreamUrgency().Return(streamUrgency, true)
+	tp.framer.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(cf []ackhandler.Frame, sf []ackhandler.StreamFrame, _ protocol.ByteCount, _ monotime.Time, v protocol.Version) ([]ackhandler.Frame, []ackhandler.StreamFrame, protocol.ByteCount) {
+			// when the stream data is more urgent, the DATAGRAM frame is packed afterwards
+			if expectDatagramLast {
+				require.Empty(t, cf)
+			} else {
+				require.Len(t, cf, 1)
+			}
+			f := &wire.StreamFrame{StreamID: 4, Data: []byte("foobar")}
+			return cf, append(sf, ackhandler.StreamFrame{Frame: f}), f.Length(v)
+		},
+	)
+	p, err := tp.packer.AppendPacket(getPacketBuffer(), protocol.MaxByteCount, monotime.Now(), protocol.Version1)
+	require.NoError(t, err)
+	require.Len(t, p.StreamFrames, 1)
+	require.Len(t, p.Frames, 1)
+	require.IsType(t, &wire.DatagramFrame{}, p.Frames[0].Frame)
+	require.Nil(t, tp.datagramQueue.Peek(monotime.Now()))
+}
+
+func TestPackDatagramFrameLessUrgentThanStreamData(t *testing.T) {
+	// If the stream data fills the packet, the DATAGRAM frame is sent in a later packet.
+	const maxPacketSize = 1000
+	mockCtrl := gomock.NewController(t)
+	tp := newTestPacketPacker(t, mockCtrl, protocol.PerspectiveServer)
+	f := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar")}
+	require.NoError(t, tp.datagramQueue.AddWithOptions(f, DatagramSendOptions{Urgency: 5}))
+	tp.ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, gomock.Any(), false)
+	tp.pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+	tp.pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+	tp.sealingManager.EXPECT().Get1RTTSealer().Return(newMockShortHeaderSealer(mockCtrl), nil)
+	tp.framer.EXPECT().HasData().Return(true)
+	tp.framer.EXPECT().StreamUrgency().Return(uint8(1), true)
+	tp.framer.EXPECT().Append(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(cf []ackhandler.Frame, sf []ackhandler.StreamFrame, size protocol.ByteCount, _ monotime.Time, v protocol.Version) ([]ackhandler.Frame, []ackhandler.StreamFrame, protocol.ByteCount) {
+			sf2, split := (&wire.StreamFrame{Data: make([]byte, 2*maxPacketSize)}).MaybeSplitOffFrame(size, v)
+			require.True(t, split)
+			return cf, append(sf, ackhandler.StreamFrame{Frame: sf2}), sf2.Length(v)
+		},
+	)
+	p, err := tp.packer.AppendPacket(getPacketBuffer(), maxPacketSize, monotime.Now(), protocol.Version1)
+	require.NoError(t, err)
+	require.Len(t, p.StreamFrames, 1)
+	require.Empty(t, p.Frames)
+	// the DATAGRAM frame is still queued
+	require.Equal(t, f, tp.datagramQueue.Peek(monotime.Now()).frame)
 }
 
 func TestPackRetransmissions(t *testing.T) {
@@ -735,7 +965,7 @@ func TestPackLongHeaderPadToAtLeast4Bytes(t *testing.T) {
 	// first bytes should be 2 PADDING frames...
 	require.Equal(t, []byte{0, 0}, data[:2])
 	// ...followed by the PING frame
-	frameParser := wire.NewFrameParser(false, false, false)
+	frameParser := wire.NewFrameParser(false, false, false, false)
 
 	frameType, lt, err := frameParser.ParseType(data[2:], protocol.EncryptionHandshake)
 	require.NoError(t, err)
@@ -778,7 +1008,7 @@ func TestPackShortHeaderPadToAtLeast4Bytes(t *testing.T) {
 	require.Equal(t, byte(0), payload[0])
 
 	// ... followed by the STREAM frame
-	frameParser := wire.NewFrameParser(false, false, false)
+	frameParser := wire.NewFrameParser(false, false, false, false)
 	frameType, l, err := frameParser.ParseType(payload[1:], protocol.Encryption1RTT)
 	require.NoError(t, err)
 	require.Equal(t, 1, l)
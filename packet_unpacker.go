@@ -37,14 +37,17 @@ type packetUnpacker struct {
 	cs handshake.CryptoSetup
 
 	shortHdrConnIDLen int
+	// set if we advertised the grease_quic_bit transport parameter (RFC 9287)
+	allowClearedQUICBit bool
 }
 
 var _ unpacker = &packetUnpacker{}
 
-func newPacketUnpacker(cs handshake.CryptoSetup, shortHdrConnIDLen int) *packetUnpacker {
+func newPacketUnpacker(cs handshake.CryptoSetup, shortHdrConnIDLen int, allowClearedQUICBit bool) *packetUnpacker {
 	return &packetUnpacker{
-		cs:                cs,
-		shortHdrConnIDLen: shortHdrConnIDLen,
+		cs:                  cs,
+		shortHdrConnIDLen:   shortHdrConnIDLen,
+		allowClearedQUICBit: allowClearedQUICBit,
 	}
 }
 
@@ -60,7 +63,7 @@ func (u *packetUnpacker) UnpackLongHeader(hdr *wire.Header, data []byte) (*unpac
 	switch hdr.Type {
 	case protocol.PacketTypeInitial:
 		encLevel = protocol.EncryptionInitial
-		opener, err := u.cs.GetInitialOpener()
+		opener, err := u.cs.GetInitialOpener(hdr.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -107,11 +110,17 @@ func (u *packetUnpacker) UnpackLongHeader(hdr *wire.Header, data []byte) (*unpac
 }
 
 func (u *packetUnpacker) UnpackShortHeader(rcvTime monotime.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
+	return u.UnpackPathShortHeader(rcvTime, protocol.InitialPathID, data)
+}
+
+// UnpackPathShortHeader unpacks a short header packet received on the given path.
+// Every path uses its own packet number space, see the multipath extension.
+func (u *packetUnpacker) UnpackPathShortHeader(rcvTime monotime.Time, pathID protocol.PathID, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
 	opener, err := u.cs.Get1RTTOpener()
 	if err != nil {
 		return 0, 0, 0, nil, err
 	}
-	pn, pnLen, kp, decrypted, err := u.unpackShortHeaderPacket(opener, rcvTime, data)
+	pn, pnLen, kp, decrypted, err := u.unpackShortHeaderPacket(opener, rcvTime, pathID, data)
 	if err != nil {
 		return 0, 0, 0, nil, err
 	}
@@ -144,7 +153,7 @@ func (u *packetUnpacker) unpackLongHeaderPacket(opener handshake.LongHeaderOpene
 	return extHdr, decrypted, nil
 }
 
-func (u *packetUnpacker) unpackShortHeaderPacket(opener handshake.ShortHeaderOpener, rcvTime monotime.Time, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
+func (u *packetUnpacker) unpackShortHeaderPacket(opener handshake.ShortHeaderOpener, rcvTime monotime.Time, pathID protocol.PathID, data []byte) (protocol.PacketNumber, protocol.PacketNumberLen, protocol.KeyPhaseBit, []byte, error) {
 	l, pn, pnLen, kp, parseErr := u.unpackShortHeader(opener, data)
 	// If the reserved bits are set incorrectly, we still need to continue unpacking.
 	// This avoids a timing side-channel, which otherwise might allow an attacker
@@ -152,8 +161,15 @@ func (u *packetUnpacker) unpackShortHeaderPacket(opener handshake.ShortHeaderOpe
 	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
 		return 0, 0, 0, nil, &headerParseError{parseErr}
 	}
-	pn = opener.DecodePacketNumber(pn, pnLen)
-	decrypted, err := opener.Open(data[l:l], data[l:], rcvTime, pn, kp, data[:l])
+	var decrypted []byte
+	var err error
+	if pathID == protocol.InitialPathID {
+		pn = opener.DecodePacketNumber(pn, pnLen)
+		decrypted, err = opener.Open(data[l:l], data[l:], rcvTime, pn, kp, data[:l])
+	} else {
+		pn = opener.DecodePathPacketNumber(pathID, pn, pnLen)
+		decrypted, err = opener.OpenPath(data[l:l], data[l:], rcvTime, pathID, pn, kp, data[:l])
+	}
 	if err != nil {
 		return 0, 0, 0, nil, err
 	}
@@ -174,7 +190,11 @@ func (u *packetUnpacker) unpackShortHeader(hd headerDecryptor, data []byte) (int
 		data[hdrLen:hdrLen+4],
 	)
 	// 3. parse the header (and learn the actual length of the packet number)
-	l, pn, pnLen, kp, parseErr := wire.ParseShortHeader(data, u.shortHdrConnIDLen)
+	parseShortHeader := wire.ParseShortHeader
+	if u.allowClearedQUICBit {
+		parseShortHeader = wire.ParseShortHeaderAllowClearedQUICBit
+	}
+	l, pn, pnLen, kp, parseErr := parseShortHeader(data, u.shortHdrConnIDLen)
 	if parseErr != nil && parseErr != wire.ErrInvalidReservedBits {
 		return l, pn, pnLen, kp, parseErr
 	}
@@ -93,7 +93,7 @@ func testUnpackLongHeaderPacket(t *testing.T,
 ) {
 	mockCtrl := gomock.NewController(t)
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
-	unpacker := newPacketUnpacker(cs, 4)
+	unpacker := newPacketUnpacker(cs, 4, false)
 
 	var packetType protocol.PacketType
 	switch encLevel {
@@ -129,7 +129,7 @@ func testUnpackLongHeaderPacket(t *testing.T,
 	var calls []any
 	switch encLevel {
 	case protocol.EncryptionInitial:
-		calls = append(calls, cs.EXPECT().GetInitialOpener().Return(opener, nil))
+		calls = append(calls, cs.EXPECT().GetInitialOpener(gomock.Any()).Return(opener, nil))
 	case protocol.EncryptionHandshake:
 		calls = append(calls, cs.EXPECT().GetHandshakeOpener().Return(opener, nil))
 	case protocol.Encryption0RTT:
@@ -190,7 +190,7 @@ func testUnpackShortHeaderPacket(t *testing.T, incorrectReservedBits bool, decry
 	mockCtrl := gomock.NewController(t)
 	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5})
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
-	unpacker := newPacketUnpacker(cs, connID.Len())
+	unpacker := newPacketUnpacker(cs, connID.Len(), false)
 	payload := []byte("Lorem ipsum dolor sit amet")
 
 	hdrRaw, err := wire.AppendShortHeader(
@@ -199,6 +199,7 @@ func testUnpackShortHeaderPacket(t *testing.T, incorrectReservedBits bool, decry
 		0x1337,
 		protocol.PacketNumberLen3,
 		protocol.KeyPhaseOne,
+		false,
 	)
 	require.NoError(t, err)
 	if incorrectReservedBits {
@@ -223,10 +224,46 @@ func testUnpackShortHeaderPacket(t *testing.T, incorrectReservedBits bool, decry
 	require.Equal(t, protocol.KeyPhaseOne, kp)
 }
 
+func TestUnpackShortHeaderClearedQUICBit(t *testing.T) {
+	t.Run("greasing enabled", func(t *testing.T) {
+		testUnpackShortHeaderClearedQUICBit(t, true)
+	})
+
+	t.Run("greasing disabled", func(t *testing.T) {
+		testUnpackShortHeaderClearedQUICBit(t, false)
+	})
+}
+
+func testUnpackShortHeaderClearedQUICBit(t *testing.T, allowClearedQUICBit bool) {
+	mockCtrl := gomock.NewController(t)
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5})
+	cs := mocks.NewMockCryptoSetup(mockCtrl)
+	unpacker := newPacketUnpacker(cs, connID.Len(), allowClearedQUICBit)
+
+	hdrRaw, err := wire.AppendShortHeader(nil, connID, 0x1337, protocol.PacketNumberLen3, protocol.KeyPhaseOne, false)
+	require.NoError(t, err)
+	hdrRaw[0] &^= 0x40
+	opener := mocks.NewMockShortHeaderOpener(mockCtrl)
+	cs.EXPECT().Get1RTTOpener().Return(opener, nil)
+	opener.EXPECT().DecryptHeader(gomock.Any(), gomock.Any(), gomock.Any())
+	if !allowClearedQUICBit {
+		_, _, _, _, err = unpacker.UnpackShortHeader(monotime.Now(), append(hdrRaw, []byte("Lorem ipsum dolor sit amet")...))
+		require.IsType(t, &headerParseError{}, err)
+		require.ErrorContains(t, err, "not a QUIC packet")
+		return
+	}
+	opener.EXPECT().DecodePacketNumber(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(1234))
+	opener.EXPECT().Open(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte("decrypted"), nil)
+	pn, _, _, data, err := unpacker.UnpackShortHeader(monotime.Now(), append(hdrRaw, []byte("Lorem ipsum dolor sit amet")...))
+	require.NoError(t, err)
+	require.Equal(t, protocol.PacketNumber(1234), pn)
+	require.Equal(t, []byte("decrypted"), data)
+}
+
 func TestUnpackHeaderSampleLongHeader(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
-	unpacker := newPacketUnpacker(cs, 4)
+	unpacker := newPacketUnpacker(cs, 4, false)
 
 	extHdr := &wire.ExtendedHeader{
 		Header: wire.Header{
@@ -266,7 +303,7 @@ func TestUnpackHeaderSampleLongHeader(t *testing.T) {
 func TestUnpackHeaderSampleShortHeader(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
-	unpacker := newPacketUnpacker(cs, 4)
+	unpacker := newPacketUnpacker(cs, 4, false)
 
 	data, err := wire.AppendShortHeader(
 		nil,
@@ -274,6 +311,7 @@ func TestUnpackHeaderSampleShortHeader(t *testing.T) {
 		1337,
 		protocol.PacketNumberLen2,
 		protocol.KeyPhaseOne,
+		false,
 	)
 	require.NoError(t, err)
 	b := make([]byte, 2+16) // 2 bytes to fill up the packet number, 16 bytes for the sample
@@ -301,7 +339,7 @@ func TestUnpackHeaderSampleShortHeader(t *testing.T) {
 func TestUnpackErrors(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
-	unpacker := newPacketUnpacker(cs, 4)
+	unpacker := newPacketUnpacker(cs, 4, false)
 
 	// opener not available
 	cs.EXPECT().GetHandshakeOpener().Return(nil, handshake.ErrKeysNotYetAvailable)
@@ -321,7 +359,7 @@ func TestUnpackErrors(t *testing.T) {
 func TestUnpackHeaderDecryption(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	cs := mocks.NewMockCryptoSetup(mockCtrl)
-	unpacker := newPacketUnpacker(cs, 4)
+	unpacker := newPacketUnpacker(cs, 4, false)
 	connID := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef})
 
 	extHdr := &wire.ExtendedHeader{
@@ -67,6 +67,8 @@ func (p *Path) Probe(ctx context.Context) error {
 
 // Switch switches the QUIC connection to this path.
 // It immediately stops sending on the old path, and sends on this new path.
+// If the multipath extension was negotiated, all validated paths are used at the same time,
+// and Switch has no effect.
 func (p *Path) Switch() error {
 	if err := p.pathManager.switchToPath(p.id); err != nil {
 		switch {
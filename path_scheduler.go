@@ -0,0 +1,81 @@
+package quic
+
+import (
+	"net"
+	"time"
+)
+
+// PathInfo contains information about a path used by the multipath extension.
+type PathInfo struct {
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	// Backup is set if the peer asked us to not use this path,
+	// unless no other path is available (using a PATH_STATUS_BACKUP frame).
+	Backup bool
+	// SmoothedRTT is the smoothed RTT of the path.
+	SmoothedRTT time.Duration
+	// CanSend is set if congestion control and pacing currently allow sending a packet on the path.
+	CanSend bool
+}
+
+// A PathScheduler decides which path a packet is sent on, when using the multipath extension.
+// It is only called from the connection's run loop, so implementations don't need to be safe for concurrent use.
+type PathScheduler interface {
+	// SelectPath is called every time a packet containing application data can be sent.
+	// paths contains all validated paths, the initial path is always the first element.
+	// It returns the index of the path to send on, or -1 if no packet should be sent right now.
+	// Returning a path that can't send results in no packet being sent.
+	SelectPath(paths []PathInfo) int
+}
+
+// NewMinRTTPathScheduler creates a PathScheduler that sends on the path with the lowest RTT
+// that can currently send a packet.
+// Backup paths are only used if no other path can currently send.
+// This is the default scheduler.
+func NewMinRTTPathScheduler() PathScheduler { return &minRTTPathScheduler{} }
+
+type minRTTPathScheduler struct{}
+
+func (s *minRTTPathScheduler) SelectPath(paths []PathInfo) int {
+	useBackup := useBackupPaths(paths)
+	selected := -1
+	for i, p := range paths {
+		if !p.CanSend || (p.Backup && !useBackup) {
+			continue
+		}
+		if selected == -1 || p.SmoothedRTT < paths[selected].SmoothedRTT {
+			selected = i
+		}
+	}
+	return selected
+}
+
+// NewRoundRobinPathScheduler creates a PathScheduler that cycles through all paths that can currently send.
+// Backup paths are only used if no other path can currently send.
+func NewRoundRobinPathScheduler() PathScheduler { return &roundRobinPathScheduler{} }
+
+type roundRobinPathScheduler struct {
+	last int
+}
+
+func (s *roundRobinPathScheduler) SelectPath(paths []PathInfo) int {
+	useBackup := useBackupPaths(paths)
+	for i := 1; i <= len(paths); i++ {
+		idx := (s.last + i) % len(paths)
+		if p := paths[idx]; p.CanSend && (!p.Backup || useBackup) {
+			s.last = idx
+			return idx
+		}
+	}
+	return -1
+}
+
+// useBackupPaths returns true if none of the non-backup paths can currently send.
+func useBackupPaths(paths []PathInfo) bool {
+	for _, p := range paths {
+		if !p.Backup && p.CanSend {
+			return false
+		}
+	}
+	return true
+}
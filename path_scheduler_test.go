@@ -0,0 +1,65 @@
+package quic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinRTTPathScheduler(t *testing.T) {
+	s := NewMinRTTPathScheduler()
+	require.Equal(t, -1, s.SelectPath(nil))
+
+	paths := []PathInfo{
+		{SmoothedRTT: 50 * time.Millisecond, CanSend: true},
+		{SmoothedRTT: 20 * time.Millisecond, CanSend: true},
+		{SmoothedRTT: 10 * time.Millisecond, CanSend: true, Backup: true},
+	}
+	require.Equal(t, 1, s.SelectPath(paths))
+	// if the path with the lowest RTT is congestion limited, the next best path is used
+	paths[1].CanSend = false
+	require.Equal(t, 0, s.SelectPath(paths))
+	// backup paths are not used as long as another path can send
+	paths[0].CanSend = true
+	paths[2].SmoothedRTT = time.Millisecond
+	require.Equal(t, 0, s.SelectPath(paths))
+	// backup paths are used if no other path can send
+	paths[0].CanSend = false
+	require.Equal(t, 2, s.SelectPath(paths))
+	// backup paths are used if there are no other paths
+	require.Equal(t, 0, s.SelectPath(paths[2:]))
+	paths[2].CanSend = false
+	require.Equal(t, -1, s.SelectPath(paths))
+}
+
+func TestRoundRobinPathScheduler(t *testing.T) {
+	s := NewRoundRobinPathScheduler()
+	require.Equal(t, -1, s.SelectPath(nil))
+
+	paths := []PathInfo{{CanSend: true}, {CanSend: true}, {CanSend: true}}
+	var selected []int
+	for range 6 {
+		selected = append(selected, s.SelectPath(paths))
+	}
+	require.Equal(t, []int{1, 2, 0, 1, 2, 0}, selected)
+
+	// paths that can't send are skipped
+	paths[2].CanSend = false
+	require.Equal(t, 1, s.SelectPath(paths))
+	require.Equal(t, 0, s.SelectPath(paths))
+	// backup paths are not used as long as another path can send
+	paths[1].Backup = true
+	require.Equal(t, 0, s.SelectPath(paths))
+	require.Equal(t, 0, s.SelectPath(paths))
+	// backup paths are used if no other path can send
+	paths[0].CanSend = false
+	require.Equal(t, 1, s.SelectPath(paths))
+	require.Equal(t, 1, s.SelectPath(paths))
+	// once the primary path can send again, the backup path is not used anymore
+	paths[0].CanSend = true
+	require.Equal(t, 0, s.SelectPath(paths))
+	paths[0].CanSend = false
+	paths[1].CanSend = false
+	require.Equal(t, -1, s.SelectPath(paths))
+}
@@ -0,0 +1,125 @@
+package quic
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/utils"
+)
+
+// ECNState is the state of the ECN validation of a network path, see section 13.4.2 of RFC 9000.
+type ECNState uint8
+
+const (
+	// ECNStateDisabled means that ECN is not used on the path,
+	// either because it was disabled, or because it is not supported on this platform.
+	ECNStateDisabled = ECNState(ackhandler.ECNStateDisabled)
+	// ECNStateTesting means that the path is being tested for ECN support.
+	ECNStateTesting = ECNState(ackhandler.ECNStateTesting)
+	// ECNStateUnknown means that all testing packets were sent,
+	// but it's not yet known if the path supports ECN.
+	ECNStateUnknown = ECNState(ackhandler.ECNStateUnknown)
+	// ECNStateCapable means that the path supports ECN.
+	ECNStateCapable = ECNState(ackhandler.ECNStateCapable)
+	// ECNStateFailed means that the ECN validation failed, and ECN is not used on the path.
+	ECNStateFailed = ECNState(ackhandler.ECNStateFailed)
+)
+
+func (s ECNState) String() string {
+	switch s {
+	case ECNStateDisabled:
+		return "disabled"
+	case ECNStateTesting:
+		return "testing"
+	case ECNStateUnknown:
+		return "unknown"
+	case ECNStateCapable:
+		return "capable"
+	case ECNStateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown ECN state: %d", uint8(s))
+	}
+}
+
+// ECNStats contains the ECN statistics of a network path.
+type ECNStats struct {
+	// State is the state of the ECN validation.
+	State ECNState
+	// ECT0Sent is the number of packets sent with the ECT(0) codepoint.
+	ECT0Sent uint64
+	// ECT1Sent is the number of packets sent with the ECT(1) codepoint.
+	ECT1Sent uint64
+	// ECT0 is the ECT(0) count reported by the peer in ACK frames.
+	ECT0 uint64
+	// ECT1 is the ECT(1) count reported by the peer in ACK frames.
+	ECT1 uint64
+	// ECNCE is the ECN-CE count reported by the peer in ACK frames.
+	ECNCE uint64
+}
+
+// PathStats contains statistics about a network path.
+type PathStats struct {
+	// LocalAddr is the local address of the path.
+	LocalAddr net.Addr
+	// RemoteAddr is the remote address of the path.
+	RemoteAddr net.Addr
+
+	// MinRTT is the estimate of the minimum RTT observed on the path.
+	MinRTT time.Duration
+	// LatestRTT is the last RTT sample observed on the path.
+	LatestRTT time.Duration
+	// SmoothedRTT is an exponentially weighted moving average of the RTT samples on the path.
+	SmoothedRTT time.Duration
+	// MeanDeviation estimates the variation in the RTT samples on the path.
+	MeanDeviation time.Duration
+
+	// CongestionWindow is the current size of the congestion window.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent in ack-eliciting packets
+	// that have neither been acknowledged nor declared lost.
+	BytesInFlight uint64
+	// InSlowStart says if the congestion controller is in slow start.
+	InSlowStart bool
+	// InRecovery says if the congestion controller is in recovery.
+	InRecovery bool
+	// PacingRate is the rate at which packets are paced, in bytes per second.
+	// It is 0 if the congestion controller doesn't report a pacing rate.
+	PacingRate uint64
+	// PTOCount is the number of times the probe timeout (PTO) fired
+	// without receiving an acknowledgment, see section 6.2 of RFC 9002.
+	PTOCount uint32
+	// PathMTU is the maximum size of a QUIC packet sent on the path.
+	// It is increased by Path MTU Discovery.
+	PathMTU uint64
+	// ECN contains the ECN statistics of the path.
+	ECN ECNStats
+}
+
+func newPathStats(local, remote net.Addr, rttStats *utils.RTTStats, stats *ackhandler.PathStats) PathStats {
+	return PathStats{
+		LocalAddr:        local,
+		RemoteAddr:       remote,
+		MinRTT:           rttStats.MinRTT(),
+		LatestRTT:        rttStats.LatestRTT(),
+		SmoothedRTT:      rttStats.SmoothedRTT(),
+		MeanDeviation:    rttStats.MeanDeviation(),
+		CongestionWindow: stats.CongestionWindow.Load(),
+		BytesInFlight:    stats.BytesInFlight.Load(),
+		InSlowStart:      stats.InSlowStart.Load(),
+		InRecovery:       stats.InRecovery.Load(),
+		PacingRate:       stats.PacingRate.Load(),
+		PTOCount:         stats.PTOCount.Load(),
+		PathMTU:          stats.MaxDatagramSize.Load(),
+		ECN: ECNStats{
+			State:    ECNState(stats.ECNState.Load()),
+			ECT0Sent: stats.ECT0Sent.Load(),
+			ECT1Sent: stats.ECT1Sent.Load(),
+			ECT0:     stats.ECT0Acked.Load(),
+			ECT1:     stats.ECT1Acked.Load(),
+			ECNCE:    stats.ECNCEAcked.Load(),
+		},
+	}
+}
@@ -0,0 +1,204 @@
+package quic
+
+import (
+	"crypto/rand"
+	"net"
+	"slices"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/monotime"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// The path ID used for the connection ID that the client uses to probe the server's preferred address.
+// It doesn't collide with the path IDs used by the pathManagerOutgoing, which start at 1.
+const preferredAddressPathID pathID = -2
+
+// maxPreferredAddressProbes is the number of PATH_CHALLENGEs sent to the server's preferred address,
+// before the client gives up migrating to it.
+const maxPreferredAddressProbes = 3
+
+// preferredAddressMigrator migrates a client connection to the server's preferred address,
+// see section 9.6 of RFC 9000.
+type preferredAddressMigrator struct {
+	addr *net.UDPAddr
+
+	pathChallenges [][8]byte
+	needsProbe     bool
+	probesLost     int
+	validated      bool
+
+	retireConnID func()
+	logger       utils.Logger
+}
+
+// newPreferredAddressMigrator returns nil if the server's preferred address
+// doesn't contain an address of the address family the connection is using.
+func newPreferredAddressMigrator(
+	pa *wire.PreferredAddress,
+	remoteAddr net.Addr,
+	retireConnID func(),
+	logger utils.Logger,
+) *preferredAddressMigrator {
+	remote, ok := remoteAddr.(*net.UDPAddr)
+	if !ok {
+		return nil
+	}
+	addr := pa.IPv6
+	if remote.IP.To4() != nil {
+		addr = pa.IPv4
+	}
+	// An all-zero address and port indicates that the server doesn't have an address of this address family.
+	if !addr.IsValid() || addr.Addr().IsUnspecified() || addr.Port() == 0 {
+		return nil
+	}
+	return &preferredAddressMigrator{
+		addr:         net.UDPAddrFromAddrPort(addr),
+		needsProbe:   true,
+		retireConnID: retireConnID,
+		logger:       logger,
+	}
+}
+
+func (m *preferredAddressMigrator) Addr() net.Addr { return m.addr }
+
+func (m *preferredAddressMigrator) ShouldSendProbe() bool { return m.needsProbe }
+
+func (m *preferredAddressMigrator) NextProbe() ackhandler.Frame {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	m.pathChallenges = append(m.pathChallenges, b)
+	m.needsProbe = false
+	return ackhandler.Frame{
+		Frame:   &wire.PathChallengeFrame{Data: b},
+		Handler: (*preferredAddressMigratorAckHandler)(m),
+	}
+}
+
+// HandlePathResponseFrame returns true if the PATH_RESPONSE validated the path to the preferred address.
+func (m *preferredAddressMigrator) HandlePathResponseFrame(f *wire.PathResponseFrame) bool {
+	if m.validated || !slices.Contains(m.pathChallenges, f.Data) {
+		return false
+	}
+	m.validated = true
+	m.needsProbe = false
+	m.pathChallenges = nil
+	return true
+}
+
+// ShouldMigrate returns true once the path to the preferred address was validated.
+func (m *preferredAddressMigrator) ShouldMigrate() bool { return m.validated }
+
+type preferredAddressMigratorAckHandler preferredAddressMigrator
+
+var _ ackhandler.FrameHandler = &preferredAddressMigratorAckHandler{}
+
+// Acknowledging the frame doesn't validate the path, only receiving the PATH_RESPONSE does.
+func (m *preferredAddressMigratorAckHandler) OnAcked(wire.Frame) {}
+
+func (m *preferredAddressMigratorAckHandler) OnLost(f wire.Frame) {
+	pc, ok := f.(*wire.PathChallengeFrame)
+	if !ok || m.validated || !slices.Contains(m.pathChallenges, pc.Data) {
+		return
+	}
+	m.probesLost++
+	if m.probesLost < maxPreferredAddressProbes {
+		m.needsProbe = true
+		return
+	}
+	if m.probesLost == maxPreferredAddressProbes {
+		m.logger.Debugf("failed to validate path to preferred address %s", m.addr)
+		m.retireConnID()
+	}
+}
+
+// preferredAddressHandler passes packets received on the server's preferred address to the connection.
+type preferredAddressHandler struct{ *Conn }
+
+func (h preferredAddressHandler) handlePacket(p receivedPacket) {
+	p.onPreferredAddress = true
+	h.Conn.handlePacket(p)
+}
+
+// setupPreferredAddress registers the connection with the Transport of the preferred address,
+// and adds the preferred_address transport parameter.
+func (c *Conn) setupPreferredAddress(pa *PreferredAddress, params *wire.TransportParameters) {
+	if err := pa.Transport.init(false); err != nil {
+		c.logger.Debugf("Not using preferred address: %s", err)
+		return
+	}
+	runner := (*packetHandlerMap)(pa.Transport)
+	c.connIDGenerator.AddConnRunner(
+		runner,
+		connRunnerCallbacks{
+			AddConnectionID:    func(connID protocol.ConnectionID) { runner.Add(connID, preferredAddressHandler{c}) },
+			RemoveConnectionID: runner.Remove,
+			ReplaceWithClosed:  runner.ReplaceWithClosed,
+		},
+	)
+	connID, token, err := c.connIDGenerator.IssuePreferredAddressConnID()
+	if err != nil {
+		c.logger.Debugf("Not using preferred address: %s", err)
+		return
+	}
+	params.PreferredAddress = &wire.PreferredAddress{
+		IPv4:                pa.IPv4,
+		IPv6:                pa.IPv6,
+		ConnectionID:        connID,
+		StatelessResetToken: token,
+	}
+	c.preferredAddressTransport = pa.Transport
+}
+
+// handlePreferredAddressPacket handles a packet that the server received on its preferred address,
+// before the connection migrated to the preferred address.
+func (c *Conn) handlePreferredAddressPacket(
+	p receivedPacket,
+	pn protocol.PacketNumber,
+	pathChallenge *wire.PathChallengeFrame,
+	isNonProbing bool,
+) error {
+	if pathChallenge != nil {
+		probe, buf, err := c.packer.PackPathProbePacket(
+			c.connIDManager.Get(),
+			[]ackhandler.Frame{{Frame: &wire.PathResponseFrame{Data: pathChallenge.Data}}},
+			c.version,
+		)
+		if err != nil {
+			return err
+		}
+		c.logger.Debugf("sending path probe packet from preferred address to %s", p.remoteAddr)
+		c.logShortHeaderPacket(probe, protocol.ECNNon, buf.Len())
+		c.registerPackedShortHeaderPacket(probe, protocol.ECNNon, p.rcvTime, 0)
+		_, err = c.preferredAddressTransport.WriteTo(buf.Data, p.remoteAddr)
+		buf.Release()
+		if err != nil {
+			return err
+		}
+	}
+	// The client migrates to the preferred address by sending a non-probing packet.
+	// As with any migration, we only switch paths in response to the highest-numbered non-probing packet,
+	// see section 9.3 of RFC 9000.
+	if !isNonProbing || pn != c.largestRcvdAppData {
+		return nil
+	}
+	c.logger.Debugf("client migrated to preferred address %s", c.preferredAddressTransport.Conn.LocalAddr())
+	c.usingPreferredAddress = true
+	// Only the local address changes here.
+	// If the client's address changed as well, this is handled like any other client migration.
+	c.switchSendConn(newSendConn(c.preferredAddressTransport.conn, c.conn.RemoteAddr(), p.info, c.logger), p.rcvTime)
+	return nil
+}
+
+// migrateToPreferredAddress is called by the client once the path to the server's preferred address was validated.
+func (c *Conn) migrateToPreferredAddress(now monotime.Time) {
+	c.logger.Debugf("migrating to preferred address %s", c.preferredAddress.Addr())
+	c.conn.ChangeRemoteAddr(c.preferredAddress.Addr(), packetInfo{})
+	// Use the connection ID that was used to probe the preferred address, see section 9.6.1 of RFC 9000.
+	c.connIDManager.SwitchToConnIDForPath(preferredAddressPathID)
+	c.resetPathState(now)
+	c.preferredAddress = nil
+	c.qlogMigrationComplete()
+}
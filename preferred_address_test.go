@@ -0,0 +1,71 @@
+package quic
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredAddressMigratorAddressSelection(t *testing.T) {
+	pa := &wire.PreferredAddress{
+		IPv4: netip.MustParseAddrPort("1.2.3.4:1234"),
+		IPv6: netip.MustParseAddrPort("[2001:db8::1]:4321"),
+	}
+	m := newPreferredAddressMigrator(pa, &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 443}, func() {}, utils.DefaultLogger)
+	require.NotNil(t, m)
+	require.Equal(t, "1.2.3.4:1234", m.Addr().String())
+
+	m = newPreferredAddressMigrator(pa, &net.UDPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443}, func() {}, utils.DefaultLogger)
+	require.NotNil(t, m)
+	require.Equal(t, "[2001:db8::1]:4321", m.Addr().String())
+
+	// the server didn't provide an IPv6 address
+	pa.IPv6 = netip.AddrPortFrom(netip.IPv6Unspecified(), 0)
+	require.Nil(t, newPreferredAddressMigrator(pa, &net.UDPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443}, func() {}, utils.DefaultLogger))
+}
+
+func TestPreferredAddressMigratorValidation(t *testing.T) {
+	pa := &wire.PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:1234")}
+	m := newPreferredAddressMigrator(pa, &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 443}, func() { t.Fatal("didn't expect the connection ID to be retired") }, utils.DefaultLogger)
+	require.True(t, m.ShouldSendProbe())
+	f1 := m.NextProbe()
+	require.False(t, m.ShouldSendProbe())
+	require.False(t, m.ShouldMigrate())
+
+	// loss of the PATH_CHALLENGE triggers sending of a new one
+	f1.Handler.OnLost(f1.Frame)
+	require.True(t, m.ShouldSendProbe())
+	f2 := m.NextProbe()
+	require.NotEqual(t, f1.Frame.(*wire.PathChallengeFrame).Data, f2.Frame.(*wire.PathChallengeFrame).Data)
+
+	require.False(t, m.HandlePathResponseFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3}}))
+	require.False(t, m.ShouldMigrate())
+	// a response to any of the PATH_CHALLENGEs validates the path
+	require.True(t, m.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f1.Frame.(*wire.PathChallengeFrame).Data}))
+	require.True(t, m.ShouldMigrate())
+	// duplicate PATH_RESPONSEs are ignored
+	require.False(t, m.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f2.Frame.(*wire.PathChallengeFrame).Data}))
+	// losing a PATH_CHALLENGE after validation doesn't do anything
+	f2.Handler.OnLost(f2.Frame)
+	require.False(t, m.ShouldSendProbe())
+}
+
+func TestPreferredAddressMigratorValidationFailure(t *testing.T) {
+	pa := &wire.PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:1234")}
+	var retired int
+	m := newPreferredAddressMigrator(pa, &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 443}, func() { retired++ }, utils.DefaultLogger)
+	for range maxPreferredAddressProbes {
+		require.True(t, m.ShouldSendProbe())
+		f := m.NextProbe()
+		require.Zero(t, retired)
+		f.Handler.OnLost(f.Frame)
+	}
+	require.Equal(t, 1, retired)
+	require.False(t, m.ShouldSendProbe())
+	require.False(t, m.ShouldMigrate())
+}
@@ -186,6 +186,8 @@ func (e ConnectionClosed) Encode(enc *jsontext.Encoder, _ time.Time) error {
 				h.WriteToken(jsontext.String("aead_limit_reached"))
 			case qerr.NoViablePathError:
 				h.WriteToken(jsontext.String("no_viable_path"))
+			case qerr.VersionNegotiationErrorErrorCode:
+				h.WriteToken(jsontext.String("version_negotiation_error"))
 			default:
 				h.WriteToken(jsontext.String("unknown"))
 				h.WriteToken(jsontext.String("error_code"))
@@ -604,6 +606,7 @@ type ParametersSet struct {
 	MaxUDPPayloadSize               protocol.ByteCount
 	AckDelayExponent                uint8
 	MaxAckDelay                     time.Duration
+	MinAckDelay                     *time.Duration
 	ActiveConnectionIDLimit         uint64
 	InitialMaxData                  protocol.ByteCount
 	InitialMaxStreamDataBidiLocal   protocol.ByteCount
@@ -613,6 +616,7 @@ type ParametersSet struct {
 	InitialMaxStreamsUni            int64
 	PreferredAddress                *PreferredAddress
 	MaxDatagramFrameSize            protocol.ByteCount
+	GreaseQUICBit                   bool
 	EnableResetStreamAt             bool
 }
 
@@ -662,6 +666,10 @@ func (e ParametersSet) Encode(enc *jsontext.Encoder, _ time.Time) error {
 		h.WriteToken(jsontext.String("max_ack_delay"))
 		h.WriteToken(jsontext.Float(milliseconds(e.MaxAckDelay)))
 	}
+	if e.MinAckDelay != nil {
+		h.WriteToken(jsontext.String("min_ack_delay"))
+		h.WriteToken(jsontext.Float(milliseconds(*e.MinAckDelay)))
+	}
 	if e.ActiveConnectionIDLimit != 0 {
 		h.WriteToken(jsontext.String("active_connection_id_limit"))
 		h.WriteToken(jsontext.Uint(e.ActiveConnectionIDLimit))
@@ -700,6 +708,10 @@ func (e ParametersSet) Encode(enc *jsontext.Encoder, _ time.Time) error {
 		h.WriteToken(jsontext.String("max_datagram_frame_size"))
 		h.WriteToken(jsontext.Int(int64(e.MaxDatagramFrameSize)))
 	}
+	if e.GreaseQUICBit {
+		h.WriteToken(jsontext.String("grease_quic_bit"))
+		h.WriteToken(jsontext.True)
+	}
 	if e.EnableResetStreamAt {
 		h.WriteToken(jsontext.String("reset_stream_at"))
 		h.WriteToken(jsontext.True)
@@ -811,6 +823,32 @@ func (e ECNStateUpdated) Encode(enc *jsontext.Encoder, _ time.Time) error {
 	return h.err
 }
 
+// MigrationStateUpdated is the transport:migration_state_updated event.
+type MigrationStateUpdated struct {
+	State  MigrationState
+	Local  PathEndpointInfo
+	Remote PathEndpointInfo
+}
+
+func (e MigrationStateUpdated) Name() string { return "transport:migration_state_updated" }
+
+func (e MigrationStateUpdated) Encode(enc *jsontext.Encoder, _ time.Time) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("new"))
+	h.WriteToken(jsontext.String(string(e.State)))
+	h.WriteToken(jsontext.String("path_local"))
+	if err := e.Local.encode(enc); err != nil {
+		return err
+	}
+	h.WriteToken(jsontext.String("path_remote"))
+	if err := e.Remote.encode(enc); err != nil {
+		return err
+	}
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
 type ALPNInformation struct {
 	ChosenALPN string
 }
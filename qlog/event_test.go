@@ -177,6 +177,7 @@ func TestTransportErrors(t *testing.T) {
 		{qerr.KeyUpdateError, "key_update_error"},
 		{qerr.AEADLimitReached, "aead_limit_reached"},
 		{qerr.NoViablePathError, "no_viable_path"},
+		{qerr.VersionNegotiationErrorErrorCode, "version_negotiation_error"},
 	}
 
 	for _, tt := range tests {
@@ -213,6 +214,7 @@ func TestTransportCryptoError(t *testing.T) {
 
 func TestSentTransportParameters(t *testing.T) {
 	rcid := protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad})
+	minAckDelay := 2 * time.Millisecond
 	name, ev := testEventEncoding(t, &ParametersSet{
 		Initiator:                       InitiatorLocal,
 		SentBy:                          protocol.PerspectiveServer,
@@ -225,6 +227,7 @@ func TestSentTransportParameters(t *testing.T) {
 		MaxUDPPayloadSize:               1234,
 		AckDelayExponent:                12,
 		MaxAckDelay:                     123 * time.Millisecond,
+		MinAckDelay:                     &minAckDelay,
 		ActiveConnectionIDLimit:         7,
 		InitialMaxData:                  4000,
 		InitialMaxStreamDataBidiLocal:   1000,
@@ -233,6 +236,7 @@ func TestSentTransportParameters(t *testing.T) {
 		InitialMaxStreamsBidi:           10,
 		InitialMaxStreamsUni:            20,
 		MaxDatagramFrameSize:            protocol.InvalidByteCount,
+		GreaseQUICBit:                   true,
 		EnableResetStreamAt:             true,
 	})
 
@@ -245,6 +249,8 @@ func TestSentTransportParameters(t *testing.T) {
 	require.Equal(t, float64(321), ev["max_idle_timeout"])
 	require.Equal(t, float64(1234), ev["max_udp_payload_size"])
 	require.Equal(t, float64(12), ev["ack_delay_exponent"])
+	require.Equal(t, float64(123), ev["max_ack_delay"])
+	require.Equal(t, float64(2), ev["min_ack_delay"])
 	require.Equal(t, float64(7), ev["active_connection_id_limit"])
 	require.Equal(t, float64(4000), ev["initial_max_data"])
 	require.Equal(t, float64(1000), ev["initial_max_stream_data_bidi_local"])
@@ -252,6 +258,7 @@ func TestSentTransportParameters(t *testing.T) {
 	require.Equal(t, float64(3000), ev["initial_max_stream_data_uni"])
 	require.Equal(t, float64(10), ev["initial_max_streams_bidi"])
 	require.Equal(t, float64(20), ev["initial_max_streams_uni"])
+	require.True(t, ev["grease_quic_bit"].(bool))
 	require.True(t, ev["reset_stream_at"].(bool))
 	require.NotContains(t, ev, "preferred_address")
 	require.NotContains(t, ev, "max_datagram_frame_size")
@@ -868,6 +875,20 @@ func TestECNStateUpdatedWithTrigger(t *testing.T) {
 	require.Equal(t, "ACK doesn't contain ECN marks", ev["trigger"])
 }
 
+func TestMigrationStateUpdated(t *testing.T) {
+	name, ev := testEventEncoding(t, &MigrationStateUpdated{
+		State:  MigrationStateComplete,
+		Local:  PathEndpointInfo{IPv4: netip.MustParseAddrPort("192.168.13.37:42")},
+		Remote: PathEndpointInfo{IPv6: netip.MustParseAddrPort("[2001:db8::1]:24")},
+	})
+
+	require.Equal(t, "transport:migration_state_updated", name)
+	require.Len(t, ev, 3)
+	require.Equal(t, "migration_complete", ev["new"])
+	require.Equal(t, map[string]any{"ip_v4": "192.168.13.37", "port_v4": float64(42)}, ev["path_local"])
+	require.Equal(t, map[string]any{"ip_v6": "2001:db8::1", "port_v6": float64(24)}, ev["path_remote"])
+}
+
 func TestALPNInformation(t *testing.T) {
 	name, ev := testEventEncoding(t, &ALPNInformation{
 		ChosenALPN: "h3",
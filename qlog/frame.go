@@ -52,6 +52,22 @@ type (
 	AckFrequencyFrame = wire.AckFrequencyFrame
 	// An ImmediateAckFrame is an IMMEDIATE_ACK frame.
 	ImmediateAckFrame = wire.ImmediateAckFrame
+	// A PathAckFrame is a PATH_ACK frame.
+	PathAckFrame = wire.PathAckFrame
+	// A PathAbandonFrame is a PATH_ABANDON frame.
+	PathAbandonFrame = wire.PathAbandonFrame
+	// A PathStatusFrame is a PATH_STATUS_BACKUP or PATH_STATUS_AVAILABLE frame.
+	PathStatusFrame = wire.PathStatusFrame
+	// A PathNewConnectionIDFrame is a PATH_NEW_CONNECTION_ID frame.
+	PathNewConnectionIDFrame = wire.PathNewConnectionIDFrame
+	// A PathRetireConnectionIDFrame is a PATH_RETIRE_CONNECTION_ID frame.
+	PathRetireConnectionIDFrame = wire.PathRetireConnectionIDFrame
+	// A MaxPathIDFrame is a MAX_PATH_ID frame.
+	MaxPathIDFrame = wire.MaxPathIDFrame
+	// A PathsBlockedFrame is a PATHS_BLOCKED frame.
+	PathsBlockedFrame = wire.PathsBlockedFrame
+	// A PathCIDsBlockedFrame is a PATH_CIDS_BLOCKED frame.
+	PathCIDsBlockedFrame = wire.PathCIDsBlockedFrame
 )
 
 type AckRange = wire.AckRange
@@ -133,6 +149,22 @@ func (f Frame) Encode(enc *jsontext.Encoder) error {
 		return encodeAckFrequencyFrame(enc, frame)
 	case *ImmediateAckFrame:
 		return encodeImmediateAckFrame(enc, frame)
+	case *PathAckFrame:
+		return encodePathAckFrame(enc, frame)
+	case *PathAbandonFrame:
+		return encodePathAbandonFrame(enc, frame)
+	case *PathStatusFrame:
+		return encodePathStatusFrame(enc, frame)
+	case *PathNewConnectionIDFrame:
+		return encodePathNewConnectionIDFrame(enc, frame)
+	case *PathRetireConnectionIDFrame:
+		return encodePathRetireConnectionIDFrame(enc, frame)
+	case *MaxPathIDFrame:
+		return encodeMaxPathIDFrame(enc, frame)
+	case *PathsBlockedFrame:
+		return encodePathsBlockedFrame(enc, frame)
+	case *PathCIDsBlockedFrame:
+		return encodePathCIDsBlockedFrame(enc, frame)
 	default:
 		panic("unknown frame type")
 	}
@@ -179,6 +211,29 @@ func encodeAckFrame(enc *jsontext.Encoder, f *AckFrame) error {
 	h.WriteToken(jsontext.BeginObject)
 	h.WriteToken(jsontext.String("frame_type"))
 	h.WriteToken(jsontext.String("ack"))
+	if err := encodeAckFrameFields(enc, f); err != nil {
+		return err
+	}
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodePathAckFrame(enc *jsontext.Encoder, f *PathAckFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("path_ack"))
+	h.WriteToken(jsontext.String("path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.PathID)))
+	if err := encodeAckFrameFields(enc, &f.AckFrame); err != nil {
+		return err
+	}
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodeAckFrameFields(enc *jsontext.Encoder, f *AckFrame) error {
+	h := encoderHelper{enc: enc}
 	if f.DelayTime > 0 {
 		h.WriteToken(jsontext.String("ack_delay"))
 		h.WriteToken(jsontext.Float(milliseconds(f.DelayTime)))
@@ -196,7 +251,6 @@ func encodeAckFrame(enc *jsontext.Encoder, f *AckFrame) error {
 		h.WriteToken(jsontext.String("ce"))
 		h.WriteToken(jsontext.Uint(f.ECNCE))
 	}
-	h.WriteToken(jsontext.EndObject)
 	return h.err
 }
 
@@ -479,3 +533,102 @@ func encodeImmediateAckFrame(enc *jsontext.Encoder, _ *ImmediateAckFrame) error
 	h.WriteToken(jsontext.EndObject)
 	return h.err
 }
+
+func encodePathAbandonFrame(enc *jsontext.Encoder, f *PathAbandonFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("path_abandon"))
+	h.WriteToken(jsontext.String("path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.PathID)))
+	h.WriteToken(jsontext.String("error_code"))
+	h.WriteToken(jsontext.Uint(f.ErrorCode))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodePathStatusFrame(enc *jsontext.Encoder, f *PathStatusFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	if f.Backup {
+		h.WriteToken(jsontext.String("path_status_backup"))
+	} else {
+		h.WriteToken(jsontext.String("path_status_available"))
+	}
+	h.WriteToken(jsontext.String("path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.PathID)))
+	h.WriteToken(jsontext.String("sequence_number"))
+	h.WriteToken(jsontext.Uint(f.SequenceNumber))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodePathNewConnectionIDFrame(enc *jsontext.Encoder, f *PathNewConnectionIDFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("path_new_connection_id"))
+	h.WriteToken(jsontext.String("path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.PathID)))
+	h.WriteToken(jsontext.String("sequence_number"))
+	h.WriteToken(jsontext.Uint(f.SequenceNumber))
+	h.WriteToken(jsontext.String("retire_prior_to"))
+	h.WriteToken(jsontext.Uint(f.RetirePriorTo))
+	h.WriteToken(jsontext.String("length"))
+	h.WriteToken(jsontext.Int(int64(f.ConnectionID.Len())))
+	h.WriteToken(jsontext.String("connection_id"))
+	h.WriteToken(jsontext.String(f.ConnectionID.String()))
+	h.WriteToken(jsontext.String("stateless_reset_token"))
+	h.WriteToken(jsontext.String(hex.EncodeToString(f.StatelessResetToken[:])))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodePathRetireConnectionIDFrame(enc *jsontext.Encoder, f *PathRetireConnectionIDFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("path_retire_connection_id"))
+	h.WriteToken(jsontext.String("path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.PathID)))
+	h.WriteToken(jsontext.String("sequence_number"))
+	h.WriteToken(jsontext.Uint(f.SequenceNumber))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodeMaxPathIDFrame(enc *jsontext.Encoder, f *MaxPathIDFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("max_path_id"))
+	h.WriteToken(jsontext.String("maximum_path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.MaxPathID)))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodePathsBlockedFrame(enc *jsontext.Encoder, f *PathsBlockedFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("paths_blocked"))
+	h.WriteToken(jsontext.String("maximum_path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.MaxPathID)))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
+
+func encodePathCIDsBlockedFrame(enc *jsontext.Encoder, f *PathCIDsBlockedFrame) error {
+	h := encoderHelper{enc: enc}
+	h.WriteToken(jsontext.BeginObject)
+	h.WriteToken(jsontext.String("frame_type"))
+	h.WriteToken(jsontext.String("path_cids_blocked"))
+	h.WriteToken(jsontext.String("path_id"))
+	h.WriteToken(jsontext.Uint(uint64(f.PathID)))
+	h.WriteToken(jsontext.String("next_sequence_number"))
+	h.WriteToken(jsontext.Uint(f.NextSequenceNumber))
+	h.WriteToken(jsontext.EndObject)
+	return h.err
+}
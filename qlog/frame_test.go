@@ -158,6 +158,117 @@ func TestImmediateAckFrame(t *testing.T) {
 	)
 }
 
+func TestPathAckFrame(t *testing.T) {
+	check(t,
+		&PathAckFrame{
+			PathID: 3,
+			AckFrame: AckFrame{
+				DelayTime: 86 * time.Millisecond,
+				AckRanges: []AckRange{{Smallest: 120, Largest: 140}, {Smallest: 100, Largest: 100}},
+			},
+		},
+		map[string]any{
+			"frame_type":   "path_ack",
+			"path_id":      3,
+			"ack_delay":    86,
+			"acked_ranges": [][]float64{{120, 140}, {100}},
+		},
+	)
+}
+
+func TestPathAbandonFrame(t *testing.T) {
+	check(t,
+		&PathAbandonFrame{PathID: 3, ErrorCode: 42},
+		map[string]any{
+			"frame_type": "path_abandon",
+			"path_id":    3,
+			"error_code": 42,
+		},
+	)
+}
+
+func TestPathStatusFrame(t *testing.T) {
+	check(t,
+		&PathStatusFrame{PathID: 3, SequenceNumber: 7, Backup: true},
+		map[string]any{
+			"frame_type":      "path_status_backup",
+			"path_id":         3,
+			"sequence_number": 7,
+		},
+	)
+	check(t,
+		&PathStatusFrame{PathID: 3, SequenceNumber: 8},
+		map[string]any{
+			"frame_type":      "path_status_available",
+			"path_id":         3,
+			"sequence_number": 8,
+		},
+	)
+}
+
+func TestPathNewConnectionIDFrame(t *testing.T) {
+	check(t,
+		&PathNewConnectionIDFrame{
+			PathID:              2,
+			SequenceNumber:      42,
+			RetirePriorTo:       24,
+			ConnectionID:        protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+			StatelessResetToken: protocol.StatelessResetToken{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8, 0x9, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf, 0x0},
+		},
+		map[string]any{
+			"frame_type":            "path_new_connection_id",
+			"path_id":               2,
+			"sequence_number":       42,
+			"retire_prior_to":       24,
+			"length":                4,
+			"connection_id":         "deadbeef",
+			"stateless_reset_token": "0102030405060708090a0b0c0d0e0f00",
+		},
+	)
+}
+
+func TestPathRetireConnectionIDFrame(t *testing.T) {
+	check(t,
+		&PathRetireConnectionIDFrame{PathID: 2, SequenceNumber: 1337},
+		map[string]any{
+			"frame_type":      "path_retire_connection_id",
+			"path_id":         2,
+			"sequence_number": 1337,
+		},
+	)
+}
+
+func TestMaxPathIDFrame(t *testing.T) {
+	check(t,
+		&MaxPathIDFrame{MaxPathID: 10},
+		map[string]any{
+			"frame_type":      "max_path_id",
+			"maximum_path_id": 10,
+		},
+	)
+}
+
+func TestPathsBlockedFrame(t *testing.T) {
+	check(t,
+		&PathsBlockedFrame{MaxPathID: 10},
+		map[string]any{
+			"frame_type":      "paths_blocked",
+			"maximum_path_id": 10,
+		},
+	)
+}
+
+func TestPathCIDsBlockedFrame(t *testing.T) {
+	check(t,
+		&PathCIDsBlockedFrame{PathID: 2, NextSequenceNumber: 5},
+		map[string]any{
+			"frame_type":           "path_cids_blocked",
+			"path_id":              2,
+			"next_sequence_number": 5,
+		},
+	)
+}
+
 func TestStopSendingFrame(t *testing.T) {
 	check(t,
 		&StopSendingFrame{StreamID: 987, ErrorCode: 42},
@@ -0,0 +1,48 @@
+package qlog
+
+import (
+	"strings"
+
+	"github.com/quic-go/quic-go/internal/qerr"
+)
+
+// The functions in this file convert qlog event fields into label (or attribute) values,
+// for use by packages that derive metrics from qlog events.
+// Label values are bounded in number, to limit the cardinality of the resulting metrics.
+
+// PacketTypeLabel returns the label value for a packet type.
+// It returns "unknown" if the packet type couldn't be determined.
+func PacketTypeLabel(t PacketType) string {
+	if t == "" {
+		return "unknown"
+	}
+	return string(t)
+}
+
+// TransportErrorLabel returns the label value for a transport error code.
+// All CRYPTO_ERRORs share the same label value, and error codes not defined by the IETF
+// are reported as "unknown_error", since they are chosen by the peer.
+func TransportErrorLabel(code TransportErrorCode) string {
+	switch {
+	case code.IsCryptoError():
+		return "crypto_error"
+	case code > qerr.VersionNegotiationErrorErrorCode:
+		return "unknown_error"
+	default:
+		return strings.ToLower(code.String())
+	}
+}
+
+// CloseReasonLabel returns the label value describing why a connection was closed.
+func CloseReasonLabel(ev ConnectionClosed) string {
+	switch {
+	case ev.Trigger != "":
+		return string(ev.Trigger)
+	case ev.ApplicationError != nil:
+		return "application_error"
+	case ev.ConnectionError != nil:
+		return TransportErrorLabel(*ev.ConnectionError)
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,32 @@
+package qlog
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/qerr"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketTypeLabel(t *testing.T) {
+	require.Equal(t, "initial", PacketTypeLabel(PacketTypeInitial))
+	require.Equal(t, "1RTT", PacketTypeLabel(PacketType1RTT))
+	require.Equal(t, "unknown", PacketTypeLabel(""))
+}
+
+func TestTransportErrorLabel(t *testing.T) {
+	require.Equal(t, "no_error", TransportErrorLabel(qerr.NoError))
+	require.Equal(t, "protocol_violation", TransportErrorLabel(qerr.ProtocolViolation))
+	require.Equal(t, "version_negotiation_error", TransportErrorLabel(qerr.VersionNegotiationErrorErrorCode))
+	require.Equal(t, "crypto_error", TransportErrorLabel(0x100+42))
+	require.Equal(t, "unknown_error", TransportErrorLabel(0x1337))
+}
+
+func TestCloseReasonLabel(t *testing.T) {
+	transportErr := TransportErrorCode(qerr.FlowControlError)
+	appErr := ApplicationErrorCode(42)
+	require.Equal(t, "idle_timeout", CloseReasonLabel(ConnectionClosed{Trigger: ConnectionCloseTriggerIdleTimeout}))
+	require.Equal(t, "application_error", CloseReasonLabel(ConnectionClosed{ApplicationError: &appErr}))
+	require.Equal(t, "flow_control_error", CloseReasonLabel(ConnectionClosed{ConnectionError: &transportErr}))
+	require.Equal(t, "unknown", CloseReasonLabel(ConnectionClosed{}))
+}
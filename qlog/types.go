@@ -155,6 +155,8 @@ func (e transportError) String() string {
 		return "aead_limit_reached"
 	case qerr.NoViablePathError:
 		return "no_viable_path"
+	case qerr.VersionNegotiationErrorErrorCode:
+		return "version_negotiation_error"
 	default:
 		return ""
 	}
@@ -256,12 +258,22 @@ type CongestionState string
 const (
 	// CongestionStateSlowStart is the slow start phase of Reno / Cubic
 	CongestionStateSlowStart CongestionState = "slow_start"
+	// CongestionStateConservativeSlowStart is the Conservative Slow Start phase of HyStart++ (RFC 9406)
+	CongestionStateConservativeSlowStart CongestionState = "conservative_slow_start"
 	// CongestionStateCongestionAvoidance is the congestion avoidance phase of Reno / Cubic
 	CongestionStateCongestionAvoidance CongestionState = "congestion_avoidance"
 	// CongestionStateRecovery is the recovery phase of Reno / Cubic
 	CongestionStateRecovery CongestionState = "recovery"
 	// CongestionStateApplicationLimited means that the congestion controller is application limited
 	CongestionStateApplicationLimited CongestionState = "application_limited"
+	// CongestionStateStartup is the startup phase of BBR
+	CongestionStateStartup CongestionState = "startup"
+	// CongestionStateDrain is the drain phase of BBR
+	CongestionStateDrain CongestionState = "drain"
+	// CongestionStateProbeBW is the bandwidth probing phase of BBR
+	CongestionStateProbeBW CongestionState = "probe_bw"
+	// CongestionStateProbeRTT is the RTT probing phase of BBR
+	CongestionStateProbeRTT CongestionState = "probe_rtt"
 )
 
 func (s CongestionState) String() string {
@@ -282,6 +294,14 @@ const (
 	ECNStateCapable ECNState = "capable"
 )
 
+// MigrationState is the state of a connection migration
+type MigrationState string
+
+const (
+	// MigrationStateComplete means that the connection migrated to a new path
+	MigrationStateComplete MigrationState = "migration_complete"
+)
+
 type ConnectionCloseTrigger string
 
 const (
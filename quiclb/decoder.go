@@ -0,0 +1,73 @@
+package quiclb
+
+import "fmt"
+
+// A Decoder extracts the server ID from connection IDs.
+// It is used by the load balancer to route packets to the server that generated the connection ID.
+// It is safe for concurrent use.
+type Decoder struct {
+	codecs [MaxConfigID + 1]*codec
+}
+
+// NewDecoder creates a new Decoder.
+// Multiple configurations can be used at the same time, as long as they use different config IDs.
+// This allows rotating keys without breaking existing connections.
+func NewDecoder(configs ...*Config) (*Decoder, error) {
+	var d Decoder
+	for _, conf := range configs {
+		c, err := newCodec(conf)
+		if err != nil {
+			return nil, err
+		}
+		if d.codecs[conf.ConfigID] != nil {
+			return nil, fmt.Errorf("quiclb: duplicate config ID %d", conf.ConfigID)
+		}
+		d.codecs[conf.ConfigID] = c
+	}
+	return &d, nil
+}
+
+// ConnectionIDLen returns the length of the connection ID at the beginning of b.
+// For short header packets, the load balancer can use this to parse the Destination Connection ID,
+// which is not length-prefixed.
+// It returns ErrUnroutable if the connection ID doesn't use a known configuration.
+func (d *Decoder) ConnectionIDLen(b []byte) (int, error) {
+	c, err := d.codec(b)
+	if err != nil {
+		return 0, err
+	}
+	return c.connIDLen(), nil
+}
+
+// ServerID extracts the server ID from the connection ID at the beginning of b.
+// The slice may be longer than the connection ID: for short header packets,
+// the packet can be passed directly, starting at the Destination Connection ID.
+// It returns the config ID of the configuration that was used to generate the connection ID.
+// It returns ErrUnroutable if the connection ID doesn't use a known configuration.
+func (d *Decoder) ServerID(b []byte) (configID uint8, serverID []byte, _ error) {
+	c, err := d.codec(b)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(b) < c.connIDLen() {
+		return 0, nil, fmt.Errorf("quiclb: connection ID too short (%d bytes, expected %d)", len(b), c.connIDLen())
+	}
+	plaintext := make([]byte, c.serverIDLen+c.nonceLen)
+	c.decode(plaintext, b)
+	return c.configID, plaintext[:c.serverIDLen], nil
+}
+
+func (d *Decoder) codec(b []byte) (*codec, error) {
+	if len(b) == 0 {
+		return nil, ErrUnroutable
+	}
+	configID := b[0] >> 5
+	if configID == unroutableConfigID {
+		return nil, ErrUnroutable
+	}
+	c := d.codecs[configID]
+	if c == nil {
+		return nil, ErrUnroutable
+	}
+	return c, nil
+}
@@ -0,0 +1,100 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderPlaintext(t *testing.T) {
+	conf := &Config{ConfigID: 3, ServerIDLen: 2, NonceLen: 5}
+	d, err := NewDecoder(conf)
+	require.NoError(t, err)
+
+	b := []byte{3<<5 | 0x1f, 0xca, 0xfe, 1, 2, 3, 4, 5}
+	l, err := d.ConnectionIDLen(b)
+	require.NoError(t, err)
+	require.Equal(t, 8, l)
+	configID, serverID, err := d.ServerID(b)
+	require.NoError(t, err)
+	require.Equal(t, uint8(3), configID)
+	require.Equal(t, []byte{0xca, 0xfe}, serverID)
+
+	// the slice can be longer than the connection ID
+	configID, serverID, err = d.ServerID(append(b, []byte("packet payload")...))
+	require.NoError(t, err)
+	require.Equal(t, uint8(3), configID)
+	require.Equal(t, []byte{0xca, 0xfe}, serverID)
+
+	_, _, err = d.ServerID(b[:7])
+	require.EqualError(t, err, "quiclb: connection ID too short (7 bytes, expected 8)")
+}
+
+func TestDecoderUnroutable(t *testing.T) {
+	d, err := NewDecoder(&Config{ConfigID: 0, ServerIDLen: 2, NonceLen: 5})
+	require.NoError(t, err)
+
+	_, _, err = d.ServerID(nil)
+	require.ErrorIs(t, err, ErrUnroutable)
+	// unroutable config rotation codepoint
+	_, _, err = d.ServerID([]byte{0b111 << 5, 1, 2, 3, 4, 5, 6, 7})
+	require.ErrorIs(t, err, ErrUnroutable)
+	_, err = d.ConnectionIDLen([]byte{0b111 << 5, 1, 2, 3, 4, 5, 6, 7})
+	require.ErrorIs(t, err, ErrUnroutable)
+	// unknown config ID
+	_, _, err = d.ServerID([]byte{1 << 5, 1, 2, 3, 4, 5, 6, 7})
+	require.ErrorIs(t, err, ErrUnroutable)
+}
+
+func TestDecoderInvalidConfigs(t *testing.T) {
+	_, err := NewDecoder(
+		&Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 5},
+		&Config{ConfigID: 1, ServerIDLen: 3, NonceLen: 5},
+	)
+	require.EqualError(t, err, "quiclb: duplicate config ID 1")
+
+	_, err = NewDecoder(&Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 2})
+	require.EqualError(t, err, "quiclb: invalid nonce length 2")
+
+	_, err = NewDecoder(&Config{ConfigID: MaxConfigID + 1, ServerIDLen: 2, NonceLen: 5})
+	require.EqualError(t, err, "quiclb: invalid config ID 7")
+}
+
+func TestDecoderConfigRotation(t *testing.T) {
+	oldKey := make([]byte, KeyLen)
+	rand.Read(oldKey)
+	newKey := make([]byte, KeyLen)
+	rand.Read(newKey)
+	oldConf := &Config{ConfigID: 0, ServerIDLen: 2, NonceLen: 6, Key: oldKey}
+	newConf := &Config{ConfigID: 1, ServerIDLen: 3, NonceLen: 8, Key: newKey}
+
+	d, err := NewDecoder(oldConf, newConf)
+	require.NoError(t, err)
+
+	oldGen, err := NewGenerator(oldConf, []byte{1, 2})
+	require.NoError(t, err)
+	newGen, err := NewGenerator(newConf, []byte{3, 4, 5})
+	require.NoError(t, err)
+
+	oldConnID, err := oldGen.GenerateConnectionID()
+	require.NoError(t, err)
+	newConnID, err := newGen.GenerateConnectionID()
+	require.NoError(t, err)
+
+	l, err := d.ConnectionIDLen(oldConnID.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 9, l)
+	configID, serverID, err := d.ServerID(oldConnID.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, uint8(0), configID)
+	require.Equal(t, []byte{1, 2}, serverID)
+
+	l, err = d.ConnectionIDLen(newConnID.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 12, l)
+	configID, serverID, err = d.ServerID(newConnID.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, uint8(1), configID)
+	require.Equal(t, []byte{3, 4, 5}, serverID)
+}
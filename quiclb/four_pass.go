@@ -0,0 +1,97 @@
+package quiclb
+
+import "crypto/aes"
+
+// fourPass implements the four-pass encryption, which is used if the server ID and the nonce
+// have a combined length other than 16 bytes.
+// It's a Feistel network with four rounds, using AES-128-ECB as the round function.
+//
+// The plaintext is split into two halves. If the plaintext has an odd length,
+// both halves contain the middle byte: the left half uses its most significant nibble,
+// and the right half uses its least significant nibble.
+type fourPass struct {
+	plaintextLen int
+	halfLen      int
+	odd          bool
+
+	left, right [aes.BlockSize]byte
+	in, out     [aes.BlockSize]byte
+}
+
+func newFourPass(plaintext []byte) *fourPass {
+	l := len(plaintext)
+	p := &fourPass{
+		plaintextLen: l,
+		halfLen:      (l + 1) / 2,
+		odd:          l%2 == 1,
+	}
+	copy(p.left[:p.halfLen], plaintext[:p.halfLen])
+	copy(p.right[:p.halfLen], plaintext[l-p.halfLen:])
+	if p.odd {
+		p.left[p.halfLen-1] &= 0xf0
+		p.right[0] &= 0x0f
+	}
+	return p
+}
+
+// expand creates the input block for a round:
+// the half, padded with zeros, followed by the plaintext length and the round index.
+func (p *fourPass) expand(half []byte, index byte) []byte {
+	p.in = [aes.BlockSize]byte{}
+	copy(p.in[:], half[:p.halfLen])
+	p.in[aes.BlockSize-2] = byte(p.plaintextLen)
+	p.in[aes.BlockSize-1] = index
+	return p.in[:]
+}
+
+// updateLeft XORs the left half with the first halfLen bytes of the round function output.
+func (c *codec) updateLeft(p *fourPass, index byte) {
+	c.block.Encrypt(p.out[:], p.expand(p.right[:], index))
+	if p.odd {
+		p.out[p.halfLen-1] &= 0xf0
+	}
+	for i := range p.halfLen {
+		p.left[i] ^= p.out[i]
+	}
+}
+
+// updateRight XORs the right half with the last halfLen bytes of the round function output.
+func (c *codec) updateRight(p *fourPass, index byte) {
+	c.block.Encrypt(p.out[:], p.expand(p.left[:], index))
+	truncated := p.out[aes.BlockSize-p.halfLen:]
+	if p.odd {
+		truncated[0] &= 0x0f
+	}
+	for i := range p.halfLen {
+		p.right[i] ^= truncated[i]
+	}
+}
+
+// join writes the two halves to b.
+func (p *fourPass) join(b []byte) {
+	copy(b, p.left[:p.halfLen])
+	if p.odd {
+		b[p.halfLen-1] |= p.right[0]
+		copy(b[p.halfLen:], p.right[1:p.halfLen])
+	} else {
+		copy(b[p.halfLen:], p.right[:p.halfLen])
+	}
+}
+
+func (c *codec) fourPassEncrypt(ciphertext, plaintext []byte) {
+	p := newFourPass(plaintext)
+	c.updateRight(p, 1)
+	c.updateLeft(p, 2)
+	c.updateRight(p, 3)
+	c.updateLeft(p, 4)
+	p.join(ciphertext)
+}
+
+func (c *codec) fourPassDecrypt(plaintext, ciphertext []byte) {
+	p := newFourPass(ciphertext)
+	c.updateLeft(p, 4)
+	c.updateRight(p, 3)
+	c.updateLeft(p, 2)
+	c.updateRight(p, 1)
+	p.join(plaintext)
+}
@@ -0,0 +1,57 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFourPassEncryption(t *testing.T) {
+	key := make([]byte, KeyLen)
+	rand.Read(key)
+
+	for l := MinServerIDLen + MinNonceLen; l <= maxPlaintextLen; l++ {
+		t.Run(fmt.Sprintf("%d bytes", l), func(t *testing.T) {
+			c, err := newCodec(&Config{ServerIDLen: 1, NonceLen: l - 1, Key: key})
+			require.NoError(t, err)
+
+			plaintext := make([]byte, l)
+			rand.Read(plaintext)
+			ciphertext := make([]byte, l)
+			c.fourPassEncrypt(ciphertext, plaintext)
+			require.NotEqual(t, plaintext, ciphertext)
+
+			decrypted := make([]byte, l)
+			c.fourPassDecrypt(decrypted, ciphertext)
+			require.Equal(t, plaintext, decrypted)
+		})
+	}
+}
+
+func TestFourPassEncryptionOddLengthNibbles(t *testing.T) {
+	key := make([]byte, KeyLen)
+	rand.Read(key)
+	c, err := newCodec(&Config{ServerIDLen: 2, NonceLen: 5, Key: key})
+	require.NoError(t, err)
+
+	// plaintexts that only differ in one nibble of the middle byte
+	p1 := []byte{1, 2, 3, 0x40, 5, 6, 7}
+	p2 := []byte{1, 2, 3, 0x4f, 5, 6, 7}
+	p3 := []byte{1, 2, 3, 0xf0, 5, 6, 7}
+	c1 := make([]byte, 7)
+	c2 := make([]byte, 7)
+	c3 := make([]byte, 7)
+	c.fourPassEncrypt(c1, p1)
+	c.fourPassEncrypt(c2, p2)
+	c.fourPassEncrypt(c3, p3)
+	require.NotEqual(t, c1, c2)
+	require.NotEqual(t, c1, c3)
+
+	for _, tc := range []struct{ plaintext, ciphertext []byte }{{p1, c1}, {p2, c2}, {p3, c3}} {
+		decrypted := make([]byte, 7)
+		c.fourPassDecrypt(decrypted, tc.ciphertext)
+		require.Equal(t, tc.plaintext, decrypted)
+	}
+}
@@ -0,0 +1,103 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+)
+
+// ErrNoncesExhausted is returned by the Generator when all nonces have been used.
+// Since nonces are never reused when encryption is used, the server needs to switch to a new configuration.
+var ErrNoncesExhausted = errors.New("quiclb: nonces exhausted")
+
+// A Generator generates connection IDs that encode the server ID.
+// It implements the quic.ConnectionIDGenerator interface.
+// It is safe for concurrent use.
+type Generator struct {
+	codec    *codec
+	serverID []byte
+
+	mx sync.Mutex
+	// When encryption is used, the nonce is a counter, starting at a random value.
+	// This makes sure that nonces are never reused.
+	nonce      []byte
+	firstNonce []byte
+	exhausted  bool
+}
+
+var _ quic.ConnectionIDGenerator = &Generator{}
+
+// NewGenerator creates a new Generator for the server with the given server ID.
+// The length of the server ID must match the ServerIDLen of the configuration.
+func NewGenerator(conf *Config, serverID []byte) (*Generator, error) {
+	c, err := newCodec(conf)
+	if err != nil {
+		return nil, err
+	}
+	if len(serverID) != conf.ServerIDLen {
+		return nil, fmt.Errorf("quiclb: server ID has length %d, expected %d", len(serverID), conf.ServerIDLen)
+	}
+	g := &Generator{
+		codec:    c,
+		serverID: append([]byte{}, serverID...),
+	}
+	if c.block != nil {
+		g.nonce = make([]byte, conf.NonceLen)
+		if _, err := rand.Read(g.nonce); err != nil {
+			return nil, err
+		}
+		g.firstNonce = append([]byte{}, g.nonce...)
+	}
+	return g, nil
+}
+
+// GenerateConnectionID generates a new connection ID.
+func (g *Generator) GenerateConnectionID() (quic.ConnectionID, error) {
+	var random [1]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return quic.ConnectionID{}, err
+	}
+	plaintext := make([]byte, g.codec.serverIDLen+g.codec.nonceLen)
+	copy(plaintext, g.serverID)
+	if err := g.nextNonce(plaintext[g.codec.serverIDLen:]); err != nil {
+		return quic.ConnectionID{}, err
+	}
+	b := make([]byte, g.codec.connIDLen())
+	g.codec.encode(b, random[0], plaintext)
+	return quic.ConnectionIDFromBytes(b), nil
+}
+
+// nextNonce writes the next nonce to b.
+// In plaintext mode, the nonce is chosen randomly.
+func (g *Generator) nextNonce(b []byte) error {
+	if g.codec.block == nil {
+		_, err := rand.Read(b)
+		return err
+	}
+
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	if g.exhausted {
+		return ErrNoncesExhausted
+	}
+	copy(b, g.nonce)
+	for i := len(g.nonce) - 1; i >= 0; i-- {
+		g.nonce[i]++
+		if g.nonce[i] != 0 {
+			break
+		}
+	}
+	if string(g.nonce) == string(g.firstNonce) {
+		g.exhausted = true
+	}
+	return nil
+}
+
+// ConnectionIDLen returns the length of the generated connection IDs.
+func (g *Generator) ConnectionIDLen() int {
+	return g.codec.connIDLen()
+}
@@ -0,0 +1,184 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratorInvalidConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config Config
+		errMsg string
+	}{
+		{name: "config ID", config: Config{ConfigID: 7, ServerIDLen: 2, NonceLen: 8}, errMsg: "invalid config ID 7"},
+		{name: "server ID too short", config: Config{ServerIDLen: 0, NonceLen: 8}, errMsg: "invalid server ID length 0"},
+		{name: "server ID too long", config: Config{ServerIDLen: 16, NonceLen: 4}, errMsg: "invalid server ID length 16"},
+		{name: "nonce too short", config: Config{ServerIDLen: 2, NonceLen: 3}, errMsg: "invalid nonce length 3"},
+		{name: "nonce too long", config: Config{ServerIDLen: 2, NonceLen: 19}, errMsg: "invalid nonce length 19"},
+		{name: "connection ID too long", config: Config{ServerIDLen: 10, NonceLen: 10}, errMsg: "server ID and nonce too long (20 bytes)"},
+		{name: "key length", config: Config{ServerIDLen: 2, NonceLen: 8, Key: make([]byte, 32)}, errMsg: "invalid key length 32"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewGenerator(&tc.config, make([]byte, tc.config.ServerIDLen))
+			require.EqualError(t, err, "quiclb: "+tc.errMsg)
+		})
+	}
+
+	_, err := NewGenerator(&Config{ServerIDLen: 2, NonceLen: 8}, []byte{1, 2, 3})
+	require.EqualError(t, err, "quiclb: server ID has length 3, expected 2")
+}
+
+func TestGeneratorPlaintext(t *testing.T) {
+	conf := &Config{ConfigID: 5, ServerIDLen: 3, NonceLen: 6}
+	g, err := NewGenerator(conf, []byte{0xa, 0xb, 0xc})
+	require.NoError(t, err)
+	require.Equal(t, 10, g.ConnectionIDLen())
+	require.Equal(t, 10, conf.ConnectionIDLen())
+
+	connID, err := g.GenerateConnectionID()
+	require.NoError(t, err)
+	require.Equal(t, 10, connID.Len())
+	b := connID.Bytes()
+	require.Equal(t, uint8(5), b[0]>>5)
+	require.Equal(t, []byte{0xa, 0xb, 0xc}, b[1:4])
+
+	connID2, err := g.GenerateConnectionID()
+	require.NoError(t, err)
+	require.NotEqual(t, connID, connID2)
+}
+
+func TestGeneratorLengthSelfDescription(t *testing.T) {
+	conf := &Config{ConfigID: 2, ServerIDLen: 4, NonceLen: 8, LengthSelfDescription: true}
+	g, err := NewGenerator(conf, []byte{1, 2, 3, 4})
+	require.NoError(t, err)
+	for range 10 {
+		connID, err := g.GenerateConnectionID()
+		require.NoError(t, err)
+		require.Equal(t, byte(2<<5|12) /* 13 byte connection ID */, connID.Bytes()[0])
+	}
+}
+
+func TestGeneratorEncrypted(t *testing.T) {
+	for _, tc := range []struct{ serverIDLen, nonceLen int }{
+		{serverIDLen: 4, nonceLen: 12}, // single-pass
+		{serverIDLen: 3, nonceLen: 4},  // four-pass, odd length
+		{serverIDLen: 2, nonceLen: 6},  // four-pass, even length
+		{serverIDLen: 15, nonceLen: 4}, // four-pass, maximum length
+	} {
+		t.Run(fmt.Sprintf("server ID: %d, nonce: %d", tc.serverIDLen, tc.nonceLen), func(t *testing.T) {
+			testGeneratorEncrypted(t, tc.serverIDLen, tc.nonceLen)
+		})
+	}
+}
+
+func testGeneratorEncrypted(t *testing.T, serverIDLen, nonceLen int) {
+	key := make([]byte, KeyLen)
+	rand.Read(key)
+	conf := &Config{ConfigID: 1, ServerIDLen: serverIDLen, NonceLen: nonceLen, Key: key}
+	serverID := make([]byte, serverIDLen)
+	rand.Read(serverID)
+	g, err := NewGenerator(conf, serverID)
+	require.NoError(t, err)
+	d, err := NewDecoder(conf)
+	require.NoError(t, err)
+
+	connIDs := make(map[string]struct{})
+	for range 100 {
+		connID, err := g.GenerateConnectionID()
+		require.NoError(t, err)
+		require.Equal(t, 1+serverIDLen+nonceLen, connID.Len())
+		b := connID.Bytes()
+		require.Equal(t, uint8(1), b[0]>>5)
+		// for short server IDs, the ciphertext matches the server ID by chance too often
+		if serverIDLen >= 4 {
+			require.NotEqual(t, serverID, b[1:1+serverIDLen], "server ID not encrypted")
+		}
+		connIDs[string(b)] = struct{}{}
+
+		configID, decoded, err := d.ServerID(b)
+		require.NoError(t, err)
+		require.Equal(t, uint8(1), configID)
+		require.Equal(t, serverID, decoded)
+	}
+	require.Len(t, connIDs, 100)
+}
+
+func TestGeneratorNonceExhaustion(t *testing.T) {
+	key := make([]byte, KeyLen)
+	rand.Read(key)
+	g, err := NewGenerator(&Config{ServerIDLen: 2, NonceLen: 4, Key: key}, []byte{1, 2})
+	require.NoError(t, err)
+
+	// pretend that we already used all nonces but one
+	g.firstNonce = []byte{0, 0, 0, 0}
+	g.nonce = []byte{0xff, 0xff, 0xff, 0xff}
+	_, err = g.GenerateConnectionID()
+	require.NoError(t, err)
+	_, err = g.GenerateConnectionID()
+	require.ErrorIs(t, err, ErrNoncesExhausted)
+}
+
+// Known-answer tests for the connection ID encodings.
+// The single-pass encryption is AES-128-ECB, so it is checked using the example vector from FIPS 197, Appendix C.1.
+// TODO: add the single-pass and four-pass test vectors from the draft's appendix.
+// The four-pass encryption is currently only covered by round-trip tests.
+func TestKnownAnswerVectors(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		config          Config
+		serverID, nonce string
+		connID          string
+	}{
+		{
+			name:     "plaintext",
+			config:   Config{ConfigID: 0, ServerIDLen: 3, NonceLen: 4, LengthSelfDescription: true},
+			serverID: "c4605e",
+			nonce:    "4504cc4f",
+			connID:   "07c4605e4504cc4f",
+		},
+		{
+			name:     "plaintext, config ID 1",
+			config:   Config{ConfigID: 1, ServerIDLen: 5, NonceLen: 5, LengthSelfDescription: true},
+			serverID: "350d28b420",
+			nonce:    "3487d970b0",
+			connID:   "2a350d28b4203487d970b0",
+		},
+		{
+			name:     "single-pass",
+			config:   Config{ConfigID: 2, ServerIDLen: 8, NonceLen: 8, Key: mustDecodeHex("000102030405060708090a0b0c0d0e0f"), LengthSelfDescription: true},
+			serverID: "0011223344556677",
+			nonce:    "8899aabbccddeeff",
+			connID:   "5069c4e0d86a7b0430d8cdb78070b4c55a",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			serverID := mustDecodeHex(tc.serverID)
+			plaintext := append(serverID, mustDecodeHex(tc.nonce)...)
+			c, err := newCodec(&tc.config)
+			require.NoError(t, err)
+			connID := make([]byte, c.connIDLen())
+			c.encode(connID, 0, plaintext)
+			require.Equal(t, tc.connID, hex.EncodeToString(connID))
+
+			d, err := NewDecoder(&tc.config)
+			require.NoError(t, err)
+			configID, decodedServerID, err := d.ServerID(mustDecodeHex(tc.connID))
+			require.NoError(t, err)
+			require.Equal(t, tc.config.ConfigID, configID)
+			require.Equal(t, serverID, decodedServerID)
+		})
+	}
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,163 @@
+// Package quiclb implements routable connection IDs, as specified in draft-ietf-quic-load-balancers.
+//
+// Servers behind a load balancer use a [Generator] as the Transport.ConnectionIDGenerator.
+// The connection IDs it generates encode the server ID, optionally encrypted using a key
+// shared with the load balancer.
+// The load balancer uses a [Decoder] to extract the server ID from the Destination Connection ID
+// of incoming packets, and routes the packet to the respective server.
+//
+// Depending on the configuration, one of three modes is used:
+//   - Plaintext: If no key is configured, the server ID is encoded without any protection.
+//   - Single-pass encryption: If the server ID and the nonce have a combined length of 16 bytes,
+//     they are encrypted using a single AES-128-ECB operation.
+//   - Four-pass encryption: For all other lengths, a four-round Feistel network using AES-128-ECB is used.
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+)
+
+const (
+	// MaxConfigID is the largest config rotation codepoint that can be used for a configuration.
+	// The codepoint 0b111 is reserved for unroutable connection IDs.
+	MaxConfigID = 6
+	// unroutableConfigID is the config rotation codepoint of connection IDs that can't be routed using QUIC-LB.
+	unroutableConfigID = 0b111
+
+	// MinServerIDLen and MaxServerIDLen are the limits for the length of the server ID.
+	MinServerIDLen = 1
+	MaxServerIDLen = 15
+	// MinNonceLen and MaxNonceLen are the limits for the length of the nonce.
+	MinNonceLen = 4
+	MaxNonceLen = 18
+
+	// the combined length of server ID and nonce can't exceed 19 bytes,
+	// such that the connection ID is not longer than 20 bytes
+	maxPlaintextLen = 19
+	// KeyLen is the length of the key used for encrypted connection IDs.
+	KeyLen = 16
+)
+
+// ErrUnroutable is returned by the Decoder when a connection ID can't be routed using QUIC-LB,
+// either because it uses the unroutable config rotation codepoint, or because no configuration
+// for the config rotation codepoint is known.
+var ErrUnroutable = errors.New("quiclb: unroutable connection ID")
+
+// Config is a QUIC-LB configuration.
+// It needs to be shared between the load balancer and all servers behind it.
+type Config struct {
+	// ConfigID is the config rotation codepoint, encoded in the first 3 bits of the connection ID.
+	// It allows the load balancer to distinguish between connection IDs generated using different configurations,
+	// for example during a key rotation.
+	// It must be between 0 and 6.
+	ConfigID uint8
+	// ServerIDLen is the length of the server ID in bytes.
+	// It must be between 1 and 15.
+	ServerIDLen int
+	// NonceLen is the length of the nonce in bytes.
+	// It must be between 4 and 18, and ServerIDLen + NonceLen must not exceed 19.
+	// The nonce needs to be long enough that a server doesn't run out of nonces,
+	// since a nonce is never used twice when encryption is used.
+	NonceLen int
+	// Key is the AES-128 key used to encrypt the connection IDs.
+	// If nil, connection IDs are not encrypted, and observers can extract the server ID.
+	Key []byte
+	// LengthSelfDescription encodes the length of the connection ID in the first byte.
+	// This is useful if the load balancer needs to parse connection IDs of different lengths.
+	LengthSelfDescription bool
+}
+
+// ConnectionIDLen returns the length of the connection IDs using this configuration.
+func (c *Config) ConnectionIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *Config) validate() error {
+	if c.ConfigID > MaxConfigID {
+		return fmt.Errorf("quiclb: invalid config ID %d", c.ConfigID)
+	}
+	if c.ServerIDLen < MinServerIDLen || c.ServerIDLen > MaxServerIDLen {
+		return fmt.Errorf("quiclb: invalid server ID length %d", c.ServerIDLen)
+	}
+	if c.NonceLen < MinNonceLen || c.NonceLen > MaxNonceLen {
+		return fmt.Errorf("quiclb: invalid nonce length %d", c.NonceLen)
+	}
+	if c.ServerIDLen+c.NonceLen > maxPlaintextLen {
+		return fmt.Errorf("quiclb: server ID and nonce too long (%d bytes)", c.ServerIDLen+c.NonceLen)
+	}
+	if c.Key != nil && len(c.Key) != KeyLen {
+		return fmt.Errorf("quiclb: invalid key length %d", len(c.Key))
+	}
+	return nil
+}
+
+// codec encodes and decodes the server ID and nonce of a connection ID.
+type codec struct {
+	configID              uint8
+	serverIDLen           int
+	nonceLen              int
+	lengthSelfDescription bool
+
+	block cipher.Block // nil in plaintext mode
+}
+
+func newCodec(c *Config) (*codec, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	cd := &codec{
+		configID:              c.ConfigID,
+		serverIDLen:           c.ServerIDLen,
+		nonceLen:              c.NonceLen,
+		lengthSelfDescription: c.LengthSelfDescription,
+	}
+	if c.Key != nil {
+		block, err := aes.NewCipher(c.Key)
+		if err != nil {
+			return nil, err
+		}
+		cd.block = block
+	}
+	return cd, nil
+}
+
+func (c *codec) connIDLen() int { return 1 + c.serverIDLen + c.nonceLen }
+
+// firstByte returns the first byte of the connection ID.
+// The lower 5 bits are taken from random, unless the length is self-described.
+func (c *codec) firstByte(random byte) byte {
+	if c.lengthSelfDescription {
+		return c.configID<<5 | byte(c.connIDLen()-1)
+	}
+	return c.configID<<5 | random&0x1f
+}
+
+// encode writes the connection ID to b.
+// The plaintext is the server ID followed by the nonce.
+func (c *codec) encode(b []byte, random byte, plaintext []byte) {
+	b[0] = c.firstByte(random)
+	switch {
+	case c.block == nil:
+		copy(b[1:], plaintext)
+	case len(plaintext) == aes.BlockSize:
+		c.block.Encrypt(b[1:], plaintext)
+	default:
+		c.fourPassEncrypt(b[1:], plaintext)
+	}
+}
+
+// decode extracts the plaintext (the server ID followed by the nonce) from a connection ID.
+func (c *codec) decode(plaintext, connID []byte) {
+	ciphertext := connID[1:c.connIDLen()]
+	switch {
+	case c.block == nil:
+		copy(plaintext, ciphertext)
+	case len(ciphertext) == aes.BlockSize:
+		c.block.Decrypt(plaintext, ciphertext)
+	default:
+		c.fourPassDecrypt(plaintext, ciphertext)
+	}
+}
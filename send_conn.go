@@ -4,13 +4,22 @@ import (
 	"net"
 	"sync/atomic"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 )
 
 // A sendConn allows sending using a simple Write() on a non-connected packet conn.
 type sendConn interface {
-	Write(b []byte, gsoSize uint16, ecn protocol.ECN) error
+	// Write writes a packet to the remote address.
+	// If txTime is not zero, the kernel sends the packet at that time.
+	// It is invalid to set txTime if capabilities.KernelPacing is not set.
+	Write(b []byte, gsoSize uint16, ecn protocol.ECN, txTime monotime.Time) error
+	// WriteBatch writes multiple packets to the remote address, using as few syscalls as possible.
+	// Only the data, the ECN marking and the send time of the packets need to be set.
+	// It returns the number of packets written.
+	// It is invalid to call WriteBatch if capabilities.BatchWrite is not set.
+	WriteBatch([]outgoingPacket) (int, error)
 	WriteTo([]byte, net.Addr) error
 	Close() error
 	LocalAddr() net.Addr
@@ -36,6 +45,10 @@ type sconn struct {
 
 	// If GSO enabled, and we receive a GSO error for this remote address, GSO is disabled.
 	gotGSOError bool
+	// If kernel pacing is enabled, and sending a packet with a send time fails, kernel pacing is disabled.
+	gotTXTimeError bool
+	// Used to split GSO packets when GSO fails.
+	segments []outgoingPacket
 	// Used to catch the error sometimes returned by the first sendmsg call on Linux,
 	// see https://github.com/golang/go/issues/63322.
 	wroteFirstPacket bool
@@ -54,9 +67,9 @@ func newSendConn(c rawConn, remote net.Addr, info packetInfo, logger utils.Logge
 	}
 
 	oob := info.OOB()
-	// increase oob slice capacity, so we can add the UDP_SEGMENT and ECN control messages without allocating
+	// increase oob slice capacity, so we can add the SCM_TXTIME, UDP_SEGMENT and ECN control messages without allocating
 	l := len(oob)
-	oob = append(oob, make([]byte, 64)...)[:l]
+	oob = append(oob, make([]byte, 96)...)[:l]
 	sc := &sconn{
 		rawConn:   c,
 		localAddr: localAddr,
@@ -69,22 +82,39 @@ func newSendConn(c rawConn, remote net.Addr, info packetInfo, logger utils.Logge
 	return sc
 }
 
-func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
+func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN, txTime monotime.Time) error {
 	ai := c.remoteAddrInfo.Load()
-	err := c.writePacket(p, ai.addr, ai.oob, gsoSize, ecn)
+	oob := ai.oob
+	if !txTime.IsZero() && c.capabilities().KernelPacing {
+		oob = appendTXTimeMsg(oob, txTime)
+	}
+	err := c.writePacket(p, ai.addr, oob, gsoSize, ecn)
+	if err != nil && len(oob) > len(ai.oob) && isTXTimeError(err) {
+		c.disableKernelPacing(ai.addr)
+		// send the packet right away
+		oob = ai.oob
+		err = c.writePacket(p, ai.addr, oob, gsoSize, ecn)
+	}
 	if err != nil && isGSOError(err) {
 		// disable GSO for future calls
 		c.gotGSOError = true
 		if c.logger.Debug() {
 			c.logger.Debugf("GSO failed when sending to %s", ai.addr)
 		}
+		if c.rawConn.capabilities().BatchWrite {
+			var segmentTXTime monotime.Time
+			if len(oob) > len(ai.oob) {
+				segmentTXTime = txTime
+			}
+			return c.writeSegments(p, ai, gsoSize, ecn, segmentTXTime)
+		}
 		// send out the packets one by one
 		for len(p) > 0 {
 			l := len(p)
 			if l > int(gsoSize) {
 				l = int(gsoSize)
 			}
-			if err := c.writePacket(p[:l], ai.addr, ai.oob, 0, ecn); err != nil {
+			if err := c.writePacket(p[:l], ai.addr, oob, 0, ecn); err != nil {
 				return err
 			}
 			p = p[l:]
@@ -94,6 +124,64 @@ func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
 	return err
 }
 
+// writeSegments splits a GSO packet into its segments, and writes them using as few syscalls as possible.
+func (c *sconn) writeSegments(p []byte, ai *remoteAddrInfo, gsoSize uint16, ecn protocol.ECN, txTime monotime.Time) error {
+	packets := c.segments[:0]
+	for len(p) > 0 {
+		l := min(len(p), int(gsoSize))
+		packets = append(packets, outgoingPacket{
+			data:          p[:l],
+			addr:          ai.addr,
+			packetInfoOOB: ai.oob,
+			ecn:           ecn,
+			txTime:        txTime,
+		})
+		p = p[l:]
+	}
+	c.segments = packets
+	_, err := c.WritePackets(packets)
+	return err
+}
+
+func (c *sconn) WriteBatch(packets []outgoingPacket) (int, error) {
+	var written int
+	if !c.wroteFirstPacket {
+		// use the retry logic for the first packet
+		if err := c.Write(packets[0].data, 0, packets[0].ecn, packets[0].txTime); err != nil {
+			return 0, err
+		}
+		written++
+		packets = packets[1:]
+	}
+	ai := c.remoteAddrInfo.Load()
+	kernelPacing := c.capabilities().KernelPacing
+	for i := range packets {
+		packets[i].addr = ai.addr
+		packets[i].packetInfoOOB = ai.oob
+		if !kernelPacing {
+			packets[i].txTime = 0
+		}
+	}
+	n, err := c.WritePackets(packets)
+	if err != nil && !packets[n].txTime.IsZero() && isTXTimeError(err) {
+		c.disableKernelPacing(ai.addr)
+		// send the remaining packets right away
+		for i := n; i < len(packets); i++ {
+			packets[i].txTime = 0
+		}
+		m, err := c.WritePackets(packets[n:])
+		return written + n + m, err
+	}
+	return written + n, err
+}
+
+func (c *sconn) disableKernelPacing(addr net.Addr) {
+	c.gotTXTimeError = true
+	if c.logger.Debug() {
+		c.logger.Debugf("Kernel pacing failed when sending to %s", addr)
+	}
+}
+
 func (c *sconn) writePacket(p []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) error {
 	_, err := c.WritePacket(p, addr, oob, gsoSize, ecn)
 	if err != nil && !c.wroteFirstPacket && isPermissionError(err) {
@@ -113,6 +201,9 @@ func (c *sconn) capabilities() connCapabilities {
 	if capabilities.GSO {
 		capabilities.GSO = !c.gotGSOError
 	}
+	if capabilities.KernelPacing {
+		capabilities.KernelPacing = !c.gotTXTimeError
+	}
 	return capabilities
 }
 
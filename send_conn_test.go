@@ -7,9 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -49,7 +51,7 @@ func TestSendConnOOB(t *testing.T) {
 	rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, pi.OOB(), uint16(0), protocol.ECT1)
 	require.NotEmpty(t, pi.OOB())
 	c := newSendConn(rawConn, remoteAddr, pi, utils.DefaultLogger)
-	require.NoError(t, c.Write([]byte("foobar"), 0, protocol.ECT1))
+	require.NoError(t, c.Write([]byte("foobar"), 0, protocol.ECT1, 0))
 }
 
 func TestSendConnDetectGSOFailure(t *testing.T) {
@@ -67,7 +69,36 @@ func TestSendConnDetectGSOFailure(t *testing.T) {
 		rawConn.EXPECT().WritePacket([]byte("foob"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNCE).Return(4, nil),
 		rawConn.EXPECT().WritePacket([]byte("ar"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNCE).Return(2, nil),
 	)
-	require.NoError(t, c.Write([]byte("foobar"), 4, protocol.ECNCE))
+	require.NoError(t, c.Write([]byte("foobar"), 4, protocol.ECNCE, 0))
+	require.False(t, c.capabilities().GSO)
+}
+
+func TestSendConnDetectGSOFailureBatchWrite(t *testing.T) {
+	if !platformSupportsGSO {
+		t.Skip("GSO is not supported on this platform")
+	}
+
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+	rawConn := NewMockRawConn(gomock.NewController(t))
+	rawConn.EXPECT().LocalAddr()
+	rawConn.EXPECT().capabilities().Return(connCapabilities{GSO: true, BatchWrite: true}).MinTimes(1)
+	c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
+	// the segments are sent using a single call to WritePackets
+	gomock.InOrder(
+		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(4), protocol.ECNCE).Return(0, errGSO),
+		rawConn.EXPECT().WritePackets(gomock.Any()).DoAndReturn(func(packets []outgoingPacket) (int, error) {
+			require.Len(t, packets, 2)
+			require.Equal(t, []byte("foob"), packets[0].data)
+			require.Equal(t, []byte("ar"), packets[1].data)
+			for _, p := range packets {
+				require.Equal(t, remoteAddr, p.addr)
+				require.Equal(t, protocol.ECNCE, p.ecn)
+				require.Zero(t, p.txTime)
+			}
+			return 2, nil
+		}),
+	)
+	require.NoError(t, c.Write([]byte("foobar"), 4, protocol.ECNCE, 0))
 	require.False(t, c.capabilities().GSO)
 }
 
@@ -87,7 +118,7 @@ func TestSendConnSendmsgFailures(t *testing.T) {
 			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), protocol.ECNCE).Return(0, errNotPermitted),
 			rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNCE).Return(6, nil),
 		)
-		require.NoError(t, c.Write([]byte("foobar"), 0, protocol.ECNCE))
+		require.NoError(t, c.Write([]byte("foobar"), 0, protocol.ECNCE, 0))
 	})
 
 	t.Run("later call to sendmsg fails", func(t *testing.T) {
@@ -96,7 +127,7 @@ func TestSendConnSendmsgFailures(t *testing.T) {
 		rawConn.EXPECT().capabilities().AnyTimes()
 		c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
 		rawConn.EXPECT().WritePacket([]byte("foobar"), remoteAddr, gomock.Any(), gomock.Any(), protocol.ECNCE).Return(0, errNotPermitted).Times(2)
-		require.Error(t, c.Write([]byte("foobar"), 0, protocol.ECNCE))
+		require.Error(t, c.Write([]byte("foobar"), 0, protocol.ECNCE, 0))
 	})
 }
 
@@ -111,7 +142,7 @@ func TestSendConnRemoteAddrChange(t *testing.T) {
 		utils.DefaultLogger,
 	)
 
-	require.NoError(t, c.Write([]byte("foobar"), 0, protocol.ECNUnsupported))
+	require.NoError(t, c.Write([]byte("foobar"), 0, protocol.ECNUnsupported, 0))
 	ln1.SetReadDeadline(time.Now().Add(time.Second))
 	b := make([]byte, 1024)
 	n, err := ln1.Read(b)
@@ -126,10 +157,114 @@ func TestSendConnRemoteAddrChange(t *testing.T) {
 	require.Equal(t, "foobaz", string(b[:n]))
 
 	c.ChangeRemoteAddr(ln2.LocalAddr(), packetInfo{})
-	require.NoError(t, c.Write([]byte("lorem ipsum"), 0, protocol.ECNUnsupported))
+	require.NoError(t, c.Write([]byte("lorem ipsum"), 0, protocol.ECNUnsupported, 0))
 	ln2.SetReadDeadline(time.Now().Add(time.Second))
 	b = make([]byte, 1024)
 	n, err = ln2.Read(b)
 	require.NoError(t, err)
 	require.Equal(t, "lorem ipsum", string(b[:n]))
 }
+
+func TestSendConnWriteBatch(t *testing.T) {
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+	rawConn := NewMockRawConn(gomock.NewController(t))
+	rawConn.EXPECT().LocalAddr()
+	rawConn.EXPECT().capabilities().AnyTimes()
+	c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
+
+	// the first packet is written using WritePacket, to handle the error sometimes returned by the first sendmsg call
+	rawConn.EXPECT().WritePacket([]byte("foo"), remoteAddr, gomock.Any(), uint16(0), protocol.ECT1).Return(3, nil)
+	rawConn.EXPECT().WritePackets(gomock.Any()).DoAndReturn(func(packets []outgoingPacket) (int, error) {
+		require.Len(t, packets, 2)
+		require.Equal(t, []byte("bar"), packets[0].data)
+		require.Equal(t, []byte("baz"), packets[1].data)
+		for _, p := range packets {
+			require.Equal(t, remoteAddr, p.addr)
+			require.Equal(t, protocol.ECT1, p.ecn)
+		}
+		return 2, nil
+	})
+	n, err := c.WriteBatch([]outgoingPacket{
+		{data: []byte("foo"), ecn: protocol.ECT1},
+		{data: []byte("bar"), ecn: protocol.ECT1},
+		{data: []byte("baz"), ecn: protocol.ECT1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	rawConn.EXPECT().WritePackets(gomock.Any()).Return(1, assert.AnError)
+	n, err = c.WriteBatch([]outgoingPacket{{data: []byte("foo")}, {data: []byte("bar")}})
+	require.ErrorIs(t, err, assert.AnError)
+	require.Equal(t, 1, n)
+}
+
+func TestSendConnKernelPacing(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_TXTIME is only supported on Linux")
+	}
+
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+	rawConn := NewMockRawConn(gomock.NewController(t))
+	rawConn.EXPECT().LocalAddr()
+	rawConn.EXPECT().capabilities().Return(connCapabilities{KernelPacing: true, BatchWrite: true}).AnyTimes()
+	c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
+	require.True(t, c.capabilities().KernelPacing)
+
+	sendTime := monotime.Now().Add(time.Millisecond)
+	rawConn.EXPECT().WritePacket([]byte("foo"), remoteAddr, appendTXTimeMsg(nil, sendTime), uint16(0), protocol.ECT1).Return(3, nil)
+	require.NoError(t, c.Write([]byte("foo"), 0, protocol.ECT1, sendTime))
+
+	// packets without a send time are sent right away
+	rawConn.EXPECT().WritePacket([]byte("bar"), remoteAddr, []byte{}, uint16(0), protocol.ECT1).Return(3, nil)
+	require.NoError(t, c.Write([]byte("bar"), 0, protocol.ECT1, 0))
+
+	// if the kernel rejects the send time, kernel pacing is disabled
+	gomock.InOrder(
+		rawConn.EXPECT().WritePackets(gomock.Any()).DoAndReturn(func(packets []outgoingPacket) (int, error) {
+			require.Len(t, packets, 3)
+			for _, p := range packets {
+				require.Equal(t, sendTime, p.txTime)
+			}
+			return 1, errTXTime
+		}),
+		rawConn.EXPECT().WritePackets(gomock.Any()).DoAndReturn(func(packets []outgoingPacket) (int, error) {
+			require.Len(t, packets, 2)
+			for _, p := range packets {
+				require.Zero(t, p.txTime)
+			}
+			return 2, nil
+		}),
+	)
+	n, err := c.WriteBatch([]outgoingPacket{
+		{data: []byte("foo"), txTime: sendTime},
+		{data: []byte("bar"), txTime: sendTime},
+		{data: []byte("baz"), txTime: sendTime},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.False(t, c.capabilities().KernelPacing)
+
+	// from now on, packets are sent without a send time
+	rawConn.EXPECT().WritePacket([]byte("foo"), remoteAddr, []byte{}, uint16(0), protocol.ECT1).Return(3, nil)
+	require.NoError(t, c.Write([]byte("foo"), 0, protocol.ECT1, sendTime))
+}
+
+func TestSendConnKernelPacingFailure(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_TXTIME is only supported on Linux")
+	}
+
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 200), Port: 1337}
+	rawConn := NewMockRawConn(gomock.NewController(t))
+	rawConn.EXPECT().LocalAddr()
+	rawConn.EXPECT().capabilities().Return(connCapabilities{KernelPacing: true}).AnyTimes()
+	c := newSendConn(rawConn, remoteAddr, packetInfo{}, utils.DefaultLogger)
+
+	sendTime := monotime.Now().Add(time.Millisecond)
+	gomock.InOrder(
+		rawConn.EXPECT().WritePacket([]byte("foo"), remoteAddr, appendTXTimeMsg(nil, sendTime), uint16(0), protocol.ECT1).Return(0, errTXTime),
+		rawConn.EXPECT().WritePacket([]byte("foo"), remoteAddr, []byte{}, uint16(0), protocol.ECT1).Return(3, nil),
+	)
+	require.NoError(t, c.Write([]byte("foo"), 0, protocol.ECT1, sendTime))
+	require.False(t, c.capabilities().KernelPacing)
+}
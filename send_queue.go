@@ -3,11 +3,14 @@ package quic
 import (
 	"net"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 )
 
 type sender interface {
-	Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN)
+	// Send queues a packet for sending.
+	// If txTime is not zero, the packet is handed to the kernel, which sends it at that time.
+	Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN, txTime monotime.Time)
 	SendProbe(*packetBuffer, net.Addr)
 	Run() error
 	WouldBlock() bool
@@ -19,6 +22,7 @@ type queueEntry struct {
 	buf     *packetBuffer
 	gsoSize uint16
 	ecn     protocol.ECN
+	txTime  monotime.Time
 }
 
 type sendQueue struct {
@@ -27,6 +31,10 @@ type sendQueue struct {
 	runStopped  chan struct{} // runStopped when the run loop returns
 	available   chan struct{}
 	conn        sendConn
+
+	// only used by the run loop
+	entries []queueEntry
+	packets []outgoingPacket
 }
 
 var _ sender = &sendQueue{}
@@ -46,9 +54,9 @@ func newSendQueue(conn sendConn) sender {
 // Send sends out a packet. It's guaranteed to not block.
 // Callers need to make sure that there's actually space in the send queue by calling WouldBlock.
 // Otherwise Send will panic.
-func (h *sendQueue) Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN) {
+func (h *sendQueue) Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN, txTime monotime.Time) {
 	select {
-	case h.queue <- queueEntry{buf: p, gsoSize: gsoSize, ecn: ecn}:
+	case h.queue <- queueEntry{buf: p, gsoSize: gsoSize, ecn: ecn, txTime: txTime}:
 		// clear available channel if we've reached capacity
 		if len(h.queue) == sendQueueCapacity {
 			select {
@@ -87,7 +95,50 @@ func (h *sendQueue) Run() error {
 			// make sure that all queued packets are actually sent out
 			shouldClose = true
 		case e := <-h.queue:
-			if err := h.conn.Write(e.buf.Data, e.gsoSize, e.ecn); err != nil {
+			if err := h.write(h.dequeueBatch(e)); err != nil {
+				return err
+			}
+			select {
+			case h.available <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// dequeueBatch dequeues the packets waiting in the queue, if they can be sent using a single syscall.
+// This is only done if GSO is not available, since GSO already allows sending multiple packets at once.
+// Every connection has its own send queue, so packets are only batched with packets of the same connection.
+func (h *sendQueue) dequeueBatch(first queueEntry) []queueEntry {
+	entries := append(h.entries[:0], first)
+	if first.gsoSize > 0 || len(h.queue) == 0 {
+		return entries
+	}
+	if capabilities := h.conn.capabilities(); !capabilities.BatchWrite || capabilities.GSO {
+		return entries
+	}
+	for len(entries) < sendQueueCapacity {
+		select {
+		case e := <-h.queue:
+			entries = append(entries, e)
+		default:
+			return entries
+		}
+	}
+	return entries
+}
+
+func (h *sendQueue) write(entries []queueEntry) error {
+	for len(entries) > 0 {
+		// Packets are only batched if they don't use GSO.
+		// GSO might have been disabled after these packets were queued.
+		n := 1
+		for entries[0].gsoSize == 0 && n < len(entries) && entries[n].gsoSize == 0 {
+			n++
+		}
+		if n == 1 {
+			e := entries[0]
+			if err := h.conn.Write(e.buf.Data, e.gsoSize, e.ecn, e.txTime); err != nil {
 				// This additional check enables:
 				// 1. Checking for "datagram too large" message from the kernel, as such,
 				// 2. Path MTU discovery,and
@@ -97,12 +148,36 @@ func (h *sendQueue) Run() error {
 				}
 			}
 			e.buf.Release()
-			select {
-			case h.available <- struct{}{}:
-			default:
+		} else if err := h.writeBatch(entries[:n]); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+func (h *sendQueue) writeBatch(entries []queueEntry) error {
+	packets := h.packets[:0]
+	for _, e := range entries {
+		packets = append(packets, outgoingPacket{data: e.buf.Data, ecn: e.ecn, txTime: e.txTime})
+	}
+	h.packets = packets
+	for len(packets) > 0 {
+		n, err := h.conn.WriteBatch(packets)
+		if err != nil {
+			// see above for why "datagram too large" errors are ignored
+			if !isSendMsgSizeErr(err) {
+				return err
 			}
+			// skip the packet that was too large, and send the remaining packets
+			n++
 		}
+		packets = packets[n:]
+	}
+	for _, e := range entries {
+		e.buf.Release()
 	}
+	return nil
 }
 
 func (h *sendQueue) Close() {
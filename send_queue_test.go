@@ -1,10 +1,12 @@
 package quic
 
 import (
+	"fmt"
 	"net"
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/synctest"
 
@@ -27,8 +29,8 @@ func TestSendQueueSendOnePacket(t *testing.T) {
 		q := newSendQueue(c)
 
 		written := make(chan struct{})
-		c.EXPECT().Write([]byte("foobar"), uint16(10), protocol.ECT1).Do(
-			func([]byte, uint16, protocol.ECN) error { close(written); return nil },
+		c.EXPECT().Write([]byte("foobar"), uint16(10), protocol.ECT1, gomock.Any()).Do(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error { close(written); return nil },
 		)
 
 		done := make(chan struct{})
@@ -37,7 +39,7 @@ func TestSendQueueSendOnePacket(t *testing.T) {
 			close(done)
 		}()
 
-		q.Send(getPacketWithContents([]byte("foobar")), 10, protocol.ECT1)
+		q.Send(getPacketWithContents([]byte("foobar")), 10, protocol.ECT1, 0)
 		synctest.Wait()
 
 		select {
@@ -65,8 +67,8 @@ func TestSendQueueBlocking(t *testing.T) {
 
 		blockWrite := make(chan struct{})
 		written := make(chan struct{}, 1)
-		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func([]byte, uint16, protocol.ECN) error {
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error {
 				select {
 				case written <- struct{}{}:
 				default:
@@ -85,7 +87,7 @@ func TestSendQueueBlocking(t *testing.T) {
 		// +1, since one packet will be queued in the Write call
 		for i := range sendQueueCapacity + 1 {
 			require.False(t, q.WouldBlock())
-			q.Send(getPacketWithContents([]byte("foobar")), 10, protocol.ECT1)
+			q.Send(getPacketWithContents([]byte("foobar")), 10, protocol.ECT1, 0)
 			// make sure that the first packet is actually enqueued in the Write call
 			if i == 0 {
 				select {
@@ -101,7 +103,7 @@ func TestSendQueueBlocking(t *testing.T) {
 			t.Fatal("should not be available")
 		default:
 		}
-		require.Panics(t, func() { q.Send(getPacketWithContents([]byte("foobar")), 10, protocol.ECT1) })
+		require.Panics(t, func() { q.Send(getPacketWithContents([]byte("foobar")), 10, protocol.ECT1, 0) })
 
 		// allow one packet to be sent
 		blockWrite <- struct{}{}
@@ -156,8 +158,8 @@ func TestSendQueueWriteError(t *testing.T) {
 		c := NewMockSendConn(mockCtrl)
 		q := newSendQueue(c)
 
-		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
-		q.Send(getPacketWithContents([]byte("foobar")), 6, protocol.ECNNon)
+		c.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
+		q.Send(getPacketWithContents([]byte("foobar")), 6, protocol.ECNNon, 0)
 
 		errChan := make(chan error, 1)
 		go func() { errChan <- q.Run() }()
@@ -176,7 +178,7 @@ func TestSendQueueWriteError(t *testing.T) {
 		go func() {
 			defer close(sent)
 			for range 2 * sendQueueCapacity {
-				q.Send(getPacketWithContents([]byte("raboof")), 6, protocol.ECNNon)
+				q.Send(getPacketWithContents([]byte("raboof")), 6, protocol.ECNNon, 0)
 			}
 		}()
 
@@ -199,3 +201,135 @@ func TestSendQueueSendProbe(t *testing.T) {
 	c.EXPECT().WriteTo([]byte("foobar"), addr)
 	q.SendProbe(getPacketWithContents([]byte("foobar")), addr)
 }
+
+func TestSendQueueBatching(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		c := NewMockSendConn(mockCtrl)
+		q := newSendQueue(c)
+
+		c.EXPECT().capabilities().Return(connCapabilities{BatchWrite: true}).AnyTimes()
+		blockWrite := make(chan struct{})
+		c.EXPECT().Write([]byte("packet 0"), uint16(0), protocol.ECNNon, gomock.Any()).DoAndReturn(
+			func([]byte, uint16, protocol.ECN, monotime.Time) error { <-blockWrite; return nil },
+		)
+		var batches [][]string
+		// the first call only writes some of the packets
+		c.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(func(packets []outgoingPacket) (int, error) {
+			var batch []string
+			for _, p := range packets {
+				batch = append(batch, string(p.data))
+				require.Equal(t, protocol.ECT1, p.ecn)
+			}
+			batches = append(batches, batch)
+			return min(len(packets), 2), nil
+		}).Times(2)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			q.Run()
+		}()
+
+		q.Send(getPacketWithContents([]byte("packet 0")), 0, protocol.ECNNon, 0)
+		synctest.Wait()
+		// these packets are queued while the first packet is being written
+		for i := 1; i <= 4; i++ {
+			q.Send(getPacketWithContents([]byte(fmt.Sprintf("packet %d", i))), 0, protocol.ECT1, 0)
+		}
+		close(blockWrite)
+		synctest.Wait()
+		require.Equal(t, [][]string{
+			{"packet 1", "packet 2", "packet 3", "packet 4"},
+			{"packet 3", "packet 4"},
+		}, batches)
+		select {
+		case <-q.Available():
+		default:
+			t.Fatal("should be available")
+		}
+
+		q.Close()
+		synctest.Wait()
+		select {
+		case <-done:
+		default:
+			t.Fatal("Run should have returned")
+		}
+	})
+}
+
+func TestSendQueueBatchingWriteError(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		c := NewMockSendConn(mockCtrl)
+		q := newSendQueue(c)
+
+		c.EXPECT().capabilities().Return(connCapabilities{BatchWrite: true}).AnyTimes()
+		c.EXPECT().WriteBatch(gomock.Any()).Return(1, assert.AnError)
+		q.Send(getPacketWithContents([]byte("foo")), 0, protocol.ECNNon, 0)
+		q.Send(getPacketWithContents([]byte("bar")), 0, protocol.ECNNon, 0)
+		q.Send(getPacketWithContents([]byte("baz")), 0, protocol.ECNNon, 0)
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- q.Run() }()
+		synctest.Wait()
+
+		select {
+		case err := <-errChan:
+			require.ErrorIs(t, err, assert.AnError)
+		default:
+			t.Fatal("Run should have returned")
+		}
+	})
+}
+
+func TestSendQueueNoBatchingWithGSO(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		c := NewMockSendConn(mockCtrl)
+		q := newSendQueue(c)
+
+		c.EXPECT().capabilities().Return(connCapabilities{GSO: true, BatchWrite: true}).AnyTimes()
+		var written []string
+		c.EXPECT().Write(gomock.Any(), uint16(0), protocol.ECNNon, gomock.Any()).DoAndReturn(func(b []byte, _ uint16, _ protocol.ECN, _ monotime.Time) error {
+			written = append(written, string(b))
+			return nil
+		}).Times(3)
+		q.Send(getPacketWithContents([]byte("foo")), 0, protocol.ECNNon, 0)
+		q.Send(getPacketWithContents([]byte("bar")), 0, protocol.ECNNon, 0)
+		q.Send(getPacketWithContents([]byte("baz")), 0, protocol.ECNNon, 0)
+
+		go q.Run()
+		synctest.Wait()
+		require.Equal(t, []string{"foo", "bar", "baz"}, written)
+		q.Close()
+	})
+}
+
+func TestSendQueueKernelPacing(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		c := NewMockSendConn(mockCtrl)
+		q := newSendQueue(c)
+
+		now := monotime.Now()
+		c.EXPECT().capabilities().Return(connCapabilities{BatchWrite: true, KernelPacing: true}).AnyTimes()
+		gomock.InOrder(
+			c.EXPECT().Write([]byte("foo"), uint16(3), protocol.ECNNon, now.Add(time.Millisecond)),
+			c.EXPECT().WriteBatch(gomock.Any()).DoAndReturn(func(packets []outgoingPacket) (int, error) {
+				require.Len(t, packets, 2)
+				require.Equal(t, now.Add(2*time.Millisecond), packets[0].txTime)
+				require.Equal(t, now.Add(3*time.Millisecond), packets[1].txTime)
+				return 2, nil
+			}),
+		)
+		q.Send(getPacketWithContents([]byte("foo")), 3, protocol.ECNNon, now.Add(time.Millisecond))
+		q.Send(getPacketWithContents([]byte("bar")), 0, protocol.ECNNon, now.Add(2*time.Millisecond))
+		q.Send(getPacketWithContents([]byte("baz")), 0, protocol.ECNNon, now.Add(3*time.Millisecond))
+
+		go q.Run()
+		synctest.Wait()
+		q.Close()
+	})
+}
@@ -54,6 +54,8 @@ type SendStream struct {
 	writeOnce chan struct{}
 	deadline  monotime.Time
 
+	priority StreamPriority
+
 	flowController flowcontrol.StreamFlowController
 }
 
@@ -77,6 +79,7 @@ func newSendStream(
 		writeChan:             make(chan struct{}, 1),
 		writeOnce:             make(chan struct{}, 1), // cap: 1, to protect against concurrent use of Write
 		supportsResetStreamAt: supportsResetStreamAt,
+		priority:              DefaultStreamPriority,
 	}
 	s.ctx, s.ctxCancel = context.WithCancelCause(ctx)
 	return s
@@ -635,6 +638,26 @@ func (s *SendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+// SetPriority sets the priority of the stream.
+// The priority can be changed at any time, and applies to all data that hasn't been sent yet.
+func (s *SendStream) SetPriority(p StreamPriority) {
+	s.mutex.Lock()
+	s.priority = p
+	completed := s.completed
+	s.mutex.Unlock()
+
+	if !completed {
+		s.sender.onStreamPriorityChanged(s.streamID) // must be called without holding the mutex
+	}
+}
+
+// Priority returns the priority of the stream.
+func (s *SendStream) Priority() StreamPriority {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.priority
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
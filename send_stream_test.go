@@ -61,6 +61,22 @@ func TestSendStreamSetup(t *testing.T) {
 	require.Equal(t, protocol.StreamID(1337), str.StreamID())
 }
 
+func TestSendStreamPriority(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockSender := NewMockStreamSender(mockCtrl)
+	str := newSendStream(context.Background(), 42, mockSender, mocks.NewMockStreamFlowController(mockCtrl), false)
+	require.Equal(t, DefaultStreamPriority, str.Priority())
+
+	mockSender.EXPECT().onStreamPriorityChanged(protocol.StreamID(42))
+	str.SetPriority(StreamPriority{Urgency: 1, Weight: 5})
+	require.Equal(t, StreamPriority{Urgency: 1, Weight: 5}, str.Priority())
+
+	// once the stream is completed, the sender is not notified anymore
+	str.completed = true
+	str.SetPriority(StreamPriority{Urgency: 2})
+	require.Equal(t, StreamPriority{Urgency: 2}, str.Priority())
+}
+
 func TestSendStreamWriteData(t *testing.T) {
 	const streamID protocol.StreamID = 42
 	mockCtrl := gomock.NewController(t)
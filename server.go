@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/handshake"
@@ -111,7 +112,13 @@ type baseServer struct {
 	retryQueue              chan rejectedPacket
 	handshakingCount        sync.WaitGroup
 
+	// draining is set when the Transport is shut down gracefully.
+	// New connection attempts are then refused.
+	draining atomic.Bool
+
 	verifySourceAddress func(net.Addr) bool
+	sourceAddressPolicy *SourceAddressPolicy
+	connLimiter         *connLimiter // nil if the number of connections is not limited
 
 	connQueue chan *Conn
 
@@ -246,9 +253,12 @@ func newServer(
 	config *Config,
 	qlogger qlogwriter.Recorder,
 	onClose func(),
-	tokenGeneratorKey TokenGeneratorKey,
+	tokenGenerator *handshake.TokenGenerator,
 	maxTokenAge time.Duration,
 	verifySourceAddress func(net.Addr) bool,
+	sourceAddressPolicy *SourceAddressPolicy,
+	connLimiter *connLimiter,
+	maxAcceptQueueSize int,
 	disableVersionNegotiation bool,
 	acceptEarly bool,
 ) *baseServer {
@@ -258,12 +268,14 @@ func newServer(
 		tr:                        tr,
 		tlsConf:                   tlsConf,
 		config:                    config,
-		tokenGenerator:            handshake.NewTokenGenerator(tokenGeneratorKey),
+		tokenGenerator:            tokenGenerator,
 		maxTokenAge:               maxTokenAge,
 		verifySourceAddress:       verifySourceAddress,
+		sourceAddressPolicy:       sourceAddressPolicy,
+		connLimiter:               connLimiter,
 		connIDGenerator:           connIDGenerator,
 		statelessResetter:         statelessResetter,
-		connQueue:                 make(chan *Conn, protocol.MaxAcceptQueueSize),
+		connQueue:                 make(chan *Conn, maxAcceptQueueSize),
 		errorChan:                 make(chan struct{}),
 		stopAccepting:             make(chan struct{}),
 		running:                   make(chan struct{}),
@@ -485,7 +497,12 @@ func (s *baseServer) handlePacketImpl(p receivedPacket) bool /* is the buffer st
 
 	// If we're creating a new connection, the packet will be passed to the connection.
 	// The header will then be parsed again.
-	hdr, _, _, err := wire.ParsePacket(p.data)
+	// Clients that remember our grease_quic_bit transport parameter might clear the QUIC bit (RFC 9287).
+	parsePacket := wire.ParsePacket
+	if !s.config.DisableQUICBitGreasing {
+		parsePacket = wire.ParsePacketAllowClearedQUICBit
+	}
+	hdr, _, _, err := parsePacket(p.data)
 	if err != nil {
 		if s.qlogger != nil {
 			s.qlogger.RecordEvent(qlog.PacketDropped{
@@ -701,6 +718,12 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		return nil
 	}
 
+	if s.draining.Load() {
+		s.logger.Debugf("Transport is shutting down. Refusing connection from %s.", p.remoteAddr)
+		s.refuseNewConn(p, hdr)
+		return nil
+	}
+
 	var (
 		token              *handshake.Token
 		retrySrcConnID     *protocol.ConnectionID
@@ -741,14 +764,24 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	}
 
 	if token == nil && s.verifySourceAddress != nil && s.verifySourceAddress(p.remoteAddr) {
-		// Retry invalidates all 0-RTT packets sent.
-		delete(s.zeroRTTQueues, hdr.DestConnectionID)
-		select {
-		case s.retryQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
-		default:
-			// drop packet if we can't send out Retry packets fast enough
-			p.buffer.Release()
+		s.retryNewConn(p, hdr)
+		return nil
+	}
+	if s.sourceAddressPolicy != nil {
+		switch s.sourceAddressPolicy.decide(p.remoteAddr, clientAddrVerified) {
+		case sourceAddressRetry:
+			s.logger.Debugf("Too many handshakes in flight. Requiring address validation for %s.", p.remoteAddr)
+			s.retryNewConn(p, hdr)
+			return nil
+		case sourceAddressRefuse:
+			s.logger.Debugf("Too many handshakes in flight. Refusing connection from %s.", p.remoteAddr)
+			s.refuseNewConnDOSPrevention(p, hdr)
+			return nil
 		}
+	}
+	if s.connLimiter != nil && !s.connLimiter.Allow(p.remoteAddr) {
+		s.logger.Debugf("Connection limit reached. Refusing connection from %s.", p.remoteAddr)
+		s.refuseNewConnDOSPrevention(p, hdr)
 		return nil
 	}
 
@@ -854,15 +887,59 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		delete(s.zeroRTTQueues, hdr.DestConnectionID)
 	}
 
+	if s.sourceAddressPolicy != nil {
+		s.sourceAddressPolicy.handshakeStarted(p.remoteAddr)
+	}
+	if s.connLimiter != nil {
+		remoteAddr := p.remoteAddr
+		s.connLimiter.AddConn(remoteAddr)
+		context.AfterFunc(conn.Context(), func() { s.connLimiter.RemoveConn(remoteAddr) })
+	}
 	s.handshakingCount.Add(1)
-	go func() {
+	go func(remoteAddr net.Addr) {
 		defer s.handshakingCount.Done()
 		s.handleNewConn(conn)
-	}()
+		if s.sourceAddressPolicy != nil {
+			s.sourceAddressPolicy.handshakeFinished(remoteAddr)
+		}
+	}(p.remoteAddr)
 	go conn.run()
 	return nil
 }
 
+func (s *baseServer) retryNewConn(p receivedPacket, hdr *wire.Header) {
+	// Retry invalidates all 0-RTT packets sent.
+	delete(s.zeroRTTQueues, hdr.DestConnectionID)
+	select {
+	case s.retryQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+	default:
+		// drop packet if we can't send out Retry packets fast enough
+		p.buffer.Release()
+	}
+}
+
+// drain stops accepting new connections.
+// Connections that are already handshaking can still complete the handshake.
+func (s *baseServer) drain() {
+	s.draining.Store(true)
+}
+
+// refuseNewConnDOSPrevention refuses a new connection because a limit was reached.
+func (s *baseServer) refuseNewConnDOSPrevention(p receivedPacket, hdr *wire.Header) {
+	if s.qlogger != nil {
+		s.qlogger.RecordEvent(qlog.PacketDropped{
+			Header: qlog.PacketHeader{
+				PacketType:   qlog.PacketTypeInitial,
+				PacketNumber: protocol.InvalidPacketNumber,
+				Version:      hdr.Version,
+			},
+			Raw:     qlog.RawInfo{Length: int(p.Size())},
+			Trigger: qlog.PacketDropDOSPrevention,
+		})
+	}
+	s.refuseNewConn(p, hdr)
+}
+
 func (s *baseServer) refuseNewConn(p receivedPacket, hdr *wire.Header) {
 	delete(s.zeroRTTQueues, hdr.DestConnectionID)
 	select {
@@ -32,6 +32,10 @@ type serverOpts struct {
 	tokenGeneratorKey         TokenGeneratorKey
 	maxTokenAge               time.Duration
 	useRetry                  bool
+	sourceAddressPolicy       *SourceAddressPolicy
+	maxConns                  int
+	maxConnsPerIP             int
+	maxAcceptQueueSize        int
 	disableVersionNegotiation bool
 	acceptEarly               bool
 	newConn                   func(
@@ -63,6 +67,10 @@ func newTestServer(t *testing.T, serverOpts *serverOpts) *testServer {
 	require.NoError(t, err)
 	verifySourceAddress := func(net.Addr) bool { return serverOpts.useRetry }
 	config := populateConfig(serverOpts.config)
+	maxAcceptQueueSize := serverOpts.maxAcceptQueueSize
+	if maxAcceptQueueSize == 0 {
+		maxAcceptQueueSize = protocol.MaxAcceptQueueSize
+	}
 	tr := &Transport{Conn: newUDPConnLocalhost(t)}
 	tr.init(true)
 	s := newServer(
@@ -75,9 +83,12 @@ func newTestServer(t *testing.T, serverOpts *serverOpts) *testServer {
 		config,
 		serverOpts.eventRecorder,
 		func() {},
-		serverOpts.tokenGeneratorKey,
+		handshake.NewTokenGenerator(serverOpts.tokenGeneratorKey),
 		serverOpts.maxTokenAge,
 		verifySourceAddress,
+		serverOpts.sourceAddressPolicy,
+		newConnLimiter(serverOpts.maxConns, serverOpts.maxConnsPerIP),
+		maxAcceptQueueSize,
 		serverOpts.disableVersionNegotiation,
 		serverOpts.acceptEarly,
 	)
@@ -884,6 +895,212 @@ func TestServerGetConfigForClientReject(t *testing.T) {
 	checkConnectionClose(t, conn, &eventRecorder, destConnID, srcConnID, qerr.ConnectionRefused)
 }
 
+func TestServerSourceAddressPolicy(t *testing.T) {
+	var tokenGeneratorKey handshake.TokenProtectorKey
+	rand.Read(tokenGeneratorKey[:])
+	tg := handshake.NewTokenGenerator(tokenGeneratorKey)
+
+	handshakeComplete := make(chan struct{})
+	recorder := newConnConstructorRecorder(
+		&connTestHooks{handshakeComplete: func() <-chan struct{} { return handshakeComplete }},
+		&connTestHooks{},
+		&connTestHooks{},
+	)
+	var eventRecorder events.Recorder
+	policy := &SourceAddressPolicy{RetryThreshold: 1, MaxHandshakes: 2}
+	server := newTestServer(t, &serverOpts{
+		eventRecorder:       &eventRecorder,
+		tokenGeneratorKey:   tokenGeneratorKey,
+		sourceAddressPolicy: policy,
+		newConn:             recorder.NewConn,
+	})
+	conn := newUDPConnLocalhost(t)
+
+	getInitialWithRetryToken := func(t *testing.T, srcConnID, destConnID protocol.ConnectionID) receivedPacket {
+		t.Helper()
+		token, err := tg.NewRetryToken(conn.LocalAddr(), randConnID(8), destConnID)
+		require.NoError(t, err)
+		return getLongHeaderPacket(t,
+			conn.LocalAddr(),
+			&wire.ExtendedHeader{
+				Header: wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  srcConnID,
+					DestConnectionID: destConnID,
+					Token:            token,
+					Version:          protocol.Version1,
+				},
+				PacketNumberLen: protocol.PacketNumberLen4,
+			},
+			make([]byte, protocol.MinInitialPacketSize),
+		)
+	}
+	expectNewConn := func(t *testing.T) {
+		t.Helper()
+		select {
+		case <-recorder.Args():
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	// the first connection is accepted
+	server.handlePacket(getValidInitialPacket(t, conn.LocalAddr(), randConnID(6), randConnID(8)))
+	expectNewConn(t)
+
+	// the Retry threshold is reached, the next client needs to validate its address
+	srcConnID := randConnID(6)
+	server.handlePacket(getValidInitialPacket(t, conn.LocalAddr(), srcConnID, randConnID(8)))
+	checkRetry(t, conn, &eventRecorder, srcConnID)
+
+	// connections from validated addresses are accepted
+	server.handlePacket(getInitialWithRetryToken(t, randConnID(6), randConnID(8)))
+	expectNewConn(t)
+
+	// the hard limit is reached, the connection is refused
+	eventRecorder.Clear()
+	srcConnID = randConnID(6)
+	destConnID := randConnID(8)
+	packet := getInitialWithRetryToken(t, srcConnID, destConnID)
+	server.handlePacket(packet)
+	checkConnectionClose(t, conn, &eventRecorder, destConnID, srcConnID, qerr.ConnectionRefused)
+	require.Equal(t,
+		[]qlogwriter.Event{
+			qlog.PacketDropped{
+				Header: qlog.PacketHeader{
+					PacketType:   qlog.PacketTypeInitial,
+					PacketNumber: protocol.InvalidPacketNumber,
+					Version:      protocol.Version1,
+				},
+				Raw:     qlog.RawInfo{Length: int(packet.Size())},
+				Trigger: qlog.PacketDropDOSPrevention,
+			},
+		},
+		eventRecorder.Events(qlog.PacketDropped{}),
+	)
+
+	// once a handshake completes, new connections are accepted again
+	close(handshakeComplete)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := server.Accept(ctx)
+	require.NoError(t, err)
+	require.Eventually(t,
+		func() bool {
+			policy.mx.Lock()
+			defer policy.mx.Unlock()
+			return policy.handshakes == 1
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	server.handlePacket(getInitialWithRetryToken(t, randConnID(6), randConnID(8)))
+	expectNewConn(t)
+}
+
+func TestServerConnectionLimits(t *testing.T) {
+	var hooks []*connTestHooks
+	var cancels []context.CancelFunc
+	for range 4 {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		cancels = append(cancels, cancel)
+		hooks = append(hooks, &connTestHooks{context: func() context.Context { return ctx }})
+	}
+	recorder := newConnConstructorRecorder(hooks...)
+	var eventRecorder events.Recorder
+	server := newTestServer(t, &serverOpts{
+		eventRecorder: &eventRecorder,
+		maxConns:      3,
+		maxConnsPerIP: 2,
+		newConn:       recorder.NewConn,
+	})
+
+	expectNewConn := func(t *testing.T) {
+		t.Helper()
+		select {
+		case <-recorder.Args():
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	expectRefused := func(t *testing.T, packet receivedPacket) {
+		t.Helper()
+		require.Eventually(t,
+			func() bool { return len(eventRecorder.Events(qlog.PacketDropped{})) > 0 },
+			time.Second,
+			time.Millisecond,
+		)
+		require.Equal(t,
+			[]qlogwriter.Event{
+				qlog.PacketDropped{
+					Header: qlog.PacketHeader{
+						PacketType:   qlog.PacketTypeInitial,
+						PacketNumber: protocol.InvalidPacketNumber,
+						Version:      protocol.Version1,
+					},
+					Raw:     qlog.RawInfo{Length: int(packet.Size())},
+					Trigger: qlog.PacketDropDOSPrevention,
+				},
+			},
+			eventRecorder.Events(qlog.PacketDropped{}),
+		)
+		eventRecorder.Clear()
+	}
+
+	conn := newUDPConnLocalhost(t)
+	server.handlePacket(getValidInitialPacket(t, conn.LocalAddr(), randConnID(6), randConnID(8)))
+	expectNewConn(t)
+	server.handlePacket(getValidInitialPacket(t, conn.LocalAddr(), randConnID(6), randConnID(8)))
+	expectNewConn(t)
+
+	// the limit of connections per IP is reached
+	srcConnID := randConnID(6)
+	destConnID := randConnID(8)
+	packet := getValidInitialPacket(t, conn.LocalAddr(), srcConnID, destConnID)
+	server.handlePacket(packet)
+	checkConnectionClose(t, conn, &eventRecorder, destConnID, srcConnID, qerr.ConnectionRefused)
+	expectRefused(t, packet)
+
+	// connections from other IPs are accepted, until the total limit is reached
+	server.handlePacket(getValidInitialPacket(t,
+		&net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234},
+		randConnID(6),
+		randConnID(8),
+	))
+	expectNewConn(t)
+	packet = getValidInitialPacket(t, &net.UDPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 1234}, randConnID(6), randConnID(8))
+	server.handlePacket(packet)
+	expectRefused(t, packet)
+
+	// once a connection is closed, new connections are accepted again
+	cancels[0]()
+	require.Eventually(t,
+		func() bool {
+			server.connLimiter.mx.Lock()
+			defer server.connLimiter.mx.Unlock()
+			return server.connLimiter.conns == 2
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	server.handlePacket(getValidInitialPacket(t, conn.LocalAddr(), randConnID(6), randConnID(8)))
+	expectNewConn(t)
+	require.Empty(t, eventRecorder.Events(qlog.PacketDropped{}))
+}
+
+func TestServerDraining(t *testing.T) {
+	var eventRecorder events.Recorder
+	server := newTestServer(t, &serverOpts{eventRecorder: &eventRecorder})
+	server.drain()
+
+	conn := newUDPConnLocalhost(t)
+	srcConnID := randConnID(6)
+	destConnID := randConnID(8)
+	server.handlePacket(getValidInitialPacket(t, conn.LocalAddr(), srcConnID, destConnID))
+	checkConnectionClose(t, conn, &eventRecorder, destConnID, srcConnID, qerr.ConnectionRefused)
+}
+
 func TestServerReceiveQueue(t *testing.T) {
 	var eventRecorder events.Recorder
 	acceptConn := make(chan struct{})
@@ -1038,9 +1255,18 @@ func TestServerAcceptHandshakeFailure(t *testing.T) {
 }
 
 func TestServerAcceptQueue(t *testing.T) {
+	t.Run("default queue size", func(t *testing.T) {
+		testServerAcceptQueue(t, 0, protocol.MaxAcceptQueueSize)
+	})
+	t.Run("custom queue size", func(t *testing.T) {
+		testServerAcceptQueue(t, 5, 5)
+	})
+}
+
+func testServerAcceptQueue(t *testing.T, maxAcceptQueueSize, expectedQueueSize int) {
 	var conns []*connTestHooks
 	rejectedCloseError := make(chan TransportErrorCode, 1)
-	for i := range protocol.MaxAcceptQueueSize + 2 {
+	for i := range expectedQueueSize + 2 {
 		conn := &connTestHooks{
 			handshakeComplete: func() <-chan struct{} {
 				c := make(chan struct{})
@@ -1049,15 +1275,15 @@ func TestServerAcceptQueue(t *testing.T) {
 			},
 		}
 		conns = append(conns, conn)
-		if i == protocol.MaxAcceptQueueSize {
+		if i == expectedQueueSize {
 			conn.closeWithTransportError = func(code TransportErrorCode) { rejectedCloseError <- code }
 			continue
 		}
 	}
 	recorder := newConnConstructorRecorder(conns...)
-	server := newTestServer(t, &serverOpts{newConn: recorder.NewConn})
+	server := newTestServer(t, &serverOpts{newConn: recorder.NewConn, maxAcceptQueueSize: maxAcceptQueueSize})
 
-	for range protocol.MaxAcceptQueueSize {
+	for range expectedQueueSize {
 		b := make([]byte, 16)
 		rand.Read(b)
 		connID := protocol.ParseConnectionID(b)
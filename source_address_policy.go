@@ -0,0 +1,176 @@
+package quic
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+)
+
+const (
+	defaultRetryThreshold          = 128
+	defaultRetryThresholdPerPrefix = 16
+	defaultMaxHandshakes           = 4096
+	defaultMaxHandshakesPerPrefix  = 256
+)
+
+// The prefix lengths used to group client addresses.
+const (
+	sourceAddressPrefixLenIPv4 = 24
+	sourceAddressPrefixLenIPv6 = 48
+)
+
+type sourceAddressDecision uint8
+
+const (
+	sourceAddressAccept sourceAddressDecision = iota
+	sourceAddressRetry
+	sourceAddressRefuse
+)
+
+// A SourceAddressPolicy protects a server from handshake floods.
+// It keeps track of the number of handshakes in flight, both in total and per client prefix.
+// Client addresses are grouped into /24 prefixes for IPv4 and /48 prefixes for IPv6.
+//
+// Once the load crosses one of the Retry thresholds, clients that haven't validated their address
+// are required to go through source address validation using a Retry packet (RFC 9000 section 8.1.2).
+// Since Retry doesn't require the server to keep any state, this doesn't make it possible
+// for an attacker to deny service to legitimate clients by spoofing their addresses.
+// Once the load crosses one of the hard limits, connection attempts from validated addresses
+// are refused with a CONNECTION_REFUSED error.
+// Refused connection attempts are reported to the Transport's Tracer as a qlog PacketDropped event
+// with the dos_prevention trigger. Retries are reported as a qlog PacketSent event.
+//
+// The zero value uses reasonable defaults for a public server.
+// A SourceAddressPolicy may be shared between multiple Transports.
+// It must not be copied after first use.
+type SourceAddressPolicy struct {
+	// RetryThreshold is the number of handshakes in flight from which on clients
+	// need to validate their address before a connection is accepted.
+	// If zero, it defaults to 128.
+	RetryThreshold int
+	// RetryThresholdPerPrefix is the number of handshakes in flight from a single client prefix
+	// from which on clients from this prefix need to validate their address before a connection is accepted.
+	// If zero, it defaults to 16.
+	RetryThresholdPerPrefix int
+	// MaxHandshakes is the maximum number of handshakes in flight.
+	// Connection attempts beyond this limit are refused.
+	// If zero, it defaults to 4096.
+	MaxHandshakes int
+	// MaxHandshakesPerPrefix is the maximum number of handshakes in flight from a single client prefix.
+	// Connection attempts beyond this limit are refused.
+	// If zero, it defaults to 256.
+	MaxHandshakesPerPrefix int
+
+	mx         sync.Mutex
+	handshakes int
+	prefixes   map[netip.Prefix]int
+}
+
+func (p *SourceAddressPolicy) retryThreshold() int {
+	threshold := p.RetryThreshold
+	if threshold == 0 {
+		threshold = defaultRetryThreshold
+	}
+	return min(threshold, p.maxHandshakes())
+}
+
+func (p *SourceAddressPolicy) retryThresholdPerPrefix() int {
+	threshold := p.RetryThresholdPerPrefix
+	if threshold == 0 {
+		threshold = defaultRetryThresholdPerPrefix
+	}
+	return min(threshold, p.maxHandshakesPerPrefix())
+}
+
+func (p *SourceAddressPolicy) maxHandshakes() int {
+	if p.MaxHandshakes == 0 {
+		return defaultMaxHandshakes
+	}
+	return p.MaxHandshakes
+}
+
+func (p *SourceAddressPolicy) maxHandshakesPerPrefix() int {
+	if p.MaxHandshakesPerPrefix == 0 {
+		return defaultMaxHandshakesPerPrefix
+	}
+	return p.MaxHandshakesPerPrefix
+}
+
+// decide decides if a new connection attempt is accepted, requires a Retry, or is refused.
+// Connection attempts from unvalidated addresses are never refused,
+// since the address might have been spoofed.
+func (p *SourceAddressPolicy) decide(addr net.Addr, addrVerified bool) sourceAddressDecision {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var numPrefix int
+	if prefix, ok := sourceAddressPrefix(addr); ok {
+		numPrefix = p.prefixes[prefix]
+	}
+	if !addrVerified {
+		if p.handshakes >= p.retryThreshold() || numPrefix >= p.retryThresholdPerPrefix() {
+			return sourceAddressRetry
+		}
+		return sourceAddressAccept
+	}
+	if p.handshakes >= p.maxHandshakes() || numPrefix >= p.maxHandshakesPerPrefix() {
+		return sourceAddressRefuse
+	}
+	return sourceAddressAccept
+}
+
+func (p *SourceAddressPolicy) handshakeStarted(addr net.Addr) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.handshakes++
+	if prefix, ok := sourceAddressPrefix(addr); ok {
+		if p.prefixes == nil {
+			p.prefixes = make(map[netip.Prefix]int)
+		}
+		p.prefixes[prefix]++
+	}
+}
+
+func (p *SourceAddressPolicy) handshakeFinished(addr net.Addr) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.handshakes--
+	if prefix, ok := sourceAddressPrefix(addr); ok {
+		if p.prefixes[prefix] <= 1 {
+			delete(p.prefixes, prefix)
+		} else {
+			p.prefixes[prefix]--
+		}
+	}
+}
+
+// sourceAddressPrefix returns the prefix that the address is grouped into.
+// It returns false for non-IP addresses.
+func sourceAddressPrefix(addr net.Addr) (netip.Prefix, bool) {
+	return addrPrefix(addr, sourceAddressPrefixLenIPv4, sourceAddressPrefixLenIPv6)
+}
+
+// addrPrefix returns the prefix of the given length that the address belongs to.
+// It returns false for non-IP addresses.
+func addrPrefix(addr net.Addr, bitsIPv4, bitsIPv6 int) (netip.Prefix, bool) {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ip, ok := netip.AddrFromSlice(udpAddr.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ip = ip.Unmap()
+	bits := bitsIPv6
+	if ip.Is4() {
+		bits = bitsIPv4
+	}
+	prefix, err := ip.Prefix(bits)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return prefix, true
+}
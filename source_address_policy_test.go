@@ -0,0 +1,91 @@
+package quic
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceAddressPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		addr   net.Addr
+		prefix string
+	}{
+		{name: "IPv4", addr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 42), Port: 1234}, prefix: "192.0.2.0/24"},
+		{name: "IPv4-mapped IPv6", addr: &net.UDPAddr{IP: net.ParseIP("::ffff:192.0.2.42"), Port: 1234}, prefix: "192.0.2.0/24"},
+		{name: "IPv6", addr: &net.UDPAddr{IP: net.ParseIP("2001:db8:1:2:3:4:5:6"), Port: 1234}, prefix: "2001:db8:1::/48"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prefix, ok := sourceAddressPrefix(tc.addr)
+			require.True(t, ok)
+			require.Equal(t, netip.MustParsePrefix(tc.prefix), prefix)
+		})
+	}
+
+	_, ok := sourceAddressPrefix(&net.TCPAddr{IP: net.IPv4(192, 0, 2, 42), Port: 1234})
+	require.False(t, ok)
+}
+
+func TestSourceAddressPolicyDefaults(t *testing.T) {
+	var p SourceAddressPolicy
+	require.Equal(t, defaultRetryThreshold, p.retryThreshold())
+	require.Equal(t, defaultRetryThresholdPerPrefix, p.retryThresholdPerPrefix())
+	require.Equal(t, defaultMaxHandshakes, p.maxHandshakes())
+	require.Equal(t, defaultMaxHandshakesPerPrefix, p.maxHandshakesPerPrefix())
+
+	// the Retry thresholds are capped by the hard limits
+	p = SourceAddressPolicy{MaxHandshakes: 10, MaxHandshakesPerPrefix: 5}
+	require.Equal(t, 10, p.retryThreshold())
+	require.Equal(t, 5, p.retryThresholdPerPrefix())
+}
+
+func TestSourceAddressPolicyTotal(t *testing.T) {
+	p := &SourceAddressPolicy{RetryThreshold: 2, MaxHandshakes: 3}
+	addr := func(i byte) net.Addr { return &net.UDPAddr{IP: net.IPv4(10, 0, i, 1), Port: 443} }
+
+	require.Equal(t, sourceAddressAccept, p.decide(addr(1), false))
+	p.handshakeStarted(addr(1))
+	require.Equal(t, sourceAddressAccept, p.decide(addr(2), false))
+	p.handshakeStarted(addr(2))
+	// the Retry threshold is reached
+	require.Equal(t, sourceAddressRetry, p.decide(addr(3), false))
+	require.Equal(t, sourceAddressAccept, p.decide(addr(3), true))
+	p.handshakeStarted(addr(3))
+	// the hard limit is reached
+	require.Equal(t, sourceAddressRefuse, p.decide(addr(4), true))
+	// unvalidated addresses are never refused, since they might have been spoofed
+	require.Equal(t, sourceAddressRetry, p.decide(addr(4), false))
+
+	p.handshakeFinished(addr(1))
+	require.Equal(t, sourceAddressAccept, p.decide(addr(4), true))
+	require.Equal(t, sourceAddressRetry, p.decide(addr(4), false))
+	p.handshakeFinished(addr(2))
+	require.Equal(t, sourceAddressAccept, p.decide(addr(4), false))
+	p.handshakeFinished(addr(3))
+	require.Empty(t, p.prefixes)
+}
+
+func TestSourceAddressPolicyPerPrefix(t *testing.T) {
+	p := &SourceAddressPolicy{RetryThresholdPerPrefix: 1, MaxHandshakesPerPrefix: 2}
+	addr1 := &net.UDPAddr{IP: net.ParseIP("2001:db8:1:2::1"), Port: 443}
+	addr2 := &net.UDPAddr{IP: net.ParseIP("2001:db8:1:3::1"), Port: 443} // same /48 as addr1
+	addr3 := &net.UDPAddr{IP: net.ParseIP("2001:db8:2::1"), Port: 443}
+
+	require.Equal(t, sourceAddressAccept, p.decide(addr1, false))
+	p.handshakeStarted(addr1)
+	require.Equal(t, sourceAddressRetry, p.decide(addr2, false))
+	require.Equal(t, sourceAddressAccept, p.decide(addr3, false))
+	require.Equal(t, sourceAddressAccept, p.decide(addr2, true))
+	p.handshakeStarted(addr2)
+	require.Equal(t, sourceAddressRefuse, p.decide(addr1, true))
+	require.Equal(t, sourceAddressAccept, p.decide(addr3, true))
+
+	p.handshakeFinished(addr1)
+	require.Equal(t, sourceAddressAccept, p.decide(addr1, true))
+	p.handshakeFinished(addr2)
+	require.Equal(t, sourceAddressAccept, p.decide(addr1, false))
+	require.Empty(t, p.prefixes)
+}
@@ -0,0 +1,66 @@
+package quic
+
+import (
+	"math/rand/v2"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// spinBit tracks the latency spin bit of a single path, see section 17.4 of RFC 9000.
+// The server echoes the spin value of the packet with the highest packet number received from the client,
+// and the client inverts it. The spin value therefore flips once per round trip,
+// allowing on-path observers to measure the RTT of the connection.
+type spinBit struct {
+	perspective protocol.Perspective
+	// When disabled, random values are sent, and the spin bit of received packets is ignored.
+	disabled bool
+
+	value     bool
+	largestPN protocol.PacketNumber
+	connID    protocol.ConnectionID // the connection ID that was used when sending the last packet
+}
+
+func newSpinBit(perspective protocol.Perspective, disabled bool) *spinBit {
+	return &spinBit{
+		perspective: perspective,
+		disabled:    disabled,
+		largestPN:   protocol.InvalidPacketNumber,
+	}
+}
+
+// spinBitDisabled decides if the spin bit is disabled for a connection.
+// RFC 9000 requires disabling the spin bit for at least one in every 16 connections,
+// such that the spin bit can't be used to fingerprint implementations that disabled it.
+func spinBitDisabled(conf *Config) bool {
+	return conf.DisableSpinBit || rand.IntN(16) == 0
+}
+
+func (s *spinBit) Disabled() bool { return s.disabled }
+
+// ReceivedPacket is called for every 1-RTT packet received on this path.
+func (s *spinBit) ReceivedPacket(pn protocol.PacketNumber, spin bool) {
+	if s.disabled {
+		return
+	}
+	// Only the packet with the highest packet number updates the spin value.
+	if s.largestPN != protocol.InvalidPacketNumber && pn <= s.largestPN {
+		return
+	}
+	s.largestPN = pn
+	if s.perspective == protocol.PerspectiveServer {
+		s.value = spin
+	} else {
+		s.value = !spin
+	}
+}
+
+// Value returns the spin value for a packet sent using the given connection ID.
+// It must not be called if the spin bit is disabled.
+func (s *spinBit) Value(connID protocol.ConnectionID) bool {
+	// The spin value is reset to 0 when the connection ID used on this path changes.
+	if s.connID.Len() > 0 && connID != s.connID {
+		s.value = false
+	}
+	s.connID = connID
+	return s.value
+}
@@ -0,0 +1,71 @@
+package quic
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpinBitServer(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	s := newSpinBit(protocol.PerspectiveServer, false)
+	require.False(t, s.Value(connID))
+	// the server echoes the spin value
+	s.ReceivedPacket(1, true)
+	require.True(t, s.Value(connID))
+	s.ReceivedPacket(2, false)
+	require.False(t, s.Value(connID))
+	// reordered packets are ignored
+	s.ReceivedPacket(1, true)
+	require.False(t, s.Value(connID))
+	s.ReceivedPacket(5, true)
+	require.True(t, s.Value(connID))
+}
+
+func TestSpinBitClient(t *testing.T) {
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	s := newSpinBit(protocol.PerspectiveClient, false)
+	require.False(t, s.Value(connID))
+	// the client inverts the spin value
+	s.ReceivedPacket(0, false)
+	require.True(t, s.Value(connID))
+	s.ReceivedPacket(1, true)
+	require.False(t, s.Value(connID))
+	s.ReceivedPacket(1, false) // duplicate packet number
+	require.False(t, s.Value(connID))
+}
+
+func TestSpinBitConnectionIDChange(t *testing.T) {
+	connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	connID2 := protocol.ParseConnectionID([]byte{5, 6, 7, 8})
+	s := newSpinBit(protocol.PerspectiveServer, false)
+	// packets received before the first packet is sent don't reset the spin value
+	s.ReceivedPacket(1, true)
+	require.True(t, s.Value(connID1))
+	require.True(t, s.Value(connID1))
+	// the spin value is reset when the connection ID changes
+	require.False(t, s.Value(connID2))
+	s.ReceivedPacket(2, true)
+	require.True(t, s.Value(connID2))
+}
+
+func TestSpinBitDisabled(t *testing.T) {
+	s := newSpinBit(protocol.PerspectiveServer, true)
+	require.True(t, s.Disabled())
+	s.ReceivedPacket(1, true)
+	require.False(t, s.value)
+
+	require.True(t, spinBitDisabled(&Config{DisableSpinBit: true}))
+	// the spin bit is disabled for 1 in 16 connections
+	var disabled int
+	const num = 16 * 1000
+	for range num {
+		if spinBitDisabled(&Config{}) {
+			disabled++
+		}
+	}
+	require.Greater(t, disabled, num/16*3/4)
+	require.Less(t, disabled, num/16*5/4)
+}
@@ -0,0 +1,83 @@
+// Package spinbit measures the round-trip time of QUIC connections using the latency spin bit,
+// as described in section 17.4 of RFC 9000.
+//
+// The spin bit is sent unencrypted in the first byte of short header packets.
+// The client and the server reflect the value of the spin bit, such that it flips
+// once per round trip. An on-path observer that sees the packets of a connection
+// flowing in one direction can therefore estimate the RTT by measuring the time
+// between two consecutive spin edges, i.e. changes of the spin value.
+//
+// Endpoints are allowed to disable the spin bit, in which case they send random values,
+// leading to bogus RTT samples. Packet reordering can also cause spurious spin edges.
+// Users should therefore filter the samples, for example by discarding values that are
+// far off the median of the recent samples.
+package spinbit
+
+import "time"
+
+// An Observer computes RTT samples from short header packets observed in one direction,
+// for example from packets sent from clients to servers.
+// Connections are identified by the Destination Connection ID of the packets.
+// An Observer is not safe for concurrent use.
+type Observer struct {
+	connIDLen int
+	flows     map[string]*flow
+}
+
+type flow struct {
+	spin     bool
+	lastEdge time.Time // zero until the first spin edge was observed
+	lastSeen time.Time
+}
+
+// NewObserver creates a new Observer.
+// Since short header packets don't encode the length of the Destination Connection ID,
+// all observed connections need to use connection IDs of length connIDLen.
+func NewObserver(connIDLen int) *Observer {
+	return &Observer{
+		connIDLen: connIDLen,
+		flows:     make(map[string]*flow),
+	}
+}
+
+// Observe processes a packet that was captured at time t.
+// The packet must start with the first byte of the QUIC packet,
+// i.e. it must not contain any IP or UDP headers.
+// It returns an RTT sample if the packet contains a spin edge,
+// and the previous spin edge of the connection was observed as well.
+// Long header packets don't carry a spin bit, and are ignored.
+func (o *Observer) Observe(packet []byte, t time.Time) (rtt time.Duration, ok bool) {
+	if len(packet) < 1+o.connIDLen || packet[0]&0x80 > 0 {
+		return 0, false
+	}
+	spin := packet[0]&0x20 > 0
+	f, ok := o.flows[string(packet[1:1+o.connIDLen])]
+	if !ok {
+		// We don't know when the spin value last changed, so we can't generate an RTT sample yet.
+		// Changing the connection ID resets the spin value, so connection migration
+		// is handled by treating the new connection ID as a new connection.
+		o.flows[string(packet[1:1+o.connIDLen])] = &flow{spin: spin, lastSeen: t}
+		return 0, false
+	}
+	f.lastSeen = t
+	if spin == f.spin {
+		return 0, false
+	}
+	f.spin = spin
+	lastEdge := f.lastEdge
+	f.lastEdge = t
+	if lastEdge.IsZero() {
+		return 0, false
+	}
+	return t.Sub(lastEdge), true
+}
+
+// Prune removes the state of all connections that haven't sent any packets since t.
+// It should be called regularly to prevent unbounded memory growth.
+func (o *Observer) Prune(t time.Time) {
+	for connID, f := range o.flows {
+		if f.lastSeen.Before(t) {
+			delete(o.flows, connID)
+		}
+	}
+}
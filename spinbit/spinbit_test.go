@@ -0,0 +1,101 @@
+package spinbit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func shortHeaderPacket(connID []byte, spin bool) []byte {
+	b := []byte{0x40}
+	if spin {
+		b[0] |= 0x20
+	}
+	b = append(b, connID...)
+	return append(b, []byte("encrypted payload")...)
+}
+
+func TestObserver(t *testing.T) {
+	o := NewObserver(4)
+	connID := []byte{1, 2, 3, 4}
+	start := time.Now()
+
+	for i, tc := range []struct {
+		offset time.Duration
+		spin   bool
+		rtt    time.Duration // 0 if no sample is expected
+	}{
+		{0, false, 0},
+		{5 * time.Millisecond, false, 0},
+		{10 * time.Millisecond, true, 0}, // first edge
+		{15 * time.Millisecond, true, 0},
+		{30 * time.Millisecond, false, 20 * time.Millisecond},
+		{35 * time.Millisecond, false, 0},
+		{55 * time.Millisecond, true, 25 * time.Millisecond},
+	} {
+		rtt, ok := o.Observe(shortHeaderPacket(connID, tc.spin), start.Add(tc.offset))
+		if tc.rtt == 0 {
+			require.False(t, ok, "packet %d", i)
+			continue
+		}
+		require.True(t, ok, "packet %d", i)
+		require.Equal(t, tc.rtt, rtt, "packet %d", i)
+	}
+}
+
+func TestObserverMultipleConnections(t *testing.T) {
+	o := NewObserver(4)
+	connID1 := []byte{1, 2, 3, 4}
+	connID2 := []byte{5, 6, 7, 8}
+	start := time.Now()
+
+	o.Observe(shortHeaderPacket(connID1, false), start)
+	o.Observe(shortHeaderPacket(connID1, true), start.Add(time.Millisecond))
+	o.Observe(shortHeaderPacket(connID2, true), start.Add(2*time.Millisecond))
+	o.Observe(shortHeaderPacket(connID2, false), start.Add(3*time.Millisecond))
+	rtt, ok := o.Observe(shortHeaderPacket(connID2, true), start.Add(13*time.Millisecond))
+	require.True(t, ok)
+	require.Equal(t, 10*time.Millisecond, rtt)
+	rtt, ok = o.Observe(shortHeaderPacket(connID1, false), start.Add(21*time.Millisecond))
+	require.True(t, ok)
+	require.Equal(t, 20*time.Millisecond, rtt)
+}
+
+func TestObserverIgnoredPackets(t *testing.T) {
+	o := NewObserver(4)
+	start := time.Now()
+
+	// long header packets
+	_, ok := o.Observe([]byte{0xc0 | 0x20, 0, 0, 0, 1, 4, 1, 2, 3, 4}, start)
+	require.False(t, ok)
+	// packets that are too short to contain the connection ID
+	_, ok = o.Observe([]byte{0x40, 1, 2, 3}, start)
+	require.False(t, ok)
+	_, ok = o.Observe(nil, start)
+	require.False(t, ok)
+	require.Empty(t, o.flows)
+}
+
+func TestObserverPrune(t *testing.T) {
+	o := NewObserver(4)
+	connID1 := []byte{1, 2, 3, 4}
+	connID2 := []byte{5, 6, 7, 8}
+	start := time.Now()
+
+	o.Observe(shortHeaderPacket(connID1, false), start)
+	o.Observe(shortHeaderPacket(connID2, false), start.Add(time.Second))
+	o.Observe(shortHeaderPacket(connID1, true), start.Add(2*time.Second))
+	o.Observe(shortHeaderPacket(connID2, true), start.Add(3*time.Second))
+	require.Len(t, o.flows, 2)
+
+	o.Prune(start.Add(2500 * time.Millisecond))
+	require.Len(t, o.flows, 1)
+	require.Contains(t, o.flows, string(connID2))
+	// the state of connID1 was removed, so the next edge doesn't generate a sample
+	_, ok := o.Observe(shortHeaderPacket(connID1, false), start.Add(4*time.Second))
+	require.False(t, ok)
+	rtt, ok := o.Observe(shortHeaderPacket(connID2, false), start.Add(4*time.Second))
+	require.True(t, ok)
+	require.Equal(t, time.Second, rtt)
+}
@@ -6,13 +6,27 @@ import (
 	"crypto/sha256"
 	"hash"
 	"sync"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 )
 
 type statelessResetter struct {
 	mx sync.Mutex
 	h  hash.Hash
+	// hasKey is false if a random key is used
+	hasKey bool
+	// The previous key is used for sending stateless resets until it expires,
+	// since the peer might have received tokens derived from this key.
+	// Only the most recent previous key is kept: every stateless reset sent in response to a packet
+	// amplifies the traffic, so we only send up to two of them (see section 10.3 of RFC 9000).
+	prev *expiringStatelessResetHash
+}
+
+type expiringStatelessResetHash struct {
+	h      hash.Hash
+	expiry monotime.Time
 }
 
 // newStatelessRetter creates a new stateless reset generator.
@@ -27,16 +41,57 @@ func newStatelessResetter(key *StatelessResetKey) *statelessResetter {
 		_, _ = rand.Read(b)
 		h = hmac.New(sha256.New, b)
 	}
-	return &statelessResetter{h: h}
+	return &statelessResetter{h: h, hasKey: key != nil}
+}
+
+// RotateKey replaces the key used to derive stateless reset tokens.
+// The previous key continues to be used for sending stateless resets for the duration of the grace period.
+// Keys older than the previous key are not used anymore.
+func (r *statelessResetter) RotateKey(key StatelessResetKey, gracePeriod time.Duration) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.prev = nil
+	// The random key is never used for sending stateless resets, so there's no need to keep it around.
+	if gracePeriod > 0 && r.hasKey {
+		r.prev = &expiringStatelessResetHash{h: r.h, expiry: monotime.Now().Add(gracePeriod)}
+	}
+	r.h = hmac.New(sha256.New, key[:])
+	r.hasKey = true
+}
+
+// CanSendStatelessResets says if a stateless reset key was configured.
+func (r *statelessResetter) CanSendStatelessResets() bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	return r.hasKey
 }
 
 func (r *statelessResetter) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
 	r.mx.Lock()
 	defer r.mx.Unlock()
 
+	return getStatelessResetToken(r.h, connID)
+}
+
+// GetStatelessResetTokens returns the stateless reset token derived from the current key,
+// as well as the token derived from the previous key, if it hasn't expired yet.
+func (r *statelessResetter) GetStatelessResetTokens(connID protocol.ConnectionID) []protocol.StatelessResetToken {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	tokens := []protocol.StatelessResetToken{getStatelessResetToken(r.h, connID)}
+	if r.prev != nil && r.prev.expiry.After(monotime.Now()) {
+		tokens = append(tokens, getStatelessResetToken(r.prev.h, connID))
+	}
+	return tokens
+}
+
+func getStatelessResetToken(h hash.Hash, connID protocol.ConnectionID) protocol.StatelessResetToken {
 	var token protocol.StatelessResetToken
-	r.h.Write(connID.Bytes())
-	copy(token[:], r.h.Sum(nil))
-	r.h.Reset()
+	h.Write(connID.Bytes())
+	copy(token[:], h.Sum(nil))
+	h.Reset()
 	return token
 }
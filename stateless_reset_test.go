@@ -3,8 +3,11 @@ package quic
 import (
 	"crypto/rand"
 	"testing"
+	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/synctest"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,3 +43,42 @@ func TestStatelessResetter(t *testing.T) {
 		require.NotEqual(t, token, m.GetStatelessResetToken(connID2))
 	})
 }
+
+func TestStatelessResetterKeyRotation(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		key1 := StatelessResetKey{1}
+		key2 := StatelessResetKey{2}
+		key3 := StatelessResetKey{3}
+		token1 := newStatelessResetter(&key1).GetStatelessResetToken(connID)
+		token2 := newStatelessResetter(&key2).GetStatelessResetToken(connID)
+		token3 := newStatelessResetter(&key3).GetStatelessResetToken(connID)
+
+		r := newStatelessResetter(nil)
+		require.False(t, r.CanSendStatelessResets())
+		r.RotateKey(key1, 0)
+		require.True(t, r.CanSendStatelessResets())
+		require.Equal(t, token1, r.GetStatelessResetToken(connID))
+		require.Equal(t, []protocol.StatelessResetToken{token1}, r.GetStatelessResetTokens(connID))
+
+		r.RotateKey(key2, 10*time.Second)
+		require.Equal(t, token2, r.GetStatelessResetToken(connID))
+		require.Equal(t, []protocol.StatelessResetToken{token2, token1}, r.GetStatelessResetTokens(connID))
+
+		// only the most recent previous key is used, even if older keys haven't expired yet
+		time.Sleep(5 * time.Second)
+		r.RotateKey(key3, 10*time.Second)
+		require.Equal(t, token3, r.GetStatelessResetToken(connID))
+		require.Equal(t, []protocol.StatelessResetToken{token3, token2}, r.GetStatelessResetTokens(connID))
+
+		// key2 expires
+		time.Sleep(10 * time.Second)
+		require.Equal(t, []protocol.StatelessResetToken{token3}, r.GetStatelessResetTokens(connID))
+
+		// rotating without a grace period stops using the previous key immediately
+		r.RotateKey(key1, 10*time.Second)
+		require.Equal(t, []protocol.StatelessResetToken{token1, token3}, r.GetStatelessResetTokens(connID))
+		r.RotateKey(key2, 0)
+		require.Equal(t, []protocol.StatelessResetToken{token2}, r.GetStatelessResetTokens(connID))
+	})
+}
@@ -28,6 +28,7 @@ type streamSender interface {
 	onHasConnectionData()
 	onHasStreamData(protocol.StreamID, *SendStream)
 	onHasStreamControlFrame(protocol.StreamID, streamControlFrameGetter)
+	onStreamPriorityChanged(protocol.StreamID)
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -148,6 +149,17 @@ func (s *Stream) Close() error {
 	return s.sendStr.Close()
 }
 
+// SetPriority sets the priority of the stream.
+// See [SendStream.SetPriority] for more details.
+func (s *Stream) SetPriority(p StreamPriority) {
+	s.sendStr.SetPriority(p)
+}
+
+// Priority returns the priority of the stream.
+func (s *Stream) Priority() StreamPriority {
+	return s.sendStr.Priority()
+}
+
 func (s *Stream) handleResetStreamFrame(frame *wire.ResetStreamFrame, rcvTime monotime.Time) error {
 	return s.receiveStr.handleResetStreamFrame(frame, rcvTime)
 }
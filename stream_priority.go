@@ -0,0 +1,42 @@
+package quic
+
+// maxStreamUrgency is the lowest urgency a stream can have.
+const maxStreamUrgency = 7
+
+// StreamPriority is the priority of a stream.
+// It determines the order in which data is sent on the streams of a connection,
+// and has no effect on the receive side of a stream.
+// The model follows the Extensible Prioritization Scheme for HTTP (RFC 9218).
+type StreamPriority struct {
+	// Urgency is the urgency of the stream, from 0 (most urgent) to 7 (least urgent).
+	// Streams are scheduled in strict priority order:
+	// Data is only sent on a stream if no stream with a lower urgency value has data to send.
+	// Values larger than 7 are treated as 7.
+	Urgency uint8
+	// If Incremental is set, the stream shares the available bandwidth with the other incremental streams
+	// of the same urgency.
+	// Non-incremental streams are sent one after the other, in the order in which they became ready to send,
+	// before any incremental streams of the same urgency.
+	Incremental bool
+	// Weight is the share of the bandwidth an incremental stream receives,
+	// relative to other incremental streams of the same urgency:
+	// A stream with a weight of 2 is scheduled twice as often as a stream with a weight of 1.
+	// A weight of 0 is treated as 1.
+	// It has no effect for non-incremental streams.
+	Weight uint8
+}
+
+// DefaultStreamPriority is the priority of a newly opened stream.
+// Using the default priority, all streams share the bandwidth equally.
+var DefaultStreamPriority = StreamPriority{Urgency: 3, Incremental: true, Weight: 1}
+
+// queueIndex returns the index of the framer's stream queue for this priority.
+func (p StreamPriority) queueIndex() int {
+	idx := 2 * int(min(p.Urgency, maxStreamUrgency))
+	if p.Incremental {
+		idx++
+	}
+	return idx
+}
+
+func (p StreamPriority) weight() int { return max(int(p.Weight), 1) }
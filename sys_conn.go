@@ -21,8 +21,15 @@ type connCapabilities struct {
 	DF bool
 	// GSO (Generic Segmentation Offload) supported
 	GSO bool
+	// GRO (Generic Receive Offload) enabled
+	GRO bool
+	// Sending multiple packets using a single syscall (sendmmsg) supported
+	BatchWrite bool
 	// ECN (Explicit Congestion Notifications) supported
 	ECN bool
+	// Kernel pacing (SO_TXTIME) enabled.
+	// Packets can be handed to the kernel ahead of time, and are sent at the requested time.
+	KernelPacing bool
 }
 
 // rawConn is a connection that allow reading of a receivedPackeh.
@@ -32,6 +39,11 @@ type rawConn interface {
 	// gsoSize is the size of a single packet, or 0 to disable GSO.
 	// It is invalid to set gsoSize if capabilities.GSO is not set.
 	WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN) (int, error)
+	// WritePackets writes multiple packets on the wire, using as few syscalls as possible.
+	// It returns the number of packets written.
+	// If an error occurs, the packet that caused the error and all following packets are not written.
+	// It is invalid to call WritePackets if capabilities.BatchWrite is not set.
+	WritePackets([]outgoingPacket) (int, error)
 	LocalAddr() net.Addr
 	SetReadDeadline(time.Time) error
 	io.Closer
@@ -39,6 +51,20 @@ type rawConn interface {
 	capabilities() connCapabilities
 }
 
+// An outgoingPacket is a packet written using rawConn.WritePackets.
+type outgoingPacket struct {
+	data          []byte
+	addr          net.Addr
+	packetInfoOOB []byte
+	ecn           protocol.ECN
+	txTime        monotime.Time // zero if the packet should be sent right away
+}
+
+// A kernelPacingConn is a rawConn that can hand packets to the kernel ahead of time.
+type kernelPacingConn interface {
+	enableKernelPacing() error
+}
+
 // OOBCapablePacketConn is a connection that allows the reading of ECN bits from the IP header.
 // If the PacketConn passed to the [Transport] satisfies this interface, quic-go will use it.
 // In this case, ReadMsgUDP() will be used instead of ReadFrom() to read packets.
@@ -140,4 +166,8 @@ func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte, gsoSize uint1
 	return c.WriteTo(b, addr)
 }
 
+func (c *basicConn) WritePackets([]outgoingPacket) (int, error) {
+	panic("cannot use batched writes with a basicConn")
+}
+
 func (c *basicConn) capabilities() connCapabilities { return connCapabilities{DF: c.supportsDF} }
@@ -4,6 +4,7 @@ package quic
 
 import (
 	"encoding/binary"
+	"errors"
 	"net/netip"
 	"syscall"
 
@@ -21,6 +22,9 @@ const ecnIPv4DataLen = 4
 // see https://godoc.org/golang.org/x/net/ipv4#PacketConn.ReadBatch.
 const batchSize = 1
 
+// WriteBatch only writes a single packet on OSX.
+const sendmmsgSupported = false
+
 func parseIPv4PktInfo(body []byte) (ip netip.Addr, ifIndex uint32, ok bool) {
 	// struct in_pktinfo {
 	// 	unsigned int   ipi_ifindex;  /* Interface index */
@@ -35,4 +39,10 @@ func parseIPv4PktInfo(body []byte) (ip netip.Addr, ifIndex uint32, ok bool) {
 
 func isGSOEnabled(syscall.RawConn) bool { return false }
 
+func enableGRO(syscall.RawConn) bool { return false }
+
+func setTXTime(syscall.RawConn) error { return errors.New("SO_TXTIME not supported") }
+
+func parseUDPGROMsg(unix.Cmsghdr, []byte) (int, bool) { return 0, false }
+
 func isECNEnabled() bool { return !isECNDisabledUsingEnv() }
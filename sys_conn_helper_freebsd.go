@@ -3,6 +3,7 @@
 package quic
 
 import (
+	"errors"
 	"net/netip"
 	"syscall"
 
@@ -18,6 +19,8 @@ const ecnIPv4DataLen = 1
 
 const batchSize = 8
 
+const sendmmsgSupported = false
+
 func parseIPv4PktInfo(body []byte) (ip netip.Addr, _ uint32, ok bool) {
 	// struct in_pktinfo {
 	// 	struct in_addr ipi_addr;     /* Header Destination address */
@@ -30,4 +33,10 @@ func parseIPv4PktInfo(body []byte) (ip netip.Addr, _ uint32, ok bool) {
 
 func isGSOEnabled(syscall.RawConn) bool { return false }
 
+func enableGRO(syscall.RawConn) bool { return false }
+
+func setTXTime(syscall.RawConn) error { return errors.New("SO_TXTIME not supported") }
+
+func parseUDPGROMsg(unix.Cmsghdr, []byte) (int, bool) { return 0, false }
+
 func isECNEnabled() bool { return !isECNDisabledUsingEnv() }
@@ -8,10 +8,14 @@ import (
 	"net/netip"
 	"os"
 	"strconv"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/unix"
+
+	"github.com/quic-go/quic-go/internal/monotime"
 )
 
 const (
@@ -23,6 +27,8 @@ const ecnIPv4DataLen = 1
 
 const batchSize = 8 // needs to smaller than MaxUint8 (otherwise the type of oobConn.readPos has to be changed)
 
+const sendmmsgSupported = true
+
 var kernelVersionMajor int
 
 func init() {
@@ -80,6 +86,34 @@ func isGSOEnabled(conn syscall.RawConn) bool {
 	return serr == nil
 }
 
+// enableGRO enables UDP Generic Receive Offload (GRO) on the socket.
+// The kernel then coalesces datagrams received from the same sender into a single buffer,
+// and reports the segment size in a UDP_GRO control message.
+func enableGRO(conn syscall.RawConn) bool {
+	if kernelVersionMajor < 5 {
+		return false
+	}
+	disabled, err := strconv.ParseBool(os.Getenv("QUIC_GO_DISABLE_GRO"))
+	if err == nil && disabled {
+		return false
+	}
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, unix.UDP_GRO, 1)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// parseUDPGROMsg parses the segment size from a UDP_GRO control message.
+func parseUDPGROMsg(hdr unix.Cmsghdr, body []byte) (size int, ok bool) {
+	if hdr.Level != unix.IPPROTO_UDP || hdr.Type != unix.UDP_GRO || len(body) != 4 {
+		return 0, false
+	}
+	return int(binary.NativeEndian.Uint32(body)), true
+}
+
 func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
 	startLen := len(b)
 	const dataLen = 2 // payload is a uint16
@@ -95,6 +129,58 @@ func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
 	return b
 }
 
+// setTXTime enables SO_TXTIME on the socket.
+// Packets sent with a SCM_TXTIME control message are then released by the qdisc at the requested time.
+// This requires the fq qdisc, other qdiscs send packets right away.
+func setTXTime(conn syscall.RawConn) error {
+	// struct sock_txtime {
+	// 	__kernel_clockid_t clockid;
+	// 	__u32              flags;
+	// };
+	var txTime [8]byte
+	binary.NativeEndian.PutUint32(txTime[:4], unix.CLOCK_MONOTONIC)
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_TXTIME, string(txTime[:]))
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
+// clockMonotonicOffset is the offset between a monotime.Time and CLOCK_MONOTONIC.
+var clockMonotonicOffset = sync.OnceValue(func() time.Duration {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0
+	}
+	return time.Duration(ts.Nano() - int64(monotime.Now()))
+})
+
+func appendTXTimeMsg(b []byte, t monotime.Time) []byte {
+	startLen := len(b)
+	const dataLen = 8 // payload is a uint64, in nanoseconds of CLOCK_MONOTONIC
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = unix.SOL_SOCKET
+	h.Type = unix.SCM_TXTIME
+	h.SetLen(unix.CmsgLen(dataLen))
+
+	offset := startLen + unix.CmsgSpace(0)
+	binary.NativeEndian.PutUint64(b[offset:offset+dataLen], uint64(int64(t)+int64(clockMonotonicOffset())))
+	return b
+}
+
+// isTXTimeError checks if sending a packet with a SCM_TXTIME control message failed,
+// for example because SO_TXTIME is not enabled on the socket.
+func isTXTimeError(err error) bool {
+	var serr *os.SyscallError
+	if errors.As(err, &serr) {
+		return serr.Err == unix.EINVAL
+	}
+	return false
+}
+
 func isGSOError(err error) bool {
 	var serr *os.SyscallError
 	if errors.As(err, &serr) {
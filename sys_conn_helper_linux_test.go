@@ -3,10 +3,14 @@
 package quic
 
 import (
+	"encoding/binary"
 	"errors"
 	"net"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/monotime"
 
 	"golang.org/x/sys/unix"
 
@@ -16,6 +20,7 @@ import (
 var (
 	errGSO          = &os.SyscallError{Err: unix.EIO}
 	errNotPermitted = &os.SyscallError{Syscall: "sendmsg", Err: unix.EPERM}
+	errTXTime       = &os.SyscallError{Syscall: "sendmsg", Err: unix.EINVAL}
 )
 
 func TestForcingReceiveBufferSize(t *testing.T) {
@@ -77,3 +82,46 @@ func TestGSOError(t *testing.T) {
 	require.False(t, isGSOError(nil))
 	require.False(t, isGSOError(errors.New("test")))
 }
+
+func TestTXTimeError(t *testing.T) {
+	require.True(t, isTXTimeError(errTXTime))
+	require.False(t, isTXTimeError(errGSO))
+	require.False(t, isTXTimeError(nil))
+	require.False(t, isTXTimeError(errors.New("test")))
+}
+
+func TestSetTXTime(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer c.Close()
+	syscallConn, err := c.(*net.UDPConn).SyscallConn()
+	require.NoError(t, err)
+
+	require.NoError(t, setTXTime(syscallConn))
+	var txTime [8]byte
+	var serr error
+	require.NoError(t, syscallConn.Control(func(fd uintptr) {
+		var s string
+		s, serr = unix.GetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_TXTIME)
+		copy(txTime[:], s)
+	}))
+	require.NoError(t, serr)
+	require.Equal(t, uint32(unix.CLOCK_MONOTONIC), binary.NativeEndian.Uint32(txTime[:4]))
+}
+
+func TestAppendTXTimeMsg(t *testing.T) {
+	sendTime := monotime.Now().Add(3 * time.Millisecond)
+	b := appendTXTimeMsg([]byte{}, sendTime)
+	msgs, err := unix.ParseSocketControlMessage(b)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	require.Equal(t, int32(unix.SOL_SOCKET), msgs[0].Header.Level)
+	require.Equal(t, int32(unix.SCM_TXTIME), msgs[0].Header.Type)
+	require.Len(t, msgs[0].Data, 8)
+
+	var ts unix.Timespec
+	require.NoError(t, unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts))
+	delay := time.Duration(int64(binary.NativeEndian.Uint64(msgs[0].Data)) - ts.Nano())
+	require.Greater(t, delay, time.Duration(0))
+	require.LessOrEqual(t, delay, 3*time.Millisecond)
+}
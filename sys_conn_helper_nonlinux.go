@@ -2,9 +2,14 @@
 
 package quic
 
+import "github.com/quic-go/quic-go/internal/monotime"
+
 func forceSetReceiveBuffer(c any, bytes int) error { return nil }
 func forceSetSendBuffer(c any, bytes int) error    { return nil }
 
 func appendUDPSegmentSizeMsg([]byte, uint16) []byte { return nil }
 func isGSOError(error) bool                         { return false }
 func isPermissionError(err error) bool              { return false }
+
+func appendTXTimeMsg(b []byte, _ monotime.Time) []byte { return b }
+func isTXTimeError(error) bool                         { return false }
@@ -7,4 +7,5 @@ import "errors"
 var (
 	errGSO          = errors.New("fake GSO error")
 	errNotPermitted = errors.New("fake not permitted error")
+	errTXTime       = errors.New("fake SO_TXTIME error")
 )
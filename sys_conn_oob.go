@@ -5,6 +5,7 @@ package quic
 import (
 	"encoding/binary"
 	"errors"
+	"io"
 	"log"
 	"net"
 	"net/netip"
@@ -37,6 +38,10 @@ type batchConn interface {
 	ReadBatch(ms []ipv4.Message, flags int) (int, error)
 }
 
+type batchWriteConn interface {
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
 func inspectReadBuffer(c syscall.RawConn) (int, error) {
 	var size int
 	var serr error
@@ -66,13 +71,21 @@ func isECNDisabledUsingEnv() bool {
 
 type oobConn struct {
 	OOBCapablePacketConn
-	batchConn batchConn
+	batchConn      batchConn
+	batchWriteConn batchWriteConn
 
 	readPos uint8
 	// Packets received from the kernel, but not yet returned by ReadPacket().
 	messages []ipv4.Message
 	buffers  [batchSize]*packetBuffer
 
+	// When using GRO, a single message can contain multiple coalesced packets.
+	// These fields hold the remainder of the message that is currently being split.
+	groPacket      receivedPacket // the packet metadata shared by all segments
+	groBuffer      *packetBuffer
+	groRemainder   []byte
+	groSegmentSize int
+
 	cap connCapabilities
 }
 
@@ -129,10 +142,17 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 	// to make use of the optimisation. Otherwise, ipv4.NewPacketConn would unwrap the file descriptor
 	// via SyscallConn(), and read it that way, which might not be what the caller wants.
 	var bc batchConn
+	var bwc batchWriteConn
+	pc := ipv4.NewPacketConn(c)
 	if ibc, ok := c.(batchConn); ok {
 		bc = ibc
 	} else {
-		bc = ipv4.NewPacketConn(c)
+		bc = pc
+	}
+	if ibwc, ok := c.(batchWriteConn); ok {
+		bwc = ibwc
+	} else {
+		bwc = pc
 	}
 
 	msgs := make([]ipv4.Message, batchSize)
@@ -143,12 +163,16 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 	oobConn := &oobConn{
 		OOBCapablePacketConn: c,
 		batchConn:            bc,
+		batchWriteConn:       bwc,
 		messages:             msgs,
 		readPos:              batchSize,
 		cap: connCapabilities{
 			DF:  supportsDF,
 			GSO: isGSOEnabled(rawConn),
+			GRO: enableGRO(rawConn),
 			ECN: isECNEnabled(),
+			// Writing multiple packets in a single syscall only makes sense if the kernel supports sendmmsg.
+			BatchWrite: sendmmsgSupported,
 		},
 	}
 	for i := 0; i < batchSize; i++ {
@@ -160,12 +184,21 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 var invalidCmsgOnceV4, invalidCmsgOnceV6 sync.Once
 
 func (c *oobConn) ReadPacket() (receivedPacket, error) {
+	if len(c.groRemainder) > 0 {
+		return c.nextGROSegment(), nil
+	}
 	if len(c.messages) == int(c.readPos) { // all messages read. Read the next batch of messages.
 		c.messages = c.messages[:batchSize]
 		// replace buffers data buffers up to the packet that has been consumed during the last ReadBatch call
 		for i := uint8(0); i < c.readPos; i++ {
-			buffer := getPacketBuffer()
-			buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
+			var buffer *packetBuffer
+			if c.cap.GRO {
+				buffer = getGROBuffer()
+				buffer.Data = buffer.Data[:protocol.MaxGROBufferSize]
+			} else {
+				buffer = getPacketBuffer()
+				buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
+			}
 			c.buffers[i] = buffer
 			c.messages[i].Buffers[0] = c.buffers[i].Data
 		}
@@ -189,11 +222,15 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 		data:       msg.Buffers[0][:msg.N],
 		buffer:     buffer,
 	}
+	var groSegmentSize int
 	for len(data) > 0 {
 		hdr, body, remainder, err := unix.ParseOneSocketControlMessage(data)
 		if err != nil {
 			return receivedPacket{}, err
 		}
+		if size, ok := parseUDPGROMsg(hdr, body); ok {
+			groSegmentSize = size
+		}
 		if hdr.Level == unix.IPPROTO_IP {
 			switch hdr.Type {
 			case msgTypeIPTOS:
@@ -240,12 +277,100 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 		}
 		data = remainder
 	}
-	return p, nil
+	if !c.cap.GRO {
+		return p, nil
+	}
+	// The packets are not copied: they are slices of the GRO buffer,
+	// which is put back into the pool once all packets have been released.
+	if groSegmentSize <= 0 {
+		groSegmentSize = len(p.data)
+	}
+	c.groPacket = p
+	c.groBuffer = buffer
+	c.groRemainder = p.data
+	c.groSegmentSize = groSegmentSize
+	return c.nextGROSegment(), nil
+}
+
+// nextGROSegment returns the next packet of a message received using GRO.
+// The coalesced message is split into packets of the segment size, the last one might be shorter.
+func (c *oobConn) nextGROSegment() receivedPacket {
+	size := min(c.groSegmentSize, len(c.groRemainder))
+	// Datagrams larger than the packet buffer are truncated, as they would be without GRO.
+	// Limit the capacity, so that appending to one packet can't overwrite the next one.
+	dataLen := min(size, protocol.MaxPacketBufferSize)
+	data := c.groRemainder[:dataLen:dataLen]
+	buffer := c.groBuffer.newGROSegment(data)
+	c.groRemainder = c.groRemainder[size:]
+	if len(c.groRemainder) == 0 {
+		c.groBuffer.releaseGROSegment()
+		c.groBuffer = nil
+	}
+	p := c.groPacket
+	p.data = data
+	p.buffer = buffer
+	return p
 }
 
 // WritePacket writes a new packet.
 func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gsoSize uint16, ecn protocol.ECN) (int, error) {
-	oob := packetInfoOOB
+	oob := c.appendOOB(packetInfoOOB, addr, gsoSize, ecn)
+	n, _, err := c.WriteMsgUDP(b, oob, addr.(*net.UDPAddr))
+	return n, err
+}
+
+var writeMessagesPool = sync.Pool{
+	New: func() any {
+		ms := make([]ipv4.Message, batchSize)
+		for i := range ms {
+			ms[i].Buffers = make([][]byte, 1)
+			ms[i].OOB = make([]byte, 0, oobBufferSize)
+		}
+		return &ms
+	},
+}
+
+// WritePackets writes multiple packets using sendmmsg.
+func (c *oobConn) WritePackets(packets []outgoingPacket) (int, error) {
+	if !c.capabilities().BatchWrite {
+		panic("batched writes disabled")
+	}
+	msp := writeMessagesPool.Get().(*[]ipv4.Message)
+	defer writeMessagesPool.Put(msp)
+
+	var written int
+	for len(packets) > 0 {
+		ms := (*msp)[:min(len(packets), batchSize)]
+		for i := range ms {
+			p := packets[i]
+			ms[i].Buffers[0] = p.data
+			oob := append(ms[i].OOB[:0], p.packetInfoOOB...)
+			if !p.txTime.IsZero() {
+				oob = appendTXTimeMsg(oob, p.txTime)
+			}
+			ms[i].OOB = c.appendOOB(oob, p.addr, 0, p.ecn)
+			ms[i].Addr = p.addr
+		}
+		// The kernel might write fewer packets than requested.
+		// Continue with the remaining packets until all packets are written, or an error occurs.
+		for len(ms) > 0 {
+			n, err := c.batchWriteConn.WriteBatch(ms, 0)
+			n = max(n, 0) // sendmmsg returns -1 if the first message couldn't be written
+			written += n
+			packets = packets[n:]
+			if err != nil {
+				return written, err
+			}
+			if n == 0 {
+				return written, io.ErrShortWrite
+			}
+			ms = ms[n:]
+		}
+	}
+	return written, nil
+}
+
+func (c *oobConn) appendOOB(oob []byte, addr net.Addr, gsoSize uint16, ecn protocol.ECN) []byte {
 	if gsoSize > 0 {
 		if !c.capabilities().GSO {
 			panic("GSO disabled")
@@ -264,8 +389,23 @@ func (c *oobConn) WritePacket(b []byte, addr net.Addr, packetInfoOOB []byte, gso
 			}
 		}
 	}
-	n, _, err := c.WriteMsgUDP(b, oob, addr.(*net.UDPAddr))
-	return n, err
+	return oob
+}
+
+var _ kernelPacingConn = &oobConn{}
+
+// enableKernelPacing enables sending of packets at a specific time.
+// It must be called before the connection is used.
+func (c *oobConn) enableKernelPacing() error {
+	rawConn, err := c.OOBCapablePacketConn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	if err := setTXTime(rawConn); err != nil {
+		return err
+	}
+	c.cap.KernelPacing = true
+	return nil
 }
 
 func (c *oobConn) capabilities() connCapabilities {
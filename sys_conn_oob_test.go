@@ -5,14 +5,17 @@ package quic
 import (
 	"fmt"
 	"net"
+	"runtime"
 	"testing"
 	"time"
 
 	"golang.org/x/net/ipv4"
 	"golang.org/x/sys/unix"
 
+	"github.com/quic-go/quic-go/internal/monotime"
 	"github.com/quic-go/quic-go/internal/protocol"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -275,6 +278,7 @@ func (c *oobRecordingConn) WriteMsgUDP(b, oob []byte, addr *net.UDPAddr) (n, oob
 type mockBatchConn struct {
 	t          *testing.T
 	numMsgRead int
+	bufferSize int
 
 	callCounter int
 }
@@ -285,7 +289,7 @@ func (c *mockBatchConn) ReadBatch(ms []ipv4.Message, _ int) (int, error) {
 	require.Len(c.t, ms, batchSize)
 	for i := 0; i < c.numMsgRead; i++ {
 		require.Len(c.t, ms[i].Buffers, 1)
-		require.Len(c.t, ms[i].Buffers[0], protocol.MaxPacketBufferSize)
+		require.Len(c.t, ms[i].Buffers[0], c.bufferSize)
 		data := []byte(fmt.Sprintf("message %d", c.callCounter*c.numMsgRead+i))
 		ms[i].Buffers[0] = data
 		ms[i].N = len(data)
@@ -295,11 +299,13 @@ func (c *mockBatchConn) ReadBatch(ms []ipv4.Message, _ int) (int, error) {
 }
 
 func TestReadsMultipleMessagesInOneBatch(t *testing.T) {
-	bc := &mockBatchConn{t: t, numMsgRead: batchSize/2 + 1}
-
 	udpConn := newUDPConnLocalhost(t)
 	oobConn, err := newConn(udpConn, true)
 	require.NoError(t, err)
+	bc := &mockBatchConn{t: t, numMsgRead: batchSize/2 + 1, bufferSize: protocol.MaxPacketBufferSize}
+	if oobConn.capabilities().GRO {
+		bc.bufferSize = protocol.MaxGROBufferSize
+	}
 	oobConn.batchConn = bc
 
 	for i := 0; i < batchSize+1; i++ {
@@ -310,6 +316,80 @@ func TestReadsMultipleMessagesInOneBatch(t *testing.T) {
 	require.Equal(t, 2, bc.callCounter)
 }
 
+func TestSysConnReceiveGRO(t *testing.T) {
+	if !platformSupportsGSO {
+		t.Skip("GSO not supported on this platform")
+	}
+
+	addr, packetChan := runSysConnServer(t, "udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	require.NoError(t, err)
+	defer conn.Close()
+	// send 3 segments of 5 bytes, and a shorter last segment
+	oob := appendUDPSegmentSizeMsg(nil, 5)
+	_, _, err = conn.WriteMsgUDP([]byte("foo00bar00baz00qux"), oob, addr)
+	require.NoError(t, err)
+
+	var groBuffer *packetBuffer
+	for _, expected := range []string{"foo00", "bar00", "baz00", "qux"} {
+		select {
+		case p := <-packetChan:
+			require.Equal(t, expected, string(p.data))
+			require.Equal(t, conn.LocalAddr(), p.remoteAddr)
+			// the packets are not copied, but share the GRO buffer
+			require.NotNil(t, p.buffer.parent)
+			if groBuffer == nil {
+				groBuffer = p.buffer.parent
+			}
+			require.Same(t, groBuffer, p.buffer.parent)
+			require.Equal(t, len(p.data), cap(p.data))
+			p.buffer.Release()
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for packet")
+		}
+	}
+	require.Zero(t, groBuffer.numSegments.Load())
+}
+
+func BenchmarkSysConnReceiveGRO(b *testing.B) {
+	if !platformSupportsGSO {
+		b.Skip("GSO not supported on this platform")
+	}
+
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	require.NoError(b, err)
+	defer serverConn.Close()
+	oobConn, err := newConn(serverConn, true)
+	require.NoError(b, err)
+	if !oobConn.capabilities().GRO {
+		b.Skip("GRO not supported")
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	require.NoError(b, err)
+	defer conn.Close()
+
+	const segmentSize = 1200
+	const numSegments = 40
+	msg := make([]byte, segmentSize*numSegments)
+	oob := appendUDPSegmentSizeMsg(nil, segmentSize)
+
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _, err := conn.WriteMsgUDP(msg, oob, serverConn.LocalAddr().(*net.UDPAddr))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for range numSegments {
+			p, err := oobConn.ReadPacket()
+			if err != nil {
+				b.Fatal(err)
+			}
+			p.buffer.Release()
+		}
+	}
+}
+
 func TestSysConnSendGSO(t *testing.T) {
 	if !platformSupportsGSO {
 		t.Skip("GSO not supported on this platform")
@@ -332,3 +412,136 @@ func TestSysConnSendGSO(t *testing.T) {
 	// Check that the first control message is the OOB control message.
 	require.Equal(t, expected, oobMsg[:len(expected)])
 }
+
+func TestSysConnWritePackets(t *testing.T) {
+	udpConn := newUDPConnLocalhost(t)
+	oobConn, err := newConn(udpConn, true)
+	require.NoError(t, err)
+	if !oobConn.capabilities().BatchWrite {
+		t.Skip("batched writes not supported on this platform")
+	}
+
+	addr1, packetChan1 := runSysConnServer(t, "udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	addr2, packetChan2 := runSysConnServer(t, "udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	// more packets than fit into a single batch
+	var packets []outgoingPacket
+	for i := range batchSize + 2 {
+		p := outgoingPacket{data: []byte(fmt.Sprintf("packet %d", i)), addr: addr1, ecn: protocol.ECT1}
+		if i%2 == 1 {
+			p.addr = addr2
+			p.ecn = protocol.ECNCE
+		}
+		packets = append(packets, p)
+	}
+	n, err := oobConn.WritePackets(packets)
+	require.NoError(t, err)
+	require.Equal(t, len(packets), n)
+
+	for i := range batchSize + 2 {
+		packetChan := packetChan1
+		ecn := protocol.ECT1
+		if i%2 == 1 {
+			packetChan = packetChan2
+			ecn = protocol.ECNCE
+		}
+		select {
+		case p := <-packetChan:
+			require.Equal(t, fmt.Sprintf("packet %d", i), string(p.data))
+			require.Equal(t, udpConn.LocalAddr(), p.remoteAddr)
+			require.Equal(t, ecn, p.ecn)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for packet")
+		}
+	}
+}
+
+func TestSysConnKernelPacing(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_TXTIME is only supported on Linux")
+	}
+
+	udpConn := newUDPConnLocalhost(t)
+	oobConn, err := newConn(udpConn, true)
+	require.NoError(t, err)
+	require.False(t, oobConn.capabilities().KernelPacing)
+
+	addr, packetChan := runSysConnServer(t, "udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	packets := []outgoingPacket{
+		{data: []byte("foo"), addr: addr},
+		{data: []byte("bar"), addr: addr, txTime: monotime.Now().Add(time.Millisecond)},
+	}
+	// without SO_TXTIME, the kernel rejects packets with a send time
+	n, err := oobConn.WritePackets(packets)
+	require.Error(t, err)
+	require.True(t, isTXTimeError(err))
+	require.Equal(t, 1, n)
+
+	require.NoError(t, oobConn.enableKernelPacing())
+	require.True(t, oobConn.capabilities().KernelPacing)
+	n, err = oobConn.WritePackets(packets[1:])
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	_, err = oobConn.WritePacket([]byte("baz"), addr, appendTXTimeMsg(nil, monotime.Now().Add(time.Millisecond)), 0, protocol.ECNNon)
+	require.NoError(t, err)
+
+	for _, expected := range []string{"foo", "bar", "baz"} {
+		select {
+		case p := <-packetChan:
+			require.Equal(t, expected, string(p.data))
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for packet")
+		}
+	}
+}
+
+type mockBatchWriteConn struct {
+	written  []string
+	maxBatch int
+	err      error
+}
+
+func (c *mockBatchWriteConn) WriteBatch(ms []ipv4.Message, _ int) (int, error) {
+	if c.err != nil && len(c.written) >= c.maxBatch {
+		return 0, c.err
+	}
+	n := min(len(ms), c.maxBatch)
+	for _, m := range ms[:n] {
+		c.written = append(c.written, string(m.Buffers[0]))
+	}
+	return n, nil
+}
+
+func TestSysConnWritePacketsPartialWrites(t *testing.T) {
+	udpConn := newUDPConnLocalhost(t)
+	oobConn, err := newConn(udpConn, true)
+	require.NoError(t, err)
+	if !oobConn.capabilities().BatchWrite {
+		t.Skip("batched writes not supported on this platform")
+	}
+
+	var packets []outgoingPacket
+	var expected []string
+	for i := range batchSize + 3 {
+		data := fmt.Sprintf("packet %d", i)
+		packets = append(packets, outgoingPacket{data: []byte(data), addr: udpConn.LocalAddr()})
+		expected = append(expected, data)
+	}
+
+	t.Run("partial writes", func(t *testing.T) {
+		bwc := &mockBatchWriteConn{maxBatch: 3}
+		oobConn.batchWriteConn = bwc
+		n, err := oobConn.WritePackets(packets)
+		require.NoError(t, err)
+		require.Equal(t, len(packets), n)
+		require.Equal(t, expected, bwc.written)
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		bwc := &mockBatchWriteConn{maxBatch: 3, err: assert.AnError}
+		oobConn.batchWriteConn = bwc
+		n, err := oobConn.WritePackets(packets)
+		require.ErrorIs(t, err, assert.AnError)
+		require.Equal(t, 3, n)
+		require.Equal(t, expected[:3], bwc.written)
+	})
+}
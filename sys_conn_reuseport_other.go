@@ -0,0 +1,12 @@
+//go:build !darwin && !freebsd && !linux
+
+package quic
+
+import (
+	"errors"
+	"net"
+)
+
+func listenUDPReusePort(string, *net.UDPAddr) (*net.UDPConn, error) {
+	return nil, errors.New("quic: SO_REUSEPORT is not supported on this platform")
+}
@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -39,7 +40,10 @@ func (e *errTransportClosed) Is(target error) bool {
 	return ok
 }
 
-var errListenerAlreadySet = errors.New("listener already set")
+var (
+	errListenerAlreadySet    = errors.New("listener already set")
+	errTransportShuttingDown = errors.New("quic: transport is shutting down")
+)
 
 type closePacket struct {
 	payload []byte
@@ -65,10 +69,25 @@ type Transport struct {
 	//    This allows the remote node to speed up its loss detection and recovery.
 	// 3. It uses batched syscalls (recvmmsg) to more efficiently receive packets from the socket.
 	// 4. It uses Generic Segmentation Offload (GSO) to efficiently send batches of packets (on Linux).
+	//    If GSO is not available, it uses batched syscalls (sendmmsg) instead.
+	//    Packets are only batched with other packets sent on the same connection.
+	// 5. It uses Generic Receive Offload (GRO) to efficiently receive batches of packets (on Linux).
+	//    Received packets are not copied, but share a 64 KB receive buffer with the other packets of the same batch.
+	//    This increases memory usage: every socket holds 8 of these buffers (512 KB) while waiting for packets,
+	//    and a buffer is only freed once all packets sharing it have been processed.
+	//    GRO can be disabled by setting the QUIC_GO_DISABLE_GRO environment variable to true.
 	//
 	// After passing the connection to the Transport, it's invalid to call ReadFrom or WriteTo on the connection.
 	Conn net.PacketConn
 
+	// EnableKernelPacing hands packets to the kernel ahead of time, and lets the kernel send them
+	// at the time determined by the pacer (using SO_TXTIME, on Linux).
+	// This reduces the number of timer wakeups when sending at high rates.
+	// It requires the fq qdisc to be configured on the network interface.
+	// Other qdiscs ignore the requested send time, effectively disabling pacing.
+	// If the socket doesn't support SO_TXTIME, pacing is done in user space.
+	EnableKernelPacing bool
+
 	// The length of the connection ID in bytes.
 	// It can be any value between 1 and 20.
 	// Due to the increased risk of collisions, it is not recommended to use connection IDs shorter than 4 bytes.
@@ -84,6 +103,7 @@ type Transport struct {
 
 	// The StatelessResetKey is used to generate stateless reset tokens.
 	// If no key is configured, sending of stateless resets is disabled.
+	// The key can be rotated using RotateStatelessResetKey.
 	// It is highly recommended to configure a stateless reset key, as stateless resets
 	// allow the peer to quickly recover from crashes and reboots of this node.
 	// See section 10.3 of RFC 9000 for details.
@@ -93,6 +113,7 @@ type Transport struct {
 	// If no key is configured, a random key will be generated.
 	// If multiple servers are authoritative for the same domain, they should use the same key,
 	// see section 8.1.3 of RFC 9000 for details.
+	// The key can be rotated using RotateTokenGeneratorKey.
 	TokenGeneratorKey *TokenGeneratorKey
 
 	// MaxTokenAge is the maximum age of the resumption token presented during the handshake.
@@ -114,10 +135,37 @@ type Transport struct {
 	// of an attack.
 	// Validating the source address adds one additional network roundtrip to the handshake,
 	// and should therefore only be used if a suspiciously high number of incoming connection is recorded.
-	// For most use cases, wrapping the Allow function of a rate.Limiter will be a reasonable
-	// implementation of this callback (negating its return value).
+	// For most use cases, using the SourceAddressPolicy is preferable to implementing this callback.
+	// If both are set, a Retry is sent if either of them requires source address validation.
 	VerifySourceAddress func(net.Addr) bool
 
+	// SourceAddressPolicy protects the server from handshake floods.
+	// It requires source address validation when the handshake load is high,
+	// and refuses connection attempts beyond hard limits.
+	// It is recommended to use a SourceAddressPolicy on servers exposed to the public internet.
+	// See the documentation of SourceAddressPolicy for details.
+	SourceAddressPolicy *SourceAddressPolicy
+
+	// MaxConnections is the maximum number of concurrent incoming connections,
+	// including connections that are still handshaking and connections that were not yet accepted.
+	// Connection attempts beyond this limit are refused with a CONNECTION_REFUSED error.
+	// If zero, the number of connections is not limited.
+	MaxConnections int
+
+	// MaxConnectionsPerIP is the maximum number of concurrent incoming connections from a single client.
+	// Clients are identified by their IP address for IPv4, and by their /64 prefix for IPv6.
+	// Connection attempts beyond this limit are refused with a CONNECTION_REFUSED error.
+	// Note that the client's address is not validated, unless it is required to do so by
+	// VerifySourceAddress or the SourceAddressPolicy.
+	// If zero, the number of connections per client is not limited.
+	MaxConnectionsPerIP int
+
+	// MaxAcceptQueueSize is the maximum number of connections that have completed the handshake,
+	// but have not yet been accepted by the application.
+	// If the queue is full, new connections are closed with a CONNECTION_REFUSED error.
+	// If zero, it defaults to 32.
+	MaxAcceptQueueSize int
+
 	// ConnContext is called when the server accepts a new connection. To reject a connection return
 	// a non-nil error.
 	// The context is closed when the connection is closed, or when the handshake fails for any reason.
@@ -130,6 +178,29 @@ type Transport struct {
 	// It is not used for dialed connections.
 	ConnContext func(context.Context, *ClientInfo) (context.Context, error)
 
+	// ForwardConn and ForwardAddr are used when taking over the socket of a Transport running in
+	// a different process, for example when upgrading the server binary without dropping existing connections.
+	// If set, short header packets that can't be associated with any connection of this Transport
+	// are forwarded to ForwardAddr using ForwardConn, instead of being dropped (or being replied to
+	// with a stateless reset).
+	// The Transport that previously owned the socket receives these packets after calling HandOff.
+	// Packets are queued and written to the ForwardConn by a separate Go routine,
+	// such that a slow ForwardConn doesn't block reading from the socket.
+	// If the queue is full or writing a packet fails, the packet is dropped.
+	// Packets that can't be encapsulated are handled as if no ForwardConn was set.
+	// ForwardConn and ForwardAddr must be set before the Transport is first used.
+	// Once the other Transport has shut down, StopForwarding must be called,
+	// such that peers receive stateless resets for their connections.
+	ForwardConn net.PacketConn
+	ForwardAddr net.Addr
+
+	// OnShutdown is called for every connection when Shutdown is called,
+	// including connections that are still handshaking and connections dialed using this Transport.
+	// It can be used to send an application-level message announcing the shutdown (e.g. an HTTP/3 GOAWAY frame),
+	// or to close the connection.
+	// It is called sequentially for all connections, and must not block.
+	OnShutdown func(*Conn)
+
 	// A Tracer traces events that don't belong to a single QUIC connection.
 	// Recorder.Close is called when the transport is closed.
 	Tracer qlogwriter.Recorder
@@ -147,6 +218,7 @@ type Transport struct {
 	// If no ConnectionIDGenerator is set, this is set to a default.
 	connIDGenerator   ConnectionIDGenerator
 	statelessResetter *statelessResetter
+	tokenGenerator    *handshake.TokenGenerator
 
 	server *baseServer
 
@@ -154,12 +226,24 @@ type Transport struct {
 
 	closeQueue          chan closePacket
 	statelessResetQueue chan receivedPacket
+	forwardQueue        chan *packetBuffer
 
 	listening   chan struct{} // is closed when listen returns
 	closeErr    error
 	createdConn bool
 	isSingleUse bool // was created for a single server or client, i.e. by calling quic.Listen or quic.Dial
 
+	// set when Shutdown is called, closed once all connections are closed
+	shutdownConnsClosed chan struct{}
+	// set when HandOff is called
+	handoffConn      net.PacketConn
+	handoffForwarder net.Addr
+	// set when StopForwarding is called
+	forwardingStopped atomic.Bool
+
+	// set for Transports created by a TransportGroup
+	group *TransportGroup
+
 	readingNonQUICPackets atomic.Bool
 	nonQUICPackets        chan receivedPacket
 
@@ -202,9 +286,18 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	if t.closeErr != nil {
 		return nil, t.closeErr
 	}
+	if t.shutdownConnsClosed != nil {
+		return nil, errTransportShuttingDown
+	}
+	if t.handoffConn != nil {
+		return nil, errTransportHandedOff
+	}
 	if t.server != nil {
 		return nil, errListenerAlreadySet
 	}
+	if conf != nil && conf.PreferredAddress != nil && conf.PreferredAddress.Transport == t {
+		return nil, errors.New("quic: preferred address needs to use a different Transport")
+	}
 	conf = populateConfig(conf)
 	if err := t.init(false); err != nil {
 		return nil, err
@@ -213,6 +306,10 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	if maxTokenAge == 0 {
 		maxTokenAge = 24 * time.Hour
 	}
+	maxAcceptQueueSize := t.MaxAcceptQueueSize
+	if maxAcceptQueueSize <= 0 {
+		maxAcceptQueueSize = protocol.MaxAcceptQueueSize
+	}
 	s := newServer(
 		t.conn,
 		(*packetHandlerMap)(t),
@@ -223,9 +320,12 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		conf,
 		t.Tracer,
 		t.closeServer,
-		*t.TokenGeneratorKey,
+		t.tokenGenerator,
 		maxTokenAge,
 		t.VerifySourceAddress,
+		t.SourceAddressPolicy,
+		newConnLimiter(t.MaxConnections, t.MaxConnectionsPerIP),
+		maxAcceptQueueSize,
 		t.DisableVersionNegotiationPackets,
 		allow0RTT,
 	)
@@ -250,6 +350,9 @@ func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsCon
 	if err := validateConfig(conf); err != nil {
 		return nil, err
 	}
+	if conf != nil && conf.PreferredAddress != nil {
+		return nil, errors.New("quic: PreferredAddress is only valid for the server")
+	}
 	conf = populateConfig(conf)
 	tlsConf = tlsConf.Clone()
 	setTLSConfigServerName(tlsConf, addr, host)
@@ -291,6 +394,14 @@ func (t *Transport) doDial(
 		t.mutex.Unlock()
 		return nil, t.closeErr
 	}
+	if t.shutdownConnsClosed != nil {
+		t.mutex.Unlock()
+		return nil, errTransportShuttingDown
+	}
+	if t.handoffConn != nil {
+		t.mutex.Unlock()
+		return nil, errTransportHandedOff
+	}
 
 	var qlogTrace qlogwriter.Trace
 	if config.Tracer != nil {
@@ -390,6 +501,15 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 		}
 
 		t.logger = utils.DefaultLogger // TODO: make this configurable
+		if t.EnableKernelPacing {
+			if c, ok := conn.(kernelPacingConn); ok {
+				if err := c.enableKernelPacing(); err != nil {
+					t.logger.Debugf("Failed to enable kernel pacing: %s", err)
+				}
+			} else {
+				t.logger.Debugf("Kernel pacing not supported on this connection")
+			}
+		}
 		t.conn = conn
 		t.handlers = make(map[protocol.ConnectionID]packetHandler)
 		t.resetTokens = make(map[protocol.StatelessResetToken]packetHandler)
@@ -397,6 +517,9 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 
 		t.closeQueue = make(chan closePacket, 4)
 		t.statelessResetQueue = make(chan receivedPacket, 4)
+		if t.ForwardConn != nil && t.ForwardAddr != nil {
+			t.forwardQueue = make(chan *packetBuffer, maxForwardQueueLen)
+		}
 		if t.TokenGeneratorKey == nil {
 			var key TokenGeneratorKey
 			if _, err := rand.Read(key[:]); err != nil {
@@ -405,6 +528,7 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			}
 			t.TokenGeneratorKey = &key
 		}
+		t.tokenGenerator = handshake.NewTokenGenerator(*t.TokenGeneratorKey)
 
 		if t.ConnectionIDGenerator != nil {
 			t.connIDGenerator = t.ConnectionIDGenerator
@@ -428,6 +552,9 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			}
 		}()
 		go t.runSendQueue()
+		if t.forwardQueue != nil {
+			go t.runForwardQueue()
+		}
 	})
 	return t.initErr
 }
@@ -440,6 +567,38 @@ func (t *Transport) WriteTo(b []byte, addr net.Addr) (int, error) {
 	return t.conn.WritePacket(b, addr, nil, 0, protocol.ECNUnsupported)
 }
 
+// RotateStatelessResetKey replaces the key used to derive stateless reset tokens.
+// Tokens derived from the new key are used for all connection IDs issued after this call.
+// For the duration of the grace period, stateless resets are also sent using the previous key,
+// since peers might still use connection IDs issued before the rotation.
+// The grace period should therefore be at least as long as the lifetime of long-lived connections.
+// To limit the number of stateless resets sent in response to a single packet,
+// only the most recent previous key is used: rotating the key again ends the grace period of older keys.
+// If no StatelessResetKey was configured, this enables the sending of stateless resets.
+//
+// It is safe to call this method concurrently with all other methods of the Transport.
+func (t *Transport) RotateStatelessResetKey(key StatelessResetKey, gracePeriod time.Duration) error {
+	if err := t.init(false); err != nil {
+		return err
+	}
+	t.statelessResetter.RotateKey(key, gracePeriod)
+	return nil
+}
+
+// RotateTokenGeneratorKey replaces the key used to encrypt Retry tokens and session resumption tokens.
+// New tokens are encrypted using the new key.
+// For the duration of the grace period, tokens encrypted using the previous keys are still accepted.
+// Tokens older than MaxTokenAge are rejected, so there's no need to use a grace period longer than that.
+//
+// It is safe to call this method concurrently with all other methods of the Transport.
+func (t *Transport) RotateTokenGeneratorKey(key TokenGeneratorKey, gracePeriod time.Duration) error {
+	if err := t.init(false); err != nil {
+		return err
+	}
+	t.tokenGenerator.RotateKey(key, gracePeriod)
+	return nil
+}
+
 func (t *Transport) runSendQueue() {
 	for {
 		select {
@@ -465,6 +624,11 @@ func (t *Transport) Close() error {
 	t.init(false)
 
 	t.close(nil)
+	t.mutex.Lock()
+	if t.handoffConn != nil {
+		t.handoffConn.SetReadDeadline(time.Now())
+	}
+	t.mutex.Unlock()
 	if t.createdConn {
 		if err := t.Conn.Close(); err != nil {
 			return err
@@ -479,6 +643,90 @@ func (t *Transport) Close() error {
 	return nil
 }
 
+// Shutdown gracefully shuts down the Transport.
+// It stops accepting new connections: new connection attempts are refused with a CONNECTION_REFUSED error.
+// Connections that were already established continue to be served.
+// If set, the OnShutdown callback is called for every connection.
+// It is not possible to start a new server or dial new connections after Shutdown was called.
+//
+// Shutdown waits until all connections have been closed, and then calls Close.
+// If the context expires before all connections have been closed, the Transport is closed,
+// abruptly terminating the remaining connections, and the context's error is returned.
+func (t *Transport) Shutdown(ctx context.Context) error {
+	if err := t.init(false); err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	if t.closeErr != nil {
+		t.mutex.Unlock()
+		return nil
+	}
+	var conns []*Conn
+	if t.shutdownConnsClosed == nil {
+		t.shutdownConnsClosed = make(chan struct{})
+		if t.server != nil {
+			t.server.drain()
+		}
+		if t.OnShutdown != nil {
+			conns = t.connections()
+		}
+		t.maybeSignalConnsClosed()
+	}
+	connsClosed := t.shutdownConnsClosed
+	t.mutex.Unlock()
+
+	for _, conn := range conns {
+		t.OnShutdown(conn)
+	}
+
+	select {
+	case <-connsClosed:
+		return t.Close()
+	case <-ctx.Done():
+		t.Close()
+		return ctx.Err()
+	}
+}
+
+// connections returns all connections that haven't been closed yet.
+// It must be called with the mutex held.
+func (t *Transport) connections() []*Conn {
+	seen := make(map[*Conn]struct{}, len(t.handlers))
+	var conns []*Conn
+	for _, handler := range t.handlers {
+		var conn *Conn
+		switch h := handler.(type) {
+		case *Conn:
+			conn = h
+		case *wrappedConn:
+			conn = h.Conn
+		}
+		if conn == nil {
+			continue
+		}
+		if _, ok := seen[conn]; ok {
+			continue
+		}
+		seen[conn] = struct{}{}
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// maybeSignalConnsClosed signals Shutdown once all connections have been closed.
+// It must be called with the mutex held.
+func (t *Transport) maybeSignalConnsClosed() {
+	if t.shutdownConnsClosed == nil || len(t.handlers) > 0 {
+		return
+	}
+	select {
+	case <-t.shutdownConnsClosed:
+	default:
+		close(t.shutdownConnsClosed)
+	}
+}
+
 func (t *Transport) closeServer() {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -541,10 +789,15 @@ func (t *Transport) listen(conn rawConn) {
 		if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
 			t.mutex.Lock()
 			closed := t.closeErr != nil
+			handoffConn, handoffForwarder := t.handoffConn, t.handoffForwarder
 			t.mutex.Unlock()
 			if closed {
 				return
 			}
+			if handoffConn != nil {
+				t.listenForwarded(handoffConn, handoffForwarder)
+				return
+			}
 			t.logger.Debugf("Temporary error reading from conn: %w", err)
 			continue
 		}
@@ -563,6 +816,9 @@ func (t *Transport) listen(conn rawConn) {
 func (t *Transport) maybeStopListening() {
 	if t.isSingleUse && t.closeErr != nil {
 		t.conn.SetReadDeadline(time.Now())
+		if t.handoffConn != nil {
+			t.handoffConn.SetReadDeadline(time.Now())
+		}
 	}
 }
 
@@ -570,7 +826,7 @@ func (t *Transport) handlePacket(p receivedPacket) {
 	if len(p.data) == 0 {
 		return
 	}
-	if !wire.IsPotentialQUICPacket(p.data[0]) && !wire.IsLongHeaderPacket(p.data[0]) {
+	if !wire.IsPotentialQUICPacket(p.data[0]) && !wire.IsLongHeaderPacket(p.data[0]) && !t.isGreasedQUICPacket(p.data) {
 		t.handleNonQUICPacket(p)
 		return
 	}
@@ -586,6 +842,18 @@ func (t *Transport) handlePacket(p receivedPacket) {
 		p.buffer.MaybeRelease()
 		return
 	}
+	// If the packet belongs to a different Transport of the group, pass the packet there.
+	if t.group != nil && connID.Len() > 0 {
+		if owner := t.group.owner(connID); owner != t {
+			// The owner might still be initializing, if the group was only just created.
+			if err := owner.init(false); err != nil {
+				p.buffer.Release()
+				return
+			}
+			owner.handlePacket(p)
+			return
+		}
+	}
 
 	// If there's a connection associated with the connection ID, pass the packet there.
 	if handler, ok := (*packetHandlerMap)(t).Get(connID); ok {
@@ -603,6 +871,9 @@ func (t *Transport) handlePacket(p receivedPacket) {
 		return
 	}
 	if !wire.IsLongHeaderPacket(p.data[0]) {
+		if forwarded := t.maybeForwardPacket(p); forwarded {
+			return
+		}
 		if statelessResetQueued := t.maybeSendStatelessReset(p); !statelessResetQueued {
 			if t.Tracer != nil {
 				t.Tracer.RecordEvent(qlog.PacketDropped{
@@ -632,8 +903,28 @@ func (t *Transport) handlePacket(p receivedPacket) {
 	t.server.handlePacket(p)
 }
 
+// isGreasedQUICPacket checks if a short header packet that doesn't have the QUIC bit set belongs to a connection.
+// We advertise the grease_quic_bit transport parameter (RFC 9287), so peers might clear the QUIC bit.
+// Such packets can only be distinguished from non-QUIC packets by their connection ID.
+func (t *Transport) isGreasedQUICPacket(data []byte) bool {
+	connID, err := wire.ParseConnectionID(data, t.connIDLen)
+	if err != nil {
+		return false
+	}
+	owner := t
+	if t.group != nil && connID.Len() > 0 {
+		owner = t.group.owner(connID)
+	}
+	if _, ok := (*packetHandlerMap)(owner).Get(connID); ok {
+		return true
+	}
+	// Packets for connections that were handed off to another process are forwarded there,
+	// unless the application might be interested in non-QUIC packets.
+	return t.forwarding() && !t.readingNonQUICPackets.Load()
+}
+
 func (t *Transport) maybeSendStatelessReset(p receivedPacket) (statelessResetQueued bool) {
-	if t.StatelessResetKey == nil {
+	if !t.statelessResetter.CanSendStatelessResets() {
 		return false
 	}
 
@@ -660,14 +951,17 @@ func (t *Transport) sendStatelessReset(p receivedPacket) {
 		t.logger.Errorf("error parsing connection ID on packet from %s: %s", p.remoteAddr, err)
 		return
 	}
-	token := t.statelessResetter.GetStatelessResetToken(connID)
-	t.logger.Debugf("Sending stateless reset to %s (connection ID: %s). Token: %#x", p.remoteAddr, connID, token)
-	data := make([]byte, protocol.MinStatelessResetSize-16, protocol.MinStatelessResetSize)
-	rand.Read(data)
-	data[0] = (data[0] & 0x7f) | 0x40
-	data = append(data, token[:]...)
-	if _, err := t.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported); err != nil {
-		t.logger.Debugf("Error sending Stateless Reset to %s: %s", p.remoteAddr, err)
+	// During the grace period after a key rotation, the peer might have received a token derived from the previous key.
+	// Send one stateless reset for the current and one for the previous key.
+	for _, token := range t.statelessResetter.GetStatelessResetTokens(connID) {
+		t.logger.Debugf("Sending stateless reset to %s (connection ID: %s). Token: %#x", p.remoteAddr, connID, token)
+		data := make([]byte, protocol.MinStatelessResetSize-16, protocol.MinStatelessResetSize)
+		rand.Read(data)
+		data[0] = (data[0] & 0x7f) | 0x40
+		data = append(data, token[:]...)
+		if _, err := t.conn.WritePacket(data, p.remoteAddr, p.info.OOB(), 0, protocol.ECNUnsupported); err != nil {
+			t.logger.Debugf("Error sending Stateless Reset to %s: %s", p.remoteAddr, err)
+		}
 	}
 }
 
@@ -716,6 +1010,9 @@ const maxQueuedNonQUICPackets = 32
 // ReadNonQUICPacket reads non-QUIC packets received on the underlying connection.
 // The detection logic is very simple: Any packet that has the first and second bit of the packet set to 0.
 // Note that this is stricter than the detection logic defined in RFC 9443.
+// Since peers might clear the second bit (RFC 9287), packets whose connection ID belongs to a
+// connection on this Transport are never returned.
+// Setting Config.DisableQUICBitGreasing prevents peers from clearing the second bit.
 func (t *Transport) ReadNonQUICPacket(ctx context.Context, b []byte) (int, net.Addr, error) {
 	if err := t.init(false); err != nil {
 		return 0, nil, err
@@ -807,6 +1104,7 @@ func (h *packetHandlerMap) AddWithConnID(clientDestConnID, newConnID protocol.Co
 func (h *packetHandlerMap) Remove(id protocol.ConnectionID) {
 	h.mutex.Lock()
 	delete(h.handlers, id)
+	(*Transport)(h).maybeSignalConnsClosed()
 	h.mutex.Unlock()
 	h.logger.Debugf("Removing connection ID %s.", id)
 }
@@ -845,10 +1143,11 @@ func (h *packetHandlerMap) ReplaceWithClosed(ids []protocol.ConnectionID, connCl
 		for _, id := range ids {
 			delete(h.handlers, id)
 		}
+		t := (*Transport)(h)
 		if len(h.handlers) == 0 {
-			t := (*Transport)(h)
 			t.maybeStopListening()
 		}
+		t.maybeSignalConnsClosed()
 		h.mutex.Unlock()
 		h.logger.Debugf("Removing connection IDs %s for a closed connection after it has been retired.", ids)
 	})
@@ -0,0 +1,164 @@
+package quic
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+const maxTransportGroupSize = 256
+
+// A TransportGroup runs multiple Transports on the same local address,
+// each using its own UDP socket with SO_REUSEPORT set (Linux, macOS and FreeBSD).
+// This allows the kernel to distribute incoming packets across multiple sockets,
+// so that receiving and processing packets can scale beyond a single core.
+//
+// The kernel distributes packets based on the 4-tuple.
+// If the client's address changes (for example due to a NAT rebinding),
+// the packets of a connection might arrive on a different socket.
+// To handle this, every Transport in the group is assigned a worker ID,
+// which is encoded in the first byte of the connection IDs it issues.
+// Packets arriving on a socket are passed to the Transport that owns the connection ID.
+type TransportGroup struct {
+	conns      []*net.UDPConn
+	transports []*Transport
+}
+
+// NewTransportGroup opens n UDP sockets bound to addr, and creates a Transport for each of them.
+// If addr uses port 0, all sockets are bound to the same randomly chosen port.
+// n must be between 1 and 256.
+//
+// The configure callback (which may be nil) is called for every Transport before it is used.
+// It allows setting the Transport's options, for example the StatelessResetKey.
+// Since the TransportGroup relies on the connection IDs to route packets,
+// the callback must not set a ConnectionIDGenerator.
+// Unless configured otherwise, all Transports use the same TokenGeneratorKey,
+// such that a token issued by one Transport is accepted by all others.
+func NewTransportGroup(addr *net.UDPAddr, n int, configure func(*Transport)) (*TransportGroup, error) {
+	if n < 1 || n > maxTransportGroupSize {
+		return nil, fmt.Errorf("quic: invalid transport group size: %d", n)
+	}
+	var tokenGeneratorKey TokenGeneratorKey
+	if _, err := rand.Read(tokenGeneratorKey[:]); err != nil {
+		return nil, err
+	}
+
+	g := &TransportGroup{
+		conns:      make([]*net.UDPConn, 0, n),
+		transports: make([]*Transport, 0, n),
+	}
+	for i := range n {
+		// bind all sockets to the same port, even if addr uses port 0
+		bindAddr := addr
+		if i > 0 {
+			bindAddr = g.conns[0].LocalAddr().(*net.UDPAddr)
+		}
+		conn, err := listenUDPReusePort("udp", bindAddr)
+		if err != nil {
+			g.Close()
+			return nil, err
+		}
+		g.conns = append(g.conns, conn)
+
+		tr := &Transport{Conn: conn}
+		if configure != nil {
+			configure(tr)
+		}
+		if tr.ConnectionIDGenerator != nil {
+			g.Close()
+			return nil, errors.New("quic: transport group doesn't support a custom ConnectionIDGenerator")
+		}
+		if tr.ConnectionIDLength < 0 || tr.ConnectionIDLength > protocol.MaxConnIDLen {
+			g.Close()
+			return nil, fmt.Errorf("quic: invalid connection ID length: %d", tr.ConnectionIDLength)
+		}
+		connIDLen := tr.ConnectionIDLength
+		if connIDLen == 0 {
+			connIDLen = protocol.DefaultConnectionIDLength
+		}
+		tr.ConnectionIDGenerator = &workerConnIDGenerator{workerID: uint8(i), connIDLen: connIDLen}
+		if tr.TokenGeneratorKey == nil {
+			tr.TokenGeneratorKey = &tokenGeneratorKey
+		}
+		tr.group = g
+		g.transports = append(g.transports, tr)
+	}
+	for _, tr := range g.transports {
+		if err := tr.init(false); err != nil {
+			g.Close()
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Transports returns the Transports of the group.
+// They can be used to listen for incoming connections and to dial outgoing connections.
+func (g *TransportGroup) Transports() []*Transport {
+	return g.transports
+}
+
+// Listen starts listening for incoming QUIC connections on all Transports of the group.
+// It returns one Listener per Transport.
+func (g *TransportGroup) Listen(tlsConf *tls.Config, conf *Config) ([]*Listener, error) {
+	lns := make([]*Listener, 0, len(g.transports))
+	for _, tr := range g.transports {
+		ln, err := tr.Listen(tlsConf, conf)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// Close closes all Transports of the group, and the underlying sockets.
+func (g *TransportGroup) Close() error {
+	var errs []error
+	for _, tr := range g.transports {
+		if err := tr.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, conn := range g.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// owner returns the Transport responsible for a connection ID.
+// Connection IDs issued by a Transport of the group start with the Transport's worker ID.
+// Connection IDs chosen by the client (used on the first Initial packets)
+// are distributed across the Transports based on their first byte,
+// such that all packets using the same connection ID are handled by the same Transport.
+func (g *TransportGroup) owner(connID protocol.ConnectionID) *Transport {
+	return g.transports[int(connID.Bytes()[0])%len(g.transports)]
+}
+
+// The workerConnIDGenerator generates random connection IDs that start with the worker ID.
+type workerConnIDGenerator struct {
+	workerID  uint8
+	connIDLen int
+}
+
+var _ ConnectionIDGenerator = &workerConnIDGenerator{}
+
+func (g *workerConnIDGenerator) GenerateConnectionID() (ConnectionID, error) {
+	b := make([]byte, g.connIDLen)
+	if _, err := rand.Read(b[1:]); err != nil {
+		return ConnectionID{}, err
+	}
+	b[0] = g.workerID
+	return protocol.ParseConnectionID(b), nil
+}
+
+func (g *workerConnIDGenerator) ConnectionIDLen() int { return g.connIDLen }
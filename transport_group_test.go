@@ -0,0 +1,64 @@
+package quic
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerConnIDGenerator(t *testing.T) {
+	g := &workerConnIDGenerator{workerID: 42, connIDLen: 8}
+	require.Equal(t, 8, g.ConnectionIDLen())
+	connID1, err := g.GenerateConnectionID()
+	require.NoError(t, err)
+	require.Equal(t, 8, connID1.Len())
+	require.Equal(t, byte(42), connID1.Bytes()[0])
+	connID2, err := g.GenerateConnectionID()
+	require.NoError(t, err)
+	require.Equal(t, byte(42), connID2.Bytes()[0])
+	require.NotEqual(t, connID1, connID2)
+}
+
+func TestTransportGroupInvalidSize(t *testing.T) {
+	_, err := NewTransportGroup(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 0, nil)
+	require.EqualError(t, err, "quic: invalid transport group size: 0")
+	_, err = NewTransportGroup(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 257, nil)
+	require.EqualError(t, err, "quic: invalid transport group size: 257")
+}
+
+func TestTransportGroupPacketSteering(t *testing.T) {
+	g := &TransportGroup{}
+	for range 3 {
+		g.transports = append(g.transports, &Transport{Conn: newUDPConnLocalhost(t), group: g})
+	}
+	for _, tr := range g.transports {
+		require.NoError(t, tr.init(false))
+		defer tr.Close()
+	}
+
+	connChan := make(chan receivedPacket, 1)
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	(*packetHandlerMap)(g.transports[1]).Add(connID, &mockPacketHandler{packets: connChan})
+	// connection IDs that don't contain a valid worker ID are distributed based on their first byte
+	otherConnID := protocol.ParseConnectionID([]byte{4, 3, 2, 1})
+	require.Equal(t, g.transports[1], g.owner(otherConnID))
+	(*packetHandlerMap)(g.transports[1]).Add(otherConnID, &mockPacketHandler{packets: connChan})
+
+	conn := newUDPConnLocalhost(t)
+	for _, tr := range g.transports {
+		for _, id := range []protocol.ConnectionID{connID, otherConnID} {
+			_, err := conn.WriteTo(getPacket(t, id), tr.Conn.LocalAddr())
+			require.NoError(t, err)
+			select {
+			case p := <-connChan:
+				require.Equal(t, conn.LocalAddr(), p.remoteAddr)
+			case <-time.After(time.Second):
+				t.Fatal("timeout")
+			}
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"math"
 	"net"
+	"net/netip"
+	"os"
 	"runtime"
 	"strings"
 	"sync/atomic"
@@ -157,6 +159,55 @@ func TestTransportAndDialConcurrentClose(t *testing.T) {
 	}
 }
 
+func TestTransportShutdown(t *testing.T) {
+	tr := &Transport{Conn: newUDPConnLocalhost(t)}
+	require.NoError(t, tr.init(true))
+	defer tr.Close()
+
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	(*packetHandlerMap)(tr).Add(connID, &wrappedConn{testHooks: &connTestHooks{}})
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- tr.Shutdown(context.Background()) }()
+
+	// it's not possible to start a new server or dial new connections
+	require.Eventually(t, func() bool {
+		_, err := tr.Listen(&tls.Config{}, nil)
+		return errors.Is(err, errTransportShuttingDown)
+	}, time.Second, time.Millisecond)
+	_, err := tr.Dial(context.Background(), newUDPConnLocalhost(t).LocalAddr(), &tls.Config{}, nil)
+	require.ErrorIs(t, err, errTransportShuttingDown)
+
+	select {
+	case <-errChan:
+		t.Fatal("Shutdown returned before all connections were closed")
+	case <-time.After(scaleDuration(10 * time.Millisecond)):
+	}
+
+	(*packetHandlerMap)(tr).Remove(connID)
+	select {
+	case err := <-errChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	_, err = tr.Listen(&tls.Config{}, nil)
+	require.ErrorIs(t, err, ErrTransportClosed)
+}
+
+func TestTransportDialWithPreferredAddress(t *testing.T) {
+	tr := &Transport{Conn: newUDPConnLocalhost(t)}
+	defer tr.Close()
+
+	_, err := tr.Dial(
+		context.Background(),
+		newUDPConnLocalhost(t).LocalAddr(),
+		&tls.Config{},
+		&Config{PreferredAddress: &PreferredAddress{IPv4: netip.MustParseAddrPort("1.2.3.4:5678"), Transport: tr}},
+	)
+	require.EqualError(t, err, "quic: PreferredAddress is only valid for the server")
+}
+
 func TestTransportErrFromConn(t *testing.T) {
 	t.Setenv("QUIC_GO_DISABLE_RECEIVE_BUFFER_WARNING", "true")
 
@@ -209,7 +260,7 @@ func TestTransportStatelessResetReceiving(t *testing.T) {
 	connID := protocol.ParseConnectionID([]byte{9, 10, 11, 12})
 	// now send a packet with a connection ID that doesn't exist
 	token := protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
-	b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne)
+	b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, false)
 	require.NoError(t, err)
 	b = append(b, token[:]...)
 
@@ -248,7 +299,7 @@ func TestTransportStatelessResetSending(t *testing.T) {
 		connID := protocol.ParseConnectionID([]byte{9, 10, 11, 12})
 
 		// now send a packet with a connection ID that doesn't exist
-		b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne)
+		b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, false)
 		require.NoError(t, err)
 
 		// no stateless reset sent for packets smaller than MinStatelessResetSize
@@ -282,6 +333,248 @@ func TestTransportStatelessResetSending(t *testing.T) {
 	})
 }
 
+func TestTransportStatelessResetKeyRotation(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		const rtt = 10 * time.Millisecond
+		clientConn, serverConn, closeFn := newSimnetLink(t, rtt)
+		defer closeFn()
+
+		// no stateless reset key configured, sending of stateless resets is disabled
+		tr := &Transport{Conn: serverConn, ConnectionIDLength: 4}
+		tr.init(true)
+		defer tr.Close()
+
+		connID := protocol.ParseConnectionID([]byte{9, 10, 11, 12})
+		b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, false)
+		require.NoError(t, err)
+		packet := append(b, make([]byte, protocol.MinStatelessResetSize-len(b)+1)...)
+
+		receiveStatelessResets := func(t *testing.T) []protocol.StatelessResetToken {
+			t.Helper()
+			_, err = clientConn.WriteTo(packet, tr.Conn.LocalAddr())
+			require.NoError(t, err)
+
+			var tokens []protocol.StatelessResetToken
+			clientConn.SetReadDeadline(time.Now().Add(2 * rtt))
+			for {
+				p := make([]byte, 1024)
+				n, _, err := clientConn.ReadFrom(p)
+				if err != nil {
+					return tokens
+				}
+				tokens = append(tokens, protocol.StatelessResetToken(p[n-16:n]))
+			}
+		}
+
+		require.Empty(t, receiveStatelessResets(t))
+
+		key1 := StatelessResetKey{1, 2, 3, 4}
+		require.NoError(t, tr.RotateStatelessResetKey(key1, time.Hour))
+		token1 := newStatelessResetter(&key1).GetStatelessResetToken(connID)
+		require.Equal(t, []protocol.StatelessResetToken{token1}, receiveStatelessResets(t))
+
+		key2 := StatelessResetKey{5, 6, 7, 8}
+		require.NoError(t, tr.RotateStatelessResetKey(key2, time.Minute))
+		token2 := newStatelessResetter(&key2).GetStatelessResetToken(connID)
+		// during the grace period, a stateless reset is sent for the current and the previous key
+		require.ElementsMatch(t,
+			[]protocol.StatelessResetToken{token1, token2},
+			receiveStatelessResets(t),
+		)
+
+		// at most two stateless resets are sent, even if the key is rotated again
+		key3 := StatelessResetKey{9, 10, 11, 12}
+		require.NoError(t, tr.RotateStatelessResetKey(key3, time.Minute))
+		token3 := newStatelessResetter(&key3).GetStatelessResetToken(connID)
+		require.ElementsMatch(t,
+			[]protocol.StatelessResetToken{token2, token3},
+			receiveStatelessResets(t),
+		)
+
+		time.Sleep(time.Minute)
+		require.Equal(t, []protocol.StatelessResetToken{token3}, receiveStatelessResets(t))
+	})
+}
+
+func TestTransportPacketForwarding(t *testing.T) {
+	forwardConn := newUDPConnLocalhost(t)
+	forwardReceiver := newUDPConnLocalhost(t)
+	tr := &Transport{
+		Conn:               newUDPConnLocalhost(t),
+		ConnectionIDLength: 4,
+		StatelessResetKey:  &StatelessResetKey{1, 2, 3, 4},
+		ForwardConn:        forwardConn,
+		ForwardAddr:        forwardReceiver.LocalAddr(),
+	}
+	require.NoError(t, tr.init(true))
+	defer tr.Close()
+
+	// packets for unknown connection IDs are forwarded, instead of triggering a stateless reset
+	conn := newUDPConnLocalhost(t)
+	b, err := wire.AppendShortHeader(nil, protocol.ParseConnectionID([]byte{1, 2, 3, 4}), 1337, 2, protocol.KeyPhaseOne, false)
+	require.NoError(t, err)
+	packet := append(b, make([]byte, protocol.MinStatelessResetSize)...)
+	_, err = conn.WriteTo(packet, tr.Conn.LocalAddr())
+	require.NoError(t, err)
+
+	forwardReceiver.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 2000)
+	n, addr, err := forwardReceiver.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, forwardConn.LocalAddr(), addr)
+	clientAddr, _, data, err := parseForwardedPacket(buf[:n])
+	require.NoError(t, err)
+	require.Equal(t, conn.LocalAddr().String(), clientAddr.String())
+	require.Equal(t, packet, data)
+
+	// packets that have the QUIC bit cleared are forwarded as well
+	greased := append([]byte{}, packet...)
+	greased[0] &^= 0x40
+	_, err = conn.WriteTo(greased, tr.Conn.LocalAddr())
+	require.NoError(t, err)
+	n, _, err = forwardReceiver.ReadFrom(buf)
+	require.NoError(t, err)
+	_, _, data, err = parseForwardedPacket(buf[:n])
+	require.NoError(t, err)
+	require.Equal(t, greased, data)
+
+	// no stateless reset was sent
+	conn.SetReadDeadline(time.Now().Add(scaleDuration(10 * time.Millisecond)))
+	_, _, err = conn.ReadFrom(buf)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	// once forwarding is stopped, a stateless reset is sent
+	tr.StopForwarding()
+	_, err = conn.WriteTo(packet, tr.Conn.LocalAddr())
+	require.NoError(t, err)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err = conn.ReadFrom(buf)
+	require.NoError(t, err)
+	token := tr.statelessResetter.GetStatelessResetToken(protocol.ParseConnectionID([]byte{1, 2, 3, 4}))
+	require.Equal(t, token[:], buf[n-16:n])
+
+	forwardReceiver.SetReadDeadline(time.Now().Add(scaleDuration(10 * time.Millisecond)))
+	_, _, err = forwardReceiver.ReadFrom(buf)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+type blockingPacketConn struct {
+	net.PacketConn
+	unblock chan struct{}
+}
+
+func (c *blockingPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	<-c.unblock
+	return c.PacketConn.WriteTo(b, addr)
+}
+
+func TestTransportPacketForwardingNonBlocking(t *testing.T) {
+	forwardConn := &blockingPacketConn{PacketConn: newUDPConnLocalhost(t), unblock: make(chan struct{})}
+	forwardReceiver := newUDPConnLocalhost(t)
+	tr := &Transport{
+		Conn:               newUDPConnLocalhost(t),
+		ConnectionIDLength: 4,
+		ForwardConn:        forwardConn,
+		ForwardAddr:        forwardReceiver.LocalAddr(),
+	}
+	require.NoError(t, tr.init(true))
+	defer tr.Close()
+
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	packetChan := make(chan receivedPacket, 1)
+	(*packetHandlerMap)(tr).Add(connID, &wrappedConn{testHooks: &connTestHooks{
+		handlePacket: func(p receivedPacket) { packetChan <- p },
+	}})
+
+	// fill the forwarding queue while the ForwardConn is blocked
+	conn := newUDPConnLocalhost(t)
+	b, err := wire.AppendShortHeader(nil, protocol.ParseConnectionID([]byte{4, 3, 2, 1}), 1337, 2, protocol.KeyPhaseOne, false)
+	require.NoError(t, err)
+	unknownPacket := append(b, []byte("foobar")...)
+	for range maxForwardQueueLen + 10 {
+		_, err := conn.WriteTo(unknownPacket, tr.Conn.LocalAddr())
+		require.NoError(t, err)
+	}
+
+	// packets for known connections are still processed
+	b, err = wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, false)
+	require.NoError(t, err)
+	_, err = conn.WriteTo(append(b, []byte("foobar")...), tr.Conn.LocalAddr())
+	require.NoError(t, err)
+	select {
+	case <-packetChan:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// once the ForwardConn is unblocked, the queued packets are forwarded
+	close(forwardConn.unblock)
+	forwardReceiver.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 2000)
+	n, _, err := forwardReceiver.ReadFrom(buf)
+	require.NoError(t, err)
+	_, _, data, err := parseForwardedPacket(buf[:n])
+	require.NoError(t, err)
+	require.Equal(t, unknownPacket, data)
+}
+
+func TestTransportHandOff(t *testing.T) {
+	tr := &Transport{Conn: newUDPConnLocalhost(t), ConnectionIDLength: 4}
+	require.NoError(t, tr.init(true))
+	defer tr.Close()
+
+	connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+	packetChan := make(chan receivedPacket, 2)
+	(*packetHandlerMap)(tr).Add(connID, &wrappedConn{testHooks: &connTestHooks{
+		handlePacket: func(p receivedPacket) { packetChan <- p },
+	}})
+
+	forwarded := newUDPConnLocalhost(t)
+	forwarder := newUDPConnLocalhost(t)
+	require.NoError(t, tr.HandOff(forwarded, forwarder.LocalAddr()))
+	require.ErrorIs(t, tr.HandOff(forwarded, forwarder.LocalAddr()), errTransportHandedOff)
+	_, err := tr.Listen(&tls.Config{}, nil)
+	require.ErrorIs(t, err, errTransportHandedOff)
+
+	b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, false)
+	require.NoError(t, err)
+	packet := append(b, []byte("foobar")...)
+
+	// packets received on the socket are not processed any more
+	conn := newUDPConnLocalhost(t)
+	_, err = conn.WriteTo(packet, tr.Conn.LocalAddr())
+	require.NoError(t, err)
+
+	// packets forwarded from any other address are dropped
+	clientAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}
+	fb, err := appendForwardedPacket(nil, receivedPacket{remoteAddr: clientAddr, data: packet, ecn: protocol.ECNCE})
+	require.NoError(t, err)
+	_, err = newUDPConnLocalhost(t).WriteTo(fb, forwarded.LocalAddr())
+	require.NoError(t, err)
+
+	// but packets forwarded from the new owner of the socket are processed
+	_, err = forwarder.WriteTo(fb, forwarded.LocalAddr())
+	require.NoError(t, err)
+
+	select {
+	case p := <-packetChan:
+		require.Equal(t, clientAddr.String(), p.remoteAddr.String())
+		require.Equal(t, packet, p.data)
+		require.Equal(t, protocol.ECNCE, p.ecn)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	select {
+	case <-packetChan:
+		t.Fatal("didn't expect the packet received on the socket or from an unexpected address to be processed")
+	case <-time.After(scaleDuration(10 * time.Millisecond)):
+	}
+
+	// closing the Transport stops reading forwarded packets
+	(*packetHandlerMap)(tr).Remove(connID)
+	require.NoError(t, tr.Close())
+}
+
 func TestTransportUnparseableQUICPackets(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		const rtt = 10 * time.Millisecond
@@ -431,6 +724,59 @@ func TestTransportNonQUICPackets(t *testing.T) {
 	})
 }
 
+func TestTransportGreasedQUICBit(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		const rtt = 10 * time.Millisecond
+		clientConn, serverConn, closeFn := newSimnetLink(t, rtt)
+		defer closeFn()
+
+		tr := &Transport{Conn: serverConn, ConnectionIDLength: 4}
+		require.NoError(t, tr.init(true))
+		defer tr.Close()
+
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4})
+		connChan := make(chan receivedPacket, 1)
+		(*packetHandlerMap)(tr).Add(connID, &mockPacketHandler{packets: connChan})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, _, err := tr.ReadNonQUICPacket(ctx, make([]byte, 1024))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		getShortHeaderPacket := func(connID protocol.ConnectionID) []byte {
+			b, err := wire.AppendShortHeader(nil, connID, 1337, 2, protocol.KeyPhaseOne, false)
+			require.NoError(t, err)
+			b[0] &^= 0x40 // clear the QUIC bit
+			return append(b, bytes.Repeat([]byte{'f'}, 100)...)
+		}
+
+		// a packet without the QUIC bit for a known connection ID is passed to the connection
+		greased := getShortHeaderPacket(connID)
+		_, err = clientConn.WriteTo(greased, tr.Conn.LocalAddr())
+		require.NoError(t, err)
+		time.Sleep(rtt) // so that the packet arrives at the server
+		synctest.Wait()
+		select {
+		case p := <-connChan:
+			require.Equal(t, greased, p.data)
+		default:
+			t.Fatal("packet should have been passed to the connection")
+		}
+
+		// a packet without the QUIC bit for an unknown connection ID is treated as a non-QUIC packet
+		nonQUIC := getShortHeaderPacket(protocol.ParseConnectionID([]byte{5, 6, 7, 8}))
+		_, err = clientConn.WriteTo(nonQUIC, tr.Conn.LocalAddr())
+		require.NoError(t, err)
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		b := make([]byte, 1500)
+		n, _, err := tr.ReadNonQUICPacket(ctx, b)
+		require.NoError(t, err)
+		require.Equal(t, nonQUIC, b[:n])
+		require.Empty(t, connChan)
+	})
+}
+
 type faultySyscallConn struct{ net.PacketConn }
 
 func (c *faultySyscallConn) SyscallConn() (syscall.RawConn, error) { return nil, assert.AnError }
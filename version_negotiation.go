@@ -0,0 +1,81 @@
+package quic
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+	"github.com/quic-go/quic-go/qlog"
+)
+
+// canUpgradeVersion says if the client can switch to the version of a packet received from the server,
+// using compatible version negotiation (RFC 9368).
+// This is only possible for the first Initial packet received from the server.
+func (c *Conn) canUpgradeVersion(hdr *wire.Header) bool {
+	return c.perspective == protocol.PerspectiveClient &&
+		!c.receivedFirstPacket &&
+		c.version == c.originalVersion &&
+		hdr.Type == protocol.PacketTypeInitial &&
+		protocol.IsSupportedVersion(c.config.Versions, hdr.Version) &&
+		protocol.IsCompatibleVersion(c.version, hdr.Version)
+}
+
+// acceptsOriginalVersion says if the server accepts a packet using the client's original version.
+// After compatible version negotiation, the client keeps using the original version
+// until it receives the server's first Initial packet, so retransmissions of its Initial packets
+// need to be accepted until the handshake is confirmed.
+func (c *Conn) acceptsOriginalVersion(hdr *wire.Header) bool {
+	return c.perspective == protocol.PerspectiveServer &&
+		!c.handshakeConfirmed &&
+		hdr.Version == c.originalVersion
+}
+
+// upgradeVersion is called when the connection was upgraded to a compatible version.
+// For the client, this happens when the first Initial packet from the server was successfully decrypted.
+// For the server, this happens when processing the client's transport parameters.
+func (c *Conn) upgradeVersion(v protocol.Version) {
+	c.logger.Infof("Upgrading to compatible QUIC version %s.", v)
+	c.version = v
+	c.connStateMutex.Lock()
+	c.connState.Version = v
+	c.connStateMutex.Unlock()
+	// The client records the version information when processing the first packet.
+	if c.perspective == protocol.PerspectiveServer && c.qlogger != nil {
+		c.qlogger.RecordEvent(qlog.VersionInformation{
+			ChosenVersion:  v,
+			ServerVersions: c.config.Versions,
+		})
+	}
+}
+
+// checkVersionInformation validates the peer's version_information transport parameter,
+// see section 4 of RFC 9368.
+func (c *Conn) checkVersionInformation(vi *wire.VersionInformation) error {
+	if c.perspective == protocol.PerspectiveServer {
+		if vi != nil && vi.ChosenVersion != c.originalVersion {
+			return fmt.Errorf("chosen version %s doesn't match the version of the client's Initial packet (%s)", vi.ChosenVersion, c.originalVersion)
+		}
+		return nil
+	}
+
+	if vi == nil {
+		// Without the server's version information, it's not possible to detect a downgrade attack.
+		if c.versionNegotiated || c.version != c.originalVersion {
+			return errors.New("missing version_information after version negotiation")
+		}
+		return nil
+	}
+	if vi.ChosenVersion != c.version {
+		return fmt.Errorf("chosen version %s doesn't match the version of the connection (%s)", vi.ChosenVersion, c.version)
+	}
+	// If we acted on a Version Negotiation packet, we need to make sure that we would have chosen the same version
+	// based on the server's available versions.
+	// Otherwise, the Version Negotiation packet might have been injected by an attacker.
+	if c.versionNegotiated {
+		if v, ok := protocol.ChooseSupportedVersion(c.config.Versions, vi.AvailableVersions); !ok || v != c.originalVersion {
+			return fmt.Errorf("version downgrade detected: chose %s, server supports %s", c.originalVersion, vi.AvailableVersions)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package quic
+
+import (
+	"testing"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionUpgradeClient(t *testing.T) {
+	c := &Conn{
+		perspective:     protocol.PerspectiveClient,
+		version:         protocol.Version1,
+		originalVersion: protocol.Version1,
+		config:          &Config{Versions: []protocol.Version{protocol.Version1, protocol.Version2}},
+		logger:          utils.DefaultLogger,
+	}
+	hdr := &wire.Header{Type: protocol.PacketTypeInitial, Version: protocol.Version2}
+	require.True(t, c.canUpgradeVersion(hdr))
+	// only Initial packets can change the version
+	require.False(t, c.canUpgradeVersion(&wire.Header{Type: protocol.PacketTypeHandshake, Version: protocol.Version2}))
+	// the version needs to be supported
+	require.False(t, c.canUpgradeVersion(&wire.Header{Type: protocol.PacketTypeInitial, Version: 0x1234}))
+
+	c.receivedFirstPacket = true
+	require.False(t, c.canUpgradeVersion(hdr))
+	c.receivedFirstPacket = false
+
+	c.upgradeVersion(protocol.Version2)
+	require.Equal(t, protocol.Version2, c.version)
+	// the version can only be changed once
+	require.False(t, c.canUpgradeVersion(&wire.Header{Type: protocol.PacketTypeInitial, Version: protocol.Version1}))
+}
+
+func TestVersionUpgradeServer(t *testing.T) {
+	c := &Conn{
+		perspective:     protocol.PerspectiveServer,
+		version:         protocol.Version1,
+		originalVersion: protocol.Version1,
+		config:          &Config{Versions: []protocol.Version{protocol.Version2, protocol.Version1}},
+		logger:          utils.DefaultLogger,
+	}
+	// servers never switch versions based on packets received from the client
+	require.False(t, c.canUpgradeVersion(&wire.Header{Type: protocol.PacketTypeInitial, Version: protocol.Version2}))
+
+	c.upgradeVersion(protocol.Version2)
+	// packets using the original version are accepted until the handshake is confirmed
+	hdr := &wire.Header{Type: protocol.PacketTypeInitial, Version: protocol.Version1}
+	require.True(t, c.acceptsOriginalVersion(hdr))
+	require.False(t, c.canUpgradeVersion(hdr))
+	require.False(t, c.acceptsOriginalVersion(&wire.Header{Type: protocol.PacketTypeInitial, Version: 0x1234}))
+	c.handshakeConfirmed = true
+	require.False(t, c.acceptsOriginalVersion(hdr))
+}
+
+func TestVersionInformationValidation(t *testing.T) {
+	versions := []protocol.Version{protocol.Version1, protocol.Version2}
+
+	for _, tc := range []struct {
+		name              string
+		perspective       protocol.Perspective
+		version           protocol.Version
+		originalVersion   protocol.Version
+		versionNegotiated bool
+		versionInfo       *wire.VersionInformation
+		expectedErr       string
+	}{
+		{
+			name:            "server, without version information",
+			perspective:     protocol.PerspectiveServer,
+			version:         protocol.Version1,
+			originalVersion: protocol.Version1,
+		},
+		{
+			name:            "server, after upgrading the version",
+			perspective:     protocol.PerspectiveServer,
+			version:         protocol.Version2,
+			originalVersion: protocol.Version1,
+			versionInfo:     &wire.VersionInformation{ChosenVersion: protocol.Version1, AvailableVersions: versions},
+		},
+		{
+			name:            "server, chosen version doesn't match",
+			perspective:     protocol.PerspectiveServer,
+			version:         protocol.Version1,
+			originalVersion: protocol.Version1,
+			versionInfo:     &wire.VersionInformation{ChosenVersion: protocol.Version2, AvailableVersions: versions},
+			expectedErr:     "chosen version v2 doesn't match the version of the client's Initial packet (v1)",
+		},
+		{
+			name:            "client, without version information",
+			perspective:     protocol.PerspectiveClient,
+			version:         protocol.Version1,
+			originalVersion: protocol.Version1,
+		},
+		{
+			name:            "client, after upgrading the version",
+			perspective:     protocol.PerspectiveClient,
+			version:         protocol.Version2,
+			originalVersion: protocol.Version1,
+			versionInfo:     &wire.VersionInformation{ChosenVersion: protocol.Version2, AvailableVersions: versions},
+		},
+		{
+			name:            "client, chosen version doesn't match",
+			perspective:     protocol.PerspectiveClient,
+			version:         protocol.Version1,
+			originalVersion: protocol.Version1,
+			versionInfo:     &wire.VersionInformation{ChosenVersion: protocol.Version2, AvailableVersions: versions},
+			expectedErr:     "chosen version v2 doesn't match the version of the connection (v1)",
+		},
+		{
+			name:            "client, missing version information after upgrading the version",
+			perspective:     protocol.PerspectiveClient,
+			version:         protocol.Version2,
+			originalVersion: protocol.Version1,
+			expectedErr:     "missing version_information after version negotiation",
+		},
+		{
+			name:              "client, missing version information after version negotiation",
+			perspective:       protocol.PerspectiveClient,
+			version:           protocol.Version2,
+			originalVersion:   protocol.Version2,
+			versionNegotiated: true,
+			expectedErr:       "missing version_information after version negotiation",
+		},
+		{
+			name:              "client, after version negotiation",
+			perspective:       protocol.PerspectiveClient,
+			version:           protocol.Version2,
+			originalVersion:   protocol.Version2,
+			versionNegotiated: true,
+			versionInfo:       &wire.VersionInformation{ChosenVersion: protocol.Version2, AvailableVersions: []protocol.Version{protocol.Version2}},
+		},
+		{
+			name:              "client, downgrade after version negotiation",
+			perspective:       protocol.PerspectiveClient,
+			version:           protocol.Version2,
+			originalVersion:   protocol.Version2,
+			versionNegotiated: true,
+			versionInfo:       &wire.VersionInformation{ChosenVersion: protocol.Version2, AvailableVersions: versions},
+			expectedErr:       "version downgrade detected: chose v2, server supports [v1 v2]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Conn{
+				perspective:       tc.perspective,
+				version:           tc.version,
+				originalVersion:   tc.originalVersion,
+				versionNegotiated: tc.versionNegotiated,
+				config:            &Config{Versions: versions},
+				logger:            utils.DefaultLogger,
+			}
+			err := c.checkVersionInformation(tc.versionInfo)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}